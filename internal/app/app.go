@@ -14,14 +14,22 @@ import (
 
 
 func Run(version string) {
-	app := fx.New(
+	app := fx.New(Options(version, infra.Module))
+
+	app.Run()
+}
+
+// Options assembles the application around the given infrastructure module,
+// so tests can swap external services for in-memory ones.
+func Options(version string, infrastructure fx.Option) fx.Option {
+	return fx.Options(
 
 		fx.Supply(version),
 
 		// Core modules
 		config.Module,
 		logger.Module,
-		infra.Module,
+		infrastructure,
 
 		// Feature modules
 		FeatureModules,
@@ -30,8 +38,6 @@ func Run(version string) {
 
 		fx.Invoke(bootstrap),
 	)
-
-	app.Run()
 }
 
 // bootstrap is called after all dependencies are initialized
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/fx"
+
+	"cms-api/internal/config"
+	"cms-api/internal/infra"
+	authrepo "cms-api/internal/modules/auth/repo"
+	discoveryrepo "cms-api/internal/modules/discovery/repo"
+	importerrepo "cms-api/internal/modules/importer/repo"
+	programrepo "cms-api/internal/modules/program/repo"
+	queuerepo "cms-api/internal/modules/queue/repo"
+	retentionrepo "cms-api/internal/modules/retention/repo"
+	searchsettingsrepo "cms-api/internal/modules/searchsettings/repo"
+	workerrepo "cms-api/internal/modules/worker/repo"
+)
+
+// Hermetic assembles the application with no external services: in-memory
+// cache and search, and every repository backed by one shared memdb.Store.
+// Servers listen on ephemeral loopback ports and the queue workers poll
+// quickly. configure, if set, adjusts the loaded config further.
+//
+// The SQL repository constructors are never called, so no database is needed.
+func Hermetic(version string, configure func(cfg *config.Config)) fx.Option {
+	return fx.Options(
+		Options(version, infra.MemoryModule),
+		fx.Decorate(func(cfg *config.Config) *config.Config {
+			c := *cfg
+			c.HTTP.Host, c.HTTP.Port = "127.0.0.1", 0
+			c.GRPC.Host, c.GRPC.Port = "127.0.0.1", 0
+			c.Telemetry.Enabled = false
+			c.Worker.PollInterval = 50 * time.Millisecond
+			c.Storage.LocalRoot = filepath.Join(os.TempDir(), "cms-api-hermetic")
+			if configure != nil {
+				configure(&c)
+			}
+			return &c
+		}),
+		fx.Decorate(
+			authrepo.NewMemory,
+			programrepo.NewMemory,
+			discoveryrepo.NewMemory,
+			queuerepo.NewMemory,
+			queuerepo.NewMemoryListener,
+			workerrepo.NewMemory,
+			importerrepo.NewMemory,
+			retentionrepo.NewMemory,
+			searchsettingsrepo.NewMemory,
+		),
+	)
+}
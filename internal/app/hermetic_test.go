@@ -0,0 +1,304 @@
+package app
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+
+	"cms-api/internal/config"
+	"cms-api/internal/pkg/crypto"
+)
+
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// startHermetic boots the hermetic app and returns its handler and an admin
+// token.
+func startHermetic(t *testing.T) (http.Handler, string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	pubPath := filepath.Join(t.TempDir(), "public.pem")
+	if err := os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}), 0o600); err != nil {
+		t.Fatalf("write public key: %v", err)
+	}
+	storageRoot := t.TempDir()
+
+	var handler http.Handler
+	app := fxtest.New(t,
+		Hermetic("test", func(cfg *config.Config) {
+			cfg.JWT.PublicKeyPath = pubPath
+			cfg.Storage.LocalRoot = storageRoot
+			cfg.Log.Level = "error"
+		}),
+		fx.Populate(&handler),
+	)
+	app.RequireStart()
+	t.Cleanup(app.RequireStop)
+
+	token, err := crypto.GenerateToken(key, map[string]any{
+		"sub":   "00000000-0000-0000-0000-000000000001",
+		"email": "admin@test.com",
+		"roles": []string{"admin"},
+	}, time.Minute)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	return handler, token
+}
+
+func TestHermetic_IndexesCreatedProgram(t *testing.T) {
+	handler, token := startHermetic(t)
+
+	body, _ := json.Marshal(map[string]any{
+		"title":        "Hermetic boot check",
+		"program_type": "podcast",
+		"duration":     "01:02:03",
+		"category_id":  1,
+		"language_id":  2,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/programs/", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	decode(t, w, &created)
+
+	w = get(handler, "/api/v1/discover/programs/"+created.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("discover: expected 200, got %d: %s", w.Code, w.Body)
+	}
+
+	query := url.Values{"q": {"hermetic"}, "type": {"podcast"}, "language": {"en"}}
+	searchURL := "/api/v1/discover/programs/search?" + query.Encode()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w = get(handler, searchURL)
+		if w.Code != http.StatusOK {
+			t.Fatalf("search: expected 200, got %d: %s", w.Code, w.Body)
+		}
+		var result struct {
+			Items []struct {
+				ID       string  `json:"id"`
+				Duration *string `json:"duration"`
+			} `json:"items"`
+		}
+		decode(t, w, &result)
+
+		if len(result.Items) == 1 {
+			if result.Items[0].ID != created.ID {
+				t.Fatalf("search: unexpected hit %s", result.Items[0].ID)
+			}
+			if d := result.Items[0].Duration; d == nil || *d != "01:02:03" {
+				t.Fatalf("search: unexpected duration %v", d)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("search: program was not indexed")
+		}
+		time.Sleep(25 * time.Millisecond)
+	}
+
+	// The worker has indexed the program, so its suggestion is in place too.
+	w = get(handler, "/api/v1/discover/programs/suggest?q=Hermetic+bo")
+	if w.Code != http.StatusOK {
+		t.Fatalf("suggest: expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var suggestions struct {
+		Titles []struct {
+			ID string `json:"id"`
+		} `json:"titles"`
+	}
+	decode(t, w, &suggestions)
+	if len(suggestions.Titles) != 1 || suggestions.Titles[0].ID != created.ID {
+		t.Fatalf("suggest: unexpected titles %+v", suggestions.Titles)
+	}
+
+	w = get(handler, "/api/v1/discover/programs/suggest?q=%D9%88%D8%AB%D8%A7")
+	var categories struct {
+		Categories []struct {
+			Slug string `json:"slug"`
+		} `json:"categories"`
+	}
+	decode(t, w, &categories)
+	if len(categories.Categories) != 1 || categories.Categories[0].Slug != "documentary" {
+		t.Fatalf("suggest: unexpected categories %+v", categories.Categories)
+	}
+
+	// "وثايق" only matches "وثائقي" once hamza variants are normalized.
+	w = get(handler, "/api/v1/discover/programs/suggest?q="+url.QueryEscape("وثايق"))
+	categories.Categories = nil
+	decode(t, w, &categories)
+	if len(categories.Categories) != 1 || categories.Categories[0].Slug != "documentary" {
+		t.Fatalf("suggest: normalized query missed category, got %+v", categories.Categories)
+	}
+}
+
+func TestHermetic_SearchPointsAtTranscriptCue(t *testing.T) {
+	handler, token := startHermetic(t)
+
+	body, _ := json.Marshal(map[string]any{"title": "Cue check", "program_type": "podcast"})
+	w := send(handler, http.MethodPost, "/api/v1/programs/", token, body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body)
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	decode(t, w, &created)
+
+	vtt := []byte("WEBVTT\n\n00:00:00.000 --> 00:00:02.000\nWelcome\n\n00:00:02.000 --> 00:00:05.000\nWe reach the oasis\n")
+	if w := send(handler, http.MethodPut, "/api/v1/programs/"+created.ID+"/transcript?format=vtt", token, vtt); w.Code != http.StatusOK {
+		t.Fatalf("upload transcript: expected 200, got %d: %s", w.Code, w.Body)
+	}
+
+	type cue struct {
+		StartMS int64  `json:"start_ms"`
+		Text    string `json:"text"`
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var result struct {
+			Items []struct {
+				ID              string `json:"id"`
+				TranscriptMatch *cue   `json:"transcript_match"`
+			} `json:"items"`
+		}
+		decode(t, get(handler, "/api/v1/discover/programs/search?q=oasis"), &result)
+
+		if len(result.Items) == 1 && result.Items[0].TranscriptMatch != nil {
+			if m := result.Items[0].TranscriptMatch; result.Items[0].ID != created.ID || m.StartMS != 2000 || m.Text != "We reach the oasis" {
+				t.Fatalf("search: unexpected hit %+v", result.Items[0])
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("search: transcript cue was not matched, got %+v", result.Items)
+		}
+		time.Sleep(25 * time.Millisecond)
+	}
+}
+
+func TestHermetic_ReindexSwapsInRebuiltIndex(t *testing.T) {
+	handler, token := startHermetic(t)
+
+	for _, title := range []string{"Reindex first", "Reindex second"} {
+		body, _ := json.Marshal(map[string]any{"title": title, "program_type": "podcast"})
+		if w := send(handler, http.MethodPost, "/api/v1/programs/", token, body); w.Code != http.StatusCreated {
+			t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body)
+		}
+	}
+
+	if w := send(handler, http.MethodPost, "/api/v1/search/reindex/", "", nil); w.Code != http.StatusUnauthorized {
+		t.Fatalf("reindex without token: expected 401, got %d", w.Code)
+	}
+	w := send(handler, http.MethodPost, "/api/v1/search/reindex/", token, nil)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("reindex: expected 202, got %d: %s", w.Code, w.Body)
+	}
+	type reindexRun struct {
+		ID               string  `json:"id"`
+		Status           string  `json:"status"`
+		TotalDocuments   int     `json:"total_documents"`
+		IndexedDocuments int     `json:"indexed_documents"`
+		Progress         float64 `json:"progress"`
+		SwappedAt        *string `json:"swapped_at"`
+		Error            string  `json:"error"`
+	}
+	var run reindexRun
+	decode(t, w, &run)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for run.Status != "completed" {
+		if run.Status == "failed" {
+			t.Fatalf("reindex failed: %s", run.Error)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("reindex did not complete, last status %q", run.Status)
+		}
+		time.Sleep(25 * time.Millisecond)
+
+		w = send(handler, http.MethodGet, "/api/v1/search/reindex/"+run.ID, token, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("reindex status: expected 200, got %d: %s", w.Code, w.Body)
+		}
+		decode(t, w, &run)
+	}
+	if run.TotalDocuments != 2 || run.IndexedDocuments != 2 || run.Progress != 1 || run.SwappedAt == nil {
+		t.Fatalf("unexpected completed run %+v", run)
+	}
+
+	var latest reindexRun
+	decode(t, send(handler, http.MethodGet, "/api/v1/search/reindex/", token, nil), &latest)
+	if latest.ID != run.ID {
+		t.Fatalf("latest run = %s, want %s", latest.ID, run.ID)
+	}
+
+	var result struct {
+		EstimatedTotal int64 `json:"estimated_total"`
+	}
+	decode(t, get(handler, "/api/v1/discover/programs/search?q=reindex"), &result)
+	if result.EstimatedTotal != 2 {
+		t.Fatalf("search after swap: expected 2 hits, got %d", result.EstimatedTotal)
+	}
+}
+
+func send(h http.Handler, method, target, token string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func get(h http.Handler, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+
+	var env envelope
+	if err := json.Unmarshal(w.Body.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	if err := json.Unmarshal(env.Data, v); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+}
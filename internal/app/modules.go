@@ -7,13 +7,19 @@ import (
 	"cms-api/internal/modules/discovery"
 	"cms-api/internal/modules/importer"
 	"cms-api/internal/modules/program"
+	"cms-api/internal/modules/queue"
+	"cms-api/internal/modules/retention"
+	"cms-api/internal/modules/searchsettings"
 	"cms-api/internal/modules/worker"
 )
 
 var FeatureModules = fx.Options(
 	auth.Module,
+	queue.Module,
 	worker.Module,
+	searchsettings.Module,
 	program.Module,
 	discovery.Module,
 	importer.Module,
+	retention.Module,
 )
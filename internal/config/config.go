@@ -16,7 +16,11 @@ type Config struct {
 	Telemetry TelemetryConfig
 	Search    SearchConfig
 	Worker    WorkerConfig
+	Retention RetentionConfig
 	Cache     CacheConfig
+	Storage   StorageConfig
+	Feed      FeedConfig
+	SEO       SEOConfig
 }
 
 type AppConfig struct {
@@ -25,6 +29,11 @@ type AppConfig struct {
 	Debug        bool
 	Version      string
 	AssetBaseURL string
+	// PublicURL is the externally reachable base URL of this API, used when
+	// rendering absolute links (feeds, sitemaps).
+	PublicURL string
+	// SiteURL is the public web front-end that renders programs.
+	SiteURL string
 }
 
 type HTTPConfig struct {
@@ -90,10 +99,31 @@ type TelemetryConfig struct {
 	SampleRate   float64
 }
 
+const (
+	SearchBackendMeilisearch = "meilisearch"
+	SearchBackendPostgres    = "postgres"
+	// SearchBackendAuto prefers Meilisearch and degrades to Postgres
+	// full-text search while Meilisearch fails its health check.
+	SearchBackendAuto = "auto"
+)
+
 type SearchConfig struct {
 	Host      string
 	Port      int
 	MasterKey string
+
+	Backend        string
+	HealthInterval time.Duration
+
+	// Search hits mark matched words with these tags and crop long
+	// descriptions to CropLength words around the first match.
+	HighlightPreTag  string
+	HighlightPostTag string
+	CropLength       int
+
+	// SuggestTimeout bounds a search-as-you-type lookup; suggestions that
+	// arrive later are no longer useful to the user typing.
+	SuggestTimeout time.Duration
 }
 
 func (c SearchConfig) Addr() string {
@@ -104,6 +134,52 @@ type WorkerConfig struct {
 	PollInterval time.Duration
 	BatchSize    int
 	MaxAttempts  int
+	// Queues maps each job queue the worker runs to how many of its batches
+	// may run at once. Jobs on a queue missing here are never run.
+	Queues map[string]int
+	// Concurrency bounds the batches running at once across all queues.
+	Concurrency int
+	// JobTimeout bounds a single handler call, after which its context is
+	// canceled and the jobs in it fail.
+	JobTimeout time.Duration
+	// TaskTimeout bounds the wait for the search engine to apply a write
+	// before the jobs behind it are retried.
+	TaskTimeout time.Duration
+	// LeaseDuration is how long claimed jobs stay reserved for their worker
+	// without a heartbeat before another worker may take them back.
+	LeaseDuration time.Duration
+
+	// ReindexBatchSize is the number of programs sent per request during a
+	// full reindex. A running reindex that has not reported progress for
+	// ReindexStaleAfter is taken over by another worker. Consistency checks
+	// page and are taken over the same way.
+	ReindexBatchSize  int
+	ReindexStaleAfter time.Duration
+
+	// ConsistencyInterval is how often the worker compares the programs
+	// index with Postgres, zero to only check on request. With
+	// ConsistencyRepair set it also enqueues index jobs for what it finds.
+	ConsistencyInterval time.Duration
+	ConsistencyRepair   bool
+}
+
+// RetentionConfig drives the scheduler that deletes rows once they are older
+// than their retention period. A period of zero keeps those rows forever.
+type RetentionConfig struct {
+	Enabled  bool
+	Interval time.Duration
+	// BatchSize bounds the rows one DELETE removes and BatchPause is the wait
+	// between batches, so clearing a backlog never holds locks for long.
+	BatchSize  int
+	BatchPause time.Duration
+
+	// CompletedJobs and DeadJobs count from a job's last update, RefreshTokens
+	// from a token's expiry or revocation, and ImportLogs from the end of the
+	// import.
+	CompletedJobs time.Duration
+	DeadJobs      time.Duration
+	RefreshTokens time.Duration
+	ImportLogs    time.Duration
 }
 
 type CacheConfig struct {
@@ -111,12 +187,38 @@ type CacheConfig struct {
 	Port     int
 	Password string
 	DB       int
+
+	// LocalSize bounds the in-process tier in entries; zero disables it.
+	LocalSize int
+	LocalTTL  time.Duration
 }
 
 func (c CacheConfig) Addr() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
+type StorageConfig struct {
+	LocalRoot        string
+	MediaCacheMaxAge time.Duration
+}
+
+type FeedConfig struct {
+	Author         string
+	OwnerEmail     string
+	Image          string
+	Language       string
+	ITunesCategory string
+	Explicit       bool
+	MaxItems       int
+	CacheTTL       time.Duration
+}
+
+type SEOConfig struct {
+	// SitemapPageSize is the number of URLs per sitemap file, 1 to 50000.
+	SitemapPageSize int
+	SitemapCacheTTL time.Duration
+}
+
 func (c *Config) IsDevelopment() bool {
 	return c.App.Env == "development" || c.App.Env == "dev"
 }
@@ -9,6 +9,9 @@ import (
 
 	"github.com/joho/godotenv"
 	"go.uber.org/fx"
+
+	queueentity "cms-api/internal/modules/queue/entity"
+	workerentity "cms-api/internal/modules/worker/entity"
 )
 
 var Module = fx.Module("config",
@@ -19,6 +22,11 @@ func LoadConfig() (*Config, error) {
 	_ = godotenv.Load()
 	var missing []string
 
+	queues, err := getEnvIntMap("WORKER_QUEUES", map[string]int{queueentity.DefaultQueue: 2, workerentity.IndexJobQueue: 1})
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
 		App: AppConfig{
 			Name:         getEnv("APP_NAME", "cms-api"),
@@ -26,6 +34,8 @@ func LoadConfig() (*Config, error) {
 			Debug:        getEnvBool("APP_DEBUG", true),
 			Version:      getEnv("APP_VERSION", "1.0.0"),
 			AssetBaseURL: getEnv("ASSET_BASE_URL", ""),
+			PublicURL:    strings.TrimRight(getEnv("APP_PUBLIC_URL", "http://localhost:8080"), "/"),
+			SiteURL:      strings.TrimRight(getEnv("APP_SITE_URL", "http://localhost:3000"), "/"),
 		},
 		HTTP: HTTPConfig{
 			Host:            getEnv("HTTP_HOST", "0.0.0.0"),
@@ -77,17 +87,70 @@ func LoadConfig() (*Config, error) {
 			Host:      getEnv("MEILI_HOST", "meilisearch"),
 			Port:      getEnvInt("MEILI_PORT", 7700),
 			MasterKey: getEnv("MEILI_MASTER_KEY", ""),
+
+			Backend:        getEnv("SEARCH_BACKEND", SearchBackendAuto),
+			HealthInterval: getEnvDuration("SEARCH_HEALTH_INTERVAL", 15*time.Second),
+
+			HighlightPreTag:  getEnv("SEARCH_HIGHLIGHT_PRE_TAG", "<mark>"),
+			HighlightPostTag: getEnv("SEARCH_HIGHLIGHT_POST_TAG", "</mark>"),
+			CropLength:       getEnvInt("SEARCH_CROP_LENGTH", 30),
+
+			SuggestTimeout: getEnvDuration("SEARCH_SUGGEST_TIMEOUT", 300*time.Millisecond),
 		},
 		Worker: WorkerConfig{
 			PollInterval: getEnvDuration("WORKER_POLL_INTERVAL", 5*time.Second),
 			BatchSize:    getEnvInt("WORKER_BATCH_SIZE", 10),
 			MaxAttempts:  getEnvInt("WORKER_MAX_ATTEMPTS", 5),
+			Queues:       queues,
+			Concurrency:  getEnvInt("WORKER_CONCURRENCY", 4),
+			JobTimeout:   getEnvDuration("WORKER_JOB_TIMEOUT", 5*time.Minute),
+			TaskTimeout:  getEnvDuration("WORKER_TASK_TIMEOUT", 30*time.Second),
+
+			LeaseDuration: getEnvDuration("WORKER_LEASE_DURATION", 2*time.Minute),
+
+			ReindexBatchSize:  getEnvInt("WORKER_REINDEX_BATCH_SIZE", 500),
+			ReindexStaleAfter: getEnvDuration("WORKER_REINDEX_STALE_AFTER", 10*time.Minute),
+
+			ConsistencyInterval: getEnvDuration("WORKER_CONSISTENCY_INTERVAL", 0),
+			ConsistencyRepair:   getEnvBool("WORKER_CONSISTENCY_REPAIR", false),
+		},
+		Retention: RetentionConfig{
+			Enabled:    getEnvBool("RETENTION_ENABLED", true),
+			Interval:   getEnvDuration("RETENTION_INTERVAL", time.Hour),
+			BatchSize:  getEnvInt("RETENTION_BATCH_SIZE", 1000),
+			BatchPause: getEnvDuration("RETENTION_BATCH_PAUSE", 100*time.Millisecond),
+
+			CompletedJobs: getEnvDuration("RETENTION_COMPLETED_JOBS", 7*24*time.Hour),
+			DeadJobs:      getEnvDuration("RETENTION_DEAD_JOBS", 30*24*time.Hour),
+			RefreshTokens: getEnvDuration("RETENTION_REFRESH_TOKENS", 7*24*time.Hour),
+			ImportLogs:    getEnvDuration("RETENTION_IMPORT_LOGS", 90*24*time.Hour),
 		},
 		Cache: CacheConfig{
 			Host:     getEnv("REDIS_HOST", "redis"),
 			Port:     getEnvInt("REDIS_PORT", 6379),
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getEnvInt("REDIS_DB", 0),
+
+			LocalSize: getEnvInt("CACHE_LOCAL_SIZE", 10000),
+			LocalTTL:  getEnvDuration("CACHE_LOCAL_TTL", 30*time.Second),
+		},
+		Storage: StorageConfig{
+			LocalRoot:        getEnv("STORAGE_LOCAL_ROOT", "storage"),
+			MediaCacheMaxAge: getEnvDuration("STORAGE_MEDIA_CACHE_MAX_AGE", 24*time.Hour),
+		},
+		Feed: FeedConfig{
+			Author:         getEnv("FEED_AUTHOR", "cms-api"),
+			OwnerEmail:     getEnv("FEED_OWNER_EMAIL", ""),
+			Image:          getEnv("FEED_IMAGE", ""),
+			Language:       getEnv("FEED_LANGUAGE", "ar"),
+			ITunesCategory: getEnv("FEED_ITUNES_CATEGORY", "Society & Culture"),
+			Explicit:       getEnvBool("FEED_EXPLICIT", false),
+			MaxItems:       getEnvInt("FEED_MAX_ITEMS", 300),
+			CacheTTL:       getEnvDuration("FEED_CACHE_TTL", 5*time.Minute),
+		},
+		SEO: SEOConfig{
+			SitemapPageSize: getEnvInt("SEO_SITEMAP_PAGE_SIZE", 50000),
+			SitemapCacheTTL: getEnvDuration("SEO_SITEMAP_CACHE_TTL", time.Hour),
 		},
 	}
 
@@ -101,6 +164,34 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
 	}
 
+	// Jobs are enqueued on these queues without checking that a worker runs
+	// them: index jobs by the program triggers, the rest by default.
+	for _, queue := range []string{queueentity.DefaultQueue, workerentity.IndexJobQueue} {
+		if _, ok := cfg.Worker.Queues[queue]; !ok {
+			return nil, fmt.Errorf("WORKER_QUEUES must include the %q queue", queue)
+		}
+	}
+	if cfg.Worker.PollInterval <= 0 {
+		return nil, fmt.Errorf("WORKER_POLL_INTERVAL must be positive, got %s", cfg.Worker.PollInterval)
+	}
+	// Claimed jobs are heartbeated every third of a lease; shorter leases
+	// would be reaped before their first heartbeat.
+	if cfg.Worker.LeaseDuration < time.Second {
+		return nil, fmt.Errorf("WORKER_LEASE_DURATION must be at least 1s, got %s", cfg.Worker.LeaseDuration)
+	}
+
+	if cfg.Retention.Enabled && cfg.Retention.Interval <= 0 {
+		return nil, fmt.Errorf("RETENTION_INTERVAL must be positive, got %s", cfg.Retention.Interval)
+	}
+	if cfg.Retention.BatchSize < 1 {
+		return nil, fmt.Errorf("RETENTION_BATCH_SIZE must be at least 1, got %d", cfg.Retention.BatchSize)
+	}
+
+	// The sitemap protocol allows at most 50,000 URLs per file.
+	if n := cfg.SEO.SitemapPageSize; n < 1 || n > 50000 {
+		return nil, fmt.Errorf("SEO_SITEMAP_PAGE_SIZE must be between 1 and 50000, got %d", n)
+	}
+
 	return cfg, nil
 }
 
@@ -163,3 +254,22 @@ func getEnvSlice(key string, fallback []string) []string {
 	}
 	return fallback
 }
+
+// getEnvIntMap reads comma-separated name:value pairs, e.g. "default:2,search:1",
+// with each value a positive integer.
+func getEnvIntMap(key string, fallback map[string]int) (map[string]int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	m := make(map[string]int)
+	for _, pair := range strings.Split(v, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		i, err := strconv.Atoi(value)
+		if !ok || name == "" || err != nil || i < 1 {
+			return nil, fmt.Errorf("%s: invalid pair %q, want name:count with a positive count", key, pair)
+		}
+		m[name] = i
+	}
+	return m, nil
+}
@@ -12,4 +12,9 @@ type Cache interface {
 	Get(ctx context.Context, key string) ([]byte, error)
 	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
 	Delete(ctx context.Context, keys ...string) error
+	// SetWithTags stores value like Set and records key under each tag, so it
+	// can later be removed by InvalidateTags without knowing the key.
+	SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+	// InvalidateTags deletes every key recorded under any of tags.
+	InvalidateTags(ctx context.Context, tags ...string) error
 }
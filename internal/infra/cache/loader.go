@@ -0,0 +1,248 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"cms-api/internal/pkg/apperror"
+	"cms-api/internal/pkg/goroutine"
+)
+
+const (
+	lockTTL       = 10 * time.Second
+	lockWait      = 2 * time.Second
+	lockPoll      = 50 * time.Millisecond
+	loadTimeout   = 10 * time.Second
+	lockKeyPrefix = "lock:"
+)
+
+// LoadOptions controls how long a loaded value is served.
+type LoadOptions struct {
+	// TTL is the soft TTL. Once it passes the value is stale: it is still
+	// returned, and a background refresh is started.
+	TTL time.Duration
+	// StaleTTL is how long past TTL a stale value may be served before the
+	// entry expires and callers block on a fresh load.
+	StaleTTL time.Duration
+	// NegativeTTL caches apperror.ErrNotFound from the loader. Zero disables
+	// negative caching.
+	NegativeTTL time.Duration
+	// Tags are attached to every entry stored for the key, negative ones
+	// included.
+	Tags []string
+}
+
+// WithTags returns a copy of o with tags appended.
+func (o LoadOptions) WithTags(tags ...string) LoadOptions {
+	o.Tags = slices.Concat(o.Tags, tags)
+	return o
+}
+
+type LoadFunc func(ctx context.Context) ([]byte, error)
+
+// TaggedLoadFunc also returns tags derived from the loaded value; they are
+// stored in addition to LoadOptions.Tags.
+type TaggedLoadFunc func(ctx context.Context) ([]byte, []string, error)
+
+// Loader is a read-through helper over Cache. Concurrent misses for a key are
+// coalesced in-process with singleflight and across replicas with a Locker.
+type Loader struct {
+	cache  Cache
+	locker Locker
+	group  singleflight.Group
+	log    *zap.Logger
+}
+
+// NewLoader builds a Loader. A nil locker limits coalescing to this process.
+func NewLoader(cache Cache, locker Locker, log *zap.Logger) *Loader {
+	return &Loader{cache: cache, locker: locker, log: log.Named("cache")}
+}
+
+// GetOrLoad returns the cached value for key, calling load on a miss. A
+// negatively cached key returns apperror.ErrNotFound.
+func (l *Loader) GetOrLoad(ctx context.Context, key string, opts LoadOptions, load LoadFunc) ([]byte, error) {
+	return l.GetOrLoadTagged(ctx, key, opts, func(ctx context.Context) ([]byte, []string, error) {
+		v, err := load(ctx)
+		return v, nil, err
+	})
+}
+
+// GetOrLoadTagged is GetOrLoad for values whose tags are only known once
+// loaded.
+func (l *Loader) GetOrLoadTagged(ctx context.Context, key string, opts LoadOptions, load TaggedLoadFunc) ([]byte, error) {
+	if e, ok := l.read(ctx, key); ok {
+		if time.Now().After(e.softExpiry) {
+			l.refresh(key, opts, load)
+		}
+		return e.result()
+	}
+
+	v, err, _ := l.group.Do(key, func() (any, error) {
+		return l.loadAndStore(ctx, key, opts, load)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+// refresh reloads a stale key in the background. The singleflight key differs
+// from the foreground one so a refresh never blocks readers.
+func (l *Loader) refresh(key string, opts LoadOptions, load TaggedLoadFunc) {
+	goroutine.SafeWithTimeout(l.log, loadTimeout, func(ctx context.Context) {
+		_, err, _ := l.group.Do("refresh:"+key, func() (any, error) {
+			if l.locker != nil {
+				unlock, ok, err := l.locker.TryLock(ctx, lockKeyPrefix+key, lockTTL)
+				if err != nil || !ok {
+					// Another replica is refreshing, or the lock is unavailable;
+					// keep serving the stale value.
+					return nil, err
+				}
+				defer l.unlock(unlock)
+			}
+			return l.loadOnce(ctx, key, opts, load)
+		})
+		if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+			l.log.Warn("background cache refresh failed", zap.String("key", key), zap.Error(err))
+		}
+	})
+}
+
+func (l *Loader) loadAndStore(ctx context.Context, key string, opts LoadOptions, load TaggedLoadFunc) ([]byte, error) {
+	// The load outlives any single caller since others may be waiting on it.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+	defer cancel()
+
+	if l.locker != nil {
+		unlock, ok, err := l.locker.TryLock(ctx, lockKeyPrefix+key, lockTTL)
+		if err != nil {
+			l.log.Warn("cache lock unavailable", zap.String("key", key), zap.Error(err))
+		}
+		if ok {
+			defer l.unlock(unlock)
+		} else if err == nil {
+			if e, found := l.waitForValue(ctx, key); found {
+				return e.result()
+			}
+		}
+	}
+
+	return l.loadOnce(ctx, key, opts, load)
+}
+
+// waitForValue polls while another replica holds the lock and is expected to
+// populate key.
+func (l *Loader) waitForValue(ctx context.Context, key string) (*entry, bool) {
+	deadline := time.NewTimer(lockWait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(lockPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-deadline.C:
+			return nil, false
+		case <-ctx.Done():
+			return nil, false
+		case <-ticker.C:
+			if e, ok := l.read(ctx, key); ok {
+				return e, true
+			}
+		}
+	}
+}
+
+func (l *Loader) loadOnce(ctx context.Context, key string, opts LoadOptions, load TaggedLoadFunc) ([]byte, error) {
+	value, tags, err := load(ctx)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) && opts.NegativeTTL > 0 {
+			l.write(ctx, key, &entry{notFound: true, softExpiry: time.Now().Add(opts.NegativeTTL)}, opts.NegativeTTL, opts.Tags)
+		}
+		return nil, err
+	}
+
+	l.write(ctx, key, &entry{value: value, softExpiry: time.Now().Add(opts.TTL)}, opts.TTL+opts.StaleTTL, slices.Concat(opts.Tags, tags))
+	return value, nil
+}
+
+func (l *Loader) read(ctx context.Context, key string) (*entry, bool) {
+	data, err := l.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrCacheMiss) {
+			l.log.Warn("cache read failed", zap.String("key", key), zap.Error(err))
+		}
+		return nil, false
+	}
+
+	e, err := decodeEntry(data)
+	if err != nil {
+		// Written by something other than the Loader; treat as a miss.
+		return nil, false
+	}
+	return e, true
+}
+
+func (l *Loader) write(ctx context.Context, key string, e *entry, ttl time.Duration, tags []string) {
+	if err := l.cache.SetWithTags(ctx, key, e.encode(), ttl, tags...); err != nil {
+		l.log.Warn("cache write failed", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func (l *Loader) unlock(unlock func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := unlock(ctx); err != nil {
+		l.log.Warn("cache unlock failed", zap.Error(err))
+	}
+}
+
+// entry is the stored envelope: a version byte, a flags byte, the soft
+// expiry in unix nanoseconds, then the raw value.
+type entry struct {
+	value      []byte
+	notFound   bool
+	softExpiry time.Time
+}
+
+const (
+	entryVersion      = 1
+	entryHeaderSize   = 10
+	entryFlagNotFound = 1
+)
+
+var errInvalidEntry = errors.New("cache: invalid entry")
+
+func (e *entry) encode() []byte {
+	buf := make([]byte, entryHeaderSize+len(e.value))
+	buf[0] = entryVersion
+	if e.notFound {
+		buf[1] = entryFlagNotFound
+	}
+	binary.BigEndian.PutUint64(buf[2:10], uint64(e.softExpiry.UnixNano()))
+	copy(buf[entryHeaderSize:], e.value)
+	return buf
+}
+
+func decodeEntry(data []byte) (*entry, error) {
+	if len(data) < entryHeaderSize || data[0] != entryVersion {
+		return nil, errInvalidEntry
+	}
+	return &entry{
+		value:      data[entryHeaderSize:],
+		notFound:   data[1]&entryFlagNotFound != 0,
+		softExpiry: time.Unix(0, int64(binary.BigEndian.Uint64(data[2:10]))),
+	}, nil
+}
+
+func (e *entry) result() ([]byte, error) {
+	if e.notFound {
+		return nil, apperror.ErrNotFound
+	}
+	return e.value, nil
+}
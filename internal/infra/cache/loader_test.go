@@ -0,0 +1,258 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"cms-api/internal/pkg/apperror"
+)
+
+type memCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	tags map[string][]string
+}
+
+func newMemCache() *memCache {
+	return &memCache{data: make(map[string][]byte), tags: make(map[string][]string)}
+}
+
+func (c *memCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.data[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	return v, nil
+}
+
+func (c *memCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = value
+	return nil
+}
+
+func (c *memCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, k := range keys {
+		delete(c.data, k)
+	}
+	return nil
+}
+
+func (c *memCache) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = value
+	for _, t := range tags {
+		c.tags[t] = append(c.tags[t], key)
+	}
+	return nil
+}
+
+func (c *memCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	_, err := c.invalidateTags(ctx, tags...)
+	return err
+}
+
+func (c *memCache) invalidateTags(ctx context.Context, tags ...string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var removed []string
+	for _, t := range tags {
+		for _, k := range c.tags[t] {
+			delete(c.data, k)
+			removed = append(removed, k)
+		}
+		delete(c.tags, t)
+	}
+	return removed, nil
+}
+
+// heldLocker reports every lock as held by another replica.
+type heldLocker struct{}
+
+func (heldLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (func(context.Context) error, bool, error) {
+	return nil, false, nil
+}
+
+func TestLoader_CoalescesConcurrentMisses(t *testing.T) {
+	l := NewLoader(newMemCache(), nil, zap.NewNop())
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) ([]byte, error) {
+		calls.Add(1)
+		<-release
+		return []byte("v"), nil
+	}
+
+	const callers = 20
+	var wg sync.WaitGroup
+	results := make(chan string, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := l.GetOrLoad(context.Background(), "k", LoadOptions{TTL: time.Minute}, load)
+			if err != nil {
+				t.Errorf("get or load: %v", err)
+			}
+			results <- string(v)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected one load, got %d", n)
+	}
+	for v := range results {
+		if v != "v" {
+			t.Fatalf("unexpected value %q", v)
+		}
+	}
+}
+
+func TestLoader_CachesNotFound(t *testing.T) {
+	l := NewLoader(newMemCache(), nil, zap.NewNop())
+
+	calls := 0
+	load := func(ctx context.Context) ([]byte, error) {
+		calls++
+		return nil, apperror.ErrNotFound
+	}
+	opts := LoadOptions{TTL: time.Minute, NegativeTTL: time.Minute}
+
+	for range 3 {
+		if _, err := l.GetOrLoad(context.Background(), "k", opts, load); !errors.Is(err, apperror.ErrNotFound) {
+			t.Fatalf("expected not found, got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected one load, got %d", calls)
+	}
+}
+
+func TestLoader_DoesNotCacheErrors(t *testing.T) {
+	l := NewLoader(newMemCache(), nil, zap.NewNop())
+	errBoom := errors.New("boom")
+
+	calls := 0
+	load := func(ctx context.Context) ([]byte, error) {
+		calls++
+		return nil, errBoom
+	}
+	opts := LoadOptions{TTL: time.Minute, NegativeTTL: time.Minute}
+
+	for range 2 {
+		if _, err := l.GetOrLoad(context.Background(), "k", opts, load); !errors.Is(err, errBoom) {
+			t.Fatalf("expected boom, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected two loads, got %d", calls)
+	}
+}
+
+func TestLoader_ServesStaleWhileRefreshing(t *testing.T) {
+	c := newMemCache()
+	l := NewLoader(c, nil, zap.NewNop())
+	stale := &entry{value: []byte("old"), softExpiry: time.Now().Add(-time.Second)}
+	_ = c.Set(context.Background(), "k", stale.encode(), 0)
+
+	refreshed := make(chan struct{})
+	load := func(ctx context.Context) ([]byte, error) {
+		defer close(refreshed)
+		return []byte("new"), nil
+	}
+
+	v, err := l.GetOrLoad(context.Background(), "k", LoadOptions{TTL: time.Minute}, load)
+	if err != nil {
+		t.Fatalf("get or load: %v", err)
+	}
+	if string(v) != "old" {
+		t.Fatalf("expected stale value, got %q", v)
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatalf("expected background refresh")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		v, err = l.GetOrLoad(context.Background(), "k", LoadOptions{TTL: time.Minute}, load)
+		if err == nil && string(v) == "new" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected refreshed value, got %q (%v)", v, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLoader_TagsEntries(t *testing.T) {
+	c := newMemCache()
+	l := NewLoader(c, nil, zap.NewNop())
+	ctx := context.Background()
+
+	calls := 0
+	load := func(ctx context.Context) ([]byte, []string, error) {
+		calls++
+		return []byte("v"), []string{"category:7"}, nil
+	}
+	opts := LoadOptions{TTL: time.Minute, Tags: []string{"programs"}}
+
+	for _, tag := range []string{"programs", "category:7"} {
+		if _, err := l.GetOrLoadTagged(ctx, "k", opts, load); err != nil {
+			t.Fatalf("get or load: %v", err)
+		}
+		if err := c.InvalidateTags(ctx, tag); err != nil {
+			t.Fatalf("invalidate %s: %v", tag, err)
+		}
+		if _, err := c.Get(ctx, "k"); !errors.Is(err, ErrCacheMiss) {
+			t.Fatalf("expected %s to invalidate the entry, got %v", tag, err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected two loads, got %d", calls)
+	}
+}
+
+func TestLoader_WaitsForLockHolder(t *testing.T) {
+	c := newMemCache()
+	l := NewLoader(c, heldLocker{}, zap.NewNop())
+
+	// Another replica populates the key while this one waits on the lock.
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		e := &entry{value: []byte("remote"), softExpiry: time.Now().Add(time.Minute)}
+		_ = c.Set(context.Background(), "k", e.encode(), 0)
+	}()
+
+	calls := 0
+	v, err := l.GetOrLoad(context.Background(), "k", LoadOptions{TTL: time.Minute}, func(ctx context.Context) ([]byte, error) {
+		calls++
+		return []byte("local"), nil
+	})
+	if err != nil {
+		t.Fatalf("get or load: %v", err)
+	}
+	if string(v) != "remote" || calls != 0 {
+		t.Fatalf("expected value from lock holder without loading, got %q after %d loads", v, calls)
+	}
+}
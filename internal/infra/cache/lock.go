@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Locker is a best-effort distributed mutex used to let a single replica
+// rebuild a cache entry.
+type Locker interface {
+	// TryLock acquires key for ttl without blocking. ok is false when another
+	// holder owns the lock.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (unlock func(context.Context) error, ok bool, err error)
+}
+
+// unlockScript deletes the lock only if it still carries our token, so an
+// expired lock taken over by another replica is never released by us.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+type redisLocker struct {
+	client *redis.Client
+}
+
+func NewRedisLocker(client *redis.Client) Locker {
+	return &redisLocker{client: client}
+}
+
+func (l *redisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (func(context.Context) error, bool, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, false, err
+	}
+	token := hex.EncodeToString(buf)
+
+	ok, err := l.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	unlock := func(ctx context.Context) error {
+		return unlockScript.Run(ctx, l.client, []string{key}, token).Err()
+	}
+	return unlock, true, nil
+}
@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// MemoryModule provides the in-process cache and locker in place of Redis,
+// for tests and local runs without external services.
+var MemoryModule = fx.Module("cache",
+	fx.Provide(newMemoryOut),
+	fx.Provide(NewLoader),
+)
+
+func newMemoryOut() CacheOut {
+	return CacheOut{Cache: NewMemory(), Locker: NewMemoryLocker()}
+}
+
+type memoryItem struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (i memoryItem) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && !now.Before(i.expiresAt)
+}
+
+// memoryCache is an unbounded map with the same TTL and tag semantics as the
+// Redis cache. Expired entries are dropped lazily when read.
+type memoryCache struct {
+	mu   sync.Mutex
+	data map[string]memoryItem
+	tags map[string]map[string]struct{}
+}
+
+func NewMemory() Cache {
+	return &memoryCache{
+		data: make(map[string]memoryItem),
+		tags: make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.data[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	if item.expired(time.Now()) {
+		delete(c.data, key)
+		return nil, ErrCacheMiss
+	}
+	return item.value, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value, ttl)
+	return nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, k := range keys {
+		delete(c.data, k)
+	}
+	return nil
+}
+
+func (c *memoryCache) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value, ttl)
+	for _, tag := range tags {
+		keys, ok := c.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	return nil
+}
+
+func (c *memoryCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	_, err := c.invalidateTags(ctx, tags...)
+	return err
+}
+
+func (c *memoryCache) invalidateTags(ctx context.Context, tags ...string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var removed []string
+	for _, tag := range tags {
+		for k := range c.tags[tag] {
+			if _, ok := c.data[k]; ok {
+				delete(c.data, k)
+				removed = append(removed, k)
+			}
+		}
+		delete(c.tags, tag)
+	}
+	return removed, nil
+}
+
+func (c *memoryCache) set(key string, value []byte, ttl time.Duration) {
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expiresAt = time.Now().Add(ttl)
+	}
+	c.data[key] = item
+}
+
+// memoryLocker is a process-local Locker with the same expiry semantics as
+// the Redis one.
+type memoryLocker struct {
+	mu    sync.Mutex
+	next  uint64
+	locks map[string]memoryLock
+}
+
+type memoryLock struct {
+	token     uint64
+	expiresAt time.Time
+}
+
+func NewMemoryLocker() Locker {
+	return &memoryLocker{locks: make(map[string]memoryLock)}
+}
+
+func (l *memoryLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (func(context.Context) error, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	held, ok := l.locks[key]
+	if ok && now.Before(held.expiresAt) {
+		return nil, false, nil
+	}
+
+	l.next++
+	token := l.next
+	l.locks[key] = memoryLock{token: token, expiresAt: now.Add(ttl)}
+
+	unlock := func(context.Context) error {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if cur, ok := l.locks[key]; ok && cur.token == token {
+			delete(l.locks, key)
+		}
+		return nil
+	}
+	return unlock, true, nil
+}
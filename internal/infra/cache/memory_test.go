@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemory_ExpiresAndInvalidatesTags(t *testing.T) {
+	c := NewMemory()
+	ctx := context.Background()
+
+	_ = c.Set(ctx, "short", []byte("v"), 10*time.Millisecond)
+	_ = c.SetWithTags(ctx, "tagged", []byte("v"), 0, "programs", "category:1")
+	_ = c.SetWithTags(ctx, "other", []byte("v"), 0, "category:2")
+
+	time.Sleep(20 * time.Millisecond)
+	if _, err := c.Get(ctx, "short"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("expected expired entry to miss, got %v", err)
+	}
+
+	keys, err := c.(tagStore).invalidateTags(ctx, "category:1")
+	if err != nil {
+		t.Fatalf("invalidate: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != "tagged" {
+		t.Fatalf("unexpected invalidated keys %v", keys)
+	}
+	if _, err := c.Get(ctx, "other"); err != nil {
+		t.Fatalf("expected untagged entry to survive, got %v", err)
+	}
+}
+
+func TestMemoryLocker_ExcludesUntilUnlockOrExpiry(t *testing.T) {
+	l := NewMemoryLocker()
+	ctx := context.Background()
+
+	unlock, ok, _ := l.TryLock(ctx, "k", time.Minute)
+	if !ok {
+		t.Fatalf("expected first lock to succeed")
+	}
+	if _, ok, _ := l.TryLock(ctx, "k", time.Minute); ok {
+		t.Fatalf("expected held lock to be refused")
+	}
+	_ = unlock(ctx)
+
+	if _, ok, _ := l.TryLock(ctx, "k", time.Millisecond); !ok {
+		t.Fatalf("expected lock after unlock")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, ok, _ := l.TryLock(ctx, "k", time.Minute); !ok {
+		t.Fatalf("expected lock after expiry")
+	}
+	// The stale unlock must not release the current holder.
+	_ = unlock(ctx)
+	if _, ok, _ := l.TryLock(ctx, "k", time.Minute); ok {
+		t.Fatalf("expected lock to still be held")
+	}
+}
@@ -14,13 +14,21 @@ import (
 
 var Module = fx.Module("cache",
 	fx.Provide(NewRedis),
+	fx.Provide(NewLoader),
 )
 
 type redisCache struct {
 	client *redis.Client
 }
 
-func NewRedis(lc fx.Lifecycle, cfg *config.Config, log *zap.Logger) (Cache, error) {
+type CacheOut struct {
+	fx.Out
+
+	Cache  Cache
+	Locker Locker
+}
+
+func NewRedis(lc fx.Lifecycle, cfg *config.Config, log *zap.Logger) (CacheOut, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr:     cfg.Cache.Addr(),
 		Password: cfg.Cache.Password,
@@ -31,7 +39,7 @@ func NewRedis(lc fx.Lifecycle, cfg *config.Config, log *zap.Logger) (Cache, erro
 	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+		return CacheOut{}, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
 	log.Info("Redis connected",
@@ -47,7 +55,84 @@ func NewRedis(lc fx.Lifecycle, cfg *config.Config, log *zap.Logger) (Cache, erro
 		},
 	})
 
-	return &redisCache{client: client}, nil
+	out := CacheOut{
+		Cache:  &redisCache{client: client},
+		Locker: NewRedisLocker(client),
+	}
+
+	if cfg.Cache.LocalSize > 0 {
+		tiered := NewTieredCache(out.Cache, cfg.Cache.LocalSize, cfg.Cache.LocalTTL, func(ctx context.Context, payload []byte) error {
+			return client.Publish(ctx, invalidationChannel, payload).Err()
+		}, log)
+		if err := tiered.RegisterMetrics(); err != nil {
+			log.Warn("failed to register cache metrics", zap.Error(err))
+		}
+		subscribeInvalidations(lc, client, tiered, log)
+
+		log.Info("Local cache tier enabled",
+			zap.Int("size", cfg.Cache.LocalSize),
+			zap.Duration("ttl", cfg.Cache.LocalTTL),
+		)
+		out.Cache = tiered
+	}
+
+	return out, nil
+}
+
+// subscribeInvalidations feeds broadcast evictions into the local tier. Any
+// message may be lost while the subscription reconnects, so the local tier is
+// purged every time it is re-established.
+func subscribeInvalidations(lc fx.Lifecycle, client *redis.Client, tiered *TieredCache, log *zap.Logger) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	var pubsub *redis.PubSub
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			pubsub = client.Subscribe(ctx, invalidationChannel)
+			go func() {
+				defer close(done)
+
+				subscribed := false
+				for {
+					msg, err := pubsub.Receive(ctx)
+					if err != nil {
+						if ctx.Err() != nil {
+							return
+						}
+						log.Warn("cache invalidation subscription failed", zap.Error(err))
+						select {
+						case <-ctx.Done():
+							return
+						case <-time.After(time.Second):
+						}
+						continue
+					}
+
+					switch m := msg.(type) {
+					case *redis.Subscription:
+						if subscribed {
+							tiered.Purge()
+						}
+						subscribed = true
+					case *redis.Message:
+						tiered.HandleInvalidation([]byte(m.Payload))
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			// Closing unblocks Receive, which does not watch ctx while reading.
+			err := pubsub.Close()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return err
+		},
+	})
 }
 
 func (c *redisCache) Get(ctx context.Context, key string) ([]byte, error) {
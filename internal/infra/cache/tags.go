@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const tagKeyPrefix = "tag:"
+
+// tagStore is implemented by stores that can report which keys a tag
+// invalidation removed, letting the local tier evict exactly those.
+type tagStore interface {
+	invalidateTags(ctx context.Context, tags ...string) ([]string, error)
+}
+
+// setWithTagsScript writes KEYS[1] and adds it to the tag sets in KEYS[2..].
+// A tag set lives as long as its longest-lived member; ARGV[2] is the TTL in
+// milliseconds, 0 meaning no expiry.
+var setWithTagsScript = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ttl)
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	local existed = redis.call("EXISTS", KEYS[i])
+	redis.call("SADD", KEYS[i], KEYS[1])
+	if ttl == 0 then
+		redis.call("PERSIST", KEYS[i])
+	else
+		local current = redis.call("PTTL", KEYS[i])
+		if existed == 0 or (current >= 0 and current < ttl) then
+			redis.call("PEXPIRE", KEYS[i], ttl)
+		end
+	end
+end
+return 1
+`)
+
+// invalidateTagsScript deletes the members of every tag set in KEYS along
+// with the sets themselves, returning the deleted member keys. Running it as
+// a script keeps a concurrent SetWithTags from landing between the read and
+// the delete and escaping invalidation.
+var invalidateTagsScript = redis.NewScript(`
+local removed = {}
+for i = 1, #KEYS do
+	local members = redis.call("SMEMBERS", KEYS[i])
+	for j = 1, #members, 500 do
+		redis.call("DEL", unpack(members, j, math.min(j + 499, #members)))
+	end
+	for _, m in ipairs(members) do
+		removed[#removed + 1] = m
+	end
+	redis.call("DEL", KEYS[i])
+end
+return removed
+`)
+
+func (c *redisCache) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if len(tags) == 0 {
+		return c.Set(ctx, key, value, ttl)
+	}
+
+	keys := make([]string, 0, len(tags)+1)
+	keys = append(keys, key)
+	for _, t := range tags {
+		keys = append(keys, tagKeyPrefix+t)
+	}
+
+	ms := ttl.Milliseconds()
+	if ttl > 0 && ms == 0 {
+		ms = 1
+	}
+	return setWithTagsScript.Run(ctx, c.client, keys, value, ms).Err()
+}
+
+func (c *redisCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	_, err := c.invalidateTags(ctx, tags...)
+	return err
+}
+
+func (c *redisCache) invalidateTags(ctx context.Context, tags ...string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(tags))
+	for i, t := range tags {
+		keys[i] = tagKeyPrefix + t
+	}
+	return invalidateTagsScript.Run(ctx, c.client, keys).StringSlice()
+}
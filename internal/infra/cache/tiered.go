@@ -0,0 +1,250 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// invalidationChannel carries key evictions between replicas.
+const invalidationChannel = "cache:invalidate"
+
+type TierStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+type Stats struct {
+	Local       TierStats `json:"local"`
+	Remote      TierStats `json:"remote"`
+	LocalLength int       `json:"local_length"`
+}
+
+type tierCounters struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func (c *tierCounters) snapshot() TierStats {
+	return TierStats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+type invalidationMessage struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys,omitempty"`
+	Purge  bool     `json:"purge,omitempty"`
+}
+
+// PublishFunc broadcasts an invalidation message to every replica.
+type PublishFunc func(ctx context.Context, payload []byte) error
+
+// TieredCache serves reads from a bounded in-process LRU before falling back
+// to the remote cache. Writes and deletes go to both tiers and are broadcast
+// so other replicas drop their local copies. Local entries never outlive
+// localTTL, which bounds staleness if a broadcast is lost.
+type TieredCache struct {
+	local    *lru
+	localTTL time.Duration
+	remote   Cache
+	publish  PublishFunc
+	origin   string
+	log      *zap.Logger
+
+	localStats  tierCounters
+	remoteStats tierCounters
+}
+
+func NewTieredCache(remote Cache, size int, localTTL time.Duration, publish PublishFunc, log *zap.Logger) *TieredCache {
+	return &TieredCache{
+		local:    newLRU(size),
+		localTTL: localTTL,
+		remote:   remote,
+		publish:  publish,
+		origin:   newOrigin(),
+		log:      log.Named("cache"),
+	}
+}
+
+func (c *TieredCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if v, ok := c.local.get(key); ok {
+		c.localStats.hits.Add(1)
+		return v, nil
+	}
+	c.localStats.misses.Add(1)
+
+	v, err := c.remote.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrCacheMiss) {
+			c.remoteStats.misses.Add(1)
+		}
+		return nil, err
+	}
+	c.remoteStats.hits.Add(1)
+
+	c.local.set(key, v, c.localTTL)
+	return v, nil
+}
+
+func (c *TieredCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.remote.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	c.setLocal(ctx, key, value, ttl)
+	return nil
+}
+
+func (c *TieredCache) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if err := c.remote.SetWithTags(ctx, key, value, ttl, tags...); err != nil {
+		return err
+	}
+	c.setLocal(ctx, key, value, ttl)
+	return nil
+}
+
+func (c *TieredCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	c.local.delete(keys...)
+	if err := c.remote.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	c.broadcast(ctx, keys...)
+	return nil
+}
+
+// InvalidateTags removes the tagged keys remotely, then evicts them from every
+// local tier. Tags are only tracked remotely, so when the remote cannot report
+// which keys it removed the whole local tier is dropped instead.
+func (c *TieredCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ts, ok := c.remote.(tagStore)
+	if !ok {
+		if err := c.remote.InvalidateTags(ctx, tags...); err != nil {
+			return err
+		}
+		c.local.purge()
+		c.broadcastPurge(ctx)
+		return nil
+	}
+
+	keys, err := ts.invalidateTags(ctx, tags...)
+	if err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		c.local.delete(keys...)
+		c.broadcast(ctx, keys...)
+	}
+	return nil
+}
+
+func (c *TieredCache) setLocal(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	localTTL := c.localTTL
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	c.local.set(key, value, localTTL)
+	c.broadcast(ctx, key)
+}
+
+// Stats returns hit and miss counts per tier since startup.
+func (c *TieredCache) Stats() Stats {
+	return Stats{
+		Local:       c.localStats.snapshot(),
+		Remote:      c.remoteStats.snapshot(),
+		LocalLength: c.local.len(),
+	}
+}
+
+// RegisterMetrics exports the per-tier counters as cache.hits and
+// cache.misses through the global OpenTelemetry meter provider.
+func (c *TieredCache) RegisterMetrics() error {
+	meter := otel.Meter("cms-api/internal/infra/cache")
+
+	hits, err := meter.Int64ObservableCounter("cache.hits", metric.WithDescription("Cache lookups served per tier"))
+	if err != nil {
+		return err
+	}
+	misses, err := meter.Int64ObservableCounter("cache.misses", metric.WithDescription("Cache lookups not found per tier"))
+	if err != nil {
+		return err
+	}
+
+	local := metric.WithAttributes(attribute.String("tier", "local"))
+	remote := metric.WithAttributes(attribute.String("tier", "remote"))
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		s := c.Stats()
+		o.ObserveInt64(hits, int64(s.Local.Hits), local)
+		o.ObserveInt64(misses, int64(s.Local.Misses), local)
+		o.ObserveInt64(hits, int64(s.Remote.Hits), remote)
+		o.ObserveInt64(misses, int64(s.Remote.Misses), remote)
+		return nil
+	}, hits, misses)
+	return err
+}
+
+// HandleInvalidation applies a message received from the broadcast channel.
+// Messages published by this instance are ignored.
+func (c *TieredCache) HandleInvalidation(payload []byte) {
+	var msg invalidationMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		c.log.Warn("invalid cache invalidation message", zap.Error(err))
+		return
+	}
+	if msg.Origin == c.origin {
+		return
+	}
+	if msg.Purge {
+		c.local.purge()
+		return
+	}
+	c.local.delete(msg.Keys...)
+}
+
+// Purge drops every local entry. It is used when broadcasts may have been
+// missed, e.g. after the subscription reconnects.
+func (c *TieredCache) Purge() {
+	c.local.purge()
+}
+
+func (c *TieredCache) broadcast(ctx context.Context, keys ...string) {
+	c.send(ctx, invalidationMessage{Origin: c.origin, Keys: keys})
+}
+
+func (c *TieredCache) broadcastPurge(ctx context.Context) {
+	c.send(ctx, invalidationMessage{Origin: c.origin, Purge: true})
+}
+
+func (c *TieredCache) send(ctx context.Context, msg invalidationMessage) {
+	if c.publish == nil {
+		return
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	if err := c.publish(ctx, payload); err != nil {
+		c.log.Warn("failed to broadcast cache invalidation", zap.Strings("keys", msg.Keys), zap.Error(err))
+	}
+}
+
+func newOrigin() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// newReplicas builds tiered caches over one remote store whose broadcasts
+// are delivered synchronously to every replica, like the Redis channel.
+func newReplicas(n int) ([]*TieredCache, *memCache) {
+	remote := newMemCache()
+	replicas := make([]*TieredCache, n)
+	publish := func(ctx context.Context, payload []byte) error {
+		for _, r := range replicas {
+			r.HandleInvalidation(payload)
+		}
+		return nil
+	}
+	for i := range replicas {
+		replicas[i] = NewTieredCache(remote, 10, time.Minute, publish, zap.NewNop())
+	}
+	return replicas, remote
+}
+
+func TestTieredCache_ServesRepeatReadsLocally(t *testing.T) {
+	replicas, remote := newReplicas(1)
+	c := replicas[0]
+	ctx := context.Background()
+
+	_ = remote.Set(ctx, "k", []byte("v"), 0)
+	for range 3 {
+		v, err := c.Get(ctx, "k")
+		if err != nil || string(v) != "v" {
+			t.Fatalf("get: %q %v", v, err)
+		}
+	}
+	if _, err := c.Get(ctx, "missing"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("expected miss, got %v", err)
+	}
+
+	want := Stats{
+		Local:       TierStats{Hits: 2, Misses: 2},
+		Remote:      TierStats{Hits: 1, Misses: 1},
+		LocalLength: 1,
+	}
+	if got := c.Stats(); got != want {
+		t.Fatalf("expected stats %+v, got %+v", want, got)
+	}
+}
+
+func TestTieredCache_DeleteEvictsOtherReplicas(t *testing.T) {
+	replicas, _ := newReplicas(2)
+	a, b := replicas[0], replicas[1]
+	ctx := context.Background()
+
+	if err := a.Set(ctx, "k", []byte("v1"), 0); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if v, _ := b.Get(ctx, "k"); string(v) != "v1" {
+		t.Fatalf("expected v1 on b, got %q", v)
+	}
+
+	if err := a.Set(ctx, "k", []byte("v2"), 0); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if v, _ := b.Get(ctx, "k"); string(v) != "v2" {
+		t.Fatalf("expected b to drop its local copy after a write on a, got %q", v)
+	}
+
+	if err := a.Delete(ctx, "k"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := b.Get(ctx, "k"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("expected miss on b after delete on a, got %v", err)
+	}
+}
+
+func TestTieredCache_InvalidateTagsEvictsOtherReplicas(t *testing.T) {
+	replicas, _ := newReplicas(2)
+	a, b := replicas[0], replicas[1]
+	ctx := context.Background()
+
+	_ = a.SetWithTags(ctx, "list:1", []byte("v"), 0, "programs", "category:1")
+	_ = a.SetWithTags(ctx, "list:2", []byte("v"), 0, "programs", "category:2")
+	for _, k := range []string{"list:1", "list:2"} {
+		if _, err := b.Get(ctx, k); err != nil {
+			t.Fatalf("warm %s on b: %v", k, err)
+		}
+	}
+
+	if err := a.InvalidateTags(ctx, "category:1"); err != nil {
+		t.Fatalf("invalidate tags: %v", err)
+	}
+	if _, err := b.Get(ctx, "list:1"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("expected list:1 evicted on b, got %v", err)
+	}
+	if _, err := b.Get(ctx, "list:2"); err != nil {
+		t.Fatalf("expected list:2 to survive, got %v", err)
+	}
+}
+
+func TestTieredCache_LocalTTLFollowsShorterRemoteTTL(t *testing.T) {
+	replicas, remote := newReplicas(1)
+	c := replicas[0]
+	ctx := context.Background()
+
+	_ = c.Set(ctx, "k", []byte("v"), 20*time.Millisecond)
+	// The fake remote ignores TTLs, so a read after expiry reaching it proves
+	// the local copy expired with the shorter TTL.
+	_ = remote.Set(ctx, "k", []byte("remote"), 0)
+	time.Sleep(30 * time.Millisecond)
+
+	if v, _ := c.Get(ctx, "k"); string(v) != "remote" {
+		t.Fatalf("expected local entry to expire, got %q", v)
+	}
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLRU(2)
+	c.set("a", []byte("1"), time.Minute)
+	c.set("b", []byte("2"), time.Minute)
+	c.get("a")
+	c.set("c", []byte("3"), time.Minute)
+
+	if _, ok := c.get("b"); ok {
+		t.Fatalf("expected b to be evicted")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Fatalf("expected a to survive")
+	}
+	if c.len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", c.len())
+	}
+}
@@ -0,0 +1,81 @@
+package memdb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var intervalUnits = map[string]time.Duration{
+	"second": time.Second, "seconds": time.Second, "sec": time.Second, "secs": time.Second, "s": time.Second,
+	"minute": time.Minute, "minutes": time.Minute, "min": time.Minute, "mins": time.Minute, "m": time.Minute,
+	"hour": time.Hour, "hours": time.Hour, "hr": time.Hour, "hrs": time.Hour, "h": time.Hour,
+	"day": 24 * time.Hour, "days": 24 * time.Hour, "d": 24 * time.Hour,
+}
+
+// ParseInterval accepts the interval forms the API is fed in practice:
+// "HH:MM[:SS]", bare seconds, Go durations ("1h2m") and "1 hour 2 minutes".
+// Like Postgres, "02:15" is two hours fifteen minutes.
+func ParseInterval(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" {
+		return 0, fmt.Errorf("memdb: invalid interval %q", s)
+	}
+
+	if strings.Contains(s, ":") {
+		parts := strings.Split(s, ":")
+		if len(parts) > 3 {
+			return 0, fmt.Errorf("memdb: invalid interval %q", s)
+		}
+		units := []time.Duration{time.Hour, time.Minute, time.Second}
+		var d time.Duration
+		for i, p := range parts {
+			n, err := strconv.ParseFloat(p, 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("memdb: invalid interval %q", s)
+			}
+			d += time.Duration(n * float64(units[i]))
+		}
+		return d, nil
+	}
+
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(n * float64(time.Second)), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+
+	fields := strings.Fields(s)
+	if len(fields)%2 != 0 {
+		return 0, fmt.Errorf("memdb: invalid interval %q", s)
+	}
+	var d time.Duration
+	for i := 0; i < len(fields); i += 2 {
+		n, err := strconv.ParseFloat(fields[i], 64)
+		unit, ok := intervalUnits[fields[i+1]]
+		if err != nil || !ok {
+			return 0, fmt.Errorf("memdb: invalid interval %q", s)
+		}
+		d += time.Duration(n * float64(unit))
+	}
+	return d, nil
+}
+
+// FormatInterval renders d the way Postgres prints an interval as text.
+func FormatInterval(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	h, m, s := d/time.Hour, (d%time.Hour)/time.Minute, (d%time.Minute)/time.Second
+
+	clock := fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+	switch {
+	case days == 1:
+		return "1 day " + clock
+	case days > 1:
+		return fmt.Sprintf("%d days %s", days, clock)
+	}
+	return clock
+}
@@ -0,0 +1,451 @@
+// Package memdb is an in-process stand-in for the Postgres schema. It holds
+// the tables the repositories read, seeded with the same reference data as
+// the migrations (but no programs), and emulates the triggers and
+// constraints the repositories depend on, so in-memory repositories behave
+// like their SQL counterparts without a database.
+package memdb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("memdb",
+	fx.Provide(New),
+)
+
+var (
+	ErrDuplicateKey = errors.New("memdb: duplicate key")
+	ErrForeignKey   = errors.New("memdb: foreign key violation")
+)
+
+type Program struct {
+	ID             string
+	Title          string
+	Description    string
+	ProgramType    string
+	Duration       sql.NullString
+	PublishedAt    sql.NullTime
+	Thumbnail      string
+	VideoURL       string
+	MediaPath      sql.NullString
+	MediaType      sql.NullString
+	ExternalID     sql.NullString
+	Status         string
+	CategoryID     sql.NullInt64
+	LanguageID     sql.NullInt64
+	ImportSourceID sql.NullInt64
+	CreatedBy      sql.NullString
+	UpdatedBy      sql.NullString
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      sql.NullTime
+}
+
+// Published reports whether the program is visible on the public API.
+func (p *Program) Published() bool {
+	return p.Status == "active" && !p.DeletedAt.Valid
+}
+
+type Category struct {
+	ID          int64
+	Name        string
+	Slug        string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type Language struct {
+	ID   int64
+	Name string
+	Code string
+}
+
+type Transcript struct {
+	ProgramID string
+	Format    string
+	Language  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type TranscriptCue struct {
+	Position int
+	StartMS  int64
+	EndMS    int64
+	Text     string
+}
+
+type Chapter struct {
+	Position int
+	StartMS  int64
+	EndMS    sql.NullInt64
+	Title    string
+	URL      string
+	Image    string
+	TOC      bool
+}
+
+type Job struct {
+	ID          string
+	Queue       string
+	Kind        string
+	Payload     []byte
+	Priority    int
+	UniqueKey   sql.NullString
+	Status      string
+	Attempts    int
+	MaxAttempts int
+	LastError   sql.NullString
+	ScheduledAt time.Time
+	ProcessedAt sql.NullTime
+	LockedUntil sql.NullTime
+	LockedBy    sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type User struct {
+	ID           string
+	Email        string
+	PasswordHash string
+	Status       string
+	Roles        []string
+	CreatedAt    time.Time
+	DeletedAt    sql.NullTime
+}
+
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	RevokedAt sql.NullTime
+	CreatedAt time.Time
+}
+
+type ImportSource struct {
+	ID         int64
+	Name       string
+	SourceType string
+	BaseURL    string
+	IsActive   bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type ImportLog struct {
+	ID              string
+	SourceID        int64
+	TriggeredBy     *string
+	Status          string
+	RecordsImported int
+	ErrorMessage    string
+	StartedAt       *time.Time
+	FinishedAt      *time.Time
+	CreatedAt       time.Time
+}
+
+type SearchSettings struct {
+	IndexName               string
+	Synonyms                [][]string
+	StopWords               []string
+	RankingRules            []string
+	TypoEnabled             bool
+	TypoMinWordSizeOneTypo  int
+	TypoMinWordSizeTwoTypos int
+	TypoDisableOnWords      []string
+	TypoDisableOnAttributes []string
+	UpdatedAt               time.Time
+}
+
+type ReindexRun struct {
+	ID               string
+	IndexName        string
+	TargetIndex      string
+	Status           string
+	TotalDocuments   int
+	IndexedDocuments int
+	CaughtUp         int
+	ErrorMessage     sql.NullString
+	RequestedBy      sql.NullString
+	StartedAt        sql.NullTime
+	SwappedAt        sql.NullTime
+	FinishedAt       sql.NullTime
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+type ConsistencyRun struct {
+	ID            string
+	IndexName     string
+	Repair        bool
+	Status        string
+	Programs      int
+	Documents     int
+	MissingCount  int
+	StaleCount    int
+	OrphanedCount int
+	MissingIDs    []string
+	StaleIDs      []string
+	OrphanedIDs   []string
+	RepairJobs    int
+	ErrorMessage  sql.NullString
+	RequestedBy   sql.NullString
+	StartedAt     sql.NullTime
+	FinishedAt    sql.NullTime
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Tables is the schema. Rows are held by pointer; callers copy what they
+// return so no row escapes the store's lock.
+type Tables struct {
+	Programs        map[string]*Program
+	Categories      map[int64]*Category
+	Languages       map[int64]*Language
+	Transcripts     map[string]*Transcript
+	Cues            map[string][]*TranscriptCue
+	Chapters        map[string][]*Chapter
+	Jobs            map[string]*Job
+	Users           map[string]*User
+	RefreshTokens   map[string]*RefreshToken
+	ImportSources   map[int64]*ImportSource
+	ImportLogs      map[string]*ImportLog
+	SearchSettings  map[string]*SearchSettings
+	ReindexRuns     map[string]*ReindexRun
+	ConsistencyRuns map[string]*ConsistencyRun
+
+	// Now is fixed for the duration of a Write, like NOW() in a transaction.
+	Now time.Time
+}
+
+type Store struct {
+	mu sync.RWMutex
+	t  Tables
+}
+
+// New returns a store holding the rows the migrations seed.
+func New() *Store {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	s := &Store{t: Tables{
+		Programs:        make(map[string]*Program),
+		Categories:      make(map[int64]*Category),
+		Languages:       make(map[int64]*Language),
+		Transcripts:     make(map[string]*Transcript),
+		Cues:            make(map[string][]*TranscriptCue),
+		Chapters:        make(map[string][]*Chapter),
+		Jobs:            make(map[string]*Job),
+		Users:           make(map[string]*User),
+		RefreshTokens:   make(map[string]*RefreshToken),
+		ImportSources:   make(map[int64]*ImportSource),
+		ImportLogs:      make(map[string]*ImportLog),
+		SearchSettings:  make(map[string]*SearchSettings),
+		ReindexRuns:     make(map[string]*ReindexRun),
+		ConsistencyRuns: make(map[string]*ConsistencyRun),
+	}}
+
+	s.t.Categories[1] = &Category{ID: 1, Name: "بودكاست", Slug: "podcast", Description: "حلقات بودكاست صوتية ومرئية", CreatedAt: now, UpdatedAt: now}
+	s.t.Categories[2] = &Category{ID: 2, Name: "وثائقي", Slug: "documentary", Description: "أفلام وثائقية ومحتوى مرئي", CreatedAt: now, UpdatedAt: now}
+	s.t.Languages[1] = &Language{ID: 1, Name: "العربية", Code: "ar"}
+	s.t.Languages[2] = &Language{ID: 2, Name: "English", Code: "en"}
+	s.t.ImportSources[1] = &ImportSource{ID: 1, Name: "YouTube - Thmanyah", SourceType: "youtube", BaseURL: "https://www.youtube.com/@thmanyahPodcasts", IsActive: true, CreatedAt: now, UpdatedAt: now}
+
+	s.t.SearchSettings["programs"] = &SearchSettings{
+		IndexName:               "programs",
+		Synonyms:                [][]string{{"podcast", "بودكاست"}, {"documentary", "وثائقي"}},
+		StopWords:               []string{},
+		RankingRules:            []string{"words", "typo", "proximity", "attribute", "sort", "exactness"},
+		TypoEnabled:             true,
+		TypoMinWordSizeOneTypo:  5,
+		TypoMinWordSizeTwoTypos: 9,
+		TypoDisableOnWords:      []string{},
+		TypoDisableOnAttributes: []string{},
+		UpdatedAt:               now,
+	}
+
+	// Same credentials as the migration's admin seed.
+	admin := uuid.NewString()
+	s.t.Users[admin] = &User{
+		ID:           admin,
+		Email:        "admin@test.com",
+		PasswordHash: "$2a$12$eM11HfXzop1zNjCXpFnWXe/w.O6DAqfN8MPr3tTODoxUohOf8scHy",
+		Status:       "active",
+		Roles:        []string{"admin"},
+		CreatedAt:    now,
+	}
+
+	return s
+}
+
+// Read runs fn with shared access. fn must not modify the tables.
+func (s *Store) Read(fn func(t *Tables) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fn(&s.t)
+}
+
+// Write runs fn with exclusive access. There is no rollback, so fn should
+// check its preconditions before changing anything.
+func (s *Store) Write(fn func(t *Tables) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Postgres timestamps carry microseconds.
+	s.t.Now = time.Now().UTC().Truncate(time.Microsecond)
+	return fn(&s.t)
+}
+
+// InsertProgram adds a row, applying the column defaults and the index
+// trigger.
+func (t *Tables) InsertProgram(p *Program) error {
+	if _, ok := t.Programs[p.ID]; ok {
+		return ErrDuplicateKey
+	}
+	if err := t.checkProgram(p); err != nil {
+		return err
+	}
+	if p.Status == "" {
+		p.Status = "active"
+	}
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = t.Now
+	}
+	if p.UpdatedAt.IsZero() {
+		p.UpdatedAt = t.Now
+	}
+
+	t.Programs[p.ID] = p
+	t.enqueueIndexJob(p.ID, "upsert")
+	return nil
+}
+
+// UpdateProgram replaces an existing row and fires the same triggers as an
+// UPDATE on programs: the soft-delete trigger before, the index trigger after.
+func (t *Tables) UpdateProgram(p *Program) error {
+	if err := t.checkProgram(p); err != nil {
+		return err
+	}
+
+	if old, ok := t.Programs[p.ID]; ok && !old.DeletedAt.Valid && p.DeletedAt.Valid {
+		t.onSoftDelete(p.ID)
+	}
+
+	t.Programs[p.ID] = p
+	t.enqueueIndexJob(p.ID, "upsert")
+	return nil
+}
+
+// checkProgram enforces the column constraints and normalises the duration
+// to its text form, as reading an interval back from Postgres would.
+func (t *Tables) checkProgram(p *Program) error {
+	if p.CategoryID.Valid && t.Categories[p.CategoryID.Int64] == nil {
+		return fmt.Errorf("%w: category %d", ErrForeignKey, p.CategoryID.Int64)
+	}
+	if p.LanguageID.Valid && t.Languages[p.LanguageID.Int64] == nil {
+		return fmt.Errorf("%w: language %d", ErrForeignKey, p.LanguageID.Int64)
+	}
+	if p.Duration.Valid {
+		d, err := ParseInterval(p.Duration.String)
+		if err != nil {
+			return err
+		}
+		p.Duration.String = FormatInterval(d)
+	}
+	return nil
+}
+
+// CategoryOf follows the program's category_id, like a LEFT JOIN.
+func (t *Tables) CategoryOf(p *Program) *Category {
+	if !p.CategoryID.Valid {
+		return nil
+	}
+	return t.Categories[p.CategoryID.Int64]
+}
+
+// LanguageOf follows the program's language_id, like a LEFT JOIN.
+func (t *Tables) LanguageOf(p *Program) *Language {
+	if !p.LanguageID.Valid {
+		return nil
+	}
+	return t.Languages[p.LanguageID.Int64]
+}
+
+// enqueueIndexJob mirrors notify_program_index: at most one active job per
+// program and action, rescheduled rather than duplicated.
+func (t *Tables) enqueueIndexJob(programID, action string) {
+	payload, _ := json.Marshal(map[string]string{"program_id": programID, "action": action})
+	t.EnqueueJob(&Job{Queue: "search", Kind: "search.index", Payload: payload, UniqueKey: sql.NullString{String: action + ":" + programID, Valid: true}})
+}
+
+// onSoftDelete mirrors notify_program_soft_delete.
+func (t *Tables) onSoftDelete(programID string) {
+	upsert, del := "upsert:"+programID, "delete:"+programID
+	for id, j := range t.Jobs {
+		if j.Kind == "search.index" && (j.UniqueKey.String == upsert || j.UniqueKey.String == del) &&
+			(j.Status == "completed" || j.Status == "dead") {
+			delete(t.Jobs, id)
+		}
+	}
+
+	t.enqueueIndexJob(programID, "delete")
+
+	for id, j := range t.Jobs {
+		if j.Kind == "search.index" && j.UniqueKey.String == upsert && (j.Status == "pending" || j.Status == "failed") {
+			delete(t.Jobs, id)
+		}
+	}
+}
+
+// EnqueueJob inserts j with the column defaults applied. A job whose unique
+// key is held by an active job of the same kind reschedules that job instead,
+// like the ON CONFLICT clause of the enqueue queries, and it is returned.
+func (t *Tables) EnqueueJob(j *Job) *Job {
+	if j.ScheduledAt.IsZero() {
+		j.ScheduledAt = t.Now
+	}
+	if j.UniqueKey.Valid {
+		if active := t.ActiveJob(j.Kind, j.UniqueKey.String); active != nil {
+			active.ScheduledAt = j.ScheduledAt
+			active.UpdatedAt = t.Now
+			return active
+		}
+	}
+
+	if j.ID == "" {
+		j.ID = uuid.NewString()
+	}
+	if j.Queue == "" {
+		j.Queue = "default"
+	}
+	if j.Payload == nil {
+		j.Payload = []byte("{}")
+	}
+	if j.MaxAttempts == 0 {
+		j.MaxAttempts = 5
+	}
+	j.Status = "pending"
+	j.CreatedAt = t.Now
+	j.UpdatedAt = t.Now
+	t.Jobs[j.ID] = j
+	return j
+}
+
+// ActiveJob returns the pending, processing or failed job of kind holding
+// uniqueKey; the active unique-key index allows at most one.
+func (t *Tables) ActiveJob(kind, uniqueKey string) *Job {
+	for _, j := range t.Jobs {
+		if j.Kind == kind && j.UniqueKey.Valid && j.UniqueKey.String == uniqueKey &&
+			(j.Status == "pending" || j.Status == "processing" || j.Status == "failed") {
+			return j
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package memdb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func jobsFor(t *Tables, programID string) map[string]string {
+	out := make(map[string]string)
+	for _, j := range t.Jobs {
+		var payload struct {
+			ProgramID string `json:"program_id"`
+			Action    string `json:"action"`
+		}
+		if err := json.Unmarshal(j.Payload, &payload); err == nil && j.Kind == "search.index" && payload.ProgramID == programID {
+			out[payload.Action] = j.Status
+		}
+	}
+	return out
+}
+
+func TestStore_IndexTriggers(t *testing.T) {
+	s := New()
+
+	err := s.Write(func(tb *Tables) error {
+		if err := tb.InsertProgram(&Program{ID: "p1", Title: "t"}); err != nil {
+			return err
+		}
+		if got := jobsFor(tb, "p1"); len(got) != 1 || got["upsert"] != "pending" {
+			t.Fatalf("expected one pending upsert, got %v", got)
+		}
+
+		// Updating again reschedules rather than duplicating.
+		next := *tb.Programs["p1"]
+		next.Title = "t2"
+		if err := tb.UpdateProgram(&next); err != nil {
+			return err
+		}
+		if n := len(tb.Jobs); n != 1 {
+			t.Fatalf("expected one job, got %d", n)
+		}
+
+		deleted := *tb.Programs["p1"]
+		deleted.DeletedAt = sql.NullTime{Time: tb.Now, Valid: true}
+		if err := tb.UpdateProgram(&deleted); err != nil {
+			return err
+		}
+		// As in Postgres, the upsert trigger also fires after the soft
+		// delete; the worker skips it once the program is gone.
+		if got := jobsFor(tb, "p1"); got["delete"] != "pending" || got["upsert"] != "pending" {
+			t.Fatalf("expected pending delete and upsert, got %v", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestStore_ProgramConstraints(t *testing.T) {
+	s := New()
+
+	err := s.Write(func(tb *Tables) error {
+		if err := tb.InsertProgram(&Program{ID: "p1", CategoryID: sql.NullInt64{Int64: 99, Valid: true}}); err == nil {
+			t.Fatalf("expected unknown category to fail")
+		}
+		if err := tb.InsertProgram(&Program{ID: "p1", Duration: sql.NullString{String: "soon", Valid: true}}); err == nil {
+			t.Fatalf("expected invalid duration to fail")
+		}
+		if len(tb.Programs) != 0 || len(tb.Jobs) != 0 {
+			t.Fatalf("rejected inserts must not change the tables")
+		}
+
+		p := &Program{ID: "p1", Duration: sql.NullString{String: "1 hour 39 minutes", Valid: true}}
+		if err := tb.InsertProgram(p); err != nil {
+			return err
+		}
+		if p.Duration.String != "01:39:00" || p.Status != "active" {
+			t.Fatalf("unexpected defaults: %+v", p)
+		}
+		if err := tb.InsertProgram(&Program{ID: "p1"}); err != ErrDuplicateKey {
+			t.Fatalf("expected duplicate key, got %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := map[string]time.Duration{
+		"01:02:03":                    time.Hour + 2*time.Minute + 3*time.Second,
+		"02:15":                       2*time.Hour + 15*time.Minute,
+		"90":                          90 * time.Second,
+		"1h30m":                       90 * time.Minute,
+		"1 hour 2 minutes 15 seconds": time.Hour + 2*time.Minute + 15*time.Second,
+	}
+	for in, want := range tests {
+		got, err := ParseInterval(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseInterval(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if got := FormatInterval(26*time.Hour + 5*time.Second); got != "1 day 02:00:05" {
+		t.Fatalf("unexpected format %q", got)
+	}
+}
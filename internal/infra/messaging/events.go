@@ -23,6 +23,11 @@ const (
 	EventMessageRead     = "message.read"
 	EventTypingStarted   = "message.typing_started"
 	EventTypingStopped   = "message.typing_stopped"
+
+	EventProgramCreated   = "program.created"
+	EventProgramUpdated   = "program.updated"
+	EventProgramDeleted   = "program.deleted"
+	EventProgramsImported = "program.imported"
 )
 
 const (
@@ -30,6 +35,7 @@ const (
 	ChannelAuth          = "auth"
 	ChannelConversations = "conversations"
 	ChannelMessages      = "messages"
+	ChannelPrograms      = "programs"
 )
 
 type UserEvent struct {
@@ -62,3 +68,15 @@ type TypingEvent struct {
 	UserID         string `json:"user_id"`
 	IsTyping       bool   `json:"is_typing"`
 }
+
+// ProgramEvent identifies the program that changed. Bulk changes such as an
+// import run carry only the SourceID. Updates also carry the category and
+// language before and after the change, and whether it can move the program
+// into, out of or within listings.
+type ProgramEvent struct {
+	ProgramID     string   `json:"program_id,omitempty"`
+	SourceID      int64    `json:"source_id,omitempty"`
+	CategoryIDs   []int64  `json:"category_ids,omitempty"`
+	LanguageCodes []string `json:"language_codes,omitempty"`
+	Reordered     bool     `json:"reordered,omitempty"`
+}
@@ -1,3 +1,95 @@
 package messaging
 
-// TODO: Implement pub/sub messaging later
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Module("messaging",
+	fx.Provide(NewBus),
+)
+
+type Event struct {
+	Channel string
+	Name    string
+	Payload any
+}
+
+type Handler func(ctx context.Context, event Event) error
+
+type Publisher interface {
+	Publish(ctx context.Context, channel, name string, payload any) error
+}
+
+type Subscriber interface {
+	Subscribe(channel string, handler Handler)
+}
+
+type BusOut struct {
+	fx.Out
+
+	Publisher  Publisher
+	Subscriber Subscriber
+}
+
+// Bus is an in-process pub/sub. Publish runs every subscriber of the channel
+// synchronously, so side effects such as cache purges have completed by the
+// time the publisher returns.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	log      *zap.Logger
+}
+
+func NewBus(log *zap.Logger) BusOut {
+	bus := NewInProcessBus(log)
+	return BusOut{Publisher: bus, Subscriber: bus}
+}
+
+func NewInProcessBus(log *zap.Logger) *Bus {
+	return &Bus{handlers: make(map[string][]Handler), log: log.Named("messaging")}
+}
+
+func (b *Bus) Subscribe(channel string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[channel] = append(b.handlers[channel], handler)
+}
+
+// Publish delivers the event to every subscriber even if some fail, and
+// returns the joined handler errors.
+func (b *Bus) Publish(ctx context.Context, channel, name string, payload any) error {
+	b.mu.RLock()
+	handlers := b.handlers[channel]
+	b.mu.RUnlock()
+
+	event := Event{Channel: channel, Name: name, Payload: payload}
+
+	var errs []error
+	for _, h := range handlers {
+		if err := b.dispatch(ctx, h, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (b *Bus) dispatch(ctx context.Context, h Handler, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.log.Error("event handler panicked",
+				zap.String("channel", event.Channel),
+				zap.String("event", event.Name),
+				zap.Any("panic", r),
+			)
+			err = fmt.Errorf("handler for %s panicked: %v", event.Name, r)
+		}
+	}()
+	return h(ctx, event)
+}
@@ -0,0 +1,51 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestBus_PublishDeliversToChannelSubscribers(t *testing.T) {
+	bus := NewInProcessBus(zap.NewNop())
+
+	var got []string
+	bus.Subscribe(ChannelPrograms, func(ctx context.Context, event Event) error {
+		got = append(got, event.Name+":"+event.Payload.(ProgramEvent).ProgramID)
+		return nil
+	})
+	bus.Subscribe(ChannelUsers, func(ctx context.Context, event Event) error {
+		t.Fatalf("unexpected delivery to users channel")
+		return nil
+	})
+
+	if err := bus.Publish(context.Background(), ChannelPrograms, EventProgramDeleted, ProgramEvent{ProgramID: "p1"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if len(got) != 1 || got[0] != "program.deleted:p1" {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+}
+
+func TestBus_PublishIsolatesFailures(t *testing.T) {
+	bus := NewInProcessBus(zap.NewNop())
+	errBoom := errors.New("boom")
+
+	delivered := 0
+	bus.Subscribe(ChannelPrograms, func(ctx context.Context, event Event) error { return errBoom })
+	bus.Subscribe(ChannelPrograms, func(ctx context.Context, event Event) error { panic("bad handler") })
+	bus.Subscribe(ChannelPrograms, func(ctx context.Context, event Event) error {
+		delivered++
+		return nil
+	})
+
+	err := bus.Publish(context.Background(), ChannelPrograms, EventProgramUpdated, ProgramEvent{ProgramID: "p1"})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected joined handler error, got %v", err)
+	}
+	if delivered != 1 {
+		t.Fatalf("expected later subscribers to still run, got %d deliveries", delivered)
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"cms-api/internal/infra/cache"
 	"cms-api/internal/infra/database"
 	"cms-api/internal/infra/httpclient"
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/infra/messaging"
 	"cms-api/internal/infra/search"
+	"cms-api/internal/infra/storage"
 	"cms-api/internal/infra/telemetry"
 )
 
@@ -16,4 +19,19 @@ var Module = fx.Module("infra",
 	search.Module,
 	telemetry.Module,
 	cache.Module,
+	storage.Module,
+	messaging.Module,
+)
+
+// MemoryModule replaces Postgres, Redis and Meilisearch with in-process
+// implementations. Feature modules still need their repositories swapped for
+// the memdb-backed ones; see app.Hermetic.
+var MemoryModule = fx.Module("infra",
+	memdb.Module,
+	httpclient.Module,
+	search.MemoryModule,
+	telemetry.Module,
+	cache.MemoryModule,
+	storage.Module,
+	messaging.Module,
 )
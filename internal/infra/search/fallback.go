@@ -0,0 +1,109 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// HealthFunc reports whether a backend is currently usable.
+type HealthFunc func(ctx context.Context) error
+
+const healthCheckTimeout = 5 * time.Second
+
+// FallbackSearcher sends searches to primary while it is healthy and to
+// fallback otherwise. Health is polled in the background; a failed primary
+// search also marks it unhealthy immediately and is retried on fallback.
+type FallbackSearcher struct {
+	primary  Searcher
+	fallback Searcher
+	health   HealthFunc
+	healthy  atomic.Bool
+	log      *zap.Logger
+}
+
+func NewFallbackSearcher(primary, fallback Searcher, health HealthFunc, log *zap.Logger) *FallbackSearcher {
+	return &FallbackSearcher{primary: primary, fallback: fallback, health: health, log: log.Named("search")}
+}
+
+func (s *FallbackSearcher) Search(ctx context.Context, index string, req SearchRequest) (*SearchResult, error) {
+	if s.healthy.Load() {
+		result, err := s.primary.Search(ctx, index, req)
+		// A caller that gave up or ran out of time says nothing about the
+		// primary, and the fallback would fail the same way.
+		if err == nil || ctx.Err() != nil || errors.Is(err, context.Canceled) {
+			return result, err
+		}
+		s.setHealthy(false, err)
+	}
+	return s.fallback.Search(ctx, index, req)
+}
+
+// Healthy reports whether searches currently go to the primary backend.
+func (s *FallbackSearcher) Healthy() bool {
+	return s.healthy.Load()
+}
+
+// Check probes the primary backend once and updates the routing.
+func (s *FallbackSearcher) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	err := s.health(ctx)
+	s.setHealthy(err == nil, err)
+	return err
+}
+
+func (s *FallbackSearcher) setHealthy(healthy bool, err error) {
+	if s.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		s.log.Info("Primary search backend healthy")
+		return
+	}
+	s.log.Warn("Primary search backend unhealthy, falling back", zap.Error(err))
+}
+
+// RegisterLifecycle checks health once at startup, then every interval until
+// the app stops.
+func (s *FallbackSearcher) RegisterLifecycle(lc fx.Lifecycle, interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			// Searches use the fallback until the first check passes.
+			if err := s.Check(ctx); err != nil {
+				s.log.Warn("Primary search backend unavailable at startup, using fallback", zap.Error(err))
+			}
+			go func() {
+				defer close(done)
+
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						_ = s.Check(ctx)
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,118 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+type stubSearcher struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (s *stubSearcher) Search(ctx context.Context, index string, req SearchRequest) (*SearchResult, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &SearchResult{Hits: []json.RawMessage{json.RawMessage(`"` + s.name + `"`)}}, nil
+}
+
+func TestFallbackSearcher(t *testing.T) {
+	ctx := context.Background()
+	primary := &stubSearcher{name: "primary"}
+	fallback := &stubSearcher{name: "fallback"}
+
+	var healthErr error
+	s := NewFallbackSearcher(primary, fallback, func(context.Context) error { return healthErr }, zap.NewNop())
+
+	search := func() string {
+		t.Helper()
+		res, err := s.Search(ctx, "programs", SearchRequest{})
+		if err != nil {
+			t.Fatalf("search: %v", err)
+		}
+		return string(res.Hits[0][1 : len(res.Hits[0])-1])
+	}
+
+	// Unchecked primaries are not trusted.
+	if got := search(); got != "fallback" {
+		t.Fatalf("before check: got %s", got)
+	}
+
+	if err := s.Check(ctx); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if got := search(); got != "primary" {
+		t.Fatalf("healthy: got %s", got)
+	}
+
+	// A failing primary search is retried on the fallback and flips routing.
+	primary.err = errors.New("connection refused")
+	if got := search(); got != "fallback" {
+		t.Fatalf("primary error: got %s", got)
+	}
+	if s.Healthy() {
+		t.Fatal("expected primary to be marked unhealthy")
+	}
+	calls := primary.calls
+	search()
+	if primary.calls != calls {
+		t.Fatal("unhealthy primary should not be queried")
+	}
+
+	healthErr = errors.New("still down")
+	if err := s.Check(ctx); err == nil {
+		t.Fatal("expected check error")
+	}
+
+	primary.err, healthErr = nil, nil
+	if err := s.Check(ctx); err != nil {
+		t.Fatalf("recovery check: %v", err)
+	}
+	if got := search(); got != "primary" {
+		t.Fatalf("recovered: got %s", got)
+	}
+}
+
+func TestFallbackSearcher_CanceledDoesNotFailOver(t *testing.T) {
+	primary := &stubSearcher{name: "primary"}
+	fallback := &stubSearcher{name: "fallback"}
+	s := NewFallbackSearcher(primary, fallback, func(context.Context) error { return nil }, zap.NewNop())
+	if err := s.Check(context.Background()); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	primary.err = context.Canceled
+	if _, err := s.Search(context.Background(), "programs", SearchRequest{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if !s.Healthy() || fallback.calls != 0 {
+		t.Fatal("a canceled request must not fail over")
+	}
+}
+
+func TestFallbackSearcher_CallerDeadlineDoesNotFailOver(t *testing.T) {
+	primary := &stubSearcher{name: "primary"}
+	fallback := &stubSearcher{name: "fallback"}
+	s := NewFallbackSearcher(primary, fallback, func(context.Context) error { return nil }, zap.NewNop())
+	if err := s.Check(context.Background()); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	primary.err = fmt.Errorf("meilisearch search: %w", context.DeadlineExceeded)
+	if _, err := s.Search(ctx, "programs", SearchRequest{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if !s.Healthy() || fallback.calls != 0 {
+		t.Fatal("a request past its deadline must not fail over")
+	}
+}
@@ -0,0 +1,390 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// filterExpr is a parsed Meilisearch filter expression evaluated against a
+// decoded document.
+type filterExpr interface {
+	match(doc map[string]any) bool
+}
+
+type andExpr []filterExpr
+
+func (e andExpr) match(doc map[string]any) bool {
+	for _, x := range e {
+		if !x.match(doc) {
+			return false
+		}
+	}
+	return true
+}
+
+type orExpr []filterExpr
+
+func (e orExpr) match(doc map[string]any) bool {
+	for _, x := range e {
+		if x.match(doc) {
+			return true
+		}
+	}
+	return false
+}
+
+type notExpr struct{ expr filterExpr }
+
+func (e notExpr) match(doc map[string]any) bool { return !e.expr.match(doc) }
+
+type compareExpr struct {
+	attr   string
+	op     string
+	values []string
+}
+
+// match follows Meilisearch: an array attribute matches when any element
+// does, string equality ignores case, and the ordering operators compare
+// numbers numerically and anything else lexically.
+func (e compareExpr) match(doc map[string]any) bool {
+	fields := lookup(doc, e.attr)
+
+	switch e.op {
+	case "EXISTS":
+		return len(fields) > 0
+	case "!=":
+		return !compareExpr{attr: e.attr, op: "=", values: e.values}.match(doc)
+	}
+
+	for _, f := range fields {
+		fv := scalarString(f)
+		for _, v := range e.values {
+			if compareValues(fv, e.op, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func compareValues(field, op, value string) bool {
+	if op == "=" || op == "IN" {
+		return strings.EqualFold(field, value)
+	}
+
+	var cmp int
+	fn, ferr := strconv.ParseFloat(field, 64)
+	vn, verr := strconv.ParseFloat(value, 64)
+	switch {
+	case ferr == nil && verr == nil:
+		switch {
+		case fn < vn:
+			cmp = -1
+		case fn > vn:
+			cmp = 1
+		}
+	default:
+		cmp = strings.Compare(field, value)
+	}
+
+	switch op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+// parseFilter parses the subset of the Meilisearch filter grammar used by
+// the services: comparisons, IN [...], EXISTS, NOT, AND, OR and parentheses.
+// Values may be bare words or single or double quoted with backslash escapes.
+func parseFilter(s string) (filterExpr, []string, error) {
+	toks, err := tokenizeFilter(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(toks) == 0 {
+		return andExpr{}, nil, nil
+	}
+
+	p := &filterParser{toks: toks}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, nil, fmt.Errorf("search: unexpected %q in filter", p.toks[p.pos].text)
+	}
+	return expr, p.attrs, nil
+}
+
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+func tokenizeFilter(s string) ([]filterToken, error) {
+	var toks []filterToken
+	rs := []rune(s)
+
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == '[' || r == ']' || r == ',':
+			toks = append(toks, filterToken{text: string(r)})
+			i++
+		case r == '\'' || r == '"':
+			var b strings.Builder
+			i++
+			closed := false
+			for i < len(rs) {
+				if rs[i] == '\\' && i+1 < len(rs) {
+					b.WriteRune(rs[i+1])
+					i += 2
+					continue
+				}
+				if rs[i] == r {
+					closed = true
+					i++
+					break
+				}
+				b.WriteRune(rs[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("search: unterminated string in filter")
+			}
+			toks = append(toks, filterToken{text: b.String(), quoted: true})
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			if i+1 < len(rs) && rs[i+1] == '=' {
+				toks = append(toks, filterToken{text: string(rs[i : i+2])})
+				i += 2
+			} else if r == '!' {
+				return nil, fmt.Errorf("search: unexpected '!' in filter")
+			} else {
+				toks = append(toks, filterToken{text: string(r)})
+				i++
+			}
+		default:
+			start := i
+			for i < len(rs) && !unicode.IsSpace(rs[i]) && !strings.ContainsRune("()[],=!<>'\"", rs[i]) {
+				i++
+			}
+			toks = append(toks, filterToken{text: string(rs[start:i])})
+		}
+	}
+	return toks, nil
+}
+
+type filterParser struct {
+	toks  []filterToken
+	pos   int
+	attrs []string
+}
+
+func (p *filterParser) peekKeyword(kw string) bool {
+	return p.pos < len(p.toks) && !p.toks[p.pos].quoted && strings.EqualFold(p.toks[p.pos].text, kw)
+}
+
+func (p *filterParser) next() (filterToken, error) {
+	if p.pos >= len(p.toks) {
+		return filterToken{}, fmt.Errorf("search: unexpected end of filter")
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *filterParser) expect(text string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.quoted || t.text != text {
+		return fmt.Errorf("search: expected %q in filter, got %q", text, t.text)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	exprs := orExpr{left}
+	for p.peekKeyword("OR") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, right)
+	}
+	if len(exprs) == 1 {
+		return left, nil
+	}
+	return exprs, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	exprs := andExpr{left}
+	for p.peekKeyword("AND") {
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, right)
+	}
+	if len(exprs) == 1 {
+		return left, nil
+	}
+	return exprs, nil
+}
+
+func (p *filterParser) parseNot() (filterExpr, error) {
+	if p.peekKeyword("NOT") {
+		p.pos++
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (filterExpr, error) {
+	if p.pos < len(p.toks) && !p.toks[p.pos].quoted && p.toks[p.pos].text == "(" {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+
+	attr, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	p.attrs = append(p.attrs, attr.text)
+
+	switch {
+	case p.peekKeyword("EXISTS"):
+		p.pos++
+		return compareExpr{attr: attr.text, op: "EXISTS"}, nil
+	case p.peekKeyword("NOT") && p.pos+1 < len(p.toks) && strings.EqualFold(p.toks[p.pos+1].text, "IN"):
+		p.pos += 2
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{compareExpr{attr: attr.text, op: "IN", values: values}}, nil
+	case p.peekKeyword("IN"):
+		p.pos++
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return compareExpr{attr: attr.text, op: "IN", values: values}, nil
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch op.text {
+	case "=", "!=", ">", ">=", "<", "<=":
+	default:
+		return nil, fmt.Errorf("search: unsupported operator %q in filter", op.text)
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	return compareExpr{attr: attr.text, op: op.text, values: []string{value.text}}, nil
+}
+
+func (p *filterParser) parseList() ([]string, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	var values []string
+	for {
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if !t.quoted && t.text == "]" {
+			return values, nil
+		}
+		if !t.quoted && t.text == "," {
+			continue
+		}
+		values = append(values, t.text)
+	}
+}
+
+// lookup resolves a dotted attribute path, flattening arrays along the way.
+func lookup(v any, path string) []any {
+	if path == "" {
+		switch x := v.(type) {
+		case []any:
+			var out []any
+			for _, e := range x {
+				out = append(out, lookup(e, "")...)
+			}
+			return out
+		case nil:
+			return nil
+		}
+		return []any{v}
+	}
+
+	head, rest, _ := strings.Cut(path, ".")
+	switch x := v.(type) {
+	case map[string]any:
+		child, ok := x[head]
+		if !ok {
+			return nil
+		}
+		return lookup(child, rest)
+	case []any:
+		var out []any
+		for _, e := range x {
+			out = append(out, lookup(e, path)...)
+		}
+		return out
+	}
+	return nil
+}
+
+func scalarString(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,185 @@
+package search
+
+import (
+	"cmp"
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+
+	"cms-api/internal/pkg/textnorm"
+)
+
+// Meilisearch defaults, applied when a request leaves them unset.
+const (
+	defaultHighlightPreTag  = "<em>"
+	defaultHighlightPostTag = "</em>"
+	defaultCropMarker       = "…"
+	defaultCropLength       = 10
+)
+
+// formatter renders the "_formatted" values of a hit for the backends that
+// do not get them from Meilisearch. Text is split into words on runes, with
+// combining marks kept inside words, so Arabic diacritics and multi-byte
+// letters are never cut.
+type formatter struct {
+	highlight  []string
+	crop       []string
+	pre, post  string
+	marker     string
+	cropLength int
+}
+
+func newFormatter(req SearchRequest) *formatter {
+	if len(req.AttributesToHighlight) == 0 && len(req.AttributesToCrop) == 0 {
+		return nil
+	}
+
+	f := &formatter{
+		highlight:  req.AttributesToHighlight,
+		crop:       req.AttributesToCrop,
+		pre:        cmp.Or(req.HighlightPreTag, defaultHighlightPreTag),
+		post:       cmp.Or(req.HighlightPostTag, defaultHighlightPostTag),
+		marker:     cmp.Or(req.CropMarker, defaultCropMarker),
+		cropLength: req.CropLength,
+	}
+	if f.cropLength <= 0 {
+		f.cropLength = defaultCropLength
+	}
+	return f
+}
+
+// attributes returns every attribute that is highlighted, cropped or both.
+func (f *formatter) attributes() []string {
+	attrs := slices.Clone(f.highlight)
+	for _, a := range f.crop {
+		if !slices.Contains(attrs, a) {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
+}
+
+// format renders attr's text. matched reports whether the word at
+// text[from:to] matched the query.
+func (f *formatter) format(attr, text string, matched func(from, to int) bool) string {
+	highlight := slices.Contains(f.highlight, attr)
+	crop := slices.Contains(f.crop, attr)
+
+	words := splitWords(text)
+	hits := make([]bool, len(words))
+	first := -1
+	for i, w := range words {
+		hits[i] = matched(w[0], w[1])
+		if hits[i] && first < 0 {
+			first = i
+		}
+	}
+
+	// Crop to a window of words centred on the first match, or the start of
+	// the text when nothing matched.
+	start, end := 0, len(words)
+	if crop && len(words) > f.cropLength {
+		start = max(first-f.cropLength/2, 0)
+		start = min(start, len(words)-f.cropLength)
+		end = start + f.cropLength
+	}
+
+	from, to := 0, len(text)
+	if start > 0 {
+		from = words[start][0]
+	}
+	if end < len(words) {
+		to = words[end-1][1]
+	}
+
+	// The result is HTML: only the highlight tags are left unescaped.
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(html.EscapeString(f.marker))
+	}
+	pos := from
+	for i := start; i < end; i++ {
+		if !highlight || !hits[i] {
+			continue
+		}
+		w := words[i]
+		b.WriteString(html.EscapeString(text[pos:w[0]]))
+		b.WriteString(f.pre)
+		b.WriteString(html.EscapeString(text[w[0]:w[1]]))
+		b.WriteString(f.post)
+		pos = w[1]
+	}
+	b.WriteString(html.EscapeString(text[pos:to]))
+	if end < len(words) {
+		b.WriteString(html.EscapeString(f.marker))
+	}
+	return b.String()
+}
+
+// escapeFormatted HTML-escapes s, a value formatted by Meilisearch, except
+// for the pre and post highlight tags it inserted. Text that happens to
+// spell a tag is indistinguishable from one and is kept as well.
+func escapeFormatted(s, pre, post string) string {
+	var b strings.Builder
+	for s != "" {
+		i, tag := strings.Index(s, pre), pre
+		if j := strings.Index(s, post); j >= 0 && (i < 0 || j < i) {
+			i, tag = j, post
+		}
+		if i < 0 {
+			b.WriteString(html.EscapeString(s))
+			break
+		}
+		b.WriteString(html.EscapeString(s[:i]))
+		b.WriteString(tag)
+		s = s[i+len(tag):]
+	}
+	return b.String()
+}
+
+// splitWords returns the byte range of each word in text.
+func splitWords(text string) [][2]int {
+	var words [][2]int
+	start := -1
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			words = append(words, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, [2]int{start, len(text)})
+	}
+	return words
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+// containsAny matches a word containing any query term, which is how the
+// memory engine matches documents. Words and terms are both normalized, so
+// a query matched through a normalized shadow field highlights the original
+// text.
+func containsAny(text string, terms []string) func(from, to int) bool {
+	normalized := make([]string, len(terms))
+	for i, t := range terms {
+		normalized[i] = textnorm.Normalize(t)
+	}
+	return func(from, to int) bool {
+		word := textnorm.Normalize(text[from:to])
+		for _, t := range normalized {
+			if strings.Contains(word, t) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,73 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatter_Format(t *testing.T) {
+	f := newFormatter(SearchRequest{
+		AttributesToHighlight: []string{"title", "description"},
+		AttributesToCrop:      []string{"description"},
+		CropLength:            4,
+		HighlightPreTag:       "<b>",
+		HighlightPostTag:      "</b>",
+	})
+
+	tests := []struct {
+		name  string
+		attr  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{"highlight only keeps full text", "title", "The desert, at night", []string{"desert"}, "The <b>desert</b>, at night"},
+		{"crop centres on first match", "description", "one two three four desert six seven eight", []string{"desert"}, "…three four <b>desert</b> six…"},
+		{"crop without match keeps the start", "description", "one two three four five", []string{"zzz"}, "one two three four…"},
+		{"short text is not cropped", "description", "one desert", []string{"desert"}, "one <b>desert</b>"},
+		{"arabic words with diacritics stay whole", "title", "تَارِيخُ الصَّحْرَاءِ الكبرى", []string{"الصَّحْرَاءِ"}, "تَارِيخُ <b>الصَّحْرَاءِ</b> الكبرى"},
+		{"arabic variants highlight the original spelling", "title", "أُسامة في الصحراء", []string{"اسامه"}, "<b>أُسامة</b> في الصحراء"},
+		{"markup in the text is escaped", "title", `<i>Tom & Jerry</i> desert`, []string{"desert"}, "&lt;i&gt;Tom &amp; Jerry&lt;/i&gt; <b>desert</b>"},
+		{"arabic match inside a prefixed word", "description", "رحلة في عمق الصحراء وتاريخها القديم جدا", []string{"تاريخ"}, "…عمق الصحراء <b>وتاريخها</b> القديم…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := f.format(tt.attr, tt.text, containsAny(tt.text, tt.terms))
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatter_Defaults(t *testing.T) {
+	if newFormatter(SearchRequest{}) != nil {
+		t.Fatal("expected no formatter without attributes")
+	}
+
+	f := newFormatter(SearchRequest{AttributesToCrop: []string{"d"}})
+	text := strings.Repeat("word ", 11) + "match"
+	got := f.format("d", text, containsAny(text, []string{"match"}))
+	// Cropped to ten words, not highlighted.
+	if got != "…word word word word word word word word word match" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestEscapeFormatted(t *testing.T) {
+	got := escapeFormatted(`<script>"x"</script> <em>a&b</em> <em>c`, "<em>", "</em>")
+	if want := "&lt;script&gt;&#34;x&#34;&lt;/script&gt; <em>a&amp;b</em> <em>c"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseHeadline(t *testing.T) {
+	text, matches := parseHeadline("a \x02صحراء\x03 b \x02c\x03")
+	if text != "a صحراء b c" {
+		t.Fatalf("text = %q", text)
+	}
+	if len(matches) != 2 || text[matches[0][0]:matches[0][1]] != "صحراء" || text[matches[1][0]:matches[1][1]] != "c" {
+		t.Fatalf("matches = %v", matches)
+	}
+}
@@ -3,13 +3,44 @@ package search
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 )
 
+// ErrTaskFailed is wrapped by errors for write tasks the engine accepted and
+// then rejected, such as a batch holding an invalid document. Retrying the
+// same input will fail again.
+var ErrTaskFailed = errors.New("search task failed")
+
+// ErrUnsupported is returned by Indexer methods, and searches of indexes,
+// the configured backend has no equivalent for.
+var ErrUnsupported = errors.New("not supported by the search backend")
+
+// taskError is a write task that was processed and failed, with the
+// engine's error code.
+type taskError struct {
+	op      string
+	code    string
+	message string
+}
+
+func (e *taskError) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", e.op, e.message, e.code)
+}
+
+func (e *taskError) Unwrap() error {
+	return ErrTaskFailed
+}
+
 type SearchResult struct {
 	Hits      []json.RawMessage
 	Page      int
 	PerPage   int
 	TotalHits int64
+
+	// Facets holds, for each requested facet attribute, the number of
+	// matching documents per value, counted over all pages.
+	Facets map[string]map[string]int64
 }
 
 type SearchRequest struct {
@@ -18,6 +49,42 @@ type SearchRequest struct {
 	Sort    []string
 	Page    int
 	PerPage int
+
+	// Facets lists filterable attributes to count values of.
+	Facets []string
+
+	// AttributesToRetrieve limits hits to the named attributes, or returns
+	// whole documents when empty. "_formatted" is unaffected.
+	AttributesToRetrieve []string
+
+	// Distinct names a filterable attribute whose values hits must not
+	// share: only the best ranked document of each value is returned.
+	Distinct string
+
+	// AttributesToHighlight and AttributesToCrop name string attributes to
+	// return under "_formatted" in each hit, as Meilisearch does. Highlighted
+	// attributes wrap matched words in HighlightPreTag/HighlightPostTag;
+	// cropped ones are cut to CropLength words around the first match, with
+	// CropMarker where text was removed.
+	AttributesToHighlight []string
+	AttributesToCrop      []string
+	CropLength            int
+	CropMarker            string
+	HighlightPreTag       string
+	HighlightPostTag      string
+}
+
+// DocumentPage is a page of an index's stored documents.
+type DocumentPage struct {
+	Documents []json.RawMessage
+	// Total counts every document in the index.
+	Total int64
+}
+
+// Task is a handle on an enqueued write.
+type Task struct {
+	UID int64
+	op  string
 }
 
 type IndexConfig struct {
@@ -32,6 +99,31 @@ type Searcher interface {
 
 type Indexer interface {
 	EnsureIndex(ctx context.Context, index string, primaryKey string, cfg IndexConfig) error
-	AddDocuments(ctx context.Context, index string, docs []any) error
-	DeleteDocument(ctx context.Context, index string, docID string) error
+	// AddDocuments, DeleteDocument and DeleteDocuments enqueue a single
+	// write task and return as soon as the engine accepts it. The write is
+	// only known to be applied once WaitForTask returns nil for the task.
+	AddDocuments(ctx context.Context, index string, docs []any) (Task, error)
+	DeleteDocument(ctx context.Context, index string, docID string) (Task, error)
+	// DeleteDocuments ignores IDs with no document.
+	DeleteDocuments(ctx context.Context, index string, docIDs []string) (Task, error)
+	// DeleteDocumentsByFilter removes the documents matching filter, which
+	// uses the Search filter syntax.
+	DeleteDocumentsByFilter(ctx context.Context, index string, filter string) (Task, error)
+	// WaitForTask polls task until the engine has processed it, or until ctx
+	// is done. A processed task that failed returns an error wrapping
+	// ErrTaskFailed.
+	WaitForTask(ctx context.Context, task Task) error
+	// Settings returns the index's current relevance settings.
+	Settings(ctx context.Context, index string) (*IndexSettings, error)
+	// ApplySettings updates the non-nil fields of settings.
+	ApplySettings(ctx context.Context, index string, settings IndexSettings) error
+	// SwapIndexes atomically exchanges the documents and settings of two
+	// existing indexes, once every change already queued for them is applied.
+	SwapIndexes(ctx context.Context, a, b string) error
+	// DeleteIndex removes an index. A missing index is not an error.
+	DeleteIndex(ctx context.Context, index string) error
+	// Documents returns up to limit stored documents of index from offset,
+	// in the engine's storage order, holding only fields if any are named.
+	// Writes between calls may shift documents across pages.
+	Documents(ctx context.Context, index string, offset, limit int, fields []string) (*DocumentPage, error)
 }
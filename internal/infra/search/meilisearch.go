@@ -1,8 +1,10 @@
 package search
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,10 +15,6 @@ import (
 	"cms-api/internal/config"
 )
 
-var Module = fx.Module("search",
-	fx.Provide(NewMeilisearch),
-)
-
 type meilisearchClient struct {
 	client meilisearch.ServiceManager
 }
@@ -29,12 +27,12 @@ type SearchOut struct {
 }
 
 func NewMeilisearch(lc fx.Lifecycle, cfg *config.Config, log *zap.Logger) (SearchOut, error) {
-	client := meilisearch.New(cfg.Search.Addr(), meilisearch.WithAPIKey(cfg.Search.MasterKey))
+	ms := newMeilisearchClient(cfg)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if _, err := client.HealthWithContext(ctx); err != nil {
+	if err := ms.Health(ctx); err != nil {
 		return SearchOut{}, fmt.Errorf("failed to connect to meilisearch: %w", err)
 	}
 
@@ -50,19 +48,39 @@ func NewMeilisearch(lc fx.Lifecycle, cfg *config.Config, log *zap.Logger) (Searc
 		},
 	})
 
-	ms := &meilisearchClient{client: client}
 	return SearchOut{
 		Searcher: ms,
 		Indexer:  ms,
 	}, nil
 }
 
+func newMeilisearchClient(cfg *config.Config) *meilisearchClient {
+	return &meilisearchClient{
+		client: meilisearch.New(cfg.Search.Addr(), meilisearch.WithAPIKey(cfg.Search.MasterKey)),
+	}
+}
+
+func (m *meilisearchClient) Health(ctx context.Context) error {
+	_, err := m.client.HealthWithContext(ctx)
+	return err
+}
+
 func (m *meilisearchClient) Search(ctx context.Context, index string, req SearchRequest) (*SearchResult, error) {
 	msReq := &meilisearch.SearchRequest{
 		Page:        int64(req.Page),
 		HitsPerPage: int64(req.PerPage),
 		Filter:      req.Filter,
 		Sort:        req.Sort,
+		Facets:      req.Facets,
+		Distinct:    req.Distinct,
+
+		AttributesToRetrieve:  req.AttributesToRetrieve,
+		AttributesToHighlight: req.AttributesToHighlight,
+		AttributesToCrop:      req.AttributesToCrop,
+		CropLength:            int64(req.CropLength),
+		CropMarker:            req.CropMarker,
+		HighlightPreTag:       req.HighlightPreTag,
+		HighlightPostTag:      req.HighlightPostTag,
 	}
 
 	resp, err := m.client.Index(index).SearchWithContext(ctx, req.Query, msReq)
@@ -70,8 +88,13 @@ func (m *meilisearchClient) Search(ctx context.Context, index string, req Search
 		return nil, fmt.Errorf("meilisearch search: %w", err)
 	}
 
+	pre := cmp.Or(req.HighlightPreTag, defaultHighlightPreTag)
+	post := cmp.Or(req.HighlightPostTag, defaultHighlightPostTag)
 	hits := make([]json.RawMessage, 0, len(resp.Hits))
 	for _, hit := range resp.Hits {
+		if err := escapeFormattedHit(hit, pre, post); err != nil {
+			return nil, err
+		}
 		raw, err := json.Marshal(hit)
 		if err != nil {
 			return nil, fmt.Errorf("marshal search hit: %w", err)
@@ -79,14 +102,52 @@ func (m *meilisearchClient) Search(ctx context.Context, index string, req Search
 		hits = append(hits, raw)
 	}
 
+	var facets map[string]map[string]int64
+	if len(resp.FacetDistribution) > 0 {
+		if err := json.Unmarshal(resp.FacetDistribution, &facets); err != nil {
+			return nil, fmt.Errorf("decode facet distribution: %w", err)
+		}
+	}
+
 	return &SearchResult{
 		Hits:      hits,
 		Page:      req.Page,
 		PerPage:   req.PerPage,
 		TotalHits: resp.TotalHits,
+		Facets:    facets,
 	}, nil
 }
 
+// escapeFormattedHit HTML-escapes the string values of hit's "_formatted",
+// which Meilisearch returns raw, so they match the other backends.
+func escapeFormattedHit(hit meilisearch.Hit, pre, post string) error {
+	raw, ok := hit["_formatted"]
+	if !ok {
+		return nil
+	}
+	var formatted map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &formatted); err != nil {
+		return fmt.Errorf("decode formatted hit: %w", err)
+	}
+	for attr, value := range formatted {
+		var text string
+		if json.Unmarshal(value, &text) != nil {
+			continue
+		}
+		escaped, err := json.Marshal(escapeFormatted(text, pre, post))
+		if err != nil {
+			return fmt.Errorf("encode formatted hit: %w", err)
+		}
+		formatted[attr] = escaped
+	}
+	raw, err := json.Marshal(formatted)
+	if err != nil {
+		return fmt.Errorf("encode formatted hit: %w", err)
+	}
+	hit["_formatted"] = raw
+	return nil
+}
+
 func (m *meilisearchClient) EnsureIndex(ctx context.Context, index string, primaryKey string, cfg IndexConfig) error {
 	_, err := m.client.GetIndex(index)
 	if err != nil {
@@ -120,16 +181,171 @@ func (m *meilisearchClient) EnsureIndex(ctx context.Context, index string, prima
 	return nil
 }
 
-func (m *meilisearchClient) AddDocuments(ctx context.Context, index string, docs []any) error {
-	if _, err := m.client.Index(index).AddDocuments(docs, nil); err != nil {
-		return fmt.Errorf("add documents: %w", err)
+func (m *meilisearchClient) AddDocuments(ctx context.Context, index string, docs []any) (Task, error) {
+	info, err := m.client.Index(index).AddDocumentsWithContext(ctx, docs, nil)
+	if err != nil {
+		return Task{}, fmt.Errorf("add documents: %w", err)
+	}
+	return Task{UID: info.TaskUID, op: "add documents"}, nil
+}
+
+func (m *meilisearchClient) DeleteDocument(ctx context.Context, index string, docID string) (Task, error) {
+	info, err := m.client.Index(index).DeleteDocumentWithContext(ctx, docID, nil)
+	if err != nil {
+		return Task{}, fmt.Errorf("delete document: %w", err)
+	}
+	return Task{UID: info.TaskUID, op: "delete document"}, nil
+}
+
+func (m *meilisearchClient) DeleteDocuments(ctx context.Context, index string, docIDs []string) (Task, error) {
+	info, err := m.client.Index(index).DeleteDocumentsWithContext(ctx, docIDs, nil)
+	if err != nil {
+		return Task{}, fmt.Errorf("delete documents: %w", err)
+	}
+	return Task{UID: info.TaskUID, op: "delete documents"}, nil
+}
+
+func (m *meilisearchClient) DeleteDocumentsByFilter(ctx context.Context, index string, filter string) (Task, error) {
+	info, err := m.client.Index(index).DeleteDocumentsByFilterWithContext(ctx, filter, nil)
+	if err != nil {
+		return Task{}, fmt.Errorf("delete documents by filter: %w", err)
+	}
+	return Task{UID: info.TaskUID, op: "delete documents by filter"}, nil
+}
+
+func (m *meilisearchClient) WaitForTask(ctx context.Context, task Task) error {
+	return m.waitForTask(ctx, task.UID, task.op)
+}
+
+func (m *meilisearchClient) Settings(ctx context.Context, index string) (*IndexSettings, error) {
+	st, err := m.client.Index(index).GetSettingsWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get settings: %w", err)
+	}
+
+	settings := &IndexSettings{
+		Synonyms:     st.Synonyms,
+		StopWords:    st.StopWords,
+		RankingRules: st.RankingRules,
+	}
+	if settings.Synonyms == nil {
+		settings.Synonyms = map[string][]string{}
+	}
+	if settings.StopWords == nil {
+		settings.StopWords = []string{}
+	}
+	if t := st.TypoTolerance; t != nil {
+		settings.TypoTolerance = &TypoTolerance{
+			Enabled:             t.Enabled,
+			MinWordSizeOneTypo:  int(t.MinWordSizeForTypos.OneTypo),
+			MinWordSizeTwoTypos: int(t.MinWordSizeForTypos.TwoTypos),
+			DisableOnWords:      t.DisableOnWords,
+			DisableOnAttributes: t.DisableOnAttributes,
+		}
+	}
+	return settings, nil
+}
+
+func (m *meilisearchClient) ApplySettings(ctx context.Context, index string, settings IndexSettings) error {
+	idx := m.client.Index(index)
+
+	if settings.Synonyms != nil {
+		if _, err := idx.UpdateSynonymsWithContext(ctx, &settings.Synonyms); err != nil {
+			return fmt.Errorf("update synonyms: %w", err)
+		}
+	}
+	if settings.StopWords != nil {
+		if _, err := idx.UpdateStopWordsWithContext(ctx, &settings.StopWords); err != nil {
+			return fmt.Errorf("update stop words: %w", err)
+		}
+	}
+	if settings.RankingRules != nil {
+		if _, err := idx.UpdateRankingRulesWithContext(ctx, &settings.RankingRules); err != nil {
+			return fmt.Errorf("update ranking rules: %w", err)
+		}
+	}
+	if t := settings.TypoTolerance; t != nil {
+		if _, err := idx.UpdateTypoToleranceWithContext(ctx, &meilisearch.TypoTolerance{
+			Enabled: t.Enabled,
+			MinWordSizeForTypos: meilisearch.MinWordSizeForTypos{
+				OneTypo:  int64(t.MinWordSizeOneTypo),
+				TwoTypos: int64(t.MinWordSizeTwoTypos),
+			},
+			DisableOnWords:      nonNil(t.DisableOnWords),
+			DisableOnAttributes: nonNil(t.DisableOnAttributes),
+		}); err != nil {
+			return fmt.Errorf("update typo tolerance: %w", err)
+		}
 	}
 	return nil
 }
 
-func (m *meilisearchClient) DeleteDocument(ctx context.Context, index string, docID string) error {
-	if _, err := m.client.Index(index).DeleteDocument(docID, nil); err != nil {
-		return fmt.Errorf("delete document: %w", err)
+func (m *meilisearchClient) Documents(ctx context.Context, index string, offset, limit int, fields []string) (*DocumentPage, error) {
+	var resp meilisearch.DocumentsResult
+	err := m.client.Index(index).GetDocumentsWithContext(ctx, &meilisearch.DocumentsQuery{
+		Offset: int64(offset),
+		Limit:  int64(limit),
+		Fields: fields,
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("get documents: %w", err)
+	}
+
+	docs := make([]json.RawMessage, 0, len(resp.Results))
+	for _, doc := range resp.Results {
+		raw, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("marshal document: %w", err)
+		}
+		docs = append(docs, raw)
+	}
+	return &DocumentPage{Documents: docs, Total: resp.Total}, nil
+}
+
+// taskPollInterval is how often a waited-on Meilisearch task is polled.
+const taskPollInterval = 50 * time.Millisecond
+
+// SwapIndexes relies on Meilisearch processing tasks in the order they are
+// enqueued: the swap runs after every document update already queued for
+// either index.
+func (m *meilisearchClient) SwapIndexes(ctx context.Context, a, b string) error {
+	info, err := m.client.SwapIndexesWithContext(ctx, []*meilisearch.SwapIndexesParams{{Indexes: []string{a, b}}})
+	if err != nil {
+		return fmt.Errorf("swap indexes: %w", err)
+	}
+	return m.waitForTask(ctx, info.TaskUID, "swap indexes")
+}
+
+func (m *meilisearchClient) DeleteIndex(ctx context.Context, index string) error {
+	info, err := m.client.DeleteIndexWithContext(ctx, index)
+	if err != nil {
+		return fmt.Errorf("delete index: %w", err)
+	}
+	// Wait so an index recreated under the same name is not deleted too.
+	err = m.waitForTask(ctx, info.TaskUID, "delete index")
+	var taskErr *taskError
+	if errors.As(err, &taskErr) && taskErr.code == "index_not_found" {
+		return nil
+	}
+	return err
+}
+
+func (m *meilisearchClient) waitForTask(ctx context.Context, taskUID int64, op string) error {
+	task, err := m.client.WaitForTaskWithContext(ctx, taskUID, taskPollInterval)
+	if err != nil {
+		return fmt.Errorf("%s: wait for task %d: %w", op, taskUID, err)
+	}
+	if task.Status != meilisearch.TaskStatusSucceeded {
+		return &taskError{op: op, code: task.Error.Code, message: task.Error.Message}
 	}
 	return nil
 }
+
+// nonNil sends an empty list instead of null, which Meilisearch rejects for
+// list settings.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
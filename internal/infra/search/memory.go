@@ -0,0 +1,560 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.uber.org/fx"
+)
+
+// MemoryModule provides the in-process search engine in place of
+// Meilisearch, for tests and local runs without external services.
+var MemoryModule = fx.Module("search",
+	fx.Provide(newMemoryOut),
+)
+
+func newMemoryOut() SearchOut {
+	m := NewMemory()
+	return SearchOut{Searcher: m, Indexer: m}
+}
+
+const defaultPerPage = 20
+
+type memoryDoc struct {
+	raw    json.RawMessage
+	fields map[string]any
+}
+
+type memoryIndex struct {
+	primaryKey string
+	cfg        IndexConfig
+	settings   IndexSettings
+	ids        []string
+	docs       map[string]memoryDoc
+}
+
+// Memory is an in-process Searcher and Indexer. It supports the filter
+// grammar and sort syntax the services send to Meilisearch and rejects
+// attributes the index was not configured to filter or sort on, so a
+// misconfigured index fails in tests as it would in production. Matching is
+// a case-insensitive substring test of every query word, with no ranking
+// beyond the requested sort and insertion order.
+type Memory struct {
+	mu      sync.RWMutex
+	indexes map[string]*memoryIndex
+
+	lastTaskUID int64
+	failedTasks map[int64]error
+}
+
+func NewMemory() *Memory {
+	return &Memory{indexes: make(map[string]*memoryIndex), failedTasks: make(map[int64]error)}
+}
+
+func (m *Memory) EnsureIndex(ctx context.Context, index string, primaryKey string, cfg IndexConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if idx, ok := m.indexes[index]; ok {
+		idx.cfg = cfg
+		return nil
+	}
+	if primaryKey == "" {
+		primaryKey = "id"
+	}
+	m.indexes[index] = &memoryIndex{primaryKey: primaryKey, cfg: cfg, docs: make(map[string]memoryDoc)}
+	return nil
+}
+
+func (m *Memory) AddDocuments(ctx context.Context, index string, docs []any) (Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx, ok := m.indexes[index]
+	if !ok {
+		// Meilisearch creates missing indexes on first write.
+		idx = &memoryIndex{primaryKey: "id", docs: make(map[string]memoryDoc)}
+		m.indexes[index] = idx
+	}
+
+	decoded := make([]memoryDoc, 0, len(docs))
+	for _, d := range docs {
+		raw, err := json.Marshal(d)
+		if err != nil {
+			return Task{}, fmt.Errorf("add documents: %w", err)
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return Task{}, fmt.Errorf("add documents: %w", err)
+		}
+		if _, ok := fields[idx.primaryKey]; !ok {
+			return m.enqueue("add documents", &taskError{
+				op:      "add documents",
+				code:    "missing_document_id",
+				message: fmt.Sprintf("document does not have a %q attribute", idx.primaryKey),
+			}), nil
+		}
+		decoded = append(decoded, memoryDoc{raw: raw, fields: fields})
+	}
+
+	for _, d := range decoded {
+		id := scalarString(d.fields[idx.primaryKey])
+		if _, ok := idx.docs[id]; !ok {
+			idx.ids = append(idx.ids, id)
+		}
+		idx.docs[id] = d
+	}
+	return m.enqueue("add documents", nil), nil
+}
+
+func (m *Memory) DeleteDocument(ctx context.Context, index string, docID string) (Task, error) {
+	return m.DeleteDocuments(ctx, index, []string{docID})
+}
+
+func (m *Memory) DeleteDocuments(ctx context.Context, index string, docIDs []string) (Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx, ok := m.indexes[index]
+	if !ok {
+		return m.enqueue("delete documents", &taskError{
+			op:      "delete documents",
+			code:    "index_not_found",
+			message: fmt.Sprintf("index %q not found", index),
+		}), nil
+	}
+	for _, docID := range docIDs {
+		if _, ok := idx.docs[docID]; ok {
+			delete(idx.docs, docID)
+			idx.ids = slices.DeleteFunc(idx.ids, func(id string) bool { return id == docID })
+		}
+	}
+	return m.enqueue("delete documents", nil), nil
+}
+
+func (m *Memory) DeleteDocumentsByFilter(ctx context.Context, index string, filter string) (Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx, ok := m.indexes[index]
+	if !ok {
+		return m.enqueue("delete documents by filter", &taskError{
+			op:      "delete documents by filter",
+			code:    "index_not_found",
+			message: fmt.Sprintf("index %q not found", index),
+		}), nil
+	}
+	expr, attrs, err := parseFilter(filter)
+	if err != nil {
+		return Task{}, fmt.Errorf("delete documents by filter: %w", err)
+	}
+	for _, a := range attrs {
+		if !slices.Contains(idx.cfg.FilterableAttributes, a) {
+			return Task{}, fmt.Errorf("delete documents by filter: attribute %q is not filterable", a)
+		}
+	}
+	idx.ids = slices.DeleteFunc(idx.ids, func(id string) bool {
+		if expr.match(idx.docs[id].fields) {
+			delete(idx.docs, id)
+			return true
+		}
+		return false
+	})
+	return m.enqueue("delete documents by filter", nil), nil
+}
+
+// enqueue records a write task. Writes are applied before it returns, so
+// the task is already finished; a rejected write fails its task rather than
+// the call, as in Meilisearch. Callers hold m.mu.
+func (m *Memory) enqueue(op string, failure *taskError) Task {
+	m.lastTaskUID++
+	if failure != nil {
+		m.failedTasks[m.lastTaskUID] = failure
+	}
+	return Task{UID: m.lastTaskUID, op: op}
+}
+
+// WaitForTask reports the outcome of a task once; a failed task is
+// forgotten after it has been waited on.
+func (m *Memory) WaitForTask(ctx context.Context, task Task) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err, ok := m.failedTasks[task.UID]; ok {
+		delete(m.failedTasks, task.UID)
+		return err
+	}
+	return nil
+}
+
+// Settings reports the stored settings with Meilisearch's defaults for
+// those never applied. The memory engine does not act on them.
+func (m *Memory) Settings(ctx context.Context, index string) (*IndexSettings, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	idx, ok := m.indexes[index]
+	if !ok {
+		return nil, fmt.Errorf("get settings: index %q not found", index)
+	}
+
+	s := IndexSettings{
+		Synonyms:     map[string][]string{},
+		StopWords:    []string{},
+		RankingRules: slices.Clone(DefaultRankingRules),
+	}
+	typo := DefaultTypoTolerance
+	s.TypoTolerance = &typo
+	mergeSettings(&s, idx.settings)
+	return &s, nil
+}
+
+func (m *Memory) ApplySettings(ctx context.Context, index string, settings IndexSettings) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx, ok := m.indexes[index]
+	if !ok {
+		// Meilisearch creates missing indexes on settings updates too.
+		idx = &memoryIndex{primaryKey: "id", docs: make(map[string]memoryDoc)}
+		m.indexes[index] = idx
+	}
+	mergeSettings(&idx.settings, settings)
+	return nil
+}
+
+func (m *Memory) SwapIndexes(ctx context.Context, a, b string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ia, ok := m.indexes[a]
+	if !ok {
+		return fmt.Errorf("swap indexes: index %q not found", a)
+	}
+	ib, ok := m.indexes[b]
+	if !ok {
+		return fmt.Errorf("swap indexes: index %q not found", b)
+	}
+	m.indexes[a], m.indexes[b] = ib, ia
+	return nil
+}
+
+func (m *Memory) DeleteIndex(ctx context.Context, index string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.indexes, index)
+	return nil
+}
+
+func (m *Memory) Documents(ctx context.Context, index string, offset, limit int, fields []string) (*DocumentPage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	idx, ok := m.indexes[index]
+	if !ok {
+		return nil, fmt.Errorf("get documents: index %q not found", index)
+	}
+
+	page := &DocumentPage{Documents: []json.RawMessage{}, Total: int64(len(idx.ids))}
+	for _, id := range idx.ids[min(offset, len(idx.ids)):min(offset+limit, len(idx.ids))] {
+		doc := idx.docs[id]
+		if len(fields) == 0 {
+			page.Documents = append(page.Documents, doc.raw)
+			continue
+		}
+		raw, err := json.Marshal(pickFields(doc.fields, fields))
+		if err != nil {
+			return nil, fmt.Errorf("get documents: %w", err)
+		}
+		page.Documents = append(page.Documents, raw)
+	}
+	return page, nil
+}
+
+// mergeSettings copies the non-nil fields of src into dst.
+func mergeSettings(dst *IndexSettings, src IndexSettings) {
+	if src.Synonyms != nil {
+		dst.Synonyms = maps.Clone(src.Synonyms)
+	}
+	if src.StopWords != nil {
+		dst.StopWords = slices.Clone(src.StopWords)
+	}
+	if src.RankingRules != nil {
+		dst.RankingRules = slices.Clone(src.RankingRules)
+	}
+	if src.TypoTolerance != nil {
+		t := *src.TypoTolerance
+		dst.TypoTolerance = &t
+	}
+}
+
+func (m *Memory) Search(ctx context.Context, index string, req SearchRequest) (*SearchResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	idx, ok := m.indexes[index]
+	if !ok {
+		return nil, fmt.Errorf("memory search: index %q not found", index)
+	}
+
+	filter, attrs, err := parseFilter(req.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("memory search: %w", err)
+	}
+	for _, a := range attrs {
+		if !slices.Contains(idx.cfg.FilterableAttributes, a) {
+			return nil, fmt.Errorf("memory search: attribute %q is not filterable", a)
+		}
+	}
+
+	for _, a := range req.Facets {
+		if !slices.Contains(idx.cfg.FilterableAttributes, a) {
+			return nil, fmt.Errorf("memory search: attribute %q is not filterable", a)
+		}
+	}
+	if req.Distinct != "" && !slices.Contains(idx.cfg.FilterableAttributes, req.Distinct) {
+		return nil, fmt.Errorf("memory search: attribute %q is not filterable", req.Distinct)
+	}
+
+	sorts, err := parseSort(req.Sort, idx.cfg.SortableAttributes)
+	if err != nil {
+		return nil, fmt.Errorf("memory search: %w", err)
+	}
+
+	terms := strings.Fields(strings.ToLower(req.Query))
+	var matched []memoryDoc
+	for _, id := range idx.ids {
+		d := idx.docs[id]
+		if filter.match(d.fields) && matchesQuery(d.fields, idx.cfg.SearchableAttributes, terms) {
+			matched = append(matched, d)
+		}
+	}
+
+	slices.SortStableFunc(matched, func(a, b memoryDoc) int {
+		for _, s := range sorts {
+			if c := s.compare(a.fields, b.fields); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	if req.Distinct != "" {
+		matched = distinctDocs(matched, req.Distinct)
+	}
+
+	page := max(req.Page, 1)
+	perPage := req.PerPage
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	start := min((page-1)*perPage, len(matched))
+	end := min(start+perPage, len(matched))
+
+	f := newFormatter(req)
+	hits := make([]json.RawMessage, 0, end-start)
+	for _, d := range matched[start:end] {
+		if f == nil && len(req.AttributesToRetrieve) == 0 {
+			hits = append(hits, d.raw)
+			continue
+		}
+		raw, err := formatHit(d, f, terms, req.AttributesToRetrieve)
+		if err != nil {
+			return nil, fmt.Errorf("memory search: %w", err)
+		}
+		hits = append(hits, raw)
+	}
+
+	return &SearchResult{
+		Hits:      hits,
+		Page:      req.Page,
+		PerPage:   req.PerPage,
+		TotalHits: int64(len(matched)),
+		Facets:    countFacets(matched, req.Facets),
+	}, nil
+}
+
+// formatHit keeps the retrieve attributes of d, or all of them, and adds
+// "_formatted" with the string attributes f applies to when f is not nil.
+func formatHit(d memoryDoc, f *formatter, terms []string, retrieve []string) (json.RawMessage, error) {
+	fields := maps.Clone(d.fields)
+	if len(retrieve) > 0 {
+		fields = pickFields(d.fields, retrieve)
+	}
+	if f != nil {
+		formatted := make(map[string]any)
+		for _, attr := range f.attributes() {
+			if text, ok := d.fields[attr].(string); ok {
+				formatted[attr] = f.format(attr, text, containsAny(text, terms))
+			}
+		}
+		fields["_formatted"] = formatted
+	}
+	return json.Marshal(fields)
+}
+
+// pickFields returns the named top-level fields of a document.
+func pickFields(fields map[string]any, names []string) map[string]any {
+	picked := make(map[string]any, len(names))
+	for _, name := range names {
+		if v, ok := fields[name]; ok {
+			picked[name] = v
+		}
+	}
+	return picked
+}
+
+// distinctDocs keeps the first of docs for each value of attr. Documents
+// without the attribute are all kept, as in Meilisearch.
+func distinctDocs(docs []memoryDoc, attr string) []memoryDoc {
+	seen := make(map[string]bool)
+	return slices.DeleteFunc(docs, func(d memoryDoc) bool {
+		values := lookup(d.fields, attr)
+		if len(values) == 0 {
+			return false
+		}
+		key := scalarString(values[0])
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+		return false
+	})
+}
+
+// countFacets counts each distinct scalar value of the facet attributes;
+// a document with an array value counts once per element.
+func countFacets(docs []memoryDoc, facets []string) map[string]map[string]int64 {
+	if len(facets) == 0 {
+		return nil
+	}
+
+	out := make(map[string]map[string]int64, len(facets))
+	for _, attr := range facets {
+		counts := make(map[string]int64)
+		for _, d := range docs {
+			seen := make(map[string]bool)
+			for _, v := range lookup(d.fields, attr) {
+				key := scalarString(v)
+				if !seen[key] {
+					seen[key] = true
+					counts[key]++
+				}
+			}
+		}
+		out[attr] = counts
+	}
+	return out
+}
+
+func matchesQuery(doc map[string]any, searchable []string, terms []string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+
+	var text strings.Builder
+	if len(searchable) == 0 || slices.Contains(searchable, "*") {
+		for _, v := range lookup(doc, "") {
+			collectText(&text, v)
+		}
+	} else {
+		for _, attr := range searchable {
+			for _, v := range lookup(doc, attr) {
+				collectText(&text, v)
+			}
+		}
+	}
+
+	haystack := strings.ToLower(text.String())
+	for _, t := range terms {
+		if !strings.Contains(haystack, t) {
+			return false
+		}
+	}
+	return true
+}
+
+func collectText(b *strings.Builder, v any) {
+	switch x := v.(type) {
+	case string:
+		b.WriteString(x)
+		b.WriteByte(' ')
+	case map[string]any:
+		for _, child := range x {
+			collectText(b, child)
+		}
+	case []any:
+		for _, child := range x {
+			collectText(b, child)
+		}
+	}
+}
+
+type sortRule struct {
+	attr string
+	desc bool
+}
+
+func parseSort(rules []string, sortable []string) ([]sortRule, error) {
+	out := make([]sortRule, 0, len(rules))
+	for _, r := range rules {
+		attr, dir, ok := strings.Cut(r, ":")
+		if !ok || (dir != "asc" && dir != "desc") {
+			return nil, fmt.Errorf("invalid sort %q", r)
+		}
+		if !slices.Contains(sortable, attr) {
+			return nil, fmt.Errorf("attribute %q is not sortable", attr)
+		}
+		out = append(out, sortRule{attr: attr, desc: dir == "desc"})
+	}
+	return out, nil
+}
+
+// compare orders documents missing the attribute last in either direction,
+// as Meilisearch does.
+func (s sortRule) compare(a, b map[string]any) int {
+	av, aok := sortValue(a, s.attr)
+	bv, bok := sortValue(b, s.attr)
+	switch {
+	case !aok && !bok:
+		return 0
+	case !aok:
+		return 1
+	case !bok:
+		return -1
+	}
+
+	var c int
+	an, anum := av.(float64)
+	bn, bnum := bv.(float64)
+	switch {
+	case anum && bnum:
+		c = cmp.Compare(an, bn)
+	case anum:
+		// Numbers sort before strings.
+		c = -1
+	case bnum:
+		c = 1
+	default:
+		c = strings.Compare(scalarString(av), scalarString(bv))
+	}
+
+	if s.desc {
+		return -c
+	}
+	return c
+}
+
+func sortValue(doc map[string]any, attr string) (any, bool) {
+	vals := lookup(doc, attr)
+	if len(vals) == 0 {
+		return nil, false
+	}
+	return vals[0], true
+}
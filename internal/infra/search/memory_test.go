@@ -0,0 +1,338 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func newTestMemory(t *testing.T) *Memory {
+	t.Helper()
+
+	m := NewMemory()
+	ctx := context.Background()
+	if err := m.EnsureIndex(ctx, "programs", "id", IndexConfig{
+		SearchableAttributes: []string{"title", "transcript.text"},
+		FilterableAttributes: []string{"status", "program_type", "category"},
+		SortableAttributes:   []string{"published_at"},
+	}); err != nil {
+		t.Fatalf("ensure index: %v", err)
+	}
+
+	docs := []any{
+		map[string]any{"id": "a", "title": "Desert history", "status": "active", "program_type": "podcast", "category": "Talk's", "published_at": "2026-02-01T00:00:00Z"},
+		map[string]any{"id": "b", "title": "Sea life", "status": "active", "program_type": "documentary", "category": "Nature", "published_at": "2026-03-01T00:00:00Z",
+			"transcript": []any{map[string]any{"text": "the desert meets the sea"}}},
+		map[string]any{"id": "c", "title": "Desert night", "status": "inactive", "program_type": "podcast", "category": "Nature"},
+		map[string]any{"id": "d", "title": "Mountains", "status": "active", "program_type": "podcast"},
+	}
+	if _, err := m.AddDocuments(ctx, "programs", docs); err != nil {
+		t.Fatalf("add documents: %v", err)
+	}
+	return m
+}
+
+func hitIDs(t *testing.T, res *SearchResult) []string {
+	t.Helper()
+
+	ids := make([]string, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		var doc struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(h, &doc); err != nil {
+			t.Fatalf("decode hit: %v", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	return ids
+}
+
+func TestMemory_Search(t *testing.T) {
+	m := newTestMemory(t)
+
+	tests := []struct {
+		name string
+		req  SearchRequest
+		want []string
+	}{
+		{"query matches nested attributes", SearchRequest{Query: "DESERT"}, []string{"a", "b", "c"}},
+		{"every term must match", SearchRequest{Query: "desert night"}, []string{"c"}},
+		{"escaped quote", SearchRequest{Filter: `status = 'active' AND category = 'Talk\'s'`}, []string{"a"}},
+		{"equality ignores case", SearchRequest{Filter: `category = "nature"`}, []string{"b", "c"}},
+		{"or and not", SearchRequest{Filter: `program_type = documentary OR NOT (status = 'active')`}, []string{"b", "c"}},
+		{"in list", SearchRequest{Filter: `category IN ['Nature', "Talk's"] AND status != inactive`}, []string{"a", "b"}},
+		{"missing attribute", SearchRequest{Filter: `category NOT IN [Nature]`}, []string{"a", "d"}},
+		{"sort puts missing values last", SearchRequest{Filter: "status = 'active'", Sort: []string{"published_at:desc"}}, []string{"b", "a", "d"}},
+		{"paging", SearchRequest{Sort: []string{"published_at:asc"}, Page: 2, PerPage: 2}, []string{"c", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := m.Search(context.Background(), "programs", tt.req)
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			if got := hitIDs(t, res); !slices.Equal(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMemory_SearchRejectsUnconfiguredAttributes(t *testing.T) {
+	m := newTestMemory(t)
+	ctx := context.Background()
+
+	for _, req := range []SearchRequest{
+		{Filter: "title = 'x'"},
+		{Sort: []string{"title:asc"}},
+		{Filter: "status = 'active"},
+		{Filter: "status = "},
+	} {
+		if _, err := m.Search(ctx, "programs", req); err == nil {
+			t.Fatalf("expected %+v to fail", req)
+		}
+	}
+	if _, err := m.Search(ctx, "missing", SearchRequest{}); err == nil {
+		t.Fatalf("expected unknown index to fail")
+	}
+}
+
+func TestMemory_UpsertAndDelete(t *testing.T) {
+	m := newTestMemory(t)
+	ctx := context.Background()
+
+	if _, err := m.AddDocuments(ctx, "programs", []any{map[string]any{"id": "a", "title": "Renamed", "status": "active"}}); err != nil {
+		t.Fatalf("add documents: %v", err)
+	}
+	if _, err := m.DeleteDocument(ctx, "programs", "b"); err != nil {
+		t.Fatalf("delete document: %v", err)
+	}
+
+	res, err := m.Search(ctx, "programs", SearchRequest{})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if got := hitIDs(t, res); !slices.Equal(got, []string{"a", "c", "d"}) || res.TotalHits != 3 {
+		t.Fatalf("unexpected hits %v (total %d)", got, res.TotalHits)
+	}
+
+	if _, err := m.DeleteDocuments(ctx, "programs", []string{"c", "missing"}); err != nil {
+		t.Fatalf("delete documents: %v", err)
+	}
+	res, err = m.Search(ctx, "programs", SearchRequest{})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if got := hitIDs(t, res); !slices.Equal(got, []string{"a", "d"}) {
+		t.Fatalf("unexpected hits after batch delete %v", got)
+	}
+}
+
+func TestMemory_Documents(t *testing.T) {
+	m := newTestMemory(t)
+	ctx := context.Background()
+
+	page, err := m.Documents(ctx, "programs", 1, 2, []string{"id", "status"})
+	if err != nil {
+		t.Fatalf("documents: %v", err)
+	}
+	if page.Total != 4 || len(page.Documents) != 2 {
+		t.Fatalf("unexpected page of %d documents (total %d)", len(page.Documents), page.Total)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(page.Documents[0], &doc); err != nil {
+		t.Fatalf("decode document: %v", err)
+	}
+	if !reflect.DeepEqual(doc, map[string]any{"id": "b", "status": "active"}) {
+		t.Fatalf("unexpected projected document %v", doc)
+	}
+
+	page, err = m.Documents(ctx, "programs", 3, 10, nil)
+	if err != nil {
+		t.Fatalf("documents: %v", err)
+	}
+	if len(page.Documents) != 1 || !json.Valid(page.Documents[0]) {
+		t.Fatalf("unexpected last page %s", page.Documents)
+	}
+	if page, err = m.Documents(ctx, "programs", 10, 10, nil); err != nil || len(page.Documents) != 0 {
+		t.Fatalf("expected an empty page past the end, got %v, %v", page, err)
+	}
+}
+
+func TestMemory_RejectedWriteFailsTask(t *testing.T) {
+	m := newTestMemory(t)
+	ctx := context.Background()
+
+	task, err := m.AddDocuments(ctx, "programs", []any{map[string]any{"title": "No ID"}})
+	if err != nil {
+		t.Fatalf("expected the write to be accepted, got %v", err)
+	}
+	if err := m.WaitForTask(ctx, task); !errors.Is(err, ErrTaskFailed) {
+		t.Fatalf("expected failed task, got %v", err)
+	}
+
+	task, err = m.DeleteDocuments(ctx, "missing", []string{"a"})
+	if err != nil {
+		t.Fatalf("expected the write to be accepted, got %v", err)
+	}
+	if err := m.WaitForTask(ctx, task); !errors.Is(err, ErrTaskFailed) {
+		t.Fatalf("expected failed task for a missing index, got %v", err)
+	}
+
+	task, err = m.AddDocuments(ctx, "programs", []any{map[string]any{"id": "e", "title": "Fine"}})
+	if err != nil {
+		t.Fatalf("add documents: %v", err)
+	}
+	if err := m.WaitForTask(ctx, task); err != nil {
+		t.Fatalf("expected task to succeed, got %v", err)
+	}
+}
+
+func TestMemory_SwapAndDeleteIndex(t *testing.T) {
+	m := newTestMemory(t)
+	ctx := context.Background()
+
+	if err := m.SwapIndexes(ctx, "programs", "programs_v2"); err == nil {
+		t.Fatal("expected error swapping with a missing index")
+	}
+
+	if err := m.EnsureIndex(ctx, "programs_v2", "id", IndexConfig{}); err != nil {
+		t.Fatalf("ensure index: %v", err)
+	}
+	if _, err := m.AddDocuments(ctx, "programs_v2", []any{map[string]any{"id": "z", "title": "Rebuilt"}}); err != nil {
+		t.Fatalf("add documents: %v", err)
+	}
+	if err := m.SwapIndexes(ctx, "programs", "programs_v2"); err != nil {
+		t.Fatalf("swap: %v", err)
+	}
+
+	res, err := m.Search(ctx, "programs", SearchRequest{})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if got := hitIDs(t, res); !slices.Equal(got, []string{"z"}) {
+		t.Fatalf("expected rebuilt index under the live name, got %v", got)
+	}
+
+	if err := m.DeleteIndex(ctx, "programs_v2"); err != nil {
+		t.Fatalf("delete index: %v", err)
+	}
+	if err := m.DeleteIndex(ctx, "programs_v2"); err != nil {
+		t.Fatalf("deleting a missing index: %v", err)
+	}
+	if _, err := m.Search(ctx, "programs_v2", SearchRequest{}); err == nil {
+		t.Fatal("expected deleted index to be gone")
+	}
+}
+
+func TestMemory_DeleteDocumentsByFilter(t *testing.T) {
+	m := newTestMemory(t)
+	ctx := context.Background()
+
+	if _, err := m.DeleteDocumentsByFilter(ctx, "programs", `category IN ["Nature", "Talk's"]`); err != nil {
+		t.Fatalf("delete documents by filter: %v", err)
+	}
+	res, err := m.Search(ctx, "programs", SearchRequest{})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if got := hitIDs(t, res); !slices.Equal(got, []string{"d"}) {
+		t.Fatalf("unexpected hits after delete by filter %v", got)
+	}
+
+	if _, err := m.DeleteDocumentsByFilter(ctx, "programs", `title = "Mountains"`); err == nil {
+		t.Fatal("expected error for non-filterable attribute")
+	}
+}
+
+func TestMemory_SearchDistinctAndRetrieve(t *testing.T) {
+	m := newTestMemory(t)
+
+	res, err := m.Search(context.Background(), "programs", SearchRequest{
+		Distinct:             "program_type",
+		AttributesToRetrieve: []string{"id", "program_type"},
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if got := hitIDs(t, res); !slices.Equal(got, []string{"a", "b"}) || res.TotalHits != 2 {
+		t.Fatalf("unexpected distinct hits %v (total %d)", got, res.TotalHits)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(res.Hits[0], &doc); err != nil {
+		t.Fatalf("decode hit: %v", err)
+	}
+	if len(doc) != 2 {
+		t.Fatalf("expected only the retrieved attributes, got %v", doc)
+	}
+
+	if _, err := m.Search(context.Background(), "programs", SearchRequest{Distinct: "title"}); err == nil {
+		t.Fatal("expected error for non-filterable distinct attribute")
+	}
+}
+
+func TestMemory_SearchFacets(t *testing.T) {
+	m := newTestMemory(t)
+
+	res, err := m.Search(context.Background(), "programs", SearchRequest{
+		Filter:  `status = "active"`,
+		Facets:  []string{"program_type", "category"},
+		PerPage: 1,
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+
+	// Counts cover every page, and documents without the attribute are skipped.
+	want := map[string]map[string]int64{
+		"program_type": {"podcast": 2, "documentary": 1},
+		"category":     {"Talk's": 1, "Nature": 1},
+	}
+	if !reflect.DeepEqual(res.Facets, want) {
+		t.Fatalf("facets = %v, want %v", res.Facets, want)
+	}
+
+	if _, err := m.Search(context.Background(), "programs", SearchRequest{Facets: []string{"title"}}); err == nil {
+		t.Fatal("expected error for non-filterable facet")
+	}
+}
+
+func TestMemory_SearchFormatted(t *testing.T) {
+	m := newTestMemory(t)
+
+	res, err := m.Search(context.Background(), "programs", SearchRequest{
+		Query:                 "desert",
+		Filter:                `program_type = podcast`,
+		AttributesToHighlight: []string{"title"},
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+
+	var got []string
+	for _, h := range res.Hits {
+		var doc struct {
+			Title     string `json:"title"`
+			Formatted struct {
+				Title string `json:"title"`
+			} `json:"_formatted"`
+		}
+		if err := json.Unmarshal(h, &doc); err != nil {
+			t.Fatalf("decode hit: %v", err)
+		}
+		if doc.Title == "" {
+			t.Fatal("formatting must keep the original attributes")
+		}
+		got = append(got, doc.Formatted.Title)
+	}
+
+	want := []string{"<em>Desert</em> history", "<em>Desert</em> night"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("formatted titles = %v, want %v", got, want)
+	}
+}
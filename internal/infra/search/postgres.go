@@ -0,0 +1,471 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// postgresIndex and postgresSuggestIndex are the indexes the Postgres backend
+// can serve: it reads the tables directly instead of a synced copy.
+const (
+	postgresIndex        = "programs"
+	postgresSuggestIndex = "program_suggestions"
+)
+
+// postgresTranscriptIndex holds the transcript cues Meilisearch hits are
+// matched against. search_vector does not cover transcripts, so Postgres
+// hits never have a cue to find.
+const postgresTranscriptIndex = "program_transcripts"
+
+// postgresQuery matches either the Arabic or the English parse of the query,
+// so the same search box works for both languages. The query is normalized
+// like the search_vector column it is matched against.
+const postgresQuery = `(websearch_to_tsquery('arabic', normalize_arabic($1)) || websearch_to_tsquery('english', normalize_arabic($1)))`
+
+const postgresSelect = `
+	SELECT p.id, p.title, p.description, p.program_type, p.status,
+	       p.duration::TEXT AS duration, p.published_at,
+	       c.name AS category, l.code AS language,
+	       p.thumbnail, p.video_url, p.created_at,
+	       COUNT(*) OVER () AS total_hits`
+
+const postgresFrom = `
+	FROM programs p
+	LEFT JOIN categories c ON c.id = p.category_id
+	LEFT JOIN languages l ON l.id = p.language_id
+	WHERE p.deleted_at IS NULL
+`
+
+// postgresHeadline marks every word of a text column that matches the query
+// with STX/ETX, parsing the text with the program's own language so stems
+// line up. The markers become highlight tags, or are dropped, when the hit
+// is formatted.
+const postgresHeadline = `ts_headline(
+	         (CASE WHEN l.code = 'ar' THEN 'arabic' ELSE 'english' END)::regconfig,
+	         %s, ` + postgresQuery + `,
+	         'HighlightAll=true, StartSel=' || chr(2) || ', StopSel=' || chr(3))`
+
+// postgresColumns maps document attributes to the columns the worker builds
+// them from.
+var postgresColumns = map[string]string{
+	"status":       "p.status",
+	"program_type": "p.program_type",
+	"category":     "c.name",
+	"language":     "l.code",
+	"published_at": "p.published_at",
+	"created_at":   "p.created_at",
+}
+
+type postgresSearcher struct {
+	db *sqlx.DB
+}
+
+// NewPostgres returns a Searcher over the programs table's search_vector
+// column. It accepts the same filter and sort syntax as Meilisearch and
+// returns hits shaped like the documents the worker indexes, without
+// transcripts.
+func NewPostgres(db *sqlx.DB) Searcher {
+	return &postgresSearcher{db: db}
+}
+
+type postgresHit struct {
+	ID          string         `db:"id"`
+	Title       string         `db:"title"`
+	Description string         `db:"description"`
+	ProgramType string         `db:"program_type"`
+	Status      string         `db:"status"`
+	Duration    sql.NullString `db:"duration"`
+	PublishedAt sql.NullTime   `db:"published_at"`
+	Category    sql.NullString `db:"category"`
+	Language    sql.NullString `db:"language"`
+	Thumbnail   string         `db:"thumbnail"`
+	VideoURL    string         `db:"video_url"`
+	CreatedAt   time.Time      `db:"created_at"`
+	TotalHits   int64          `db:"total_hits"`
+
+	TitleHeadline       sql.NullString `db:"title_headline"`
+	DescriptionHeadline sql.NullString `db:"description_headline"`
+}
+
+type postgresFacetCount struct {
+	Value string `db:"value"`
+	Count int64  `db:"count"`
+}
+
+type postgresDocument struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	ProgramType string  `json:"program_type"`
+	Status      string  `json:"status"`
+	Duration    *string `json:"duration,omitempty"`
+	PublishedAt *string `json:"published_at,omitempty"`
+	Category    *string `json:"category,omitempty"`
+	Language    *string `json:"language,omitempty"`
+	Thumbnail   string  `json:"thumbnail"`
+	VideoURL    string  `json:"video_url"`
+	CreatedAt   string  `json:"created_at"`
+
+	Formatted map[string]string `json:"_formatted,omitempty"`
+}
+
+func (s *postgresSearcher) Search(ctx context.Context, index string, req SearchRequest) (*SearchResult, error) {
+	switch index {
+	case postgresIndex:
+	case postgresSuggestIndex:
+		return s.suggest(ctx, req)
+	case postgresTranscriptIndex:
+		return nil, fmt.Errorf("postgres search: index %q: %w", index, ErrUnsupported)
+	default:
+		return nil, fmt.Errorf("postgres search: unsupported index %q", index)
+	}
+
+	query, args, err := buildPostgresQuery(req)
+	if err != nil {
+		return nil, fmt.Errorf("postgres search: %w", err)
+	}
+
+	var rows []postgresHit
+	if err := s.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("postgres search: %w", err)
+	}
+
+	result := &SearchResult{
+		Hits:    make([]json.RawMessage, 0, len(rows)),
+		Page:    req.Page,
+		PerPage: req.PerPage,
+	}
+	f := newFormatter(req)
+	for _, row := range rows {
+		result.TotalHits = row.TotalHits
+
+		raw, err := json.Marshal(row.document(f))
+		if err != nil {
+			return nil, fmt.Errorf("marshal search hit: %w", err)
+		}
+		result.Hits = append(result.Hits, raw)
+	}
+
+	for _, attr := range req.Facets {
+		query, args, err := buildPostgresFacetQuery(req, attr)
+		if err != nil {
+			return nil, fmt.Errorf("postgres search: %w", err)
+		}
+
+		var counts []postgresFacetCount
+		if err := s.db.SelectContext(ctx, &counts, query, args...); err != nil {
+			return nil, fmt.Errorf("postgres search facets: %w", err)
+		}
+
+		if result.Facets == nil {
+			result.Facets = make(map[string]map[string]int64, len(req.Facets))
+		}
+		result.Facets[attr] = make(map[string]int64, len(counts))
+		for _, c := range counts {
+			result.Facets[attr][c.Value] = c.Count
+		}
+	}
+	return result, nil
+}
+
+func (h *postgresHit) document(f *formatter) postgresDocument {
+	doc := postgresDocument{
+		ID:          h.ID,
+		Title:       h.Title,
+		Description: h.Description,
+		ProgramType: h.ProgramType,
+		Status:      h.Status,
+		Thumbnail:   h.Thumbnail,
+		VideoURL:    h.VideoURL,
+		CreatedAt:   h.CreatedAt.Format(time.RFC3339),
+	}
+	if h.Duration.Valid {
+		doc.Duration = &h.Duration.String
+	}
+	if h.PublishedAt.Valid {
+		p := h.PublishedAt.Time.Format(time.RFC3339Nano)
+		doc.PublishedAt = &p
+	}
+	if h.Category.Valid {
+		doc.Category = &h.Category.String
+	}
+	if h.Language.Valid {
+		doc.Language = &h.Language.String
+	}
+
+	if f != nil {
+		doc.Formatted = make(map[string]string)
+		for _, attr := range f.attributes() {
+			switch attr {
+			case "title":
+				doc.Formatted[attr] = formatHeadline(f, attr, h.Title, h.TitleHeadline)
+			case "description":
+				doc.Formatted[attr] = formatHeadline(f, attr, h.Description, h.DescriptionHeadline)
+			}
+		}
+	}
+	return doc
+}
+
+// formatHeadline formats text using the matches ts_headline marked, or with
+// no matches when there was no query.
+func formatHeadline(f *formatter, attr, text string, headline sql.NullString) string {
+	var matches [][2]int
+	if headline.Valid {
+		text, matches = parseHeadline(headline.String)
+	}
+	return f.format(attr, text, func(from, to int) bool {
+		for _, m := range matches {
+			if from < m[1] && m[0] < to {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// parseHeadline strips the STX/ETX markers from a ts_headline result and
+// returns the byte ranges they enclosed.
+func parseHeadline(s string) (string, [][2]int) {
+	var b strings.Builder
+	var matches [][2]int
+	start := -1
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\x02':
+			start = b.Len()
+		case '\x03':
+			if start >= 0 {
+				matches = append(matches, [2]int{start, b.Len()})
+				start = -1
+			}
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), matches
+}
+
+// buildPostgresQuery renders req as a single statement. With a query, $1 is
+// the query text so the tsquery can be referenced from both WHERE and ORDER
+// BY. The total comes from a window count and is zero past the last page.
+func buildPostgresQuery(req SearchRequest) (string, []any, error) {
+	b := &postgresBuilder{columns: postgresColumns}
+
+	var sb strings.Builder
+	sb.WriteString(postgresSelect)
+	if strings.TrimSpace(req.Query) != "" && newFormatter(req) != nil {
+		sb.WriteString(",\n\t       " + fmt.Sprintf(postgresHeadline, "p.title") + " AS title_headline")
+		sb.WriteString(",\n\t       " + fmt.Sprintf(postgresHeadline, "p.description") + " AS description_headline")
+	} else {
+		sb.WriteString(",\n\t       NULL AS title_headline, NULL AS description_headline")
+	}
+	sb.WriteString(postgresFrom)
+
+	hasQuery, err := b.where(&sb, req)
+	if err != nil {
+		return "", nil, err
+	}
+
+	order, err := b.order(req.Sort)
+	if err != nil {
+		return "", nil, err
+	}
+	if hasQuery {
+		order = append(order, "ts_rank(p.search_vector, "+postgresQuery+") DESC")
+	}
+	order = append(order, "p.id ASC")
+	sb.WriteString("\tORDER BY " + strings.Join(order, ", ") + "\n")
+	sb.WriteString(b.limit(req))
+
+	return sb.String(), b.args, nil
+}
+
+// buildPostgresFacetQuery counts the values of attr among the rows req
+// matches, ignoring paging and sort.
+func buildPostgresFacetQuery(req SearchRequest, attr string) (string, []any, error) {
+	col, ok := postgresColumns[attr]
+	if !ok {
+		return "", nil, fmt.Errorf("attribute %q is not filterable", attr)
+	}
+
+	b := &postgresBuilder{columns: postgresColumns}
+
+	var sb strings.Builder
+	sb.WriteString("\n\tSELECT " + col + "::TEXT AS value, COUNT(*) AS count" + postgresFrom)
+	sb.WriteString("\t  AND " + col + " IS NOT NULL\n")
+	if _, err := b.where(&sb, req); err != nil {
+		return "", nil, err
+	}
+	sb.WriteString("\tGROUP BY 1\n")
+
+	return sb.String(), b.args, nil
+}
+
+// postgresBuilder binds arguments and translates filters and sorts using
+// columns, which maps document attributes to SQL expressions.
+type postgresBuilder struct {
+	columns map[string]string
+	args    []any
+}
+
+// where appends the query and filter conditions of req. The query, when
+// present, is bound first so it is always $1.
+func (b *postgresBuilder) where(sb *strings.Builder, req SearchRequest) (hasQuery bool, err error) {
+	hasQuery = strings.TrimSpace(req.Query) != ""
+	if hasQuery {
+		b.arg(req.Query)
+		sb.WriteString("\t  AND p.search_vector @@ " + postgresQuery + "\n")
+	}
+
+	filter, _, err := parseFilter(req.Filter)
+	if err != nil {
+		return false, err
+	}
+	cond, err := b.filter(filter)
+	if err != nil {
+		return false, err
+	}
+	sb.WriteString("\t  AND " + cond + "\n")
+	return hasQuery, nil
+}
+
+// order translates sort rules. Meilisearch puts documents without the
+// attribute last in either direction.
+func (b *postgresBuilder) order(rules []string) ([]string, error) {
+	var order []string
+	for _, r := range rules {
+		attr, dir, ok := strings.Cut(r, ":")
+		col, known := b.columns[attr]
+		if !ok || !known || (dir != "asc" && dir != "desc") {
+			return nil, fmt.Errorf("invalid sort %q", r)
+		}
+		order = append(order, fmt.Sprintf("%s %s NULLS LAST", col, strings.ToUpper(dir)))
+	}
+	return order, nil
+}
+
+func (b *postgresBuilder) limit(req SearchRequest) string {
+	page := max(req.Page, 1)
+	perPage := req.PerPage
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	return fmt.Sprintf("\tLIMIT %s OFFSET %s\n", b.arg(perPage), b.arg((page-1)*perPage))
+}
+
+func (b *postgresBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// filter translates a parsed filter to SQL. Comparisons against NULL are
+// wrapped in IS NOT TRUE under negation so documents lacking the attribute
+// match NOT and != as they do in Meilisearch.
+func (b *postgresBuilder) filter(e filterExpr) (string, error) {
+	switch x := e.(type) {
+	case andExpr:
+		return b.join(x, " AND ", "TRUE")
+	case orExpr:
+		return b.join(x, " OR ", "FALSE")
+	case notExpr:
+		inner, err := b.filter(x.expr)
+		if err != nil {
+			return "", err
+		}
+		return "(" + inner + ") IS NOT TRUE", nil
+	case compareExpr:
+		col, ok := b.columns[x.attr]
+		if !ok {
+			return "", fmt.Errorf("attribute %q is not filterable", x.attr)
+		}
+		switch x.op {
+		case "EXISTS":
+			return col + " IS NOT NULL", nil
+		case "=":
+			return fmt.Sprintf("lower(%s) = lower(%s::TEXT)", col, b.arg(x.values[0])), nil
+		case "!=":
+			return fmt.Sprintf("(lower(%s) = lower(%s::TEXT)) IS NOT TRUE", col, b.arg(x.values[0])), nil
+		case "IN":
+			values := make([]string, len(x.values))
+			for i, v := range x.values {
+				values[i] = strings.ToLower(v)
+			}
+			return fmt.Sprintf("lower(%s) = ANY(%s::TEXT[])", col, b.arg(pq.Array(values))), nil
+		default:
+			return fmt.Sprintf("%s %s %s", col, x.op, b.arg(x.values[0])), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported filter expression %T", e)
+}
+
+func (b *postgresBuilder) join(exprs []filterExpr, sep, empty string) (string, error) {
+	if len(exprs) == 0 {
+		return empty, nil
+	}
+	parts := make([]string, 0, len(exprs))
+	for _, e := range exprs {
+		s, err := b.filter(e)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, s)
+	}
+	return "(" + strings.Join(parts, sep) + ")", nil
+}
+
+// noopIndexer stands in for Meilisearch when Postgres is the only backend:
+// the generated search_vector column keeps itself current, and relevance
+// settings have no Postgres equivalent.
+type noopIndexer struct{}
+
+func (noopIndexer) EnsureIndex(ctx context.Context, index string, primaryKey string, cfg IndexConfig) error {
+	return nil
+}
+
+func (noopIndexer) AddDocuments(ctx context.Context, index string, docs []any) (Task, error) {
+	return Task{}, nil
+}
+
+func (noopIndexer) DeleteDocument(ctx context.Context, index string, docID string) (Task, error) {
+	return Task{}, nil
+}
+
+func (noopIndexer) DeleteDocuments(ctx context.Context, index string, docIDs []string) (Task, error) {
+	return Task{}, nil
+}
+
+func (noopIndexer) DeleteDocumentsByFilter(ctx context.Context, index string, filter string) (Task, error) {
+	return Task{}, nil
+}
+
+func (noopIndexer) WaitForTask(ctx context.Context, task Task) error {
+	return nil
+}
+
+func (noopIndexer) Settings(ctx context.Context, index string) (*IndexSettings, error) {
+	return &IndexSettings{}, nil
+}
+
+func (noopIndexer) ApplySettings(ctx context.Context, index string, settings IndexSettings) error {
+	return nil
+}
+
+func (noopIndexer) SwapIndexes(ctx context.Context, a, b string) error {
+	return nil
+}
+
+func (noopIndexer) DeleteIndex(ctx context.Context, index string) error {
+	return nil
+}
+
+func (noopIndexer) Documents(ctx context.Context, index string, offset, limit int, fields []string) (*DocumentPage, error) {
+	return nil, fmt.Errorf("get documents: %w", ErrUnsupported)
+}
@@ -0,0 +1,136 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"cms-api/internal/pkg/textnorm"
+)
+
+// postgresSuggestSelect derives the suggestion documents the worker indexes:
+// one per published program title and one per category.
+const postgresSuggestSelect = `
+	SELECT s.id, s.type, s.text, s.program_id, s.slug, s.language,
+	       COUNT(*) OVER () AS total_hits
+	FROM (
+		SELECT 'program-' || p.id AS id, 'title' AS type, p.title AS text,
+		       p.id::TEXT AS program_id, NULL AS slug, l.code AS language
+		FROM programs p
+		LEFT JOIN languages l ON l.id = p.language_id
+		WHERE p.status = 'active' AND p.deleted_at IS NULL
+		UNION ALL
+		SELECT 'category-' || c.id, 'category', c.name, NULL, c.slug, NULL
+		FROM categories c
+	) s
+	WHERE TRUE
+`
+
+var postgresSuggestColumns = map[string]string{
+	"type":     "s.type",
+	"language": "s.language",
+}
+
+type postgresSuggestHit struct {
+	ID        string         `db:"id"`
+	Type      string         `db:"type"`
+	Text      string         `db:"text"`
+	ProgramID sql.NullString `db:"program_id"`
+	Slug      sql.NullString `db:"slug"`
+	Language  sql.NullString `db:"language"`
+	TotalHits int64          `db:"total_hits"`
+}
+
+type postgresSuggestDocument struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"`
+	Text      string  `json:"text"`
+	ProgramID *string `json:"program_id,omitempty"`
+	Slug      *string `json:"slug,omitempty"`
+	Language  *string `json:"language,omitempty"`
+}
+
+func (s *postgresSearcher) suggest(ctx context.Context, req SearchRequest) (*SearchResult, error) {
+	query, args, err := buildPostgresSuggestQuery(req)
+	if err != nil {
+		return nil, fmt.Errorf("postgres suggest: %w", err)
+	}
+
+	var rows []postgresSuggestHit
+	if err := s.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("postgres suggest: %w", err)
+	}
+
+	result := &SearchResult{
+		Hits:    make([]json.RawMessage, 0, len(rows)),
+		Page:    req.Page,
+		PerPage: req.PerPage,
+	}
+	for _, row := range rows {
+		result.TotalHits = row.TotalHits
+
+		doc := postgresSuggestDocument{ID: row.ID, Type: row.Type, Text: row.Text}
+		if row.ProgramID.Valid {
+			doc.ProgramID = &row.ProgramID.String
+		}
+		if row.Slug.Valid {
+			doc.Slug = &row.Slug.String
+		}
+		if row.Language.Valid {
+			doc.Language = &row.Language.String
+		}
+
+		raw, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("marshal suggestion: %w", err)
+		}
+		result.Hits = append(result.Hits, raw)
+	}
+	return result, nil
+}
+
+// buildPostgresSuggestQuery matches every normalized query word as the start
+// of a word in the normalized text, so the last, partly typed word matches as
+// a prefix. Texts that
+// start with the query rank first, then shorter ones.
+func buildPostgresSuggestQuery(req SearchRequest) (string, []any, error) {
+	b := &postgresBuilder{columns: postgresSuggestColumns}
+
+	var sb strings.Builder
+	sb.WriteString(postgresSuggestSelect)
+
+	terms := strings.Fields(textnorm.Normalize(req.Query))
+	for _, t := range terms {
+		sb.WriteString(fmt.Sprintf("\t  AND (' ' || normalize_arabic(s.text)) LIKE %s\n", b.arg("% "+escapeLike(t)+"%")))
+	}
+
+	filter, _, err := parseFilter(req.Filter)
+	if err != nil {
+		return "", nil, err
+	}
+	cond, err := b.filter(filter)
+	if err != nil {
+		return "", nil, err
+	}
+	sb.WriteString("\t  AND " + cond + "\n")
+
+	order, err := b.order(req.Sort)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(terms) > 0 {
+		order = append(order, fmt.Sprintf("(normalize_arabic(s.text) LIKE %s) DESC", b.arg(escapeLike(strings.Join(terms, " "))+"%")))
+	}
+	order = append(order, "length(s.text) ASC", "s.id ASC")
+	sb.WriteString("\tORDER BY " + strings.Join(order, ", ") + "\n")
+	sb.WriteString(b.limit(req))
+
+	return sb.String(), b.args, nil
+}
+
+// escapeLike escapes LIKE wildcards using the default backslash escape.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
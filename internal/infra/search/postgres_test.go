@@ -0,0 +1,169 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestBuildPostgresQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      SearchRequest
+		contains []string
+		args     []any
+	}{
+		{
+			name:     "no query binds only paging",
+			req:      SearchRequest{Page: 2, PerPage: 10},
+			contains: []string{"AND TRUE", "ORDER BY p.id ASC", "LIMIT $1 OFFSET $2"},
+			args:     []any{10, 10},
+		},
+		{
+			name: "query is $1 and ranks results",
+			req:  SearchRequest{Query: "desert", Filter: `status = "active"`},
+			contains: []string{
+				"p.search_vector @@ " + postgresQuery,
+				"lower(p.status) = lower($2::TEXT)",
+				"ts_rank(p.search_vector, " + postgresQuery + ") DESC, p.id ASC",
+				"LIMIT $3 OFFSET $4",
+			},
+			args: []any{"desert", "active", defaultPerPage, 0},
+		},
+		{
+			name: "negation keeps rows without the attribute",
+			req:  SearchRequest{Filter: `NOT category = "Nature" AND language != "ar"`},
+			contains: []string{
+				"(lower(c.name) = lower($1::TEXT)) IS NOT TRUE",
+				"(lower(l.code) = lower($2::TEXT)) IS NOT TRUE",
+			},
+			args: []any{"Nature", "ar", defaultPerPage, 0},
+		},
+		{
+			name:     "IN lowercases values into an array",
+			req:      SearchRequest{Filter: `program_type IN ["Podcast", "documentary"]`},
+			contains: []string{"lower(p.program_type) = ANY($1::TEXT[])"},
+			args:     []any{pq.Array([]string{"podcast", "documentary"}), defaultPerPage, 0},
+		},
+		{
+			name:     "sort puts missing values last",
+			req:      SearchRequest{Sort: []string{"published_at:desc"}},
+			contains: []string{"ORDER BY p.published_at DESC NULLS LAST, p.id ASC"},
+			args:     []any{defaultPerPage, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := buildPostgresQuery(tt.req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(query, s) {
+					t.Errorf("query missing %q:\n%s", s, query)
+				}
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestBuildPostgresFacetQuery(t *testing.T) {
+	query, args, err := buildPostgresFacetQuery(SearchRequest{
+		Query:   "desert",
+		Filter:  `status = "active"`,
+		Sort:    []string{"published_at:desc"},
+		Page:    3,
+		PerPage: 5,
+	}, "category")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, s := range []string{
+		"SELECT c.name::TEXT AS value, COUNT(*) AS count",
+		"AND c.name IS NOT NULL",
+		"p.search_vector @@ " + postgresQuery,
+		"lower(p.status) = lower($2::TEXT)",
+		"GROUP BY 1",
+	} {
+		if !strings.Contains(query, s) {
+			t.Errorf("query missing %q:\n%s", s, query)
+		}
+	}
+	if strings.Contains(query, "LIMIT") || strings.Contains(query, "ORDER BY") {
+		t.Errorf("facet query should ignore paging and sort:\n%s", query)
+	}
+	if !reflect.DeepEqual(args, []any{"desert", "active"}) {
+		t.Errorf("args = %#v", args)
+	}
+
+	if _, _, err := buildPostgresFacetQuery(SearchRequest{}, "title"); err == nil {
+		t.Fatal("expected error for non-filterable facet")
+	}
+}
+
+func TestBuildPostgresQuery_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		req  SearchRequest
+	}{
+		{"unknown filter attribute", SearchRequest{Filter: `title = "x"`}},
+		{"unknown sort attribute", SearchRequest{Sort: []string{"title:asc"}}},
+		{"bad sort direction", SearchRequest{Sort: []string{"published_at:up"}}},
+		{"malformed filter", SearchRequest{Filter: `status = `}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := buildPostgresQuery(tt.req); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestBuildPostgresSuggestQuery(t *testing.T) {
+	query, args, err := buildPostgresSuggestQuery(SearchRequest{
+		Query:   "Desert  100%_",
+		Filter:  `type = 'title'`,
+		PerPage: 5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, s := range []string{
+		"(' ' || normalize_arabic(s.text)) LIKE $1",
+		"(' ' || normalize_arabic(s.text)) LIKE $2",
+		"lower(s.type) = lower($3::TEXT)",
+		"ORDER BY (normalize_arabic(s.text) LIKE $4) DESC, length(s.text) ASC, s.id ASC",
+		"LIMIT $5 OFFSET $6",
+	} {
+		if !strings.Contains(query, s) {
+			t.Errorf("query missing %q:\n%s", s, query)
+		}
+	}
+	want := []any{"% desert%", `% 100\%\_%`, "title", `desert 100\%\_%`, 5, 0}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %#v, want %#v", args, want)
+	}
+
+	// Terms are normalized like the text they are matched against.
+	_, args, err = buildPostgresSuggestQuery(SearchRequest{Query: "أُسامة", PerPage: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args[0] != "% اسامه%" {
+		t.Errorf("args[0] = %q, want normalized term", args[0])
+	}
+
+	if _, _, err := buildPostgresSuggestQuery(SearchRequest{Filter: `status = active`}); err == nil {
+		t.Fatal("expected error for unknown filter attribute")
+	}
+}
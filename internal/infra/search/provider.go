@@ -0,0 +1,42 @@
+package search
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"cms-api/internal/config"
+)
+
+var Module = fx.Module("search",
+	fx.Provide(New),
+)
+
+// New selects the search backend from config. In auto mode the app boots
+// even when Meilisearch is down: searches fall back to Postgres until it
+// passes a health check, and index jobs fail and retry as usual.
+func New(lc fx.Lifecycle, cfg *config.Config, db *sqlx.DB, log *zap.Logger) (SearchOut, error) {
+	switch cfg.Search.Backend {
+	case config.SearchBackendMeilisearch:
+		return NewMeilisearch(lc, cfg, log)
+
+	case config.SearchBackendPostgres:
+		log.Info("Search backed by Postgres full-text search")
+		return SearchOut{Searcher: NewPostgres(db), Indexer: noopIndexer{}}, nil
+
+	case config.SearchBackendAuto:
+		ms := newMeilisearchClient(cfg)
+		fallback := NewFallbackSearcher(ms, NewPostgres(db), ms.Health, log)
+		fallback.RegisterLifecycle(lc, cfg.Search.HealthInterval)
+
+		log.Info("Search backed by Meilisearch with Postgres fallback",
+			zap.String("host", cfg.Search.Host),
+			zap.Int("port", cfg.Search.Port),
+		)
+		return SearchOut{Searcher: fallback, Indexer: ms}, nil
+	}
+
+	return SearchOut{}, fmt.Errorf("unknown search backend %q", cfg.Search.Backend)
+}
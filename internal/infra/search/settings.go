@@ -0,0 +1,78 @@
+package search
+
+import (
+	"maps"
+	"slices"
+)
+
+// IndexSettings are the relevance settings of an index that can change at
+// runtime. A nil field means "leave as is" to ApplySettings and "unknown" to
+// DiffSettings; an empty one clears the setting.
+type IndexSettings struct {
+	// Synonyms maps a word to the words a query for it should also match.
+	Synonyms      map[string][]string
+	StopWords     []string
+	RankingRules  []string
+	TypoTolerance *TypoTolerance
+}
+
+type TypoTolerance struct {
+	Enabled             bool
+	MinWordSizeOneTypo  int
+	MinWordSizeTwoTypos int
+	DisableOnWords      []string
+	DisableOnAttributes []string
+}
+
+// DefaultRankingRules are the rules Meilisearch applies to a new index.
+var DefaultRankingRules = []string{"words", "typo", "proximity", "attribute", "sort", "exactness"}
+
+// DefaultTypoTolerance is the typo tolerance of a new Meilisearch index.
+var DefaultTypoTolerance = TypoTolerance{Enabled: true, MinWordSizeOneTypo: 5, MinWordSizeTwoTypos: 9}
+
+// Empty reports whether s changes nothing.
+func (s IndexSettings) Empty() bool {
+	return s.Synonyms == nil && s.StopWords == nil && s.RankingRules == nil && s.TypoTolerance == nil
+}
+
+// DiffSettings returns the fields of desired that differ from current, so
+// only those are sent to the engine. Word lists are compared as sets, since
+// engines may return them reordered; ranking rules are ordered.
+func DiffSettings(current, desired IndexSettings) IndexSettings {
+	var patch IndexSettings
+	if desired.Synonyms != nil && !sameSynonyms(current.Synonyms, desired.Synonyms) {
+		patch.Synonyms = desired.Synonyms
+	}
+	if desired.StopWords != nil && !sameSet(current.StopWords, desired.StopWords) {
+		patch.StopWords = desired.StopWords
+	}
+	if desired.RankingRules != nil && !slices.Equal(current.RankingRules, desired.RankingRules) {
+		patch.RankingRules = desired.RankingRules
+	}
+	if desired.TypoTolerance != nil && !sameTypoTolerance(current.TypoTolerance, desired.TypoTolerance) {
+		patch.TypoTolerance = desired.TypoTolerance
+	}
+	return patch
+}
+
+func sameSynonyms(a, b map[string][]string) bool {
+	return maps.EqualFunc(a, b, sameSet)
+}
+
+func sameTypoTolerance(a, b *TypoTolerance) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Enabled == b.Enabled &&
+		a.MinWordSizeOneTypo == b.MinWordSizeOneTypo &&
+		a.MinWordSizeTwoTypos == b.MinWordSizeTwoTypos &&
+		sameSet(a.DisableOnWords, b.DisableOnWords) &&
+		sameSet(a.DisableOnAttributes, b.DisableOnAttributes)
+}
+
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
@@ -0,0 +1,57 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffSettings(t *testing.T) {
+	typo := DefaultTypoTolerance
+	current := IndexSettings{
+		Synonyms:      map[string][]string{"podcast": {"بودكاست", "show"}},
+		StopWords:     []string{"the", "a"},
+		RankingRules:  DefaultRankingRules,
+		TypoTolerance: &typo,
+	}
+
+	t.Run("reordered word lists are equal", func(t *testing.T) {
+		desired := IndexSettings{
+			Synonyms:  map[string][]string{"podcast": {"show", "بودكاست"}},
+			StopWords: []string{"a", "the"},
+		}
+		if patch := DiffSettings(current, desired); !patch.Empty() {
+			t.Fatalf("expected empty patch, got %+v", patch)
+		}
+	})
+
+	t.Run("ranking rules are ordered", func(t *testing.T) {
+		rules := slices.Clone(DefaultRankingRules)
+		rules[0], rules[1] = rules[1], rules[0]
+		patch := DiffSettings(current, IndexSettings{RankingRules: rules})
+		if !slices.Equal(patch.RankingRules, rules) {
+			t.Fatalf("expected ranking rules in patch, got %+v", patch)
+		}
+	})
+
+	t.Run("only changed fields", func(t *testing.T) {
+		changed := typo
+		changed.DisableOnAttributes = []string{"title"}
+		patch := DiffSettings(current, IndexSettings{
+			StopWords:     []string{"the", "a"},
+			TypoTolerance: &changed,
+		})
+		if patch.StopWords != nil || patch.Synonyms != nil || patch.RankingRules != nil {
+			t.Fatalf("unexpected fields in patch %+v", patch)
+		}
+		if patch.TypoTolerance == nil || patch.TypoTolerance.DisableOnAttributes[0] != "title" {
+			t.Fatalf("expected typo tolerance in patch, got %+v", patch.TypoTolerance)
+		}
+	})
+
+	t.Run("empty clears", func(t *testing.T) {
+		patch := DiffSettings(current, IndexSettings{StopWords: []string{}})
+		if patch.StopWords == nil || len(patch.StopWords) != 0 {
+			t.Fatalf("expected stop words cleared, got %+v", patch.StopWords)
+		}
+	})
+}
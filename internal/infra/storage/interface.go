@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrObjectNotFound = errors.New("storage: object not found")
+
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+type Object interface {
+	io.ReadSeekCloser
+}
+
+type Storage interface {
+	Open(ctx context.Context, key string) (Object, *ObjectInfo, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+}
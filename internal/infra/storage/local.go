@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"cms-api/internal/config"
+)
+
+var Module = fx.Module("storage",
+	fx.Provide(NewLocal),
+)
+
+type localStorage struct {
+	root *os.Root
+}
+
+// NewLocal serves objects from a directory on disk. Keys are slash-separated
+// paths relative to the root; os.Root rejects any key that escapes it.
+func NewLocal(lc fx.Lifecycle, cfg *config.Config, log *zap.Logger) (Storage, error) {
+	dir := cfg.Storage.LocalRoot
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage root: %w", err)
+	}
+
+	log.Info("Local storage ready", zap.String("root", dir))
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return root.Close()
+		},
+	})
+
+	return &localStorage{root: root}, nil
+}
+
+func (s *localStorage) Open(ctx context.Context, key string) (Object, *ObjectInfo, error) {
+	name, err := objectName(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := s.root.Open(name)
+	if err != nil {
+		return nil, nil, mapError(err)
+	}
+
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, mapError(err)
+	}
+	if st.IsDir() {
+		f.Close()
+		return nil, nil, ErrObjectNotFound
+	}
+
+	return f, &ObjectInfo{Key: key, Size: st.Size(), ModTime: st.ModTime()}, nil
+}
+
+func (s *localStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	name, err := objectName(key)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := s.root.Stat(name)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	if st.IsDir() {
+		return nil, ErrObjectNotFound
+	}
+
+	return &ObjectInfo{Key: key, Size: st.Size(), ModTime: st.ModTime()}, nil
+}
+
+func objectName(key string) (string, error) {
+	name := strings.TrimPrefix(path.Clean("/"+key), "/")
+	if name == "" {
+		return "", ErrObjectNotFound
+	}
+	return name, nil
+}
+
+func mapError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrObjectNotFound
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/modules/auth/entity"
+	"cms-api/internal/pkg/apperror"
+)
+
+type memoryRepository struct {
+	store *memdb.Store
+}
+
+// NewMemory returns a Repository over the users, roles and refresh tokens in
+// store. The hermetic app uses it in place of Postgres; tests use it directly.
+func NewMemory(store *memdb.Store) Repository {
+	return &memoryRepository{store: store}
+}
+
+func (r *memoryRepository) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	var user *entity.User
+	err := r.store.Read(func(t *memdb.Tables) error {
+		for _, u := range t.Users {
+			if u.Email == email && !u.DeletedAt.Valid {
+				user = toUser(u)
+				return nil
+			}
+		}
+		return apperror.ErrInvalidCredentials
+	})
+	return user, err
+}
+
+func (r *memoryRepository) GetUserByID(ctx context.Context, id string) (*entity.User, error) {
+	var user *entity.User
+	err := r.store.Read(func(t *memdb.Tables) error {
+		u, ok := t.Users[id]
+		if !ok || u.DeletedAt.Valid {
+			return apperror.ErrNotFound
+		}
+		user = toUser(u)
+		return nil
+	})
+	return user, err
+}
+
+func (r *memoryRepository) GetUserRoles(ctx context.Context, userID string) ([]string, error) {
+	var roles []string
+	err := r.store.Read(func(t *memdb.Tables) error {
+		if u, ok := t.Users[userID]; ok {
+			roles = slices.Clone(u.Roles)
+		}
+		return nil
+	})
+	return roles, err
+}
+
+func (r *memoryRepository) CreateRefreshToken(ctx context.Context, rt *entity.RefreshToken) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		if _, ok := t.Users[rt.UserID]; !ok {
+			return memdb.ErrForeignKey
+		}
+		for _, existing := range t.RefreshTokens {
+			if existing.TokenHash == rt.TokenHash {
+				return memdb.ErrDuplicateKey
+			}
+		}
+
+		rt.ID = uuid.NewString()
+		rt.CreatedAt = t.Now
+		t.RefreshTokens[rt.ID] = &memdb.RefreshToken{
+			ID:        rt.ID,
+			UserID:    rt.UserID,
+			TokenHash: rt.TokenHash,
+			ExpiresAt: rt.ExpiresAt,
+			CreatedAt: rt.CreatedAt,
+		}
+		return nil
+	})
+}
+
+func (r *memoryRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	var token *entity.RefreshToken
+	err := r.store.Read(func(t *memdb.Tables) error {
+		for _, rt := range t.RefreshTokens {
+			if rt.TokenHash == tokenHash {
+				token = &entity.RefreshToken{
+					ID:        rt.ID,
+					UserID:    rt.UserID,
+					TokenHash: rt.TokenHash,
+					ExpiresAt: rt.ExpiresAt,
+					RevokedAt: rt.RevokedAt,
+					CreatedAt: rt.CreatedAt,
+				}
+				return nil
+			}
+		}
+		return apperror.ErrInvalidToken
+	})
+	return token, err
+}
+
+func (r *memoryRepository) RevokeRefreshToken(ctx context.Context, id string) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		if rt, ok := t.RefreshTokens[id]; ok && !rt.RevokedAt.Valid {
+			rt.RevokedAt = sql.NullTime{Time: t.Now, Valid: true}
+		}
+		return nil
+	})
+}
+
+func (r *memoryRepository) RevokeAllUserRefreshTokens(ctx context.Context, userID string) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		for _, rt := range t.RefreshTokens {
+			if rt.UserID == userID && !rt.RevokedAt.Valid {
+				rt.RevokedAt = sql.NullTime{Time: t.Now, Valid: true}
+			}
+		}
+		return nil
+	})
+}
+
+func toUser(u *memdb.User) *entity.User {
+	return &entity.User{
+		ID:           u.ID,
+		Email:        u.Email,
+		PasswordHash: u.PasswordHash,
+		Status:       u.Status,
+		CreatedAt:    u.CreatedAt,
+	}
+}
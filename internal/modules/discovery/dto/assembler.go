@@ -2,11 +2,15 @@ package dto
 
 import (
 	"encoding/json"
+	"time"
 
 	"cms-api/internal/modules/discovery/entity"
 	"cms-api/internal/pkg/dbutil"
+	"cms-api/internal/pkg/transcript"
 )
 
+const chaptersVersion = "1.2.0"
+
 func ToResponse(p *entity.Program) *ProgramResponse {
 	resp := &ProgramResponse{
 		ID:           p.ID,
@@ -58,6 +62,8 @@ func HitsToSearchResponse(hits []json.RawMessage, query string, page, perPage in
 			Language:    doc.Language,
 			Thumbnail:   doc.Thumbnail,
 			VideoURL:    doc.VideoURL,
+
+			Formatted: doc.Formatted,
 		})
 	}
 
@@ -70,6 +76,36 @@ func HitsToSearchResponse(hits []json.RawMessage, query string, page, perPage in
 	}, nil
 }
 
+// HitsToSuggestResponse decodes hits from the suggestion index.
+func HitsToSuggestResponse(query string, titleHits, categoryHits []json.RawMessage) (*SuggestResponse, error) {
+	resp := &SuggestResponse{
+		Query:      query,
+		Titles:     make([]*TitleSuggestion, 0, len(titleHits)),
+		Categories: make([]*CategorySuggestion, 0, len(categoryHits)),
+	}
+	for _, raw := range titleHits {
+		var doc suggestionDocument
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+		resp.Titles = append(resp.Titles, &TitleSuggestion{ID: doc.ProgramID, Title: doc.Text})
+	}
+	for _, raw := range categoryHits {
+		var doc suggestionDocument
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+		resp.Categories = append(resp.Categories, &CategorySuggestion{Name: doc.Text, Slug: doc.Slug})
+	}
+	return resp, nil
+}
+
+type suggestionDocument struct {
+	Text      string `json:"text"`
+	ProgramID string `json:"program_id"`
+	Slug      string `json:"slug"`
+}
+
 type programDocument struct {
 	ID          string  `json:"id"`
 	Title       string  `json:"title"`
@@ -82,4 +118,69 @@ type programDocument struct {
 	Language    *string `json:"language,omitempty"`
 	Thumbnail   string  `json:"thumbnail"`
 	VideoURL    string  `json:"video_url"`
+
+	Formatted *FormattedResponse `json:"_formatted,omitempty"`
+}
+
+type transcriptCueDocument struct {
+	ProgramID string `json:"program_id"`
+	StartMS   int64  `json:"start_ms"`
+	EndMS     int64  `json:"end_ms"`
+	Text      string `json:"text"`
+}
+
+// HitsToTranscriptMatches decodes hits from the transcript index into the
+// matching cue of each program.
+func HitsToTranscriptMatches(hits []json.RawMessage) (map[string]*TranscriptMatchResponse, error) {
+	matches := make(map[string]*TranscriptMatchResponse, len(hits))
+	for _, raw := range hits {
+		var cue transcriptCueDocument
+		if err := json.Unmarshal(raw, &cue); err != nil {
+			return nil, err
+		}
+		if _, ok := matches[cue.ProgramID]; !ok {
+			matches[cue.ProgramID] = &TranscriptMatchResponse{StartMS: cue.StartMS, EndMS: cue.EndMS, Text: cue.Text}
+		}
+	}
+	return matches, nil
+}
+
+func ToChaptersResponse(chapters []*entity.Chapter) *ChaptersResponse {
+	items := make([]*ChapterResponse, 0, len(chapters))
+	for _, c := range chapters {
+		item := &ChapterResponse{
+			StartTime: msToSeconds(c.StartMS),
+			Title:     c.Title,
+			Img:       c.Image.String,
+			URL:       c.URL.String,
+		}
+		if c.EndMS.Valid {
+			end := msToSeconds(c.EndMS.Int64)
+			item.EndTime = &end
+		}
+		// The spec treats a missing toc as true, so only emit the opt-out.
+		if !c.TOC {
+			toc := false
+			item.TOC = &toc
+		}
+		items = append(items, item)
+	}
+
+	return &ChaptersResponse{Version: chaptersVersion, Chapters: items}
+}
+
+func ToWebVTT(cues []*entity.TranscriptCue) []byte {
+	out := make([]transcript.Cue, 0, len(cues))
+	for _, c := range cues {
+		out = append(out, transcript.Cue{
+			Start: time.Duration(c.StartMS) * time.Millisecond,
+			End:   time.Duration(c.EndMS) * time.Millisecond,
+			Text:  c.Text,
+		})
+	}
+	return transcript.EncodeWebVTT(out)
+}
+
+func msToSeconds(ms int64) float64 {
+	return float64(ms) / 1000
 }
@@ -0,0 +1,211 @@
+package dto
+
+import (
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"cms-api/internal/modules/discovery/entity"
+)
+
+func TestHitsToTranscriptMatches(t *testing.T) {
+	hits := []json.RawMessage{
+		json.RawMessage(`{"id": "p1-1", "program_id": "p1", "start_ms": 60000, "end_ms": 65000, "text": "Today we talk about Desert Ecology"}`),
+		json.RawMessage(`{"id": "p1-2", "program_id": "p1", "start_ms": 70000, "end_ms": 72000, "text": "ecology matters"}`),
+		json.RawMessage(`{"id": "p2-0", "program_id": "p2", "start_ms": 0, "end_ms": 4000, "text": "Welcome to the show"}`),
+	}
+
+	matches, err := HitsToTranscriptMatches(hits)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected a match per program, got %v", matches)
+	}
+	// Hits come best first, so the first cue of a program wins.
+	if m := matches["p1"]; m == nil || m.StartMS != 60000 || m.EndMS != 65000 {
+		t.Fatalf("unexpected match for p1: %+v", m)
+	}
+	if m := matches["p2"]; m == nil || m.Text != "Welcome to the show" {
+		t.Fatalf("unexpected match for p2: %+v", m)
+	}
+}
+
+func TestHitsToSearchResponse_Formatted(t *testing.T) {
+	hits := []json.RawMessage{
+		json.RawMessage(`{"id": "p1", "title": "رحلة الصحراء", "_formatted": {"id": "p1", "title": "رحلة <mark>الصحراء</mark>", "description": "…في <mark>الصحراء</mark>…"}}`),
+		json.RawMessage(`{"id": "p2", "title": "Plain"}`),
+	}
+
+	resp, err := HitsToSearchResponse(hits, "الصحراء", 1, 20, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f := resp.Items[0].Formatted
+	if f == nil || f.Title != "رحلة <mark>الصحراء</mark>" || f.Description != "…في <mark>الصحراء</mark>…" {
+		t.Fatalf("unexpected formatted: %+v", f)
+	}
+	if resp.Items[1].Formatted != nil {
+		t.Fatalf("expected no formatted values, got %+v", resp.Items[1].Formatted)
+	}
+}
+
+func TestToChaptersResponse(t *testing.T) {
+	resp := ToChaptersResponse([]*entity.Chapter{
+		{StartMS: 0, Title: "Intro", TOC: true},
+		{StartMS: 1500, EndMS: sql.NullInt64{Int64: 3000, Valid: true}, Title: "Ad", Image: sql.NullString{String: "https://example.com/a.png", Valid: true}, TOC: false},
+	})
+
+	if resp.Version != "1.2.0" || len(resp.Chapters) != 2 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if resp.Chapters[0].TOC != nil || resp.Chapters[0].EndTime != nil {
+		t.Fatalf("expected toc and endTime omitted, got %+v", resp.Chapters[0])
+	}
+	ad := resp.Chapters[1]
+	if ad.StartTime != 1.5 || ad.EndTime == nil || *ad.EndTime != 3 || ad.TOC == nil || *ad.TOC || ad.Img == "" {
+		t.Fatalf("unexpected chapter %+v", ad)
+	}
+}
+
+func TestToRSSFeed(t *testing.T) {
+	published := time.Date(2026, 2, 20, 10, 0, 0, 0, time.UTC)
+	category := &entity.FeedCategory{ID: 1, Name: "بودكاست", Slug: "podcast"}
+	items := []*entity.FeedItem{
+		{
+			ID:                 "019539a2-b826-7640-9a20-e2b6c8e12345",
+			Title:              "Episode & One",
+			DurationSeconds:    sql.NullInt64{Int64: 3735, Valid: true},
+			PublishedAt:        published,
+			Thumbnail:          "https://example.com/thumb.jpg",
+			MediaPath:          "episodes/one.mp3",
+			HasChapters:        true,
+			HasTranscript:      true,
+			TranscriptLanguage: sql.NullString{String: "ar", Valid: true},
+		},
+		{ID: "skipped", Title: "No media", PublishedAt: published},
+	}
+	enclosures := map[string]FeedEnclosure{
+		"019539a2-b826-7640-9a20-e2b6c8e12345": {Length: 1024, Type: "audio/mpeg"},
+	}
+
+	out, err := ToRSSFeed(category, items, enclosures, FeedOptions{
+		SelfURL: "https://api.example.com/api/v1/discover/feeds/podcast.xml",
+		SiteURL: "https://example.com",
+		APIURL:  "https://api.example.com",
+		Author:  "CMS",
+	})
+	if err != nil {
+		t.Fatalf("render feed: %v", err)
+	}
+
+	var feed struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			Title       string `xml:"title"`
+			PodcastGUID string `xml:"https://podcastindex.org/namespace/1.0 guid"`
+			Image       struct {
+				Href string `xml:"href,attr"`
+			} `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+			Items []struct {
+				Title string `xml:"title"`
+				GUID  struct {
+					IsPermaLink string `xml:"isPermaLink,attr"`
+					Value       string `xml:",chardata"`
+				} `xml:"guid"`
+				PubDate   string `xml:"pubDate"`
+				Enclosure struct {
+					URL    string `xml:"url,attr"`
+					Length int64  `xml:"length,attr"`
+					Type   string `xml:"type,attr"`
+				} `xml:"enclosure"`
+				Duration string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+				Chapters struct {
+					URL string `xml:"url,attr"`
+				} `xml:"https://podcastindex.org/namespace/1.0 chapters"`
+				Transcript struct {
+					URL      string `xml:"url,attr"`
+					Language string `xml:"language,attr"`
+				} `xml:"https://podcastindex.org/namespace/1.0 transcript"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(out, &feed); err != nil {
+		t.Fatalf("feed is not valid XML: %v\n%s", err, out)
+	}
+
+	if feed.Version != "2.0" || feed.Channel.Title != "بودكاست" {
+		t.Fatalf("unexpected channel %+v", feed.Channel)
+	}
+	if feed.Channel.PodcastGUID == "" || feed.Channel.Image.Href != "https://example.com/thumb.jpg" {
+		t.Fatalf("unexpected channel guid/image %+v", feed.Channel)
+	}
+	if len(feed.Channel.Items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(feed.Channel.Items))
+	}
+
+	item := feed.Channel.Items[0]
+	base := "https://api.example.com/api/v1/discover/programs/019539a2-b826-7640-9a20-e2b6c8e12345"
+	if item.Title != "Episode & One" ||
+		item.GUID.Value != "019539a2-b826-7640-9a20-e2b6c8e12345" || item.GUID.IsPermaLink != "false" ||
+		item.PubDate != "Fri, 20 Feb 2026 10:00:00 +0000" ||
+		item.Enclosure.URL != base+"/media" || item.Enclosure.Length != 1024 || item.Enclosure.Type != "audio/mpeg" ||
+		item.Duration != "3735" ||
+		item.Chapters.URL != base+"/chapters" ||
+		item.Transcript.URL != base+"/transcript" || item.Transcript.Language != "ar" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+}
+
+func TestToStructuredData(t *testing.T) {
+	published := time.Date(2026, 2, 20, 10, 0, 0, 0, time.UTC)
+	base := entity.ProgramDetail{
+		Program: entity.Program{
+			ID:           "p1",
+			Title:        "Title",
+			Description:  "Desc",
+			PublishedAt:  sql.NullTime{Time: published, Valid: true},
+			Thumbnail:    "https://example.com/thumb.jpg",
+			VideoURL:     "https://youtu.be/x",
+			CategoryName: sql.NullString{String: "Podcast", Valid: true},
+			LanguageCode: sql.NullString{String: "ar", Valid: true},
+		},
+		DurationSeconds: sql.NullInt64{Int64: 3735, Valid: true},
+	}
+
+	t.Run("podcast episode", func(t *testing.T) {
+		p := base
+		p.ProgramType = "podcast"
+		p.MediaType = sql.NullString{String: "audio/mpeg", Valid: true}
+
+		sd := ToStructuredData(&p, "https://example.com", "https://api.example.com/media")
+		if sd.Type != "PodcastEpisode" || sd.Context != "https://schema.org" ||
+			sd.URL != "https://example.com/programs/p1" || sd.Duration != "PT1H2M15S" ||
+			sd.DatePublished != "2026-02-20T10:00:00Z" || sd.InLanguage != "ar" {
+			t.Fatalf("unexpected structured data %+v", sd)
+		}
+		if sd.PartOfSeries == nil || sd.PartOfSeries.Name != "Podcast" {
+			t.Fatalf("expected partOfSeries, got %+v", sd.PartOfSeries)
+		}
+		if sd.AssociatedMedia == nil || sd.AssociatedMedia.ContentURL != "https://api.example.com/media" ||
+			sd.AssociatedMedia.EncodingFormat != "audio/mpeg" || sd.Video != nil {
+			t.Fatalf("unexpected media %+v", sd.AssociatedMedia)
+		}
+	})
+
+	t.Run("documentary movie", func(t *testing.T) {
+		p := base
+		p.ProgramType = "documentary"
+
+		sd := ToStructuredData(&p, "https://example.com", "")
+		if sd.Type != "Movie" || sd.AssociatedMedia != nil || sd.PartOfSeries != nil {
+			t.Fatalf("unexpected structured data %+v", sd)
+		}
+		if sd.Video == nil || sd.Video.Type != "VideoObject" || sd.Video.EmbedURL != "https://youtu.be/x" ||
+			sd.Video.ThumbnailURL == "" || sd.Video.UploadDate == "" {
+			t.Fatalf("unexpected video %+v", sd.Video)
+		}
+	})
+}
@@ -0,0 +1,244 @@
+package dto
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cms-api/internal/modules/discovery/entity"
+)
+
+const (
+	namespaceITunes  = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+	namespacePodcast = "https://podcastindex.org/namespace/1.0"
+	namespaceAtom    = "http://www.w3.org/2005/Atom"
+)
+
+// podcastGUIDNamespace is the UUIDv5 namespace defined for podcast:guid.
+var podcastGUIDNamespace = uuid.MustParse("ead4c236-bf58-58c6-a2c6-a6b28d128cb6")
+
+// FeedOptions carries channel metadata that is not stored per category.
+type FeedOptions struct {
+	SelfURL        string
+	SiteURL        string
+	APIURL         string
+	Author         string
+	OwnerEmail     string
+	Image          string
+	Language       string
+	ITunesCategory string
+	Explicit       bool
+	LastBuild      time.Time
+}
+
+// FeedEnclosure is resolved from storage because RSS requires the byte length.
+type FeedEnclosure struct {
+	Length int64
+	Type   string
+}
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	ITunesNS  string     `xml:"xmlns:itunes,attr"`
+	PodcastNS string     `xml:"xmlns:podcast,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title          string          `xml:"title"`
+	Link           string          `xml:"link"`
+	Description    string          `xml:"description"`
+	Language       string          `xml:"language,omitempty"`
+	LastBuildDate  string          `xml:"lastBuildDate,omitempty"`
+	Generator      string          `xml:"generator,omitempty"`
+	AtomLink       rssAtomLink     `xml:"atom:link"`
+	Image          *rssImage       `xml:"image,omitempty"`
+	ITunesAuthor   string          `xml:"itunes:author,omitempty"`
+	ITunesOwner    *rssITunesOwner `xml:"itunes:owner,omitempty"`
+	ITunesImage    *rssHref        `xml:"itunes:image,omitempty"`
+	ITunesCategory *rssCategory    `xml:"itunes:category,omitempty"`
+	ITunesExplicit string          `xml:"itunes:explicit"`
+	ITunesType     string          `xml:"itunes:type"`
+	PodcastGUID    string          `xml:"podcast:guid"`
+	Items          []rssItem       `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssImage struct {
+	URL   string `xml:"url"`
+	Title string `xml:"title"`
+	Link  string `xml:"link"`
+}
+
+type rssITunesOwner struct {
+	Name  string `xml:"itunes:name"`
+	Email string `xml:"itunes:email,omitempty"`
+}
+
+type rssHref struct {
+	Href string `xml:"href,attr"`
+}
+
+type rssCategory struct {
+	Text string `xml:"text,attr"`
+}
+
+type rssItem struct {
+	Title             string                `xml:"title"`
+	Description       string                `xml:"description"`
+	Link              string                `xml:"link,omitempty"`
+	GUID              rssGUID               `xml:"guid"`
+	PubDate           string                `xml:"pubDate"`
+	Enclosure         rssEnclosure          `xml:"enclosure"`
+	ITunesTitle       string                `xml:"itunes:title"`
+	ITunesDuration    string                `xml:"itunes:duration,omitempty"`
+	ITunesImage       *rssHref              `xml:"itunes:image,omitempty"`
+	ITunesEpisodeType string                `xml:"itunes:episodeType"`
+	PodcastChapters   *rssPodcastChapters   `xml:"podcast:chapters,omitempty"`
+	PodcastTranscript *rssPodcastTranscript `xml:"podcast:transcript,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type rssPodcastChapters struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssPodcastTranscript struct {
+	URL      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	Language string `xml:"language,attr,omitempty"`
+}
+
+// ToRSSFeed renders a category as an RSS 2.0 podcast feed. Items without a
+// resolved enclosure are skipped since podcast clients cannot play them.
+func ToRSSFeed(c *entity.FeedCategory, items []*entity.FeedItem, enclosures map[string]FeedEnclosure, opts FeedOptions) ([]byte, error) {
+	channel := rssChannel{
+		Title:          c.Name,
+		Link:           opts.SiteURL,
+		Description:    c.Description,
+		Language:       opts.Language,
+		Generator:      "cms-api",
+		AtomLink:       rssAtomLink{Href: opts.SelfURL, Rel: "self", Type: "application/rss+xml"},
+		ITunesExplicit: strconv.FormatBool(opts.Explicit),
+		ITunesType:     "episodic",
+		PodcastGUID:    podcastGUID(opts.SelfURL),
+		Items:          make([]rssItem, 0, len(items)),
+	}
+	if channel.Link == "" {
+		channel.Link = opts.SelfURL
+	}
+	if channel.Description == "" {
+		channel.Description = c.Name
+	}
+	if !opts.LastBuild.IsZero() {
+		channel.LastBuildDate = opts.LastBuild.UTC().Format(time.RFC1123Z)
+	}
+	if opts.Author != "" {
+		channel.ITunesAuthor = opts.Author
+		channel.ITunesOwner = &rssITunesOwner{Name: opts.Author, Email: opts.OwnerEmail}
+	}
+	if opts.ITunesCategory != "" {
+		channel.ITunesCategory = &rssCategory{Text: opts.ITunesCategory}
+	}
+
+	image := opts.Image
+	for _, it := range items {
+		enc, ok := enclosures[it.ID]
+		if !ok {
+			continue
+		}
+		if image == "" {
+			image = it.Thumbnail
+		}
+		channel.Items = append(channel.Items, toRSSItem(it, enc, opts))
+	}
+	if image != "" {
+		channel.ITunesImage = &rssHref{Href: image}
+		channel.Image = &rssImage{URL: image, Title: channel.Title, Link: channel.Link}
+	}
+
+	return marshalXML(rssFeed{
+		Version:   "2.0",
+		ITunesNS:  namespaceITunes,
+		PodcastNS: namespacePodcast,
+		AtomNS:    namespaceAtom,
+		Channel:   channel,
+	})
+}
+
+func toRSSItem(it *entity.FeedItem, enc FeedEnclosure, opts FeedOptions) rssItem {
+	programURL := opts.APIURL + "/api/v1/discover/programs/" + it.ID
+
+	item := rssItem{
+		Title:       it.Title,
+		Description: it.Description,
+		Link:        ProgramPageURL(opts.SiteURL, it.ID),
+		GUID:        rssGUID{IsPermaLink: false, Value: it.ID},
+		PubDate:     it.PublishedAt.UTC().Format(time.RFC1123Z),
+		Enclosure: rssEnclosure{
+			URL:    programURL + "/media",
+			Length: enc.Length,
+			Type:   enc.Type,
+		},
+		ITunesTitle:       it.Title,
+		ITunesEpisodeType: "full",
+	}
+	if it.DurationSeconds.Valid {
+		item.ITunesDuration = strconv.FormatInt(it.DurationSeconds.Int64, 10)
+	}
+	if it.Thumbnail != "" {
+		item.ITunesImage = &rssHref{Href: it.Thumbnail}
+	}
+	if it.HasChapters {
+		item.PodcastChapters = &rssPodcastChapters{URL: programURL + "/chapters", Type: "application/json+chapters"}
+	}
+	if it.HasTranscript {
+		item.PodcastTranscript = &rssPodcastTranscript{
+			URL:      programURL + "/transcript",
+			Type:     "text/vtt",
+			Language: it.TranscriptLanguage.String,
+		}
+	}
+
+	return item
+}
+
+// ProgramPageURL is the front-end page that renders a program.
+func ProgramPageURL(siteURL, id string) string {
+	if siteURL == "" {
+		return ""
+	}
+	return siteURL + "/programs/" + id
+}
+
+// podcastGUID derives the channel's podcast:guid from its feed URL with the
+// scheme and trailing slashes removed, as the namespace specification requires.
+func podcastGUID(feedURL string) string {
+	u := feedURL
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+	}
+	u = strings.TrimRight(u, "/")
+	return uuid.NewSHA1(podcastGUIDNamespace, []byte(u)).String()
+}
@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"time"
+
+	"cms-api/internal/modules/discovery/entity"
+	"cms-api/internal/pkg/timeutil"
+)
+
+const schemaOrgContext = "https://schema.org"
+
+// StructuredData is a schema.org object serialised as JSON-LD. Podcasts are
+// described as PodcastEpisode and documentaries as Movie.
+type StructuredData struct {
+	Context         string        `json:"@context"`
+	Type            string        `json:"@type"`
+	ID              string        `json:"@id,omitempty"`
+	URL             string        `json:"url,omitempty"`
+	Name            string        `json:"name"`
+	Description     string        `json:"description,omitempty"`
+	DatePublished   string        `json:"datePublished,omitempty"`
+	DateModified    string        `json:"dateModified,omitempty"`
+	Duration        string        `json:"duration,omitempty"`
+	Image           string        `json:"image,omitempty"`
+	InLanguage      string        `json:"inLanguage,omitempty"`
+	Genre           string        `json:"genre,omitempty"`
+	PartOfSeries    *SchemaSeries `json:"partOfSeries,omitempty"`
+	AssociatedMedia *SchemaMedia  `json:"associatedMedia,omitempty"`
+	Video           *SchemaMedia  `json:"video,omitempty"`
+}
+
+type SchemaSeries struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type SchemaMedia struct {
+	Type           string `json:"@type"`
+	Name           string `json:"name,omitempty"`
+	Description    string `json:"description,omitempty"`
+	ContentURL     string `json:"contentUrl,omitempty"`
+	EmbedURL       string `json:"embedUrl,omitempty"`
+	EncodingFormat string `json:"encodingFormat,omitempty"`
+	Duration       string `json:"duration,omitempty"`
+	ThumbnailURL   string `json:"thumbnailUrl,omitempty"`
+	UploadDate     string `json:"uploadDate,omitempty"`
+}
+
+// ToStructuredData describes a program for search engines. mediaURL is the
+// absolute URL of the stored media, or empty when the program has none.
+func ToStructuredData(p *entity.ProgramDetail, siteURL, mediaURL string) *StructuredData {
+	pageURL := ProgramPageURL(siteURL, p.ID)
+
+	sd := &StructuredData{
+		Context:      schemaOrgContext,
+		ID:           pageURL,
+		URL:          pageURL,
+		Name:         p.Title,
+		Description:  p.Description,
+		DateModified: p.UpdatedAt.UTC().Format(time.RFC3339),
+		Image:        p.Thumbnail,
+		InLanguage:   p.LanguageCode.String,
+		Genre:        p.CategoryName.String,
+	}
+	if p.PublishedAt.Valid {
+		sd.DatePublished = p.PublishedAt.Time.UTC().Format(time.RFC3339)
+	}
+	if p.DurationSeconds.Valid {
+		sd.Duration = timeutil.ISO8601Duration(time.Duration(p.DurationSeconds.Int64) * time.Second)
+	}
+
+	media := &SchemaMedia{
+		Name:           p.Title,
+		Description:    p.Description,
+		ContentURL:     mediaURL,
+		EncodingFormat: p.MediaType.String,
+		Duration:       sd.Duration,
+		ThumbnailURL:   p.Thumbnail,
+		UploadDate:     sd.DatePublished,
+	}
+	if mediaURL == "" {
+		media.EmbedURL = p.VideoURL
+	}
+
+	switch p.ProgramType {
+	case "documentary":
+		sd.Type = "Movie"
+		media.Type = "VideoObject"
+		sd.Video = media
+	default:
+		sd.Type = "PodcastEpisode"
+		media.Type = "MediaObject"
+		if p.CategoryName.Valid {
+			sd.PartOfSeries = &SchemaSeries{Type: "PodcastSeries", Name: p.CategoryName.String}
+		}
+		sd.AssociatedMedia = media
+	}
+
+	return sd
+}
@@ -1,15 +1,21 @@
 package dto
 
+// SearchFacets are the attributes clients may request value counts for.
+var SearchFacets = []string{"category", "language", "program_type"}
+
+// SearchRequest filters are multi-select: values of one attribute are ORed,
+// different attributes are ANDed.
 type SearchRequest struct {
-	Query       string `json:"q" validate:"required,min=1,max=255"`
-	ProgramType string `json:"program_type" validate:"omitempty,oneof=podcast documentary"`
-	Category    string `json:"category" validate:"omitempty,max=255"`
-	Language    string `json:"language" validate:"omitempty,max=50"`
-	Page        int    `json:"page" validate:"omitempty,min=1"`
-	PerPage     int    `json:"per_page" validate:"omitempty,min=1,max=100"`
+	Query        string   `json:"q" validate:"required,min=1,max=255"`
+	ProgramTypes []string `json:"program_type" validate:"max=10,dive,oneof=podcast documentary"`
+	Categories   []string `json:"category" validate:"max=50,dive,max=255"`
+	Languages    []string `json:"language" validate:"max=50,dive,max=50"`
+	Facets       []string `json:"facets" validate:"max=3,dive,oneof=category language program_type"`
+	Page         int      `json:"page" validate:"omitempty,min=1"`
+	PerPage      int      `json:"per_page" validate:"omitempty,min=1,max=100"`
 }
 
-func NewSearchRequest(q, programType, category, language string, page, perPage int) SearchRequest {
+func NewSearchRequest(q string, programTypes, categories, languages, facets []string, page, perPage int) SearchRequest {
 	if page < 1 {
 		page = 1
 	}
@@ -20,13 +26,29 @@ func NewSearchRequest(q, programType, category, language string, page, perPage i
 		perPage = 100
 	}
 	return SearchRequest{
-		Query:       q,
-		ProgramType: programType,
-		Category:    category,
-		Language:    language,
-		Page:        page,
-		PerPage:     perPage,
+		Query:        q,
+		ProgramTypes: programTypes,
+		Categories:   categories,
+		Languages:    languages,
+		Facets:       facets,
+		Page:         page,
+		PerPage:      perPage,
+	}
+}
+
+type SuggestRequest struct {
+	Query string `json:"q" validate:"required,min=1,max=100"`
+	Limit int    `json:"limit" validate:"omitempty,min=1,max=10"`
+}
+
+func NewSuggestRequest(q string, limit int) SuggestRequest {
+	if limit < 1 {
+		limit = 5
+	}
+	if limit > 10 {
+		limit = 10
 	}
+	return SuggestRequest{Query: q, Limit: limit}
 }
 
 type ListRequest struct {
@@ -47,3 +69,7 @@ func NewListRequest(cursorStr string, limit int) ListRequest {
 type PathID struct {
 	ID string `validate:"required,uuid"`
 }
+
+type FeedRequest struct {
+	Slug string `validate:"required,max=120"`
+}
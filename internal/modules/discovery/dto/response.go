@@ -27,6 +27,9 @@ type SearchResultResponse struct {
 	Page           int                      `json:"page"`
 	PerPage        int                      `json:"per_page"`
 	EstimatedTotal int64                    `json:"estimated_total"`
+
+	// Facets maps each requested facet to its per-value hit counts.
+	Facets map[string]map[string]int64 `json:"facets,omitempty"`
 }
 
 type SearchProgramResponse struct {
@@ -40,4 +43,54 @@ type SearchProgramResponse struct {
 	Language    *string `json:"language"`
 	Thumbnail   string  `json:"thumbnail"`
 	VideoURL    string  `json:"video_url"`
+
+	TranscriptMatch *TranscriptMatchResponse `json:"transcript_match,omitempty"`
+	Formatted       *FormattedResponse       `json:"_formatted,omitempty"`
+}
+
+// FormattedResponse holds the title with matched words wrapped in highlight
+// tags and the description cropped to a snippet around the first match.
+type FormattedResponse struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// SuggestResponse lists completions for a partly typed query, best first.
+type SuggestResponse struct {
+	Query      string                `json:"query"`
+	Titles     []*TitleSuggestion    `json:"titles"`
+	Categories []*CategorySuggestion `json:"categories"`
+}
+
+type TitleSuggestion struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type CategorySuggestion struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// TranscriptMatchResponse points at the transcript cue that best matches the
+// search query so clients can seek straight to it.
+type TranscriptMatchResponse struct {
+	StartMS int64  `json:"start_ms"`
+	EndMS   int64  `json:"end_ms"`
+	Text    string `json:"text"`
+}
+
+// ChaptersResponse follows the Podcasting 2.0 JSON chapters format.
+type ChaptersResponse struct {
+	Version  string             `json:"version"`
+	Chapters []*ChapterResponse `json:"chapters"`
+}
+
+type ChapterResponse struct {
+	StartTime float64  `json:"startTime"`
+	EndTime   *float64 `json:"endTime,omitempty"`
+	Title     string   `json:"title"`
+	Img       string   `json:"img,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	TOC       *bool    `json:"toc,omitempty"`
 }
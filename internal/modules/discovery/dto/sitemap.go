@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+
+	"cms-api/internal/modules/discovery/entity"
+)
+
+const namespaceSitemap = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	XMLNS    string         `xml:"xmlns,attr"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// ToSitemap renders program pages on the front-end site as a sitemap urlset.
+func ToSitemap(siteURL string, entries []*entity.SitemapEntry) ([]byte, error) {
+	set := sitemapURLSet{XMLNS: namespaceSitemap, URLs: make([]sitemapURL, 0, len(entries))}
+	for _, e := range entries {
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:     ProgramPageURL(siteURL, e.ID),
+			LastMod: e.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return marshalXML(set)
+}
+
+// ToSitemapIndex renders an index pointing at the numbered sitemap pages
+// served from baseURL as /sitemap-{page}.xml.
+func ToSitemapIndex(baseURL string, pages int, lastMod time.Time) ([]byte, error) {
+	index := sitemapIndex{XMLNS: namespaceSitemap, Sitemaps: make([]sitemapEntry, 0, pages)}
+	for page := 1; page <= pages; page++ {
+		entry := sitemapEntry{Loc: baseURL + "/sitemap-" + strconv.Itoa(page) + ".xml"}
+		if !lastMod.IsZero() {
+			entry.LastMod = lastMod.UTC().Format(time.RFC3339)
+		}
+		index.Sitemaps = append(index.Sitemaps, entry)
+	}
+	return marshalXML(index)
+}
+
+func marshalXML(v any) ([]byte, error) {
+	out, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -24,3 +24,67 @@ type Program struct {
 	CategoryName sql.NullString `db:"category_name"`
 	LanguageCode sql.NullString `db:"language_code"`
 }
+
+// ProgramDetail extends Program with the fields needed to describe it as
+// structured data.
+type ProgramDetail struct {
+	Program
+	DurationSeconds sql.NullInt64  `db:"duration_seconds"`
+	MediaPath       sql.NullString `db:"media_path"`
+	MediaType       sql.NullString `db:"media_type"`
+}
+
+type ProgramMedia struct {
+	ID        string         `db:"id"`
+	MediaPath sql.NullString `db:"media_path"`
+	MediaType sql.NullString `db:"media_type"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
+
+type TranscriptCue struct {
+	StartMS int64  `db:"start_ms"`
+	EndMS   int64  `db:"end_ms"`
+	Text    string `db:"text"`
+}
+
+type Chapter struct {
+	StartMS int64          `db:"start_ms"`
+	EndMS   sql.NullInt64  `db:"end_ms"`
+	Title   string         `db:"title"`
+	URL     sql.NullString `db:"url"`
+	Image   sql.NullString `db:"image"`
+	TOC     bool           `db:"toc"`
+}
+
+type FeedCategory struct {
+	ID          int64     `db:"id"`
+	Name        string    `db:"name"`
+	Slug        string    `db:"slug"`
+	Description string    `db:"description"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+type FeedItem struct {
+	ID                 string         `db:"id"`
+	Title              string         `db:"title"`
+	Description        string         `db:"description"`
+	DurationSeconds    sql.NullInt64  `db:"duration_seconds"`
+	PublishedAt        time.Time      `db:"published_at"`
+	Thumbnail          string         `db:"thumbnail"`
+	MediaPath          string         `db:"media_path"`
+	MediaType          sql.NullString `db:"media_type"`
+	HasChapters        bool           `db:"has_chapters"`
+	TranscriptLanguage sql.NullString `db:"transcript_language"`
+	HasTranscript      bool           `db:"has_transcript"`
+	UpdatedAt          time.Time      `db:"updated_at"`
+}
+
+type SitemapStats struct {
+	Total        int64        `db:"total"`
+	LastModified sql.NullTime `db:"last_modified"`
+}
+
+type SitemapEntry struct {
+	ID        string    `db:"id"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -1,12 +1,17 @@
 package http
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 
+	"cms-api/internal/config"
 	"cms-api/internal/modules/discovery/dto"
 	"cms-api/internal/modules/discovery/service"
 	"cms-api/internal/pkg/httputil"
@@ -14,23 +19,34 @@ import (
 )
 
 type Handler struct {
-	service service.Service
-	log     *zap.Logger
+	service       service.Service
+	mediaMaxAge   time.Duration
+	feedMaxAge    time.Duration
+	sitemapMaxAge time.Duration
+	log           *zap.Logger
 }
 
-func NewHandler(service service.Service, log *zap.Logger) *Handler {
-	return &Handler{service: service, log: log}
+func NewHandler(service service.Service, cfg *config.Config, log *zap.Logger) *Handler {
+	return &Handler{
+		service:       service,
+		mediaMaxAge:   cfg.Storage.MediaCacheMaxAge,
+		feedMaxAge:    cfg.Feed.CacheTTL,
+		sitemapMaxAge: cfg.SEO.SitemapCacheTTL,
+		log:           log,
+	}
 }
 
 func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query().Get("q")
-	programType := r.URL.Query().Get("type")
-	category := r.URL.Query().Get("category")
-	language := r.URL.Query().Get("language")
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	query := r.URL.Query()
+	q := query.Get("q")
+	programTypes := nonEmpty(query["type"])
+	categories := nonEmpty(query["category"])
+	languages := nonEmpty(query["language"])
+	facets := splitList(query["facets"])
+	page, _ := strconv.Atoi(query.Get("page"))
+	perPage, _ := strconv.Atoi(query.Get("per_page"))
 
-	req := dto.NewSearchRequest(q, programType, category, language, page, perPage)
+	req := dto.NewSearchRequest(q, programTypes, categories, languages, facets, page, perPage)
 
 	if err := validator.Validate(req); err != nil {
 		httputil.ValidationError(w, err)
@@ -47,6 +63,25 @@ func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 	httputil.OK(w, resp)
 }
 
+func (h *Handler) Suggest(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	req := dto.NewSuggestRequest(r.URL.Query().Get("q"), limit)
+
+	if err := validator.Validate(req); err != nil {
+		httputil.ValidationError(w, err)
+		return
+	}
+
+	resp, err := h.service.Suggest(r.Context(), &req)
+	if err != nil {
+		h.log.Error("failed to suggest programs", zap.Error(err))
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	cursorStr := r.URL.Query().Get("cursor")
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
@@ -70,6 +105,16 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+	case "jsonld":
+		h.getStructuredData(w, r, pathID.ID)
+		return
+	default:
+		httputil.BadRequest(w, "unsupported format")
+		return
+	}
+
 	resp, err := h.service.GetByID(r.Context(), pathID.ID)
 	if err != nil {
 		httputil.HandleError(w, r, err)
@@ -78,3 +123,155 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	httputil.OK(w, resp)
 }
+
+func (h *Handler) getStructuredData(w http.ResponseWriter, r *http.Request, id string) {
+	resp, err := h.service.GetStructuredData(r.Context(), id)
+	if err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/ld+json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) StreamMedia(w http.ResponseWriter, r *http.Request) {
+	pathID := dto.PathID{ID: chi.URLParam(r, "id")}
+	if err := validator.Validate(pathID); err != nil {
+		httputil.BadRequest(w, "invalid program id")
+		return
+	}
+
+	media, err := h.service.OpenMedia(r.Context(), pathID.ID)
+	if err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+	defer media.Content.Close()
+
+	// Long episodes outlive HTTP_WRITE_TIMEOUT; the client controls pacing.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	httputil.ServeContent(w, r, httputil.ContentOptions{
+		Name:        media.Name,
+		ContentType: media.ContentType,
+		ETag:        media.ETag,
+		ModTime:     media.ModTime,
+		MaxAge:      h.mediaMaxAge,
+	}, media.Content)
+}
+
+func (h *Handler) GetChapters(w http.ResponseWriter, r *http.Request) {
+	pathID := dto.PathID{ID: chi.URLParam(r, "id")}
+	if err := validator.Validate(pathID); err != nil {
+		httputil.BadRequest(w, "invalid program id")
+		return
+	}
+
+	resp, err := h.service.GetChapters(r.Context(), pathID.ID)
+	if err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json+chapters")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) GetTranscript(w http.ResponseWriter, r *http.Request) {
+	pathID := dto.PathID{ID: chi.URLParam(r, "id")}
+	if err := validator.Validate(pathID); err != nil {
+		httputil.BadRequest(w, "invalid program id")
+		return
+	}
+
+	vtt, err := h.service.GetTranscript(r.Context(), pathID.ID)
+	if err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(vtt)
+}
+
+func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	req := dto.FeedRequest{Slug: chi.URLParam(r, "slug")}
+	if err := validator.Validate(req); err != nil {
+		httputil.BadRequest(w, "invalid feed slug")
+		return
+	}
+
+	feed, err := h.service.GetFeed(r.Context(), req.Slug)
+	if err != nil {
+		h.log.Error("failed to build feed", zap.Error(err), zap.String("slug", req.Slug))
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.ServeContent(w, r, httputil.ContentOptions{
+		Name:        req.Slug + ".xml",
+		ContentType: "application/rss+xml; charset=utf-8",
+		ETag:        feed.ETag,
+		ModTime:     feed.ModTime,
+		MaxAge:      h.feedMaxAge,
+	}, bytes.NewReader(feed.Body))
+}
+
+func (h *Handler) GetSitemapIndex(w http.ResponseWriter, r *http.Request) {
+	h.serveSitemap(w, r, 0)
+}
+
+func (h *Handler) GetSitemapPage(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(chi.URLParam(r, "page"))
+	if err != nil || page < 1 {
+		httputil.NotFound(w, "sitemap not found")
+		return
+	}
+	h.serveSitemap(w, r, page)
+}
+
+func (h *Handler) serveSitemap(w http.ResponseWriter, r *http.Request, page int) {
+	doc, err := h.service.GetSitemap(r.Context(), page)
+	if err != nil {
+		h.log.Error("failed to build sitemap", zap.Error(err), zap.Int("page", page))
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.ServeContent(w, r, httputil.ContentOptions{
+		Name:        "sitemap.xml",
+		ContentType: "application/xml; charset=utf-8",
+		ETag:        doc.ETag,
+		ModTime:     doc.ModTime,
+		MaxAge:      h.sitemapMaxAge,
+	}, bytes.NewReader(doc.Body))
+}
+
+// nonEmpty drops blank values so "category=" means no filter, as it did
+// before filters were multi-select.
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// splitList accepts both "facets=a,b" and "facets=a&facets=b".
+func splitList(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,420 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"cms-api/internal/config"
+	"cms-api/internal/modules/discovery/dto"
+	"cms-api/internal/modules/discovery/service"
+	"cms-api/internal/pkg/apperror"
+)
+
+const testProgramID = "019539a2-b826-7640-9a20-e2b6c8e12345"
+
+type readSeekNopCloser struct {
+	*bytes.Reader
+}
+
+func (readSeekNopCloser) Close() error { return nil }
+
+type fakeDiscoveryService struct {
+	media      []byte
+	modTime    time.Time
+	chapters   *dto.ChaptersResponse
+	transcript []byte
+	feed       *service.Document
+	sitemaps   map[int]*service.Document
+	search     *dto.SearchRequest
+}
+
+func (f *fakeDiscoveryService) Search(ctx context.Context, req *dto.SearchRequest) (*dto.SearchResultResponse, error) {
+	f.search = req
+	return &dto.SearchResultResponse{}, nil
+}
+
+func (f *fakeDiscoveryService) Suggest(ctx context.Context, req *dto.SuggestRequest) (*dto.SuggestResponse, error) {
+	return &dto.SuggestResponse{Query: req.Query}, nil
+}
+
+func (f *fakeDiscoveryService) List(ctx context.Context, cursorStr string, limit int) (*dto.ProgramListResponse, error) {
+	return &dto.ProgramListResponse{}, nil
+}
+
+func (f *fakeDiscoveryService) GetByID(ctx context.Context, id string) (*dto.ProgramResponse, error) {
+	return &dto.ProgramResponse{ID: id}, nil
+}
+
+func (f *fakeDiscoveryService) OpenMedia(ctx context.Context, id string) (*service.Media, error) {
+	if f.media == nil {
+		return nil, apperror.ErrNotFound
+	}
+	return &service.Media{
+		Content:     readSeekNopCloser{bytes.NewReader(f.media)},
+		Name:        "episode.mp3",
+		ContentType: "audio/mpeg",
+		ETag:        `"abc-10"`,
+		ModTime:     f.modTime,
+		Size:        int64(len(f.media)),
+	}, nil
+}
+
+func (f *fakeDiscoveryService) GetChapters(ctx context.Context, id string) (*dto.ChaptersResponse, error) {
+	if f.chapters == nil {
+		return nil, apperror.ErrNotFound
+	}
+	return f.chapters, nil
+}
+
+func (f *fakeDiscoveryService) GetTranscript(ctx context.Context, id string) ([]byte, error) {
+	if f.transcript == nil {
+		return nil, apperror.ErrNotFound
+	}
+	return f.transcript, nil
+}
+
+func (f *fakeDiscoveryService) GetFeed(ctx context.Context, slug string) (*service.Document, error) {
+	if f.feed == nil {
+		return nil, apperror.ErrNotFound
+	}
+	return f.feed, nil
+}
+
+func (f *fakeDiscoveryService) GetStructuredData(ctx context.Context, id string) (*dto.StructuredData, error) {
+	return &dto.StructuredData{Context: "https://schema.org", Type: "PodcastEpisode", Name: "Episode"}, nil
+}
+
+func (f *fakeDiscoveryService) GetSitemap(ctx context.Context, page int) (*service.Document, error) {
+	doc, ok := f.sitemaps[page]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return doc, nil
+}
+
+func newTestRouter(svc service.Service) *chi.Mux {
+	cfg := &config.Config{Storage: config.StorageConfig{MediaCacheMaxAge: time.Hour}}
+	h := NewHandler(svc, cfg, zap.NewNop())
+	router := chi.NewRouter()
+	RegisterRoutes(router, h)
+	return router
+}
+
+func TestSearch_MultiSelectAndFacets(t *testing.T) {
+	svc := &fakeDiscoveryService{}
+	router := newTestRouter(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/discover/programs/search?q=x&category=a&category=b&language=&type=podcast&facets=category,+language&facets=program_type", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+
+	got := svc.search
+	if strings.Join(got.Categories, ",") != "a,b" || len(got.Languages) != 0 || strings.Join(got.ProgramTypes, ",") != "podcast" {
+		t.Fatalf("unexpected filters: %+v", got)
+	}
+	if strings.Join(got.Facets, ",") != "category,language,program_type" {
+		t.Fatalf("unexpected facets: %v", got.Facets)
+	}
+
+	for _, target := range []string{
+		"/api/v1/discover/programs/search?q=x&facets=title",
+		"/api/v1/discover/programs/search?q=x&type=podcast&type=movie",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", target, w.Code)
+		}
+	}
+}
+
+func TestStreamMedia_Ranges(t *testing.T) {
+	modTime := time.Date(2026, 2, 20, 10, 0, 0, 0, time.UTC)
+	router := newTestRouter(&fakeDiscoveryService{media: []byte("0123456789"), modTime: modTime})
+	url := "/api/v1/discover/programs/" + testProgramID + "/media"
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+		wantBody   string
+		wantRange  string
+	}{
+		{
+			name:       "full body",
+			wantStatus: http.StatusOK,
+			wantBody:   "0123456789",
+		},
+		{
+			name:       "byte range",
+			headers:    map[string]string{"Range": "bytes=2-5"},
+			wantStatus: http.StatusPartialContent,
+			wantBody:   "2345",
+			wantRange:  "bytes 2-5/10",
+		},
+		{
+			name:       "suffix range",
+			headers:    map[string]string{"Range": "bytes=-3"},
+			wantStatus: http.StatusPartialContent,
+			wantBody:   "789",
+			wantRange:  "bytes 7-9/10",
+		},
+		{
+			name:       "unsatisfiable range",
+			headers:    map[string]string{"Range": "bytes=20-30"},
+			wantStatus: http.StatusRequestedRangeNotSatisfiable,
+		},
+		{
+			name:       "if-range matches etag",
+			headers:    map[string]string{"Range": "bytes=0-1", "If-Range": `"abc-10"`},
+			wantStatus: http.StatusPartialContent,
+			wantBody:   "01",
+		},
+		{
+			name:       "if-range stale etag returns full body",
+			headers:    map[string]string{"Range": "bytes=0-1", "If-Range": `"old"`},
+			wantStatus: http.StatusOK,
+			wantBody:   "0123456789",
+		},
+		{
+			name:       "if-none-match",
+			headers:    map[string]string{"If-None-Match": `"abc-10"`},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:       "if-modified-since",
+			headers:    map[string]string{"If-Modified-Since": modTime.Add(time.Minute).Format(http.TimeFormat)},
+			wantStatus: http.StatusNotModified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Fatalf("expected body %q, got %q", tt.wantBody, w.Body.String())
+			}
+			if tt.wantRange != "" && w.Header().Get("Content-Range") != tt.wantRange {
+				t.Fatalf("expected Content-Range %q, got %q", tt.wantRange, w.Header().Get("Content-Range"))
+			}
+			if w.Code == http.StatusOK && w.Header().Get("Accept-Ranges") != "bytes" {
+				t.Fatalf("expected Accept-Ranges bytes, got %q", w.Header().Get("Accept-Ranges"))
+			}
+		})
+	}
+}
+
+func TestStreamMedia_NotFound(t *testing.T) {
+	router := newTestRouter(&fakeDiscoveryService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/discover/programs/"+testProgramID+"/media", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestGetChapters(t *testing.T) {
+	end := 90.5
+	router := newTestRouter(&fakeDiscoveryService{chapters: &dto.ChaptersResponse{
+		Version: "1.2.0",
+		Chapters: []*dto.ChapterResponse{
+			{StartTime: 0, EndTime: &end, Title: "Intro"},
+		},
+	}})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/discover/programs/"+testProgramID+"/chapters", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json+chapters" {
+		t.Fatalf("expected chapters content type, got %q", ct)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body["version"] != "1.2.0" {
+		t.Fatalf("expected version 1.2.0, got %v", body["version"])
+	}
+	chapters, _ := body["chapters"].([]any)
+	if len(chapters) != 1 {
+		t.Fatalf("expected 1 chapter, got %v", body["chapters"])
+	}
+	first := chapters[0].(map[string]any)
+	if first["startTime"] != 0.0 || first["endTime"] != 90.5 || first["title"] != "Intro" {
+		t.Fatalf("unexpected chapter %v", first)
+	}
+}
+
+func TestGetTranscript(t *testing.T) {
+	vtt := []byte("WEBVTT\n\n00:00:00.000 --> 00:00:01.000\nhello\n")
+
+	tests := []struct {
+		name       string
+		svc        *fakeDiscoveryService
+		wantStatus int
+	}{
+		{name: "found", svc: &fakeDiscoveryService{transcript: vtt}, wantStatus: http.StatusOK},
+		{name: "missing", svc: &fakeDiscoveryService{}, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(tt.svc)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/discover/programs/"+testProgramID+"/transcript", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "text/vtt; charset=utf-8" {
+				t.Fatalf("expected text/vtt, got %q", ct)
+			}
+			if !bytes.Equal(w.Body.Bytes(), vtt) {
+				t.Fatalf("unexpected body %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetFeed(t *testing.T) {
+	modTime := time.Date(2026, 2, 20, 10, 0, 0, 0, time.UTC)
+	router := newTestRouter(&fakeDiscoveryService{feed: &service.Document{
+		Body:    []byte(`<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"></rss>`),
+		ETag:    `"feed-1"`,
+		ModTime: modTime,
+	}})
+
+	tests := []struct {
+		name       string
+		path       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{name: "full feed", path: "podcast.xml", wantStatus: http.StatusOK},
+		{name: "etag match", path: "podcast.xml", headers: map[string]string{"If-None-Match": `"feed-1"`}, wantStatus: http.StatusNotModified},
+		{name: "not modified since", path: "podcast.xml", headers: map[string]string{"If-Modified-Since": modTime.Format(http.TimeFormat)}, wantStatus: http.StatusNotModified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/discover/feeds/"+tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/rss+xml; charset=utf-8" {
+				t.Fatalf("expected rss content type, got %q", ct)
+			}
+			if w.Header().Get("Last-Modified") != modTime.Format(http.TimeFormat) {
+				t.Fatalf("unexpected Last-Modified %q", w.Header().Get("Last-Modified"))
+			}
+		})
+	}
+}
+
+func TestGetByID_Formats(t *testing.T) {
+	router := newTestRouter(&fakeDiscoveryService{})
+	url := "/api/v1/discover/programs/" + testProgramID
+
+	tests := []struct {
+		name            string
+		query           string
+		wantStatus      int
+		wantContentType string
+	}{
+		{name: "default json", query: "", wantStatus: http.StatusOK, wantContentType: "application/json"},
+		{name: "json-ld", query: "?format=jsonld", wantStatus: http.StatusOK, wantContentType: "application/ld+json"},
+		{name: "unknown format", query: "?format=xml", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, url+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantContentType != "" && w.Header().Get("Content-Type") != tt.wantContentType {
+				t.Fatalf("expected %s, got %q", tt.wantContentType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestGetSitemap(t *testing.T) {
+	router := newTestRouter(&fakeDiscoveryService{sitemaps: map[int]*service.Document{
+		0: {Body: []byte("<sitemapindex/>"), ETag: `"index"`},
+		2: {Body: []byte("<urlset/>"), ETag: `"page-2"`},
+	}})
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{path: "/sitemap.xml", wantStatus: http.StatusOK, wantBody: "<sitemapindex/>"},
+		{path: "/sitemap-2.xml", wantStatus: http.StatusOK, wantBody: "<urlset/>"},
+		{path: "/sitemap-3.xml", wantStatus: http.StatusNotFound},
+		{path: "/sitemap-0.xml", wantStatus: http.StatusNotFound},
+		{path: "/sitemap-abc.xml", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Fatalf("expected body %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+var _ service.Service = (*fakeDiscoveryService)(nil)
@@ -11,7 +11,21 @@ func RegisterRoutes(r *chi.Mux, h *Handler) {
 	r.Route("/api/v1/discover/programs", func(r chi.Router) {
 		r.Use(httprate.LimitByIP(100, 1*time.Minute))
 		r.Get("/search", h.Search)
+		r.Get("/suggest", h.Suggest)
 		r.Get("/", h.List)
 		r.Get("/{id}", h.GetByID)
+		r.Get("/{id}/media", h.StreamMedia)
+		r.Head("/{id}/media", h.StreamMedia)
+		r.Get("/{id}/chapters", h.GetChapters)
+		r.Get("/{id}/transcript", h.GetTranscript)
 	})
+
+	r.Route("/api/v1/discover/feeds", func(r chi.Router) {
+		r.Use(httprate.LimitByIP(100, 1*time.Minute))
+		r.Get("/{slug}.xml", h.GetFeed)
+		r.Head("/{slug}.xml", h.GetFeed)
+	})
+
+	r.Get("/sitemap.xml", h.GetSitemapIndex)
+	r.Get("/sitemap-{page}.xml", h.GetSitemapPage)
 }
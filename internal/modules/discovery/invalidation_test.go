@@ -0,0 +1,196 @@
+package discovery_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"cms-api/internal/config"
+	"cms-api/internal/infra/cache"
+	"cms-api/internal/infra/messaging"
+	discoveryentity "cms-api/internal/modules/discovery/entity"
+	discoveryhttp "cms-api/internal/modules/discovery/http"
+	discoveryrepo "cms-api/internal/modules/discovery/repo"
+	discoveryservice "cms-api/internal/modules/discovery/service"
+	programrepo "cms-api/internal/modules/program/repo"
+	programservice "cms-api/internal/modules/program/service"
+	"cms-api/internal/pkg/apperror"
+)
+
+const testProgramID = "019539a2-b826-7640-9a20-e2b6c8e12345"
+
+// programStore is the shared state both module repositories read, standing in
+// for the programs table.
+type programStore struct {
+	mu      sync.Mutex
+	deleted map[string]bool
+}
+
+func (s *programStore) isVisible(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted, ok := s.deleted[id]
+	return ok && !deleted
+}
+
+type fakeProgramRepo struct {
+	programrepo.Repository
+	store *programStore
+}
+
+func (r *fakeProgramRepo) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if deleted, ok := r.store.deleted[id]; !ok || deleted {
+		return apperror.ErrNotFound
+	}
+	r.store.deleted[id] = true
+	return nil
+}
+
+type fakeDiscoveryRepo struct {
+	discoveryrepo.Repository
+	store *programStore
+}
+
+func (r *fakeDiscoveryRepo) GetByID(ctx context.Context, id string) (*discoveryentity.Program, error) {
+	if !r.store.isVisible(id) {
+		return nil, apperror.ErrNotFound
+	}
+	return &discoveryentity.Program{ID: id, Title: "Episode", Status: "active"}, nil
+}
+
+func (r *fakeDiscoveryRepo) List(ctx context.Context, limit int, cursorPublishedAt *time.Time, cursorID string) ([]*discoveryentity.Program, error) {
+	var programs []*discoveryentity.Program
+	if r.store.isVisible(testProgramID) {
+		programs = append(programs, &discoveryentity.Program{ID: testProgramID, Title: "Episode", Status: "active"})
+	}
+	return programs, nil
+}
+
+type mapCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	tags map[string][]string
+}
+
+func (c *mapCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	val, ok := c.data[key]
+	if !ok {
+		return nil, cache.ErrCacheMiss
+	}
+	return val, nil
+}
+
+func (c *mapCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = value
+	return nil
+}
+
+func (c *mapCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, k := range keys {
+		delete(c.data, k)
+	}
+	return nil
+}
+
+func (c *mapCache) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = value
+	if c.tags == nil {
+		c.tags = make(map[string][]string)
+	}
+	for _, t := range tags {
+		c.tags[t] = append(c.tags[t], key)
+	}
+	return nil
+}
+
+func (c *mapCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, t := range tags {
+		for _, k := range c.tags[t] {
+			delete(c.data, k)
+		}
+		delete(c.tags, t)
+	}
+	return nil
+}
+
+func TestProgramDelete_InvalidatesDiscoveryCache(t *testing.T) {
+	log := zap.NewNop()
+	store := &programStore{deleted: map[string]bool{testProgramID: false}}
+	cacheStore := &mapCache{data: make(map[string][]byte)}
+	bus := messaging.NewInProcessBus(log)
+	cfg := &config.Config{}
+
+	discoverySvc := discoveryservice.New(&fakeDiscoveryRepo{store: store}, nil, cacheStore, cache.NewLoader(cacheStore, nil, log), nil, cfg, log)
+	discoveryservice.RegisterInvalidator(bus, discoveryservice.NewInvalidator(cacheStore, log))
+	programSvc := programservice.New(&fakeProgramRepo{store: store}, bus, log)
+
+	router := chi.NewRouter()
+	discoveryhttp.RegisterRoutes(router, discoveryhttp.NewHandler(discoverySvc, cfg, log))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	listCount := func() int {
+		w := get("/api/v1/discover/programs/")
+		var body struct {
+			Data struct {
+				Items []json.RawMessage `json:"items"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode list: %v", err)
+		}
+		return len(body.Data.Items)
+	}
+	detailPath := "/api/v1/discover/programs/" + testProgramID
+
+	if w := get(detailPath); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 before delete, got %d", w.Code)
+	}
+	if n := listCount(); n != 1 {
+		t.Fatalf("expected 1 listed program before delete, got %d", n)
+	}
+
+	// Without an event the cached entries would keep serving the program.
+	store.mu.Lock()
+	store.deleted[testProgramID] = true
+	store.mu.Unlock()
+	if w := get(detailPath); w.Code != http.StatusOK {
+		t.Fatalf("expected cached 200, got %d", w.Code)
+	}
+	store.mu.Lock()
+	store.deleted[testProgramID] = false
+	store.mu.Unlock()
+
+	if err := programSvc.Delete(context.Background(), testProgramID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if w := get(detailPath); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 immediately after delete, got %d", w.Code)
+	}
+	if n := listCount(); n != 0 {
+		t.Fatalf("expected deleted program to leave the list, got %d items", n)
+	}
+}
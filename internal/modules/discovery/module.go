@@ -11,6 +11,8 @@ import (
 var Module = fx.Module("discovery",
 	fx.Provide(repo.New),
 	fx.Provide(service.New),
+	fx.Provide(service.NewInvalidator),
+	fx.Invoke(service.RegisterInvalidator),
 	fx.Provide(discoveryhttp.NewHandler),
 	fx.Invoke(discoveryhttp.RegisterRoutes),
 )
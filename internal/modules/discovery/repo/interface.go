@@ -10,4 +10,12 @@ import (
 type Repository interface {
 	GetByID(ctx context.Context, id string) (*entity.Program, error)
 	List(ctx context.Context, limit int, cursorPublishedAt *time.Time, cursorID string) ([]*entity.Program, error)
+	GetMedia(ctx context.Context, id string) (*entity.ProgramMedia, error)
+	ListTranscriptCues(ctx context.Context, id string) ([]*entity.TranscriptCue, error)
+	ListChapters(ctx context.Context, id string) ([]*entity.Chapter, error)
+	GetFeedCategory(ctx context.Context, slug string) (*entity.FeedCategory, error)
+	ListFeedItems(ctx context.Context, categoryID int64, limit int) ([]*entity.FeedItem, error)
+	GetDetail(ctx context.Context, id string) (*entity.ProgramDetail, error)
+	SitemapStats(ctx context.Context) (*entity.SitemapStats, error)
+	ListSitemapEntries(ctx context.Context, limit, offset int) ([]*entity.SitemapEntry, error)
 }
@@ -0,0 +1,277 @@
+package repo
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/modules/discovery/entity"
+	"cms-api/internal/pkg/apperror"
+)
+
+type memoryRepository struct {
+	store *memdb.Store
+}
+
+// NewMemory returns a Repository that reads the public view of the programs
+// in store, as the Postgres queries do. It backs discovery in the hermetic
+// app and in tests.
+func NewMemory(store *memdb.Store) Repository {
+	return &memoryRepository{store: store}
+}
+
+func (r *memoryRepository) GetByID(ctx context.Context, id string) (*entity.Program, error) {
+	var p *entity.Program
+	err := r.store.Read(func(t *memdb.Tables) error {
+		row, ok := t.Programs[id]
+		if !ok || !row.Published() {
+			return apperror.ErrNotFound
+		}
+		p = toProgram(t, row)
+		return nil
+	})
+	return p, err
+}
+
+func (r *memoryRepository) List(ctx context.Context, limit int, cursorPublishedAt *time.Time, cursorID string) ([]*entity.Program, error) {
+	var programs []*entity.Program
+	err := r.store.Read(func(t *memdb.Tables) error {
+		var rows []*memdb.Program
+		for _, row := range t.Programs {
+			if !row.Published() {
+				continue
+			}
+			// A row comparison against NULL is never true, so unpublished
+			// rows only ever appear on the first page.
+			if cursorPublishedAt != nil && (!row.PublishedAt.Valid || comparePublished(row, *cursorPublishedAt, cursorID) >= 0) {
+				continue
+			}
+			rows = append(rows, row)
+		}
+
+		slices.SortFunc(rows, byPublishedDesc)
+		for _, row := range rows[:min(limit, len(rows))] {
+			programs = append(programs, toProgram(t, row))
+		}
+		return nil
+	})
+	return programs, err
+}
+
+func (r *memoryRepository) GetMedia(ctx context.Context, id string) (*entity.ProgramMedia, error) {
+	var m *entity.ProgramMedia
+	err := r.store.Read(func(t *memdb.Tables) error {
+		row, ok := t.Programs[id]
+		if !ok || !row.Published() {
+			return apperror.ErrNotFound
+		}
+		m = &entity.ProgramMedia{ID: row.ID, MediaPath: row.MediaPath, MediaType: row.MediaType, UpdatedAt: row.UpdatedAt}
+		return nil
+	})
+	return m, err
+}
+
+func (r *memoryRepository) ListTranscriptCues(ctx context.Context, id string) ([]*entity.TranscriptCue, error) {
+	var cues []*entity.TranscriptCue
+	err := r.store.Read(func(t *memdb.Tables) error {
+		rows := slices.Clone(t.Cues[id])
+		slices.SortFunc(rows, func(a, b *memdb.TranscriptCue) int { return cmp.Compare(a.Position, b.Position) })
+		for _, c := range rows {
+			cues = append(cues, &entity.TranscriptCue{StartMS: c.StartMS, EndMS: c.EndMS, Text: c.Text})
+		}
+		return nil
+	})
+	return cues, err
+}
+
+func (r *memoryRepository) ListChapters(ctx context.Context, id string) ([]*entity.Chapter, error) {
+	var chapters []*entity.Chapter
+	err := r.store.Read(func(t *memdb.Tables) error {
+		rows := slices.Clone(t.Chapters[id])
+		slices.SortFunc(rows, func(a, b *memdb.Chapter) int { return cmp.Compare(a.Position, b.Position) })
+		for _, c := range rows {
+			chapters = append(chapters, &entity.Chapter{
+				StartMS: c.StartMS,
+				EndMS:   c.EndMS,
+				Title:   c.Title,
+				URL:     sql.NullString{String: c.URL, Valid: true},
+				Image:   sql.NullString{String: c.Image, Valid: true},
+				TOC:     c.TOC,
+			})
+		}
+		return nil
+	})
+	return chapters, err
+}
+
+func (r *memoryRepository) GetFeedCategory(ctx context.Context, slug string) (*entity.FeedCategory, error) {
+	var fc *entity.FeedCategory
+	err := r.store.Read(func(t *memdb.Tables) error {
+		for _, c := range t.Categories {
+			if c.Slug == slug {
+				fc = &entity.FeedCategory{ID: c.ID, Name: c.Name, Slug: c.Slug, Description: c.Description, UpdatedAt: c.UpdatedAt}
+				return nil
+			}
+		}
+		return apperror.ErrNotFound
+	})
+	return fc, err
+}
+
+func (r *memoryRepository) ListFeedItems(ctx context.Context, categoryID int64, limit int) ([]*entity.FeedItem, error) {
+	var items []*entity.FeedItem
+	err := r.store.Read(func(t *memdb.Tables) error {
+		var rows []*memdb.Program
+		for _, row := range t.Programs {
+			if row.Published() && row.CategoryID.Valid && row.CategoryID.Int64 == categoryID &&
+				row.PublishedAt.Valid && row.MediaPath.Valid && row.MediaPath.String != "" {
+				rows = append(rows, row)
+			}
+		}
+
+		slices.SortFunc(rows, byPublishedDesc)
+		for _, row := range rows[:min(limit, len(rows))] {
+			item := &entity.FeedItem{
+				ID:              row.ID,
+				Title:           row.Title,
+				Description:     row.Description,
+				DurationSeconds: durationSeconds(row),
+				PublishedAt:     row.PublishedAt.Time,
+				Thumbnail:       row.Thumbnail,
+				MediaPath:       row.MediaPath.String,
+				MediaType:       row.MediaType,
+				HasChapters:     len(t.Chapters[row.ID]) > 0,
+				UpdatedAt:       row.UpdatedAt,
+			}
+			if tr, ok := t.Transcripts[row.ID]; ok {
+				item.HasTranscript = true
+				item.TranscriptLanguage = sql.NullString{String: tr.Language, Valid: true}
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	return items, err
+}
+
+func (r *memoryRepository) GetDetail(ctx context.Context, id string) (*entity.ProgramDetail, error) {
+	var d *entity.ProgramDetail
+	err := r.store.Read(func(t *memdb.Tables) error {
+		row, ok := t.Programs[id]
+		if !ok || !row.Published() {
+			return apperror.ErrNotFound
+		}
+		d = &entity.ProgramDetail{
+			Program:         *toProgram(t, row),
+			DurationSeconds: durationSeconds(row),
+			MediaPath:       row.MediaPath,
+			MediaType:       row.MediaType,
+		}
+		return nil
+	})
+	return d, err
+}
+
+func (r *memoryRepository) SitemapStats(ctx context.Context) (*entity.SitemapStats, error) {
+	var stats entity.SitemapStats
+	err := r.store.Read(func(t *memdb.Tables) error {
+		for _, row := range t.Programs {
+			if !row.Published() {
+				continue
+			}
+			stats.Total++
+			if !stats.LastModified.Valid || row.UpdatedAt.After(stats.LastModified.Time) {
+				stats.LastModified = sql.NullTime{Time: row.UpdatedAt, Valid: true}
+			}
+		}
+		return nil
+	})
+	return &stats, err
+}
+
+func (r *memoryRepository) ListSitemapEntries(ctx context.Context, limit, offset int) ([]*entity.SitemapEntry, error) {
+	var entries []*entity.SitemapEntry
+	err := r.store.Read(func(t *memdb.Tables) error {
+		var rows []*memdb.Program
+		for _, row := range t.Programs {
+			if row.Published() {
+				rows = append(rows, row)
+			}
+		}
+
+		slices.SortFunc(rows, func(a, b *memdb.Program) int {
+			if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.ID, b.ID)
+		})
+
+		start := min(offset, len(rows))
+		end := min(start+limit, len(rows))
+		for _, row := range rows[start:end] {
+			entries = append(entries, &entity.SitemapEntry{ID: row.ID, UpdatedAt: row.UpdatedAt})
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// byPublishedDesc orders by published_at DESC, id DESC. As in Postgres, NULLs
+// sort first in descending order.
+func byPublishedDesc(a, b *memdb.Program) int {
+	switch {
+	case !a.PublishedAt.Valid && !b.PublishedAt.Valid:
+		return -cmp.Compare(a.ID, b.ID)
+	case !a.PublishedAt.Valid:
+		return -1
+	case !b.PublishedAt.Valid:
+		return 1
+	}
+	return -comparePublished(a, b.PublishedAt.Time, b.ID)
+}
+
+func comparePublished(row *memdb.Program, publishedAt time.Time, id string) int {
+	if c := row.PublishedAt.Time.Compare(publishedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(row.ID, id)
+}
+
+func durationSeconds(row *memdb.Program) sql.NullInt64 {
+	if !row.Duration.Valid {
+		return sql.NullInt64{}
+	}
+	d, err := memdb.ParseInterval(row.Duration.String)
+	if err != nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(d.Seconds()), Valid: true}
+}
+
+func toProgram(t *memdb.Tables, row *memdb.Program) *entity.Program {
+	p := &entity.Program{
+		ID:          row.ID,
+		Title:       row.Title,
+		Description: row.Description,
+		ProgramType: row.ProgramType,
+		Duration:    row.Duration,
+		PublishedAt: row.PublishedAt,
+		Thumbnail:   row.Thumbnail,
+		VideoURL:    row.VideoURL,
+		Status:      row.Status,
+		CategoryID:  row.CategoryID,
+		LanguageID:  row.LanguageID,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	if c := t.CategoryOf(row); c != nil {
+		p.CategoryName = sql.NullString{String: c.Name, Valid: true}
+	}
+	if l := t.LanguageOf(row); l != nil {
+		p.LanguageCode = sql.NullString{String: l.Code, Valid: true}
+	}
+	return p
+}
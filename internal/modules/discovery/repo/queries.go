@@ -40,3 +40,74 @@ const queryListAfterCursor = `
 	ORDER BY p.published_at DESC, p.id DESC
 	LIMIT $1
 `
+
+const queryGetMedia = `
+	SELECT p.id, p.media_path, p.media_type, p.updated_at
+	FROM programs p
+	WHERE p.id = $1 AND p.status = 'active' AND p.deleted_at IS NULL
+`
+
+const queryListTranscriptCues = `
+	SELECT c.start_ms, c.end_ms, c.text
+	FROM program_transcript_cues c
+	WHERE c.program_id = $1
+	ORDER BY c.position ASC
+`
+
+const queryListChapters = `
+	SELECT ch.start_ms, ch.end_ms, ch.title, ch.url, ch.image, ch.toc
+	FROM program_chapters ch
+	WHERE ch.program_id = $1
+	ORDER BY ch.position ASC
+`
+
+const queryGetFeedCategory = `
+	SELECT c.id, c.name, c.slug, c.description, c.updated_at
+	FROM categories c
+	WHERE c.slug = $1
+`
+
+const queryListFeedItems = `
+	SELECT p.id, p.title, p.description,
+	       EXTRACT(EPOCH FROM p.duration)::BIGINT AS duration_seconds,
+	       p.published_at, p.thumbnail, p.media_path, p.media_type, p.updated_at,
+	       EXISTS (SELECT 1 FROM program_chapters ch WHERE ch.program_id = p.id) AS has_chapters,
+	       t.language AS transcript_language,
+	       t.program_id IS NOT NULL AS has_transcript
+	FROM programs p
+	LEFT JOIN program_transcripts t ON t.program_id = p.id
+	WHERE p.category_id = $1
+	  AND p.status = 'active' AND p.deleted_at IS NULL
+	  AND p.published_at IS NOT NULL
+	  AND p.media_path IS NOT NULL AND p.media_path <> ''
+	ORDER BY p.published_at DESC, p.id DESC
+	LIMIT $2
+`
+
+const queryGetDetail = `
+	SELECT p.id, p.title, p.description, p.program_type, p.duration,
+	       EXTRACT(EPOCH FROM p.duration)::BIGINT AS duration_seconds,
+	       p.published_at, p.thumbnail, p.video_url, p.status,
+	       p.category_id, p.language_id, p.created_at, p.updated_at,
+	       p.media_path, p.media_type,
+	       c.name AS category_name,
+	       l.code AS language_code
+	FROM programs p
+	LEFT JOIN categories c ON c.id = p.category_id
+	LEFT JOIN languages l ON l.id = p.language_id
+	WHERE p.id = $1 AND p.status = 'active' AND p.deleted_at IS NULL
+`
+
+const querySitemapStats = `
+	SELECT COUNT(*) AS total, MAX(p.updated_at) AS last_modified
+	FROM programs p
+	WHERE p.status = 'active' AND p.deleted_at IS NULL
+`
+
+const queryListSitemapEntries = `
+	SELECT p.id, p.updated_at
+	FROM programs p
+	WHERE p.status = 'active' AND p.deleted_at IS NULL
+	ORDER BY p.created_at ASC, p.id ASC
+	LIMIT $1 OFFSET $2
+`
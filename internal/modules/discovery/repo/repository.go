@@ -46,3 +46,76 @@ func (r *repository) List(ctx context.Context, limit int, cursorPublishedAt *tim
 	}
 	return programs, nil
 }
+
+func (r *repository) GetMedia(ctx context.Context, id string) (*entity.ProgramMedia, error) {
+	var m entity.ProgramMedia
+	if err := r.db.GetContext(ctx, &m, queryGetMedia, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *repository) ListTranscriptCues(ctx context.Context, id string) ([]*entity.TranscriptCue, error) {
+	var cues []*entity.TranscriptCue
+	if err := r.db.SelectContext(ctx, &cues, queryListTranscriptCues, id); err != nil {
+		return nil, err
+	}
+	return cues, nil
+}
+
+func (r *repository) ListChapters(ctx context.Context, id string) ([]*entity.Chapter, error) {
+	var chapters []*entity.Chapter
+	if err := r.db.SelectContext(ctx, &chapters, queryListChapters, id); err != nil {
+		return nil, err
+	}
+	return chapters, nil
+}
+
+func (r *repository) GetFeedCategory(ctx context.Context, slug string) (*entity.FeedCategory, error) {
+	var c entity.FeedCategory
+	if err := r.db.GetContext(ctx, &c, queryGetFeedCategory, slug); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *repository) ListFeedItems(ctx context.Context, categoryID int64, limit int) ([]*entity.FeedItem, error) {
+	var items []*entity.FeedItem
+	if err := r.db.SelectContext(ctx, &items, queryListFeedItems, categoryID, limit); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *repository) GetDetail(ctx context.Context, id string) (*entity.ProgramDetail, error) {
+	var p entity.ProgramDetail
+	if err := r.db.GetContext(ctx, &p, queryGetDetail, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *repository) SitemapStats(ctx context.Context) (*entity.SitemapStats, error) {
+	var stats entity.SitemapStats
+	if err := r.db.GetContext(ctx, &stats, querySitemapStats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (r *repository) ListSitemapEntries(ctx context.Context, limit, offset int) ([]*entity.SitemapEntry, error) {
+	var entries []*entity.SitemapEntry
+	if err := r.db.SelectContext(ctx, &entries, queryListSitemapEntries, limit, offset); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -2,12 +2,42 @@ package service
 
 import (
 	"context"
+	"time"
 
+	"cms-api/internal/infra/storage"
 	"cms-api/internal/modules/discovery/dto"
 )
 
+// Media is an opened media object ready to be served with range support.
+// Callers must close Content.
+type Media struct {
+	Content     storage.Object
+	Name        string
+	ContentType string
+	ETag        string
+	ModTime     time.Time
+	Size        int64
+}
+
+// Document is a rendered feed or sitemap with the validators needed for
+// conditional requests.
+type Document struct {
+	Body    []byte    `json:"body"`
+	ETag    string    `json:"etag"`
+	ModTime time.Time `json:"mod_time"`
+}
+
 type Service interface {
 	Search(ctx context.Context, req *dto.SearchRequest) (*dto.SearchResultResponse, error)
+	Suggest(ctx context.Context, req *dto.SuggestRequest) (*dto.SuggestResponse, error)
 	List(ctx context.Context, cursorStr string, limit int) (*dto.ProgramListResponse, error)
 	GetByID(ctx context.Context, id string) (*dto.ProgramResponse, error)
+	OpenMedia(ctx context.Context, id string) (*Media, error)
+	GetChapters(ctx context.Context, id string) (*dto.ChaptersResponse, error)
+	GetTranscript(ctx context.Context, id string) ([]byte, error)
+	GetFeed(ctx context.Context, slug string) (*Document, error)
+	GetStructuredData(ctx context.Context, id string) (*dto.StructuredData, error)
+	// GetSitemap returns the root sitemap for page 0 (a sitemap index once the
+	// catalog spans several pages) and numbered sitemap pages from 1.
+	GetSitemap(ctx context.Context, page int) (*Document, error)
 }
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"cms-api/internal/infra/cache"
+	"cms-api/internal/infra/messaging"
+)
+
+// tagPrograms marks every list-shaped entry (lists, feeds, sitemaps) so a
+// change in which programs are listed, or in their order, drops them at once.
+// List pages additionally carry the ID, category and language of each
+// program on them, letting an in-place edit drop only the pages that show it.
+const tagPrograms = "programs"
+
+func programTag(id string) string {
+	return "program:" + id
+}
+
+func categoryTag(id int64) string {
+	return "category:" + strconv.FormatInt(id, 10)
+}
+
+func languageTag(code string) string {
+	return "language:" + code
+}
+
+func detailCacheKey(id string) string {
+	return "discovery:id:" + id
+}
+
+func structuredDataCacheKey(id string) string {
+	return "discovery:jsonld:" + id
+}
+
+// Invalidator purges discovery cache entries when programs change.
+type Invalidator struct {
+	cache cache.Cache
+	log   *zap.Logger
+}
+
+func NewInvalidator(cache cache.Cache, log *zap.Logger) *Invalidator {
+	return &Invalidator{cache: cache, log: log}
+}
+
+func RegisterInvalidator(sub messaging.Subscriber, inv *Invalidator) {
+	sub.Subscribe(messaging.ChannelPrograms, inv.HandleProgramEvent)
+}
+
+func (i *Invalidator) HandleProgramEvent(ctx context.Context, event messaging.Event) error {
+	pe, ok := event.Payload.(messaging.ProgramEvent)
+	if !ok {
+		return nil
+	}
+
+	if err := i.cache.InvalidateTags(ctx, programEventTags(event.Name, pe)...); err != nil {
+		i.log.Error("failed to invalidate discovery cache",
+			zap.Error(err),
+			zap.String("event", event.Name),
+			zap.String("program_id", pe.ProgramID),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// programEventTags returns the cache tags a program change invalidates.
+func programEventTags(name string, pe messaging.ProgramEvent) []string {
+	var tags []string
+	if pe.ProgramID != "" {
+		tags = append(tags, programTag(pe.ProgramID))
+	}
+
+	// Creates, deletes, imports and visibility changes alter list membership.
+	if name != messaging.EventProgramUpdated || pe.Reordered {
+		return append(tags, tagPrograms)
+	}
+
+	for _, id := range pe.CategoryIDs {
+		tags = append(tags, categoryTag(id))
+	}
+	for _, code := range pe.LanguageCodes {
+		tags = append(tags, languageTag(code))
+	}
+	return tags
+}
@@ -2,48 +2,78 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
+	"path"
 	"strings"
 	"time"
 
 	"go.uber.org/zap"
 
+	"cms-api/internal/config"
 	"cms-api/internal/infra/cache"
 	"cms-api/internal/infra/search"
+	"cms-api/internal/infra/storage"
 	"cms-api/internal/modules/discovery/dto"
+	"cms-api/internal/modules/discovery/entity"
 	"cms-api/internal/modules/discovery/repo"
 	"cms-api/internal/pkg/apperror"
 	"cms-api/internal/pkg/cursor"
+	"cms-api/internal/pkg/textnorm"
 )
 
-const indexName = "programs"
-
 const (
-	cacheTTLList = 30 * time.Second
-	cacheTTLDetail = 60 * time.Second
+	indexName           = "programs"
+	transcriptIndexName = "program_transcripts"
+)
+
+// searchAttributes are the program document attributes search hits carry.
+// Transcripts are searchable but left out: a hit's matching cue comes from
+// the transcript index instead.
+var searchAttributes = []string{
+	"id", "title", "description", "program_type", "duration",
+	"published_at", "category", "language", "thumbnail", "video_url",
+}
+
+var (
+	cacheList   = cache.LoadOptions{TTL: 30 * time.Second, StaleTTL: 30 * time.Second}
+	cacheDetail = cache.LoadOptions{TTL: 60 * time.Second, StaleTTL: 60 * time.Second, NegativeTTL: 10 * time.Second}
 )
 
 type service struct {
-	repo   repo.Repository
-	search search.Searcher
-	cache  cache.Cache
-	log    *zap.Logger
+	repo    repo.Repository
+	search  search.Searcher
+	cache   cache.Cache
+	loader  *cache.Loader
+	storage storage.Storage
+	cfg     *config.Config
+	log     *zap.Logger
 }
 
-func New(repo repo.Repository, search search.Searcher, cache cache.Cache, log *zap.Logger) Service {
-	return &service{repo: repo, search: search, cache: cache, log: log}
+func New(repo repo.Repository, search search.Searcher, cache cache.Cache, loader *cache.Loader, storage storage.Storage, cfg *config.Config, log *zap.Logger) Service {
+	return &service{repo: repo, search: search, cache: cache, loader: loader, storage: storage, cfg: cfg, log: log}
 }
 
 func (s *service) Search(ctx context.Context, req *dto.SearchRequest) (*dto.SearchResultResponse, error) {
 	filter := buildFilter(req)
 
 	searchReq := search.SearchRequest{
-		Query:   req.Query,
+		Query:   textnorm.Normalize(req.Query),
 		Page:    req.Page,
 		PerPage: req.PerPage,
 		Filter:  filter,
 		Sort:    []string{"published_at:desc"},
+		Facets:  req.Facets,
+
+		AttributesToRetrieve:  searchAttributes,
+		AttributesToHighlight: []string{"title", "description"},
+		AttributesToCrop:      []string{"description"},
+		CropLength:            s.cfg.Search.CropLength,
+		HighlightPreTag:       s.cfg.Search.HighlightPreTag,
+		HighlightPostTag:      s.cfg.Search.HighlightPostTag,
 	}
 
 	result, err := s.search.Search(ctx, indexName, searchReq)
@@ -55,20 +85,49 @@ func (s *service) Search(ctx context.Context, req *dto.SearchRequest) (*dto.Sear
 	if err != nil {
 		return nil, fmt.Errorf("decode search hits: %w", err)
 	}
+	resp.Facets = result.Facets
+	s.matchTranscripts(ctx, searchReq.Query, resp.Items)
 
 	return resp, nil
 }
 
-func (s *service) List(ctx context.Context, cursorStr string, limit int) (*dto.ProgramListResponse, error) {
-	cacheKey := fmt.Sprintf("discovery:list:%s:%d", cursorStr, limit)
+// matchTranscripts points each item whose transcript matches query at its
+// best matching cue, found in the transcript index in one search. Programs
+// are found without it, so a failed lookup only costs the cue links.
+func (s *service) matchTranscripts(ctx context.Context, query string, items []*dto.SearchProgramResponse) {
+	if strings.TrimSpace(query) == "" || len(items) == 0 {
+		return
+	}
 
-	if data, err := s.cache.Get(ctx, cacheKey); err == nil {
-		var resp dto.ProgramListResponse
-		if err := json.Unmarshal(data, &resp); err == nil {
-			return &resp, nil
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	result, err := s.search.Search(ctx, transcriptIndexName, search.SearchRequest{
+		Query:    query,
+		Filter:   anyOf("program_id", ids),
+		Distinct: "program_id",
+		Page:     1,
+		PerPage:  len(ids),
+	})
+	if err != nil {
+		if !errors.Is(err, search.ErrUnsupported) {
+			s.log.Warn("failed to match transcript cues", zap.Error(err))
 		}
+		return
+	}
+
+	matches, err := dto.HitsToTranscriptMatches(result.Hits)
+	if err != nil {
+		s.log.Warn("failed to decode transcript cues", zap.Error(err))
+		return
+	}
+	for _, item := range items {
+		item.TranscriptMatch = matches[item.ID]
 	}
+}
 
+func (s *service) List(ctx context.Context, cursorStr string, limit int) (*dto.ProgramListResponse, error) {
 	var cursorTime *time.Time
 	var cursorID string
 
@@ -81,9 +140,17 @@ func (s *service) List(ctx context.Context, cursorStr string, limit int) (*dto.P
 		cursorID = id
 	}
 
+	cacheKey := fmt.Sprintf("discovery:list:%s:%d", cursorStr, limit)
+
+	return loadJSON(ctx, s.loader, cacheKey, cacheList.WithTags(tagPrograms), func(ctx context.Context) (*dto.ProgramListResponse, []string, error) {
+		return s.list(ctx, cursorTime, cursorID, limit)
+	})
+}
+
+func (s *service) list(ctx context.Context, cursorTime *time.Time, cursorID string, limit int) (*dto.ProgramListResponse, []string, error) {
 	programs, err := s.repo.List(ctx, limit+1, cursorTime, cursorID)
 	if err != nil {
-		return nil, fmt.Errorf("list programs: %w", err)
+		return nil, nil, fmt.Errorf("list programs: %w", err)
 	}
 
 	hasNext := len(programs) > limit
@@ -97,37 +164,295 @@ func (s *service) List(ctx context.Context, cursorStr string, limit int) (*dto.P
 		nextCursor = cursor.EncodePair(last.PublishedAt.Time, last.ID)
 	}
 
-	resp := dto.ToListResponse(programs, nextCursor, hasNext)
+	return dto.ToListResponse(programs, nextCursor, hasNext), listTags(programs), nil
+}
 
-	if data, err := json.Marshal(resp); err == nil {
-		_ = s.cache.Set(ctx, cacheKey, data, cacheTTLList)
+// listTags tags a list page with the programs, categories and languages
+// shown on it.
+func listTags(programs []*entity.Program) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	add := func(tag string) {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
 	}
-
-	return resp, nil
+	for _, p := range programs {
+		add(programTag(p.ID))
+		if p.CategoryID.Valid {
+			add(categoryTag(p.CategoryID.Int64))
+		}
+		if p.LanguageCode.Valid {
+			add(languageTag(p.LanguageCode.String))
+		}
+	}
+	return tags
 }
 
 func (s *service) GetByID(ctx context.Context, id string) (*dto.ProgramResponse, error) {
-	cacheKey := "discovery:id:" + id
+	return loadJSON(ctx, s.loader, detailCacheKey(id), cacheDetail.WithTags(programTag(id)), func(ctx context.Context) (*dto.ProgramResponse, []string, error) {
+		p, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dto.ToResponse(p), nil, nil
+	})
+}
+
+func (s *service) OpenMedia(ctx context.Context, id string) (*Media, error) {
+	m, err := s.repo.GetMedia(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !m.MediaPath.Valid || m.MediaPath.String == "" {
+		return nil, apperror.ErrNotFound
+	}
 
-	if data, err := s.cache.Get(ctx, cacheKey); err == nil {
-		var resp dto.ProgramResponse
-		if err := json.Unmarshal(data, &resp); err == nil {
-			return &resp, nil
+	obj, info, err := s.storage.Open(ctx, m.MediaPath.String)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			s.log.Warn("program media missing from storage",
+				zap.String("program_id", id),
+				zap.String("media_path", m.MediaPath.String),
+			)
+			return nil, apperror.ErrNotFound
 		}
+		return nil, fmt.Errorf("open media: %w", err)
+	}
+
+	name := path.Base(m.MediaPath.String)
+	contentType := mediaContentType(m.MediaType.String, name)
+
+	return &Media{
+		Content:     obj,
+		Name:        name,
+		ContentType: contentType,
+		ETag:        fmt.Sprintf(`"%x-%x"`, info.ModTime.UnixNano(), info.Size),
+		ModTime:     info.ModTime,
+		Size:        info.Size,
+	}, nil
+}
+
+func (s *service) GetChapters(ctx context.Context, id string) (*dto.ChaptersResponse, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
 	}
 
-	p, err := s.repo.GetByID(ctx, id)
+	chapters, err := s.repo.ListChapters(ctx, id)
 	if err != nil {
+		return nil, fmt.Errorf("list chapters: %w", err)
+	}
+	if len(chapters) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return dto.ToChaptersResponse(chapters), nil
+}
+
+func (s *service) GetTranscript(ctx context.Context, id string) ([]byte, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
 		return nil, err
 	}
 
-	resp := dto.ToResponse(p)
+	cues, err := s.repo.ListTranscriptCues(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list transcript cues: %w", err)
+	}
+	if len(cues) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return dto.ToWebVTT(cues), nil
+}
 
-	if data, err := json.Marshal(resp); err == nil {
-		_ = s.cache.Set(ctx, cacheKey, data, cacheTTLDetail)
+func (s *service) GetFeed(ctx context.Context, slug string) (*Document, error) {
+	opts := cache.LoadOptions{
+		TTL:         s.cfg.Feed.CacheTTL,
+		StaleTTL:    s.cfg.Feed.CacheTTL,
+		NegativeTTL: cacheDetail.NegativeTTL,
+		Tags:        []string{tagPrograms},
 	}
 
-	return resp, nil
+	return loadJSON(ctx, s.loader, "discovery:feed:"+slug, opts, func(ctx context.Context) (*Document, []string, error) {
+		doc, categoryID, err := s.renderFeed(ctx, slug)
+		if err != nil {
+			return nil, nil, err
+		}
+		return doc, []string{categoryTag(categoryID)}, nil
+	})
+}
+
+func (s *service) renderFeed(ctx context.Context, slug string) (*Document, int64, error) {
+	category, err := s.repo.GetFeedCategory(ctx, slug)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items, err := s.repo.ListFeedItems(ctx, category.ID, s.cfg.Feed.MaxItems)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list feed items: %w", err)
+	}
+
+	modTime := category.UpdatedAt
+	enclosures := make(map[string]dto.FeedEnclosure, len(items))
+	for _, it := range items {
+		enc, err := s.feedEnclosure(ctx, it)
+		if err != nil {
+			return nil, 0, err
+		}
+		if enc == nil {
+			continue
+		}
+		enclosures[it.ID] = *enc
+		if it.UpdatedAt.After(modTime) {
+			modTime = it.UpdatedAt
+		}
+	}
+	// Last-Modified has second precision; truncating keeps If-Modified-Since exact.
+	modTime = modTime.UTC().Truncate(time.Second)
+
+	body, err := dto.ToRSSFeed(category, items, enclosures, dto.FeedOptions{
+		SelfURL:        s.cfg.App.PublicURL + "/api/v1/discover/feeds/" + slug + ".xml",
+		SiteURL:        s.cfg.App.SiteURL,
+		APIURL:         s.cfg.App.PublicURL,
+		Author:         s.cfg.Feed.Author,
+		OwnerEmail:     s.cfg.Feed.OwnerEmail,
+		Image:          s.cfg.Feed.Image,
+		Language:       s.cfg.Feed.Language,
+		ITunesCategory: s.cfg.Feed.ITunesCategory,
+		Explicit:       s.cfg.Feed.Explicit,
+		LastBuild:      modTime,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("render feed: %w", err)
+	}
+
+	return newDocument(body, modTime), category.ID, nil
+}
+
+func (s *service) GetStructuredData(ctx context.Context, id string) (*dto.StructuredData, error) {
+	return loadJSON(ctx, s.loader, structuredDataCacheKey(id), cacheDetail.WithTags(programTag(id)), func(ctx context.Context) (*dto.StructuredData, []string, error) {
+		p, err := s.repo.GetDetail(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var mediaURL string
+		if p.MediaPath.Valid && p.MediaPath.String != "" {
+			mediaURL = s.cfg.App.PublicURL + "/api/v1/discover/programs/" + p.ID + "/media"
+		}
+
+		return dto.ToStructuredData(p, s.cfg.App.SiteURL, mediaURL), nil, nil
+	})
+}
+
+func (s *service) GetSitemap(ctx context.Context, page int) (*Document, error) {
+	opts := cache.LoadOptions{
+		TTL:         s.cfg.SEO.SitemapCacheTTL,
+		StaleTTL:    s.cfg.SEO.SitemapCacheTTL,
+		NegativeTTL: cacheDetail.NegativeTTL,
+		Tags:        []string{tagPrograms},
+	}
+
+	return loadJSON(ctx, s.loader, fmt.Sprintf("discovery:sitemap:%d", page), opts, func(ctx context.Context) (*Document, []string, error) {
+		doc, err := s.renderSitemap(ctx, page)
+		return doc, nil, err
+	})
+}
+
+func (s *service) renderSitemap(ctx context.Context, page int) (*Document, error) {
+	stats, err := s.repo.SitemapStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sitemap stats: %w", err)
+	}
+
+	pageSize := s.cfg.SEO.SitemapPageSize
+	pages := int((stats.Total + int64(pageSize) - 1) / int64(pageSize))
+	if page > max(pages, 1) {
+		return nil, apperror.ErrNotFound
+	}
+
+	modTime := stats.LastModified.Time.UTC().Truncate(time.Second)
+
+	var body []byte
+	if page == 0 && pages > 1 {
+		body, err = dto.ToSitemapIndex(s.cfg.App.PublicURL, pages, modTime)
+	} else {
+		var entries []*entity.SitemapEntry
+		entries, err = s.repo.ListSitemapEntries(ctx, pageSize, max(page-1, 0)*pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("list sitemap entries: %w", err)
+		}
+		body, err = dto.ToSitemap(s.cfg.App.SiteURL, entries)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("render sitemap: %w", err)
+	}
+
+	return newDocument(body, modTime), nil
+}
+
+func newDocument(body []byte, modTime time.Time) *Document {
+	return &Document{
+		Body:    body,
+		ETag:    fmt.Sprintf(`"%x"`, sha256.Sum256(body)),
+		ModTime: modTime,
+	}
+}
+
+// loadJSON reads a JSON-encoded value through the loader; load also returns
+// tags derived from the value. An entry that no longer decodes, e.g. after a
+// response shape change, is rebuilt from load.
+func loadJSON[T any](ctx context.Context, loader *cache.Loader, key string, opts cache.LoadOptions, load func(ctx context.Context) (*T, []string, error)) (*T, error) {
+	data, err := loader.GetOrLoadTagged(ctx, key, opts, func(ctx context.Context) ([]byte, []string, error) {
+		v, tags, err := load(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := json.Marshal(v)
+		return data, tags, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		v, _, err := load(ctx)
+		return v, err
+	}
+	return &v, nil
+}
+
+// feedEnclosure stats the stored media for its byte length. A nil enclosure
+// means the object is missing and the item should be left out of the feed.
+func (s *service) feedEnclosure(ctx context.Context, it *entity.FeedItem) (*dto.FeedEnclosure, error) {
+	info, err := s.storage.Stat(ctx, it.MediaPath)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			s.log.Warn("program media missing from storage",
+				zap.String("program_id", it.ID),
+				zap.String("media_path", it.MediaPath),
+			)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("stat media: %w", err)
+	}
+
+	contentType := mediaContentType(it.MediaType.String, it.MediaPath)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &dto.FeedEnclosure{Length: info.Size, Type: contentType}, nil
+}
+
+func mediaContentType(stored, name string) string {
+	if stored != "" {
+		return stored
+	}
+	return mime.TypeByExtension(path.Ext(name))
 }
 
 func escapeFilterValue(s string) string {
@@ -137,15 +462,31 @@ func escapeFilterValue(s string) string {
 func buildFilter(req *dto.SearchRequest) string {
 	filters := []string{"status = 'active'"}
 
-	if req.ProgramType != "" {
-		filters = append(filters, fmt.Sprintf("program_type = '%s'", escapeFilterValue(req.ProgramType)))
+	if f := anyOf("program_type", req.ProgramTypes); f != "" {
+		filters = append(filters, f)
 	}
-	if req.Category != "" {
-		filters = append(filters, fmt.Sprintf("category = '%s'", escapeFilterValue(req.Category)))
+	if f := anyOf("category", req.Categories); f != "" {
+		filters = append(filters, f)
 	}
-	if req.Language != "" {
-		filters = append(filters, fmt.Sprintf("language = '%s'", escapeFilterValue(req.Language)))
+	if f := anyOf("language", req.Languages); f != "" {
+		filters = append(filters, f)
 	}
 
 	return strings.Join(filters, " AND ")
 }
+
+// anyOf matches attr against any of values, or returns "" for no values.
+func anyOf(attr string, values []string) string {
+	switch len(values) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("%s = '%s'", attr, escapeFilterValue(values[0]))
+	}
+
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("'%s'", escapeFilterValue(v))
+	}
+	return fmt.Sprintf("%s IN [%s]", attr, strings.Join(quoted, ", "))
+}
@@ -4,16 +4,23 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"go.uber.org/zap"
 
+	"cms-api/internal/config"
 	"cms-api/internal/infra/cache"
+	"cms-api/internal/infra/messaging"
 	"cms-api/internal/infra/search"
+	"cms-api/internal/infra/storage"
 	"cms-api/internal/modules/discovery/dto"
 	"cms-api/internal/modules/discovery/entity"
+	"cms-api/internal/pkg/apperror"
 )
 
 type fakeDiscoveryRepo struct {
@@ -24,6 +31,13 @@ type fakeDiscoveryRepo struct {
 	getErr   error
 	listHits int
 	getHits  int
+
+	feedCategory *entity.FeedCategory
+	feedItems    []*entity.FeedItem
+	feedHits     int
+
+	detail         *entity.ProgramDetail
+	sitemapEntries []*entity.SitemapEntry
 }
 
 func (f *fakeDiscoveryRepo) List(ctx context.Context, limit int, cursorPublishedAt *time.Time, cursorID string) ([]*entity.Program, error) {
@@ -37,6 +51,70 @@ func (f *fakeDiscoveryRepo) List(ctx context.Context, limit int, cursorPublished
 	return f.listResp, f.listErr
 }
 
+func (f *fakeDiscoveryRepo) GetMedia(ctx context.Context, id string) (*entity.ProgramMedia, error) {
+	_ = ctx
+	_ = id
+	return nil, f.getErr
+}
+
+func (f *fakeDiscoveryRepo) ListTranscriptCues(ctx context.Context, id string) ([]*entity.TranscriptCue, error) {
+	_ = ctx
+	_ = id
+	return nil, nil
+}
+
+func (f *fakeDiscoveryRepo) ListChapters(ctx context.Context, id string) ([]*entity.Chapter, error) {
+	_ = ctx
+	_ = id
+	return nil, nil
+}
+
+func (f *fakeDiscoveryRepo) GetFeedCategory(ctx context.Context, slug string) (*entity.FeedCategory, error) {
+	_ = ctx
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.feedHits++
+	if f.feedCategory == nil || f.feedCategory.Slug != slug {
+		return nil, apperror.ErrNotFound
+	}
+	return f.feedCategory, nil
+}
+
+func (f *fakeDiscoveryRepo) ListFeedItems(ctx context.Context, categoryID int64, limit int) ([]*entity.FeedItem, error) {
+	_ = ctx
+	_ = categoryID
+	_ = limit
+	return f.feedItems, nil
+}
+
+func (f *fakeDiscoveryRepo) GetDetail(ctx context.Context, id string) (*entity.ProgramDetail, error) {
+	_ = ctx
+	_ = id
+	if f.detail == nil {
+		return nil, apperror.ErrNotFound
+	}
+	return f.detail, nil
+}
+
+func (f *fakeDiscoveryRepo) SitemapStats(ctx context.Context) (*entity.SitemapStats, error) {
+	_ = ctx
+	stats := &entity.SitemapStats{Total: int64(len(f.sitemapEntries))}
+	for _, e := range f.sitemapEntries {
+		if e.UpdatedAt.After(stats.LastModified.Time) {
+			stats.LastModified = sql.NullTime{Time: e.UpdatedAt, Valid: true}
+		}
+	}
+	return stats, nil
+}
+
+func (f *fakeDiscoveryRepo) ListSitemapEntries(ctx context.Context, limit, offset int) ([]*entity.SitemapEntry, error) {
+	_ = ctx
+	if offset >= len(f.sitemapEntries) {
+		return nil, nil
+	}
+	return f.sitemapEntries[offset:min(offset+limit, len(f.sitemapEntries))], nil
+}
+
 func (f *fakeDiscoveryRepo) GetByID(ctx context.Context, id string) (*entity.Program, error) {
 	_ = ctx
 	_ = id
@@ -49,6 +127,7 @@ func (f *fakeDiscoveryRepo) GetByID(ctx context.Context, id string) (*entity.Pro
 type fakeCache struct {
 	mu   sync.Mutex
 	data map[string][]byte
+	tags map[string][]string
 }
 
 func newFakeCache() *fakeCache {
@@ -85,13 +164,60 @@ func (c *fakeCache) Delete(ctx context.Context, keys ...string) error {
 	return nil
 }
 
-type fakeSearcher struct{}
+func (c *fakeCache) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = value
+	if c.tags == nil {
+		c.tags = make(map[string][]string)
+	}
+	for _, t := range tags {
+		c.tags[t] = append(c.tags[t], key)
+	}
+	return nil
+}
+
+func (c *fakeCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, t := range tags {
+		for _, k := range c.tags[t] {
+			delete(c.data, k)
+		}
+		delete(c.tags, t)
+	}
+	return nil
+}
+
+type fakeStorage struct {
+	sizes map[string]int64
+}
+
+func (f *fakeStorage) Open(ctx context.Context, key string) (storage.Object, *storage.ObjectInfo, error) {
+	_ = ctx
+	_ = key
+	return nil, nil, storage.ErrObjectNotFound
+}
+
+func (f *fakeStorage) Stat(ctx context.Context, key string) (*storage.ObjectInfo, error) {
+	_ = ctx
+	size, ok := f.sizes[key]
+	if !ok {
+		return nil, storage.ErrObjectNotFound
+	}
+	return &storage.ObjectInfo{Key: key, Size: size}, nil
+}
+
+type fakeSearcher struct {
+	req    search.SearchRequest
+	facets map[string]map[string]int64
+}
 
 func (f *fakeSearcher) Search(ctx context.Context, index string, req search.SearchRequest) (*search.SearchResult, error) {
 	_ = ctx
 	_ = index
-	_ = req
-	return &search.SearchResult{Hits: []json.RawMessage{}, Page: req.Page, PerPage: req.PerPage, TotalHits: 0}, nil
+	f.req = req
+	return &search.SearchResult{Hits: []json.RawMessage{}, Page: req.Page, PerPage: req.PerPage, TotalHits: 0, Facets: f.facets}, nil
 }
 
 func makeProgram(id string, publishedAt time.Time) *entity.Program {
@@ -116,7 +242,7 @@ func TestDiscoveryService_List_UsesCache(t *testing.T) {
 	searcher := &fakeSearcher{}
 	log := zap.NewNop()
 
-	svc := New(repo, searcher, cacheStore, log)
+	svc := New(repo, searcher, cacheStore, cache.NewLoader(cacheStore, nil, log), nil, &config.Config{}, log)
 
 	p1 := makeProgram("1", time.Now().Add(-time.Hour))
 	repo.listResp = []*entity.Program{p1}
@@ -151,7 +277,7 @@ func TestDiscoveryService_GetByID_UsesCache(t *testing.T) {
 	searcher := &fakeSearcher{}
 	log := zap.NewNop()
 
-	svc := New(repo, searcher, cacheStore, log)
+	svc := New(repo, searcher, cacheStore, cache.NewLoader(cacheStore, nil, log), nil, &config.Config{}, log)
 
 	p1 := makeProgram("1", time.Now())
 	repo.getResp = p1
@@ -186,7 +312,7 @@ func TestDiscoveryService_Search_PassesThrough(t *testing.T) {
 	searcher := &fakeSearcher{}
 	log := zap.NewNop()
 
-	svc := New(repo, searcher, cacheStore, log)
+	svc := New(repo, searcher, cacheStore, cache.NewLoader(cacheStore, nil, log), nil, &config.Config{}, log)
 
 	_, err := svc.Search(context.Background(), &dto.SearchRequest{
 		Query:   "test",
@@ -197,3 +323,285 @@ func TestDiscoveryService_Search_PassesThrough(t *testing.T) {
 		t.Fatalf("search: %v", err)
 	}
 }
+
+func TestDiscoveryService_Search_Facets(t *testing.T) {
+	cacheStore := newFakeCache()
+	searcher := &fakeSearcher{facets: map[string]map[string]int64{"language": {"ar": 3, "en": 1}}}
+	log := zap.NewNop()
+
+	svc := New(&fakeDiscoveryRepo{}, searcher, cacheStore, cache.NewLoader(cacheStore, nil, log), nil, &config.Config{}, log)
+
+	resp, err := svc.Search(context.Background(), &dto.SearchRequest{
+		Query:      "test",
+		Categories: []string{"Talk's", "News"},
+		Languages:  []string{"ar"},
+		Facets:     []string{"language"},
+		Page:       1,
+		PerPage:    10,
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+
+	wantFilter := `status = 'active' AND category IN ['Talk\'s', 'News'] AND language = 'ar'`
+	if searcher.req.Filter != wantFilter {
+		t.Fatalf("filter = %q, want %q", searcher.req.Filter, wantFilter)
+	}
+	if len(searcher.req.Facets) != 1 || searcher.req.Facets[0] != "language" {
+		t.Fatalf("facets not passed through: %v", searcher.req.Facets)
+	}
+	if resp.Facets["language"]["ar"] != 3 {
+		t.Fatalf("unexpected facets: %v", resp.Facets)
+	}
+}
+
+func TestDiscoveryService_Search_NormalizesQuery(t *testing.T) {
+	cacheStore := newFakeCache()
+	searcher := &fakeSearcher{}
+	log := zap.NewNop()
+
+	svc := New(&fakeDiscoveryRepo{}, searcher, cacheStore, cache.NewLoader(cacheStore, nil, log), nil, &config.Config{}, log)
+
+	resp, err := svc.Search(context.Background(), &dto.SearchRequest{
+		Query:   "الحَلْقَةُ الأولى",
+		Page:    1,
+		PerPage: 10,
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if searcher.req.Query != "الحلقه الاولي" {
+		t.Fatalf("query = %q, want normalized", searcher.req.Query)
+	}
+	if resp.Query != "الحَلْقَةُ الأولى" {
+		t.Fatalf("response query = %q, want as typed", resp.Query)
+	}
+}
+
+// recordingSearcher keeps the last request sent to each index.
+type recordingSearcher struct {
+	*search.Memory
+	reqs map[string]search.SearchRequest
+}
+
+func (r *recordingSearcher) Search(ctx context.Context, index string, req search.SearchRequest) (*search.SearchResult, error) {
+	r.reqs[index] = req
+	return r.Memory.Search(ctx, index, req)
+}
+
+func TestDiscoveryService_Search_MatchesTranscriptCues(t *testing.T) {
+	ctx := context.Background()
+	engine := search.NewMemory()
+	_ = engine.EnsureIndex(ctx, indexName, "id", search.IndexConfig{
+		SearchableAttributes: []string{"title", "transcript.text"},
+		FilterableAttributes: []string{"status"},
+		SortableAttributes:   []string{"published_at"},
+	})
+	_ = engine.EnsureIndex(ctx, transcriptIndexName, "id", search.IndexConfig{
+		SearchableAttributes: []string{"text_normalized"},
+		FilterableAttributes: []string{"program_id"},
+	})
+	_, _ = engine.AddDocuments(ctx, indexName, []any{
+		map[string]any{"id": "p1", "title": "Episode", "status": "active", "transcript": []any{
+			map[string]any{"start_ms": 0, "end_ms": 4000, "text": "Welcome to the desert"},
+		}},
+		map[string]any{"id": "p2", "title": "Desert life", "status": "active"},
+	})
+	_, _ = engine.AddDocuments(ctx, transcriptIndexName, []any{
+		map[string]any{"id": "p1-0", "program_id": "p1", "start_ms": 0, "end_ms": 4000, "text": "Welcome to the desert", "text_normalized": "welcome to the desert"},
+		map[string]any{"id": "p1-1", "program_id": "p1", "start_ms": 4000, "end_ms": 8000, "text": "The desert at night", "text_normalized": "the desert at night"},
+		map[string]any{"id": "p3-0", "program_id": "p3", "start_ms": 0, "end_ms": 1000, "text": "desert", "text_normalized": "desert"},
+	})
+
+	cacheStore := newFakeCache()
+	log := zap.NewNop()
+	searcher := &recordingSearcher{Memory: engine, reqs: map[string]search.SearchRequest{}}
+	svc := New(&fakeDiscoveryRepo{}, searcher, cacheStore, cache.NewLoader(cacheStore, nil, log), nil, &config.Config{}, log)
+
+	resp, err := svc.Search(ctx, &dto.SearchRequest{Query: "Desert", Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if attrs := searcher.reqs[indexName].AttributesToRetrieve; len(attrs) == 0 || slices.Contains(attrs, "transcript") {
+		t.Fatalf("expected program hits without transcripts, retrieving %v", attrs)
+	}
+	if len(resp.Items) != 2 {
+		t.Fatalf("expected 2 hits, got %d", len(resp.Items))
+	}
+	if m := resp.Items[0].TranscriptMatch; m == nil || m.StartMS != 0 || m.Text != "Welcome to the desert" {
+		t.Fatalf("unexpected transcript match %+v", m)
+	}
+	if resp.Items[1].TranscriptMatch != nil {
+		t.Fatalf("expected no transcript match without cues, got %+v", resp.Items[1].TranscriptMatch)
+	}
+}
+
+type slowSearcher struct{}
+
+func (slowSearcher) Search(ctx context.Context, index string, req search.SearchRequest) (*search.SearchResult, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestDiscoveryService_Suggest_Timeout(t *testing.T) {
+	cacheStore := newFakeCache()
+	log := zap.NewNop()
+	cfg := &config.Config{Search: config.SearchConfig{SuggestTimeout: 10 * time.Millisecond}}
+
+	svc := New(&fakeDiscoveryRepo{}, slowSearcher{}, cacheStore, cache.NewLoader(cacheStore, nil, log), nil, cfg, log)
+
+	_, err := svc.Suggest(context.Background(), &dto.SuggestRequest{Query: "des", Limit: 5})
+	if !errors.Is(err, apperror.ErrServiceUnavailable) {
+		t.Fatalf("expected ErrServiceUnavailable, got %v", err)
+	}
+}
+
+func TestDiscoveryService_GetFeed(t *testing.T) {
+	updated := time.Date(2026, 2, 21, 8, 30, 15, 500, time.UTC)
+	repo := &fakeDiscoveryRepo{
+		feedCategory: &entity.FeedCategory{ID: 1, Name: "Podcast", Slug: "podcast", UpdatedAt: updated.Add(-time.Hour)},
+		feedItems: []*entity.FeedItem{
+			{ID: "a", Title: "Stored", MediaPath: "a.mp3", PublishedAt: updated, UpdatedAt: updated},
+			{ID: "b", Title: "Missing", MediaPath: "b.mp3", PublishedAt: updated, UpdatedAt: updated.Add(time.Hour)},
+		},
+	}
+	cacheStore := newFakeCache()
+	cfg := &config.Config{
+		App:  config.AppConfig{PublicURL: "https://api.example.com"},
+		Feed: config.FeedConfig{MaxItems: 10, CacheTTL: time.Minute},
+	}
+	svc := New(repo, &fakeSearcher{}, cacheStore, cache.NewLoader(cacheStore, nil, zap.NewNop()), &fakeStorage{sizes: map[string]int64{"a.mp3": 1234}}, cfg, zap.NewNop())
+
+	feed, err := svc.GetFeed(context.Background(), "podcast")
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+
+	body := string(feed.Body)
+	if !strings.Contains(body, `<enclosure url="https://api.example.com/api/v1/discover/programs/a/media" length="1234" type="audio/mpeg">`) {
+		t.Fatalf("expected enclosure for stored media, got:\n%s", body)
+	}
+	if strings.Contains(body, "Missing") {
+		t.Fatalf("expected item without stored media to be skipped")
+	}
+	if !feed.ModTime.Equal(updated.Truncate(time.Second)) {
+		t.Fatalf("expected mod time %v, got %v", updated.Truncate(time.Second), feed.ModTime)
+	}
+	if feed.ETag == "" {
+		t.Fatalf("expected etag")
+	}
+
+	again, err := svc.GetFeed(context.Background(), "podcast")
+	if err != nil {
+		t.Fatalf("get feed cached: %v", err)
+	}
+	if repo.feedHits != 1 {
+		t.Fatalf("expected repo feed hits to remain 1, got %d", repo.feedHits)
+	}
+	if again.ETag != feed.ETag {
+		t.Fatalf("expected cached etag %s, got %s", feed.ETag, again.ETag)
+	}
+
+	if _, err := svc.GetFeed(context.Background(), "unknown"); !errors.Is(err, apperror.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestDiscoveryService_GetSitemap_Paging(t *testing.T) {
+	updated := time.Date(2026, 2, 20, 10, 0, 0, 0, time.UTC)
+	repo := &fakeDiscoveryRepo{}
+	for _, id := range []string{"a", "b", "c"} {
+		repo.sitemapEntries = append(repo.sitemapEntries, &entity.SitemapEntry{ID: id, UpdatedAt: updated})
+	}
+
+	cfg := &config.Config{
+		App: config.AppConfig{PublicURL: "https://api.example.com", SiteURL: "https://example.com"},
+		SEO: config.SEOConfig{SitemapPageSize: 2, SitemapCacheTTL: time.Minute},
+	}
+	svc := newTestService(repo, cfg)
+
+	root, err := svc.GetSitemap(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("get sitemap index: %v", err)
+	}
+	body := string(root.Body)
+	if !strings.Contains(body, "<sitemapindex") ||
+		!strings.Contains(body, "<loc>https://api.example.com/sitemap-2.xml</loc>") ||
+		strings.Contains(body, "sitemap-3.xml") {
+		t.Fatalf("unexpected sitemap index:\n%s", body)
+	}
+
+	second, err := svc.GetSitemap(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("get sitemap page: %v", err)
+	}
+	body = string(second.Body)
+	if !strings.Contains(body, "<loc>https://example.com/programs/c</loc>") || strings.Contains(body, "/programs/a<") {
+		t.Fatalf("unexpected sitemap page:\n%s", body)
+	}
+
+	if _, err := svc.GetSitemap(context.Background(), 3); !errors.Is(err, apperror.ErrNotFound) {
+		t.Fatalf("expected not found past last page, got %v", err)
+	}
+
+	// A catalog that fits on one page is served directly as a urlset.
+	cfg.SEO.SitemapPageSize = 10
+	svc = newTestService(repo, cfg)
+	root, err = svc.GetSitemap(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("get small sitemap: %v", err)
+	}
+	if !strings.Contains(string(root.Body), "<urlset") || !root.ModTime.Equal(updated) {
+		t.Fatalf("expected urlset with mod time %v, got %v:\n%s", updated, root.ModTime, root.Body)
+	}
+}
+
+func newTestService(repo *fakeDiscoveryRepo, cfg *config.Config) Service {
+	cacheStore := newFakeCache()
+	return New(repo, &fakeSearcher{}, cacheStore, cache.NewLoader(cacheStore, nil, zap.NewNop()), nil, cfg, zap.NewNop())
+}
+
+func TestDiscoveryService_List_InvalidatedByTags(t *testing.T) {
+	repo := &fakeDiscoveryRepo{}
+	cacheStore := newFakeCache()
+	log := zap.NewNop()
+	svc := New(repo, &fakeSearcher{}, cacheStore, cache.NewLoader(cacheStore, nil, log), nil, &config.Config{}, log)
+	inv := NewInvalidator(cacheStore, log)
+
+	p := makeProgram("1", time.Now())
+	p.CategoryID = sql.NullInt64{Int64: 1, Valid: true}
+	// Edits to a program with no category or language reach its pages
+	// through the program tag alone.
+	bare := makeProgram("5", time.Now())
+	repo.listResp = []*entity.Program{p, bare}
+
+	steps := []struct {
+		name     string
+		event    string
+		payload  messaging.ProgramEvent
+		wantHits int
+	}{
+		{"other category edit", messaging.EventProgramUpdated, messaging.ProgramEvent{ProgramID: "2", CategoryIDs: []int64{2}}, 1},
+		{"listed category edit", messaging.EventProgramUpdated, messaging.ProgramEvent{ProgramID: "1", CategoryIDs: []int64{1}}, 2},
+		{"uncategorized listed edit", messaging.EventProgramUpdated, messaging.ProgramEvent{ProgramID: "5"}, 3},
+		{"uncategorized unlisted edit", messaging.EventProgramUpdated, messaging.ProgramEvent{ProgramID: "6"}, 3},
+		{"visibility change", messaging.EventProgramUpdated, messaging.ProgramEvent{ProgramID: "2", CategoryIDs: []int64{2}, Reordered: true}, 4},
+		{"create", messaging.EventProgramCreated, messaging.ProgramEvent{ProgramID: "3"}, 5},
+	}
+
+	if _, err := svc.List(context.Background(), "", 20); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, step := range steps {
+		event := messaging.Event{Channel: messaging.ChannelPrograms, Name: step.event, Payload: step.payload}
+		if err := inv.HandleProgramEvent(context.Background(), event); err != nil {
+			t.Fatalf("%s: handle event: %v", step.name, err)
+		}
+		if _, err := svc.List(context.Background(), "", 20); err != nil {
+			t.Fatalf("%s: list: %v", step.name, err)
+		}
+		if repo.listHits != step.wantHits {
+			t.Fatalf("%s: expected repo list hits %d, got %d", step.name, step.wantHits, repo.listHits)
+		}
+	}
+}
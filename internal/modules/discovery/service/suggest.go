@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"cms-api/internal/infra/cache"
+	"cms-api/internal/infra/search"
+	"cms-api/internal/modules/discovery/dto"
+	"cms-api/internal/pkg/apperror"
+	"cms-api/internal/pkg/textnorm"
+)
+
+const suggestIndexName = "program_suggestions"
+
+// Suggestions are requested on every keystroke and tolerate being a little
+// behind, so they are served stale for much longer than they are fresh.
+var cacheSuggest = cache.LoadOptions{TTL: time.Minute, StaleTTL: 10 * time.Minute}
+
+func (s *service) Suggest(ctx context.Context, req *dto.SuggestRequest) (*dto.SuggestResponse, error) {
+	query := strings.Join(strings.Fields(textnorm.Normalize(req.Query)), " ")
+	cacheKey := fmt.Sprintf("discovery:suggest:%d:%s", req.Limit, query)
+
+	return loadJSON(ctx, s.loader, cacheKey, cacheSuggest.WithTags(tagPrograms), func(ctx context.Context) (*dto.SuggestResponse, []string, error) {
+		resp, err := s.suggest(ctx, query, req.Limit)
+		return resp, nil, err
+	})
+}
+
+// suggest looks up titles and categories in parallel, each capped at limit
+// so neither crowds out the other.
+func (s *service) suggest(ctx context.Context, query string, limit int) (*dto.SuggestResponse, error) {
+	if timeout := s.cfg.Search.SuggestTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var titles, categories *search.SearchResult
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() (err error) {
+		titles, err = s.searchSuggestions(gctx, query, "title", limit)
+		return err
+	})
+	g.Go(func() (err error) {
+		categories, err = s.searchSuggestions(gctx, query, "category", limit)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, apperror.ErrServiceUnavailable
+		}
+		return nil, fmt.Errorf("search suggestions: %w", err)
+	}
+
+	resp, err := dto.HitsToSuggestResponse(query, titles.Hits, categories.Hits)
+	if err != nil {
+		return nil, fmt.Errorf("decode suggestions: %w", err)
+	}
+	return resp, nil
+}
+
+func (s *service) searchSuggestions(ctx context.Context, query, kind string, limit int) (*search.SearchResult, error) {
+	return s.search.Search(ctx, suggestIndexName, search.SearchRequest{
+		Query:   query,
+		Filter:  fmt.Sprintf("type = '%s'", kind),
+		Page:    1,
+		PerPage: limit,
+	})
+}
@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/modules/importer/entity"
+	"cms-api/internal/pkg/apperror"
+)
+
+type memoryRepository struct {
+	store *memdb.Store
+}
+
+// NewMemory returns a Repository that reads import sources from store and
+// records import logs there, for the hermetic app and tests.
+func NewMemory(store *memdb.Store) Repository {
+	return &memoryRepository{store: store}
+}
+
+func (r *memoryRepository) ListSources(ctx context.Context) ([]*entity.ImportSource, error) {
+	var sources []*entity.ImportSource
+	err := r.store.Read(func(t *memdb.Tables) error {
+		for _, s := range t.ImportSources {
+			sources = append(sources, toSource(s))
+		}
+		slices.SortFunc(sources, func(a, b *entity.ImportSource) int { return cmp.Compare(a.ID, b.ID) })
+		return nil
+	})
+	return sources, err
+}
+
+func (r *memoryRepository) GetSourceByID(ctx context.Context, id int64) (*entity.ImportSource, error) {
+	var src *entity.ImportSource
+	err := r.store.Read(func(t *memdb.Tables) error {
+		s, ok := t.ImportSources[id]
+		if !ok {
+			return apperror.ErrNotFound
+		}
+		src = toSource(s)
+		return nil
+	})
+	return src, err
+}
+
+func (r *memoryRepository) CreateLog(ctx context.Context, log *entity.ImportLog) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		if _, ok := t.ImportLogs[log.ID]; ok {
+			return memdb.ErrDuplicateKey
+		}
+		if _, ok := t.ImportSources[log.SourceID]; !ok {
+			return memdb.ErrForeignKey
+		}
+
+		t.ImportLogs[log.ID] = &memdb.ImportLog{
+			ID:              log.ID,
+			SourceID:        log.SourceID,
+			TriggeredBy:     log.TriggeredBy,
+			Status:          log.Status,
+			RecordsImported: log.RecordsImported,
+			ErrorMessage:    log.ErrorMessage,
+			StartedAt:       log.StartedAt,
+			FinishedAt:      log.FinishedAt,
+			CreatedAt:       t.Now,
+		}
+		return nil
+	})
+}
+
+func (r *memoryRepository) UpdateLog(ctx context.Context, log *entity.ImportLog) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		if l, ok := t.ImportLogs[log.ID]; ok {
+			l.Status = log.Status
+			l.RecordsImported = log.RecordsImported
+			l.ErrorMessage = log.ErrorMessage
+			l.FinishedAt = log.FinishedAt
+		}
+		return nil
+	})
+}
+
+func toSource(s *memdb.ImportSource) *entity.ImportSource {
+	return &entity.ImportSource{
+		ID:         s.ID,
+		Name:       s.Name,
+		SourceType: s.SourceType,
+		BaseURL:    s.BaseURL,
+		IsActive:   s.IsActive,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
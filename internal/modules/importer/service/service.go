@@ -8,6 +8,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"cms-api/internal/infra/messaging"
 	"cms-api/internal/modules/importer/dto"
 	"cms-api/internal/modules/importer/entity"
 	"cms-api/internal/modules/importer/repo"
@@ -17,16 +18,18 @@ import (
 )
 
 type service struct {
-	repo     repo.Repository
-	registry *Registry
-	log      *zap.Logger
+	repo      repo.Repository
+	registry  *Registry
+	publisher messaging.Publisher
+	log       *zap.Logger
 }
 
-func New(repo repo.Repository, registry *Registry, log *zap.Logger) Service {
+func New(repo repo.Repository, registry *Registry, publisher messaging.Publisher, log *zap.Logger) Service {
 	return &service{
-		repo:     repo,
-		registry: registry,
-		log:      log.Named("importer"),
+		repo:      repo,
+		registry:  registry,
+		publisher: publisher,
+		log:       log.Named("importer"),
 	}
 }
 
@@ -96,5 +99,12 @@ func (s *service) RunSource(ctx context.Context, sourceID int64) (*dto.ImportRun
 		return nil, err
 	}
 
+	if len(items) > 0 {
+		event := messaging.ProgramEvent{SourceID: source.ID}
+		if err := s.publisher.Publish(ctx, messaging.ChannelPrograms, messaging.EventProgramsImported, event); err != nil {
+			s.log.Error("failed to publish import event", zap.Error(err), zap.Int64("source_id", source.ID))
+		}
+	}
+
 	return dto.ToRunResponse(log), nil
 }
@@ -14,6 +14,8 @@ func ToResponse(p *entity.Program) *ProgramResponse {
 		Duration:     dbutil.NullStringToPtr(p.Duration),
 		Thumbnail:    p.Thumbnail,
 		VideoURL:     p.VideoURL,
+		MediaPath:    dbutil.NullStringToPtr(p.MediaPath),
+		MediaType:    dbutil.NullStringToPtr(p.MediaType),
 		Status:       p.Status,
 		CategoryID:   dbutil.NullInt64ToInt64Ptr(p.CategoryID),
 		CategoryName: dbutil.NullStringToPtr(p.CategoryName),
@@ -44,3 +46,39 @@ func ToListResponse(programs []*entity.Program, nextCursor string, hasNext bool)
 		HasNext:    hasNext,
 	}
 }
+
+func ToTranscriptResponse(t *entity.Transcript, cues []*entity.TranscriptCue) *TranscriptResponse {
+	items := make([]*TranscriptCueResponse, 0, len(cues))
+	for _, c := range cues {
+		items = append(items, &TranscriptCueResponse{
+			StartMS: c.StartMS,
+			EndMS:   c.EndMS,
+			Text:    c.Text,
+		})
+	}
+
+	return &TranscriptResponse{
+		ProgramID: t.ProgramID,
+		Format:    t.Format,
+		Language:  t.Language,
+		Cues:      items,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+func ToChapterListResponse(chapters []*entity.Chapter) *ChapterListResponse {
+	items := make([]*ChapterResponse, 0, len(chapters))
+	for _, c := range chapters {
+		items = append(items, &ChapterResponse{
+			StartMS: c.StartMS,
+			EndMS:   dbutil.NullInt64ToInt64Ptr(c.EndMS),
+			Title:   c.Title,
+			URL:     c.URL,
+			Image:   c.Image,
+			TOC:     c.TOC,
+		})
+	}
+
+	return &ChapterListResponse{Items: items}
+}
@@ -11,6 +11,8 @@ type CreateProgramRequest struct {
 	Duration    string `json:"duration"`
 	Thumbnail   string `json:"thumbnail" validate:"omitempty,url,max=2048"`
 	VideoURL    string `json:"video_url" validate:"omitempty,url,max=2048"`
+	MediaPath   string `json:"media_path" validate:"omitempty,max=1024"`
+	MediaType   string `json:"media_type" validate:"omitempty,max=100"`
 	Status      string `json:"status" validate:"omitempty,oneof=active inactive"`
 	CategoryID  *int64 `json:"category_id"`
 	LanguageID  *int64 `json:"language_id"`
@@ -23,6 +25,8 @@ type UpdateProgramRequest struct {
 	Duration    *string `json:"duration"`
 	Thumbnail   *string `json:"thumbnail" validate:"omitempty,url,max=2048"`
 	VideoURL    *string `json:"video_url" validate:"omitempty,url,max=2048"`
+	MediaPath   *string `json:"media_path" validate:"omitempty,max=1024"`
+	MediaType   *string `json:"media_type" validate:"omitempty,max=100"`
 	Status      *string `json:"status" validate:"omitempty,oneof=active inactive"`
 	CategoryID  *int64  `json:"category_id"`
 	LanguageID  *int64  `json:"language_id"`
@@ -42,3 +46,25 @@ func NewListProgramsRequest(cursorStr string, limit int) ListProgramsRequest {
 	}
 	return ListProgramsRequest{Cursor: cursorStr, Limit: limit}
 }
+
+// MaxTranscriptSize caps raw WebVTT/SRT uploads.
+const MaxTranscriptSize = 5 * 1024 * 1024
+
+type UploadTranscriptRequest struct {
+	Format   string `json:"format" validate:"required,oneof=vtt srt"`
+	Language string `json:"language" validate:"omitempty,max=10"`
+	Data     []byte `json:"-"`
+}
+
+type ChapterRequest struct {
+	StartMS int64  `json:"start_ms" validate:"min=0"`
+	EndMS   *int64 `json:"end_ms" validate:"omitempty,min=0"`
+	Title   string `json:"title" validate:"required,max=255"`
+	URL     string `json:"url" validate:"omitempty,url,max=2048"`
+	Image   string `json:"image" validate:"omitempty,url,max=2048"`
+	TOC     *bool  `json:"toc"`
+}
+
+type ReplaceChaptersRequest struct {
+	Chapters []ChapterRequest `json:"chapters" validate:"max=500,dive"`
+}
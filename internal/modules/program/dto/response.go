@@ -11,6 +11,8 @@ type ProgramResponse struct {
 	PublishedAt  *time.Time `json:"published_at"`
 	Thumbnail    string     `json:"thumbnail"`
 	VideoURL     string     `json:"video_url"`
+	MediaPath    *string    `json:"media_path"`
+	MediaType    *string    `json:"media_type"`
 	Status       string     `json:"status"`
 	CategoryID   *int64     `json:"category_id"`
 	CategoryName *string    `json:"category_name"`
@@ -27,3 +29,31 @@ type ProgramListResponse struct {
 	NextCursor string             `json:"next_cursor,omitempty"`
 	HasNext    bool               `json:"has_next"`
 }
+
+type TranscriptResponse struct {
+	ProgramID string                   `json:"program_id"`
+	Format    string                   `json:"format"`
+	Language  string                   `json:"language"`
+	Cues      []*TranscriptCueResponse `json:"cues"`
+	CreatedAt time.Time                `json:"created_at"`
+	UpdatedAt time.Time                `json:"updated_at"`
+}
+
+type TranscriptCueResponse struct {
+	StartMS int64  `json:"start_ms"`
+	EndMS   int64  `json:"end_ms"`
+	Text    string `json:"text"`
+}
+
+type ChapterResponse struct {
+	StartMS int64  `json:"start_ms"`
+	EndMS   *int64 `json:"end_ms"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Image   string `json:"image"`
+	TOC     bool   `json:"toc"`
+}
+
+type ChapterListResponse struct {
+	Items []*ChapterResponse `json:"items"`
+}
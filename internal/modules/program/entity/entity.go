@@ -14,6 +14,8 @@ type Program struct {
 	PublishedAt  sql.NullTime   `db:"published_at"`
 	Thumbnail    string         `db:"thumbnail"`
 	VideoURL     string         `db:"video_url"`
+	MediaPath    sql.NullString `db:"media_path"`
+	MediaType    sql.NullString `db:"media_type"`
 	ExternalID   sql.NullString `db:"external_id"`
 	Status       string         `db:"status"`
 	CategoryID   sql.NullInt64  `db:"category_id"`
@@ -29,3 +31,30 @@ type Program struct {
 	CategoryName sql.NullString `db:"category_name"`
 	LanguageCode sql.NullString `db:"language_code"`
 }
+
+type Transcript struct {
+	ProgramID string    `db:"program_id"`
+	Format    string    `db:"format"`
+	Language  string    `db:"language"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type TranscriptCue struct {
+	ProgramID string `db:"program_id"`
+	Position  int    `db:"position"`
+	StartMS   int64  `db:"start_ms"`
+	EndMS     int64  `db:"end_ms"`
+	Text      string `db:"text"`
+}
+
+type Chapter struct {
+	ProgramID string        `db:"program_id"`
+	Position  int           `db:"position"`
+	StartMS   int64         `db:"start_ms"`
+	EndMS     sql.NullInt64 `db:"end_ms"`
+	Title     string        `db:"title"`
+	URL       string        `db:"url"`
+	Image     string        `db:"image"`
+	TOC       bool          `db:"toc"`
+}
@@ -1,6 +1,8 @@
 package http
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -10,6 +12,7 @@ import (
 	"cms-api/internal/modules/program/dto"
 	"cms-api/internal/modules/program/service"
 	"cms-api/internal/pkg/httputil"
+	"cms-api/internal/pkg/transcript"
 	"cms-api/internal/pkg/validator"
 )
 
@@ -119,3 +122,122 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	httputil.OK(w, resp)
 }
+
+func (h *Handler) UploadTranscript(w http.ResponseWriter, r *http.Request) {
+	pathID := dto.PathID{ID: chi.URLParam(r, "id")}
+	if err := validator.Validate(pathID); err != nil {
+		httputil.BadRequest(w, "invalid program id")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = transcript.FormatFromContentType(r.Header.Get("Content-Type"))
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, dto.MaxTranscriptSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			httputil.BadRequest(w, "transcript too large")
+			return
+		}
+		httputil.BadRequest(w, "failed to read transcript")
+		return
+	}
+
+	req := dto.UploadTranscriptRequest{
+		Format:   format,
+		Language: r.URL.Query().Get("language"),
+		Data:     data,
+	}
+	if err := validator.Validate(req); err != nil {
+		httputil.ValidationError(w, err)
+		return
+	}
+
+	resp, err := h.service.UploadTranscript(r.Context(), pathID.ID, &req)
+	if err != nil {
+		h.log.Error("failed to upload transcript", zap.Error(err), zap.String("id", pathID.ID))
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) GetTranscript(w http.ResponseWriter, r *http.Request) {
+	pathID := dto.PathID{ID: chi.URLParam(r, "id")}
+	if err := validator.Validate(pathID); err != nil {
+		httputil.BadRequest(w, "invalid program id")
+		return
+	}
+
+	resp, err := h.service.GetTranscript(r.Context(), pathID.ID)
+	if err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) DeleteTranscript(w http.ResponseWriter, r *http.Request) {
+	pathID := dto.PathID{ID: chi.URLParam(r, "id")}
+	if err := validator.Validate(pathID); err != nil {
+		httputil.BadRequest(w, "invalid program id")
+		return
+	}
+
+	if err := h.service.DeleteTranscript(r.Context(), pathID.ID); err != nil {
+		h.log.Error("failed to delete transcript", zap.Error(err), zap.String("id", pathID.ID))
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.NoContent(w)
+}
+
+func (h *Handler) ReplaceChapters(w http.ResponseWriter, r *http.Request) {
+	pathID := dto.PathID{ID: chi.URLParam(r, "id")}
+	if err := validator.Validate(pathID); err != nil {
+		httputil.BadRequest(w, "invalid program id")
+		return
+	}
+
+	var req dto.ReplaceChaptersRequest
+	if err := httputil.DecodeJSON(w, r, &req); err != nil {
+		httputil.BadRequest(w, err.Error())
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		httputil.ValidationError(w, err)
+		return
+	}
+
+	resp, err := h.service.ReplaceChapters(r.Context(), pathID.ID, &req)
+	if err != nil {
+		h.log.Error("failed to replace chapters", zap.Error(err), zap.String("id", pathID.ID))
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) ListChapters(w http.ResponseWriter, r *http.Request) {
+	pathID := dto.PathID{ID: chi.URLParam(r, "id")}
+	if err := validator.Validate(pathID); err != nil {
+		httputil.BadRequest(w, "invalid program id")
+		return
+	}
+
+	resp, err := h.service.ListChapters(r.Context(), pathID.ID)
+	if err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
@@ -52,6 +52,26 @@ func (f *fakeProgramService) List(ctx context.Context, cursorStr string, limit i
 	return &dto.ProgramListResponse{Items: []*dto.ProgramResponse{}, HasNext: false}, nil
 }
 
+func (f *fakeProgramService) UploadTranscript(ctx context.Context, id string, req *dto.UploadTranscriptRequest) (*dto.TranscriptResponse, error) {
+	return &dto.TranscriptResponse{}, nil
+}
+
+func (f *fakeProgramService) GetTranscript(ctx context.Context, id string) (*dto.TranscriptResponse, error) {
+	return &dto.TranscriptResponse{}, nil
+}
+
+func (f *fakeProgramService) DeleteTranscript(ctx context.Context, id string) error {
+	return nil
+}
+
+func (f *fakeProgramService) ReplaceChapters(ctx context.Context, id string, req *dto.ReplaceChaptersRequest) (*dto.ChapterListResponse, error) {
+	return &dto.ChapterListResponse{}, nil
+}
+
+func (f *fakeProgramService) ListChapters(ctx context.Context, id string) (*dto.ChapterListResponse, error) {
+	return &dto.ChapterListResponse{}, nil
+}
+
 func generateKeyPair(t *testing.T) (*rsa.PrivateKey, string, func()) {
 	t.Helper()
 
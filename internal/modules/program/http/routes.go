@@ -15,5 +15,11 @@ func RegisterRoutes(r *chi.Mux, auth *middleware.AuthMiddleware, h *Handler) {
 		r.With(middleware.RequireRole("admin", "editor")).Post("/", h.Create)
 		r.With(middleware.RequireRole("admin", "editor")).Put("/{id}", h.Update)
 		r.With(middleware.RequireRole("admin")).Delete("/{id}", h.Delete)
+
+		r.With(middleware.RequireRole("admin", "editor")).Get("/{id}/transcript", h.GetTranscript)
+		r.With(middleware.RequireRole("admin", "editor")).Put("/{id}/transcript", h.UploadTranscript)
+		r.With(middleware.RequireRole("admin", "editor")).Delete("/{id}/transcript", h.DeleteTranscript)
+		r.With(middleware.RequireRole("admin", "editor")).Get("/{id}/chapters", h.ListChapters)
+		r.With(middleware.RequireRole("admin", "editor")).Put("/{id}/chapters", h.ReplaceChapters)
 	})
 }
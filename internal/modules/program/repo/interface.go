@@ -13,4 +13,11 @@ type Repository interface {
 	Delete(ctx context.Context, id string) error
 	GetByID(ctx context.Context, id string) (*entity.Program, error)
 	List(ctx context.Context, limit int, cursorCreatedAt *time.Time, cursorID string) ([]*entity.Program, error)
+
+	ReplaceTranscript(ctx context.Context, t *entity.Transcript, cues []*entity.TranscriptCue) error
+	GetTranscript(ctx context.Context, programID string) (*entity.Transcript, error)
+	ListTranscriptCues(ctx context.Context, programID string) ([]*entity.TranscriptCue, error)
+	DeleteTranscript(ctx context.Context, programID string) error
+	ReplaceChapters(ctx context.Context, programID string, chapters []*entity.Chapter) error
+	ListChapters(ctx context.Context, programID string) ([]*entity.Chapter, error)
 }
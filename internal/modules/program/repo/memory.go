@@ -0,0 +1,293 @@
+package repo
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/modules/program/entity"
+	"cms-api/internal/pkg/apperror"
+)
+
+type memoryRepository struct {
+	store *memdb.Store
+}
+
+// NewMemory returns a Repository keeping programs, transcripts and chapters
+// in store. Writes enqueue index jobs as the Postgres triggers do, so the
+// hermetic app indexes programs without a database.
+func NewMemory(store *memdb.Store) Repository {
+	return &memoryRepository{store: store}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, p *entity.Program) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		return t.InsertProgram(&memdb.Program{
+			ID:          p.ID,
+			Title:       p.Title,
+			Description: p.Description,
+			ProgramType: p.ProgramType,
+			Duration:    p.Duration,
+			Thumbnail:   p.Thumbnail,
+			VideoURL:    p.VideoURL,
+			MediaPath:   p.MediaPath,
+			MediaType:   p.MediaType,
+			Status:      p.Status,
+			CategoryID:  p.CategoryID,
+			LanguageID:  p.LanguageID,
+			CreatedBy:   p.CreatedBy,
+			UpdatedBy:   p.UpdatedBy,
+		})
+	})
+}
+
+func (r *memoryRepository) Update(ctx context.Context, p *entity.Program) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		row, ok := t.Programs[p.ID]
+		if !ok {
+			return apperror.ErrNotFound
+		}
+
+		next := *row
+		next.Title = p.Title
+		next.Description = p.Description
+		next.ProgramType = p.ProgramType
+		next.Duration = p.Duration
+		next.Thumbnail = p.Thumbnail
+		next.VideoURL = p.VideoURL
+		next.MediaPath = p.MediaPath
+		next.MediaType = p.MediaType
+		next.Status = p.Status
+		next.CategoryID = p.CategoryID
+		next.LanguageID = p.LanguageID
+		next.UpdatedBy = p.UpdatedBy
+		next.UpdatedAt = t.Now
+		return t.UpdateProgram(&next)
+	})
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id string) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		row, ok := t.Programs[id]
+		if !ok || row.DeletedAt.Valid {
+			return apperror.ErrNotFound
+		}
+
+		next := *row
+		next.DeletedAt = sql.NullTime{Time: t.Now, Valid: true}
+		return t.UpdateProgram(&next)
+	})
+}
+
+func (r *memoryRepository) GetByID(ctx context.Context, id string) (*entity.Program, error) {
+	var p *entity.Program
+	err := r.store.Read(func(t *memdb.Tables) error {
+		row, ok := t.Programs[id]
+		if !ok || row.DeletedAt.Valid {
+			return apperror.ErrNotFound
+		}
+		p = toProgram(t, row)
+		return nil
+	})
+	return p, err
+}
+
+func (r *memoryRepository) List(ctx context.Context, limit int, cursorCreatedAt *time.Time, cursorID string) ([]*entity.Program, error) {
+	var programs []*entity.Program
+	err := r.store.Read(func(t *memdb.Tables) error {
+		rows := make([]*memdb.Program, 0, len(t.Programs))
+		for _, row := range t.Programs {
+			if row.DeletedAt.Valid {
+				continue
+			}
+			if cursorCreatedAt != nil && compareCreated(row, *cursorCreatedAt, cursorID) >= 0 {
+				continue
+			}
+			rows = append(rows, row)
+		}
+
+		slices.SortFunc(rows, func(a, b *memdb.Program) int {
+			return -compareCreated(a, b.CreatedAt, b.ID)
+		})
+
+		for _, row := range rows[:min(limit, len(rows))] {
+			programs = append(programs, toProgram(t, row))
+		}
+		return nil
+	})
+	return programs, err
+}
+
+func (r *memoryRepository) ReplaceTranscript(ctx context.Context, tr *entity.Transcript, cues []*entity.TranscriptCue) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		if err := touch(t, tr.ProgramID); err != nil {
+			return err
+		}
+
+		existing, ok := t.Transcripts[tr.ProgramID]
+		createdAt := t.Now
+		if ok {
+			createdAt = existing.CreatedAt
+		}
+		t.Transcripts[tr.ProgramID] = &memdb.Transcript{
+			ProgramID: tr.ProgramID,
+			Format:    tr.Format,
+			Language:  tr.Language,
+			CreatedAt: createdAt,
+			UpdatedAt: t.Now,
+		}
+		tr.CreatedAt, tr.UpdatedAt = createdAt, t.Now
+
+		rows := make([]*memdb.TranscriptCue, 0, len(cues))
+		for _, c := range cues {
+			rows = append(rows, &memdb.TranscriptCue{Position: c.Position, StartMS: c.StartMS, EndMS: c.EndMS, Text: c.Text})
+		}
+		t.Cues[tr.ProgramID] = rows
+		return nil
+	})
+}
+
+func (r *memoryRepository) GetTranscript(ctx context.Context, programID string) (*entity.Transcript, error) {
+	var tr *entity.Transcript
+	err := r.store.Read(func(t *memdb.Tables) error {
+		row, ok := t.Transcripts[programID]
+		if !ok {
+			return apperror.ErrNotFound
+		}
+		tr = &entity.Transcript{
+			ProgramID: row.ProgramID,
+			Format:    row.Format,
+			Language:  row.Language,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		}
+		return nil
+	})
+	return tr, err
+}
+
+func (r *memoryRepository) ListTranscriptCues(ctx context.Context, programID string) ([]*entity.TranscriptCue, error) {
+	var cues []*entity.TranscriptCue
+	err := r.store.Read(func(t *memdb.Tables) error {
+		rows := slices.Clone(t.Cues[programID])
+		slices.SortFunc(rows, func(a, b *memdb.TranscriptCue) int { return cmp.Compare(a.Position, b.Position) })
+		for _, c := range rows {
+			cues = append(cues, &entity.TranscriptCue{ProgramID: programID, Position: c.Position, StartMS: c.StartMS, EndMS: c.EndMS, Text: c.Text})
+		}
+		return nil
+	})
+	return cues, err
+}
+
+func (r *memoryRepository) DeleteTranscript(ctx context.Context, programID string) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		if _, ok := t.Transcripts[programID]; !ok {
+			return apperror.ErrNotFound
+		}
+		if err := touch(t, programID); err != nil {
+			return err
+		}
+		delete(t.Transcripts, programID)
+		delete(t.Cues, programID)
+		return nil
+	})
+}
+
+func (r *memoryRepository) ReplaceChapters(ctx context.Context, programID string, chapters []*entity.Chapter) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		if _, ok := t.Programs[programID]; !ok {
+			return memdb.ErrForeignKey
+		}
+
+		rows := make([]*memdb.Chapter, 0, len(chapters))
+		for _, c := range chapters {
+			rows = append(rows, &memdb.Chapter{
+				Position: c.Position,
+				StartMS:  c.StartMS,
+				EndMS:    c.EndMS,
+				Title:    c.Title,
+				URL:      c.URL,
+				Image:    c.Image,
+				TOC:      c.TOC,
+			})
+		}
+		t.Chapters[programID] = rows
+		return nil
+	})
+}
+
+func (r *memoryRepository) ListChapters(ctx context.Context, programID string) ([]*entity.Chapter, error) {
+	var chapters []*entity.Chapter
+	err := r.store.Read(func(t *memdb.Tables) error {
+		rows := slices.Clone(t.Chapters[programID])
+		slices.SortFunc(rows, func(a, b *memdb.Chapter) int { return cmp.Compare(a.Position, b.Position) })
+		for _, c := range rows {
+			chapters = append(chapters, &entity.Chapter{
+				ProgramID: programID,
+				Position:  c.Position,
+				StartMS:   c.StartMS,
+				EndMS:     c.EndMS,
+				Title:     c.Title,
+				URL:       c.URL,
+				Image:     c.Image,
+				TOC:       c.TOC,
+			})
+		}
+		return nil
+	})
+	return chapters, err
+}
+
+// touch bumps updated_at on a live program so the index trigger fires.
+func touch(t *memdb.Tables, programID string) error {
+	row, ok := t.Programs[programID]
+	if !ok || row.DeletedAt.Valid {
+		return apperror.ErrNotFound
+	}
+	next := *row
+	next.UpdatedAt = t.Now
+	return t.UpdateProgram(&next)
+}
+
+// compareCreated orders a row against a (created_at, id) pair like a row
+// comparison in SQL.
+func compareCreated(row *memdb.Program, createdAt time.Time, id string) int {
+	if c := row.CreatedAt.Compare(createdAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(row.ID, id)
+}
+
+func toProgram(t *memdb.Tables, row *memdb.Program) *entity.Program {
+	p := &entity.Program{
+		ID:           row.ID,
+		Title:        row.Title,
+		Description:  row.Description,
+		ProgramType:  row.ProgramType,
+		Duration:     row.Duration,
+		PublishedAt:  row.PublishedAt,
+		Thumbnail:    row.Thumbnail,
+		VideoURL:     row.VideoURL,
+		MediaPath:    row.MediaPath,
+		MediaType:    row.MediaType,
+		ExternalID:   row.ExternalID,
+		Status:       row.Status,
+		CategoryID:   row.CategoryID,
+		LanguageID:   row.LanguageID,
+		ImportSource: row.ImportSourceID,
+		CreatedBy:    row.CreatedBy,
+		UpdatedBy:    row.UpdatedBy,
+		CreatedAt:    row.CreatedAt,
+		UpdatedAt:    row.UpdatedAt,
+	}
+	if c := t.CategoryOf(row); c != nil {
+		p.CategoryName = sql.NullString{String: c.Name, Valid: true}
+	}
+	if l := t.LanguageOf(row); l != nil {
+		p.LanguageCode = sql.NullString{String: l.Code, Valid: true}
+	}
+	return p
+}
@@ -1,16 +1,17 @@
 package repo
 
 const queryCreate = `
-	INSERT INTO programs (id, title, description, program_type, duration, thumbnail, video_url, status, category_id, language_id, created_by, updated_by, created_at, updated_at)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())
+	INSERT INTO programs (id, title, description, program_type, duration, thumbnail, video_url, media_path, media_type, status, category_id, language_id, created_by, updated_by, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NOW(), NOW())
 `
 
 const queryUpdate = `
 	UPDATE programs
 	SET title = $1, description = $2, program_type = $3, duration = $4,
-	    thumbnail = $5, video_url = $6, status = $7, category_id = $8, language_id = $9,
-	    updated_by = $10, updated_at = NOW()
-	WHERE id = $11
+	    thumbnail = $5, video_url = $6, media_path = $7, media_type = $8,
+	    status = $9, category_id = $10, language_id = $11,
+	    updated_by = $12, updated_at = NOW()
+	WHERE id = $13
 `
 
 const queryDelete = `
@@ -19,7 +20,8 @@ const queryDelete = `
 
 const queryGetByID = `
 	SELECT p.id, p.title, p.description, p.program_type, p.duration,
-	       p.published_at, p.thumbnail, p.video_url, p.external_id, p.status,
+	       p.published_at, p.thumbnail, p.video_url, p.media_path, p.media_type,
+	       p.external_id, p.status,
 	       p.category_id, p.language_id, p.import_source_id,
 	       p.created_by, p.updated_by, p.created_at, p.updated_at,
 	       c.name AS category_name,
@@ -32,7 +34,8 @@ const queryGetByID = `
 
 const queryListFirst = `
 	SELECT p.id, p.title, p.description, p.program_type, p.duration,
-	       p.published_at, p.thumbnail, p.video_url, p.external_id, p.status,
+	       p.published_at, p.thumbnail, p.video_url, p.media_path, p.media_type,
+	       p.external_id, p.status,
 	       p.category_id, p.language_id, p.import_source_id,
 	       p.created_by, p.updated_by, p.created_at, p.updated_at,
 	       c.name AS category_name,
@@ -47,7 +50,8 @@ const queryListFirst = `
 
 const queryListAfterCursor = `
 	SELECT p.id, p.title, p.description, p.program_type, p.duration,
-	       p.published_at, p.thumbnail, p.video_url, p.external_id, p.status,
+	       p.published_at, p.thumbnail, p.video_url, p.media_path, p.media_type,
+	       p.external_id, p.status,
 	       p.category_id, p.language_id, p.import_source_id,
 	       p.created_by, p.updated_by, p.created_at, p.updated_at,
 	       c.name AS category_name,
@@ -59,3 +63,57 @@ const queryListAfterCursor = `
 	ORDER BY p.created_at DESC, p.id DESC
 	LIMIT $1
 `
+
+const queryTouchProgram = `
+	UPDATE programs SET updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL
+`
+
+const queryUpsertTranscript = `
+	INSERT INTO program_transcripts (program_id, format, language, created_at, updated_at)
+	VALUES ($1, $2, $3, NOW(), NOW())
+	ON CONFLICT (program_id) DO UPDATE
+	SET format = EXCLUDED.format, language = EXCLUDED.language, updated_at = NOW()
+	RETURNING created_at, updated_at
+`
+
+const queryDeleteTranscriptCues = `
+	DELETE FROM program_transcript_cues WHERE program_id = $1
+`
+
+const queryInsertTranscriptCues = `
+	INSERT INTO program_transcript_cues (program_id, position, start_ms, end_ms, text)
+	VALUES (:program_id, :position, :start_ms, :end_ms, :text)
+`
+
+const queryGetTranscript = `
+	SELECT program_id, format, language, created_at, updated_at
+	FROM program_transcripts
+	WHERE program_id = $1
+`
+
+const queryListTranscriptCues = `
+	SELECT program_id, position, start_ms, end_ms, text
+	FROM program_transcript_cues
+	WHERE program_id = $1
+	ORDER BY position ASC
+`
+
+const queryDeleteTranscript = `
+	DELETE FROM program_transcripts WHERE program_id = $1
+`
+
+const queryDeleteChapters = `
+	DELETE FROM program_chapters WHERE program_id = $1
+`
+
+const queryInsertChapters = `
+	INSERT INTO program_chapters (program_id, position, start_ms, end_ms, title, url, image, toc)
+	VALUES (:program_id, :position, :start_ms, :end_ms, :title, :url, :image, :toc)
+`
+
+const queryListChapters = `
+	SELECT program_id, position, start_ms, end_ms, title, url, image, toc
+	FROM program_chapters
+	WHERE program_id = $1
+	ORDER BY position ASC
+`
@@ -8,10 +8,14 @@ import (
 
 	"github.com/jmoiron/sqlx"
 
+	"cms-api/internal/infra/database"
 	"cms-api/internal/modules/program/entity"
 	"cms-api/internal/pkg/apperror"
 )
 
+// Keeps bulk inserts well under the Postgres bind parameter limit.
+const insertChunkSize = 1000
+
 type repository struct {
 	db *sqlx.DB
 }
@@ -23,7 +27,8 @@ func New(db *sqlx.DB) Repository {
 func (r *repository) Create(ctx context.Context, p *entity.Program) error {
 	_, err := r.db.ExecContext(ctx, queryCreate,
 		p.ID, p.Title, p.Description, p.ProgramType, p.Duration,
-		p.Thumbnail, p.VideoURL, p.Status, p.CategoryID, p.LanguageID,
+		p.Thumbnail, p.VideoURL, p.MediaPath, p.MediaType,
+		p.Status, p.CategoryID, p.LanguageID,
 		p.CreatedBy, p.UpdatedBy,
 	)
 	return err
@@ -32,7 +37,8 @@ func (r *repository) Create(ctx context.Context, p *entity.Program) error {
 func (r *repository) Update(ctx context.Context, p *entity.Program) error {
 	result, err := r.db.ExecContext(ctx, queryUpdate,
 		p.Title, p.Description, p.ProgramType, p.Duration,
-		p.Thumbnail, p.VideoURL, p.Status, p.CategoryID, p.LanguageID,
+		p.Thumbnail, p.VideoURL, p.MediaPath, p.MediaType,
+		p.Status, p.CategoryID, p.LanguageID,
 		p.UpdatedBy, p.ID,
 	)
 	if err != nil {
@@ -93,3 +99,111 @@ func (r *repository) List(ctx context.Context, limit int, cursorCreatedAt *time.
 	}
 	return programs, nil
 }
+
+// ReplaceTranscript swaps the stored cues and touches the program so the
+// index trigger picks up the new transcript text.
+func (r *repository) ReplaceTranscript(ctx context.Context, t *entity.Transcript, cues []*entity.TranscriptCue) error {
+	return database.Transaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := touchProgram(ctx, tx, t.ProgramID); err != nil {
+			return err
+		}
+
+		if err := tx.QueryRowxContext(ctx, queryUpsertTranscript, t.ProgramID, t.Format, t.Language).
+			Scan(&t.CreatedAt, &t.UpdatedAt); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, queryDeleteTranscriptCues, t.ProgramID); err != nil {
+			return err
+		}
+
+		for start := 0; start < len(cues); start += insertChunkSize {
+			end := min(start+insertChunkSize, len(cues))
+			if _, err := tx.NamedExecContext(ctx, queryInsertTranscriptCues, cues[start:end]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *repository) GetTranscript(ctx context.Context, programID string) (*entity.Transcript, error) {
+	var t entity.Transcript
+	if err := r.db.GetContext(ctx, &t, queryGetTranscript, programID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *repository) ListTranscriptCues(ctx context.Context, programID string) ([]*entity.TranscriptCue, error) {
+	var cues []*entity.TranscriptCue
+	if err := r.db.SelectContext(ctx, &cues, queryListTranscriptCues, programID); err != nil {
+		return nil, err
+	}
+	return cues, nil
+}
+
+func (r *repository) DeleteTranscript(ctx context.Context, programID string) error {
+	return database.Transaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, queryDeleteTranscript, programID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return apperror.ErrNotFound
+		}
+
+		return touchProgram(ctx, tx, programID)
+	})
+}
+
+func (r *repository) ReplaceChapters(ctx context.Context, programID string, chapters []*entity.Chapter) error {
+	return database.Transaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, queryDeleteChapters, programID); err != nil {
+			return err
+		}
+
+		for start := 0; start < len(chapters); start += insertChunkSize {
+			end := min(start+insertChunkSize, len(chapters))
+			if _, err := tx.NamedExecContext(ctx, queryInsertChapters, chapters[start:end]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *repository) ListChapters(ctx context.Context, programID string) ([]*entity.Chapter, error) {
+	var chapters []*entity.Chapter
+	if err := r.db.SelectContext(ctx, &chapters, queryListChapters, programID); err != nil {
+		return nil, err
+	}
+	return chapters, nil
+}
+
+func touchProgram(ctx context.Context, tx *sqlx.Tx, programID string) error {
+	result, err := tx.ExecContext(ctx, queryTouchProgram, programID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
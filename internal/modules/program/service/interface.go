@@ -12,4 +12,10 @@ type Service interface {
 	Delete(ctx context.Context, id string) error
 	GetByID(ctx context.Context, id string) (*dto.ProgramResponse, error)
 	List(ctx context.Context, cursorStr string, limit int) (*dto.ProgramListResponse, error)
+
+	UploadTranscript(ctx context.Context, id string, req *dto.UploadTranscriptRequest) (*dto.TranscriptResponse, error)
+	GetTranscript(ctx context.Context, id string) (*dto.TranscriptResponse, error)
+	DeleteTranscript(ctx context.Context, id string) error
+	ReplaceChapters(ctx context.Context, id string, req *dto.ReplaceChaptersRequest) (*dto.ChapterListResponse, error)
+	ListChapters(ctx context.Context, id string) (*dto.ChapterListResponse, error)
 }
@@ -3,10 +3,14 @@ package service
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"slices"
+	"sort"
 	"time"
 
 	"go.uber.org/zap"
 
+	"cms-api/internal/infra/messaging"
 	"cms-api/internal/modules/program/dto"
 	"cms-api/internal/modules/program/entity"
 	"cms-api/internal/modules/program/repo"
@@ -14,16 +18,18 @@ import (
 	"cms-api/internal/pkg/contextutil"
 	"cms-api/internal/pkg/cursor"
 	"cms-api/internal/pkg/dbutil"
+	"cms-api/internal/pkg/transcript"
 	"cms-api/internal/pkg/uuidutil"
 )
 
 type service struct {
-	repo repo.Repository
-	log  *zap.Logger
+	repo      repo.Repository
+	publisher messaging.Publisher
+	log       *zap.Logger
 }
 
-func New(repo repo.Repository, log *zap.Logger) Service {
-	return &service{repo: repo, log: log}
+func New(repo repo.Repository, publisher messaging.Publisher, log *zap.Logger) Service {
+	return &service{repo: repo, publisher: publisher, log: log}
 }
 
 func (s *service) Create(ctx context.Context, req *dto.CreateProgramRequest) (*dto.ProgramResponse, error) {
@@ -47,6 +53,8 @@ func (s *service) Create(ctx context.Context, req *dto.CreateProgramRequest) (*d
 		Duration:    dbutil.NewNullString(req.Duration),
 		Thumbnail:   req.Thumbnail,
 		VideoURL:    req.VideoURL,
+		MediaPath:   dbutil.NewNullString(req.MediaPath),
+		MediaType:   dbutil.NewNullString(req.MediaType),
 		Status:      status,
 		CreatedBy:   dbutil.NewNullString(userID),
 		UpdatedBy:   dbutil.NewNullString(userID),
@@ -63,6 +71,8 @@ func (s *service) Create(ctx context.Context, req *dto.CreateProgramRequest) (*d
 		return nil, fmt.Errorf("create program: %w", err)
 	}
 
+	s.publish(ctx, messaging.EventProgramCreated, messaging.ProgramEvent{ProgramID: id})
+
 	created, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get created program: %w", err)
@@ -76,6 +86,7 @@ func (s *service) Update(ctx context.Context, id string, req *dto.UpdateProgramR
 	if err != nil {
 		return nil, err
 	}
+	before := *existing
 
 	if req.Title != nil {
 		existing.Title = *req.Title
@@ -95,6 +106,12 @@ func (s *service) Update(ctx context.Context, id string, req *dto.UpdateProgramR
 	if req.VideoURL != nil {
 		existing.VideoURL = *req.VideoURL
 	}
+	if req.MediaPath != nil {
+		existing.MediaPath = dbutil.NewNullString(*req.MediaPath)
+	}
+	if req.MediaType != nil {
+		existing.MediaType = dbutil.NewNullString(*req.MediaType)
+	}
 	if req.Status != nil {
 		existing.Status = *req.Status
 	}
@@ -113,14 +130,23 @@ func (s *service) Update(ctx context.Context, id string, req *dto.UpdateProgramR
 
 	updated, err := s.repo.GetByID(ctx, id)
 	if err != nil {
+		// Without the stored state the change cannot be scoped.
+		s.publish(ctx, messaging.EventProgramUpdated, messaging.ProgramEvent{ProgramID: id, Reordered: true})
 		return nil, fmt.Errorf("get updated program: %w", err)
 	}
 
+	s.publish(ctx, messaging.EventProgramUpdated, updateEvent(&before, updated))
+
 	return dto.ToResponse(updated), nil
 }
 
 func (s *service) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.publish(ctx, messaging.EventProgramDeleted, messaging.ProgramEvent{ProgramID: id})
+	return nil
 }
 
 func (s *service) GetByID(ctx context.Context, id string) (*dto.ProgramResponse, error) {
@@ -162,3 +188,159 @@ func (s *service) List(ctx context.Context, cursorStr string, limit int) (*dto.P
 
 	return dto.ToListResponse(programs, nextCursor, hasNext), nil
 }
+
+func (s *service) UploadTranscript(ctx context.Context, id string, req *dto.UploadTranscriptRequest) (*dto.TranscriptResponse, error) {
+	parsed, err := transcript.Parse(req.Format, req.Data)
+	if err != nil {
+		return nil, apperror.NewAppError(apperror.ErrBadRequest, err.Error(), http.StatusBadRequest)
+	}
+
+	t := &entity.Transcript{
+		ProgramID: id,
+		Format:    req.Format,
+		Language:  req.Language,
+	}
+
+	cues := make([]*entity.TranscriptCue, 0, len(parsed))
+	for i, c := range parsed {
+		cues = append(cues, &entity.TranscriptCue{
+			ProgramID: id,
+			Position:  i,
+			StartMS:   c.Start.Milliseconds(),
+			EndMS:     c.End.Milliseconds(),
+			Text:      c.Text,
+		})
+	}
+
+	if err := s.repo.ReplaceTranscript(ctx, t, cues); err != nil {
+		return nil, fmt.Errorf("replace transcript: %w", err)
+	}
+
+	s.publish(ctx, messaging.EventProgramUpdated, s.contentEvent(ctx, id))
+
+	return dto.ToTranscriptResponse(t, cues), nil
+}
+
+func (s *service) GetTranscript(ctx context.Context, id string) (*dto.TranscriptResponse, error) {
+	t, err := s.repo.GetTranscript(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cues, err := s.repo.ListTranscriptCues(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list transcript cues: %w", err)
+	}
+
+	return dto.ToTranscriptResponse(t, cues), nil
+}
+
+func (s *service) DeleteTranscript(ctx context.Context, id string) error {
+	if err := s.repo.DeleteTranscript(ctx, id); err != nil {
+		return err
+	}
+
+	s.publish(ctx, messaging.EventProgramUpdated, s.contentEvent(ctx, id))
+	return nil
+}
+
+func (s *service) ReplaceChapters(ctx context.Context, id string, req *dto.ReplaceChaptersRequest) (*dto.ChapterListResponse, error) {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.ChapterRequest, len(req.Chapters))
+	copy(items, req.Chapters)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].StartMS < items[j].StartMS })
+
+	chapters := make([]*entity.Chapter, 0, len(items))
+	for i, c := range items {
+		if c.EndMS != nil && *c.EndMS < c.StartMS {
+			return nil, apperror.NewAppError(apperror.ErrBadRequest, "chapter end_ms must not be before start_ms", http.StatusBadRequest)
+		}
+
+		toc := true
+		if c.TOC != nil {
+			toc = *c.TOC
+		}
+
+		ch := &entity.Chapter{
+			ProgramID: id,
+			Position:  i,
+			StartMS:   c.StartMS,
+			Title:     c.Title,
+			URL:       c.URL,
+			Image:     c.Image,
+			TOC:       toc,
+		}
+		if c.EndMS != nil {
+			ch.EndMS = dbutil.NewNullInt64(*c.EndMS, true)
+		}
+		chapters = append(chapters, ch)
+	}
+
+	if err := s.repo.ReplaceChapters(ctx, id, chapters); err != nil {
+		return nil, fmt.Errorf("replace chapters: %w", err)
+	}
+
+	s.publish(ctx, messaging.EventProgramUpdated, updateEvent(p, p))
+
+	return dto.ToChapterListResponse(chapters), nil
+}
+
+func (s *service) ListChapters(ctx context.Context, id string) (*dto.ChapterListResponse, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	chapters, err := s.repo.ListChapters(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list chapters: %w", err)
+	}
+
+	return dto.ToChapterListResponse(chapters), nil
+}
+
+// publish notifies subscribers of a committed change. Failures are logged
+// rather than returned since the write itself has already succeeded.
+func (s *service) publish(ctx context.Context, event string, payload messaging.ProgramEvent) {
+	if err := s.publisher.Publish(ctx, messaging.ChannelPrograms, event, payload); err != nil {
+		s.log.Error("failed to publish program event",
+			zap.Error(err),
+			zap.String("event", event),
+			zap.String("id", payload.ProgramID),
+		)
+	}
+}
+
+// contentEvent describes a change to the transcript or chapters of program
+// id. Category feeds render both, so the event carries the program's
+// category and language like any in-place edit.
+func (s *service) contentEvent(ctx context.Context, id string) messaging.ProgramEvent {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		// Without the stored state the change cannot be scoped.
+		return messaging.ProgramEvent{ProgramID: id, Reordered: true}
+	}
+	return updateEvent(p, p)
+}
+
+// updateEvent describes an update by the listing attributes it touched.
+func updateEvent(before, after *entity.Program) messaging.ProgramEvent {
+	e := messaging.ProgramEvent{
+		ProgramID: after.ID,
+		Reordered: before.Status != after.Status ||
+			before.PublishedAt.Valid != after.PublishedAt.Valid ||
+			!before.PublishedAt.Time.Equal(after.PublishedAt.Time),
+	}
+	for _, p := range []*entity.Program{before, after} {
+		if p.CategoryID.Valid && !slices.Contains(e.CategoryIDs, p.CategoryID.Int64) {
+			e.CategoryIDs = append(e.CategoryIDs, p.CategoryID.Int64)
+		}
+		if p.LanguageCode.Valid && !slices.Contains(e.LanguageCodes, p.LanguageCode.String) {
+			e.LanguageCodes = append(e.LanguageCodes, p.LanguageCode.String)
+		}
+	}
+	return e
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/infra/messaging"
+	"cms-api/internal/modules/program/dto"
+	"cms-api/internal/modules/program/repo"
+)
+
+type recordingPublisher struct {
+	events []messaging.ProgramEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, channel, name string, payload any) error {
+	if pe, ok := payload.(messaging.ProgramEvent); ok {
+		p.events = append(p.events, pe)
+	}
+	return nil
+}
+
+func TestService_ContentChangesCarryFeedScope(t *testing.T) {
+	store := memdb.New()
+	_ = store.Write(func(tb *memdb.Tables) error {
+		tb.Categories[7] = &memdb.Category{ID: 7, Name: "Talks", Slug: "talks"}
+		tb.Languages[3] = &memdb.Language{ID: 3, Name: "Arabic", Code: "ar"}
+		return tb.InsertProgram(&memdb.Program{
+			ID: "p1", Title: "Program", ProgramType: "podcast", Status: "active",
+			CategoryID: sql.NullInt64{Int64: 7, Valid: true},
+			LanguageID: sql.NullInt64{Int64: 3, Valid: true},
+		})
+	})
+	pub := &recordingPublisher{}
+	svc := New(repo.NewMemory(store), pub, zap.NewNop())
+	ctx := context.Background()
+
+	vtt := []byte("WEBVTT\n\n00:00:00.000 --> 00:00:01.000\nHello\n")
+	if _, err := svc.UploadTranscript(ctx, "p1", &dto.UploadTranscriptRequest{Format: "vtt", Data: vtt}); err != nil {
+		t.Fatalf("upload transcript: %v", err)
+	}
+	if err := svc.DeleteTranscript(ctx, "p1"); err != nil {
+		t.Fatalf("delete transcript: %v", err)
+	}
+	if _, err := svc.ReplaceChapters(ctx, "p1", &dto.ReplaceChaptersRequest{Chapters: []dto.ChapterRequest{{StartMS: 0, Title: "Intro"}}}); err != nil {
+		t.Fatalf("replace chapters: %v", err)
+	}
+
+	if len(pub.events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(pub.events))
+	}
+	for i, e := range pub.events {
+		if e.ProgramID != "p1" || e.Reordered || !slices.Equal(e.CategoryIDs, []int64{7}) || !slices.Equal(e.LanguageCodes, []string{"ar"}) {
+			t.Fatalf("event %d: unexpected scope %+v", i, e)
+		}
+	}
+}
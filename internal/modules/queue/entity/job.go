@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// DefaultQueue is the queue jobs are enqueued on unless they name another.
+const DefaultQueue = "default"
+
+type Job struct {
+	ID          string         `db:"id"`
+	Queue       string         `db:"queue"`
+	Kind        string         `db:"kind"`
+	Payload     []byte         `db:"payload"`
+	Priority    int            `db:"priority"`
+	UniqueKey   sql.NullString `db:"unique_key"`
+	Status      string         `db:"status"`
+	Attempts    int            `db:"attempts"`
+	MaxAttempts int            `db:"max_attempts"`
+	LastError   sql.NullString `db:"last_error"`
+	ScheduledAt time.Time      `db:"scheduled_at"`
+	ProcessedAt sql.NullTime   `db:"processed_at"`
+	LockedUntil sql.NullTime   `db:"locked_until"`
+	LockedBy    sql.NullString `db:"locked_by"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+}
+
+// DecodePayload unmarshals the job's JSON payload into v.
+func (j *Job) DecodePayload(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// JobFilter narrows a job listing. Empty fields match every job.
+type JobFilter struct {
+	Queue  string
+	Kind   string
+	Status string
+	// Payload matches jobs whose payload contains it, like JSONB @>.
+	Payload []byte
+}
+
+// JobStats is a snapshot of a job queue. WindowCompleted and WindowFailed
+// count the jobs whose latest attempt, completed or failed (including
+// dead), ended within the requested window.
+type JobStats struct {
+	Pending         int          `db:"pending"`
+	Processing      int          `db:"processing"`
+	Failed          int          `db:"failed"`
+	Dead            int          `db:"dead"`
+	Completed       int          `db:"completed"`
+	Due             int          `db:"due"`
+	OldestPendingAt sql.NullTime `db:"oldest_pending_at"`
+	WindowCompleted int          `db:"window_completed"`
+	WindowFailed    int          `db:"window_failed"`
+	CheckedAt       time.Time    `db:"checked_at"`
+}
@@ -0,0 +1,51 @@
+package queue
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+
+	"cms-api/internal/modules/queue/repo"
+	"cms-api/internal/modules/queue/service"
+)
+
+var Module = fx.Module("queue",
+	fx.Provide(repo.New),
+	fx.Provide(repo.NewListener),
+	fx.Provide(service.NewQueue),
+	fx.Provide(service.NewRegistry),
+	fx.Provide(service.New),
+	fx.Invoke(startWorkers),
+)
+
+// startWorkers runs the queue workers for the app's lifetime. On stop they
+// stop claiming jobs and drain the running ones within the stop timeout.
+func startWorkers(lc fx.Lifecycle, svc service.Service) {
+	var cancel context.CancelFunc
+	var done chan struct{}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			var workerCtx context.Context
+			workerCtx, cancel = context.WithCancel(context.Background())
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				svc.Start(workerCtx)
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if cancel == nil {
+				return nil
+			}
+			cancel()
+			select {
+			case <-done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			return svc.Drain(ctx)
+		},
+	})
+}
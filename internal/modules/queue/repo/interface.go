@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"cms-api/internal/modules/queue/entity"
+)
+
+type Repository interface {
+	// Enqueue inserts job and returns the stored row. When an active job of
+	// the same kind holds job's unique key, that job is rescheduled to the
+	// new job's run time and returned instead.
+	Enqueue(ctx context.Context, job *entity.Job, delay time.Duration) (*entity.Job, error)
+	// ClaimJobs moves up to limit due jobs of kinds on queue to processing,
+	// leased to workerID for lease, highest priority first and then in
+	// scheduled order.
+	ClaimJobs(ctx context.Context, queue string, kinds []string, workerID string, limit int, lease time.Duration) ([]entity.Job, error)
+	// MarkCompleted, MarkFailed and MarkDead release the job's lease. They
+	// return apperror.ErrConflict, changing nothing, once workerID no longer
+	// holds it.
+	MarkCompleted(ctx context.Context, jobID string, workerID string) error
+	MarkFailed(ctx context.Context, jobID string, workerID string, errMsg string, nextSchedule time.Time) error
+	MarkDead(ctx context.Context, jobID string, workerID string, errMsg string) error
+	// ExtendJobLeases renews the leases workerID still holds on jobIDs and
+	// returns how many it renewed.
+	ExtendJobLeases(ctx context.Context, workerID string, jobIDs []string, lease time.Duration) (int, error)
+	// ReleaseExpiredJobs returns processing jobs whose lease ran out to
+	// pending, counting an attempt, or to dead once they reach max_attempts.
+	ReleaseExpiredJobs(ctx context.Context) (int, error)
+
+	// ListJobs returns up to limit jobs matching filter, newest first,
+	// starting after the (cursorTime, cursorID) of the previous page.
+	ListJobs(ctx context.Context, filter entity.JobFilter, limit int, cursorTime *time.Time, cursorID string) ([]entity.Job, error)
+	GetJob(ctx context.Context, id string) (*entity.Job, error)
+	// RetryDeadJobs and DiscardDeadJobs act on the dead jobs of kind ("" for
+	// any) among ids, or on every such dead job when ids is nil, and return
+	// the IDs they changed. RetryDeadJobs requeues only the newest dead job
+	// per unique key, and none while an active job holds the key.
+	RetryDeadJobs(ctx context.Context, kind string, ids []string) ([]string, error)
+	DiscardDeadJobs(ctx context.Context, kind string, ids []string) ([]string, error)
+	// JobStats counts the jobs of kind ("" for any) by status, with the
+	// outcomes of attempts that ended within window.
+	JobStats(ctx context.Context, kind string, window time.Duration) (*entity.JobStats, error)
+}
+
+// Listener wakes the workers of a queue as soon as jobs become ready on it,
+// ahead of their next poll.
+type Listener interface {
+	// Wake receives after jobs may have become ready on queue. Signals
+	// coalesce, so one receive can stand for many jobs.
+	Wake(queue string) <-chan struct{}
+}
@@ -0,0 +1,145 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"cms-api/internal/config"
+)
+
+// jobsChannel is the channel the jobs table trigger notifies on, with the
+// queue name as payload, when a job becomes ready.
+const jobsChannel = "jobs"
+
+const (
+	listenerMinReconnect = time.Second
+	listenerMaxReconnect = time.Minute
+	// listenerPingInterval is how long the connection may stay silent before
+	// it is checked, so a dead connection is noticed and replaced.
+	listenerPingInterval = 90 * time.Second
+)
+
+type listener struct {
+	dsn string
+	log *zap.Logger
+
+	mu   sync.Mutex
+	wake map[string]chan struct{}
+}
+
+// NewListener holds a dedicated connection listening on jobsChannel. It
+// reconnects with backoff after losing the connection and then wakes every
+// queue once, since notifications sent in the meantime are lost.
+func NewListener(lc fx.Lifecycle, cfg *config.Config, log *zap.Logger) Listener {
+	l := &listener{
+		dsn:  cfg.Database.DSN(),
+		log:  log.Named("queue_listener"),
+		wake: make(map[string]chan struct{}),
+	}
+
+	var (
+		pl     *pq.Listener
+		cancel context.CancelFunc
+		done   chan struct{}
+	)
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			pl = pq.NewListener(l.dsn, listenerMinReconnect, listenerMaxReconnect, l.onEvent)
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				l.run(ctx, pl)
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			err := pl.Close()
+			<-done
+			return err
+		},
+	})
+	return l
+}
+
+func (l *listener) Wake(queue string) <-chan struct{} {
+	return l.channel(queue)
+}
+
+func (l *listener) channel(queue string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ch, ok := l.wake[queue]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		l.wake[queue] = ch
+	}
+	return ch
+}
+
+func (l *listener) run(ctx context.Context, pl *pq.Listener) {
+	// Listen blocks until the server acknowledges, which may be never while
+	// the database is down; Close releases it.
+	go func() {
+		if err := pl.Listen(jobsChannel); err != nil && ctx.Err() == nil {
+			l.log.Error("Failed to listen for jobs", zap.String("channel", jobsChannel), zap.Error(err))
+		}
+	}()
+
+	ping := time.NewTicker(listenerPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-pl.Notify:
+			// A nil notification follows a reconnect; wake every queue for it.
+			if n == nil {
+				l.signalAll()
+			} else {
+				signal(l.channel(n.Extra))
+			}
+			ping.Reset(listenerPingInterval)
+		case <-ping.C:
+			if err := pl.Ping(); err != nil {
+				l.log.Debug("Listener connection check failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (l *listener) signalAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, ch := range l.wake {
+		signal(ch)
+	}
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (l *listener) onEvent(ev pq.ListenerEventType, err error) {
+	switch ev {
+	case pq.ListenerEventConnected:
+		l.log.Info("Listening for jobs", zap.String("channel", jobsChannel))
+	case pq.ListenerEventReconnected:
+		l.log.Info("Listener reconnected", zap.String("channel", jobsChannel))
+	case pq.ListenerEventDisconnected:
+		l.log.Warn("Listener disconnected, falling back to polling", zap.Error(err))
+	case pq.ListenerEventConnectionAttemptFailed:
+		l.log.Warn("Listener connection attempt failed", zap.Error(err))
+	}
+}
@@ -0,0 +1,362 @@
+package repo
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"time"
+
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/modules/queue/entity"
+	"cms-api/internal/pkg/apperror"
+)
+
+type memoryRepository struct {
+	store *memdb.Store
+}
+
+// NewMemory returns a Repository keeping jobs in store, with the claim,
+// lease and unique-key rules of the SQL queries. The hermetic app runs its
+// queue workers on it; tests use it to drive the runner directly.
+func NewMemory(store *memdb.Store) Repository {
+	return &memoryRepository{store: store}
+}
+
+type memoryListener struct{}
+
+// NewMemoryListener returns a Listener that never wakes, leaving the workers
+// to their poll interval.
+func NewMemoryListener() Listener {
+	return memoryListener{}
+}
+
+func (memoryListener) Wake(string) <-chan struct{} {
+	return nil
+}
+
+func (r *memoryRepository) Enqueue(ctx context.Context, job *entity.Job, delay time.Duration) (*entity.Job, error) {
+	var stored entity.Job
+	err := r.store.Write(func(t *memdb.Tables) error {
+		j := t.EnqueueJob(&memdb.Job{
+			Queue:       job.Queue,
+			Kind:        job.Kind,
+			Payload:     bytes.Clone(job.Payload),
+			Priority:    job.Priority,
+			UniqueKey:   job.UniqueKey,
+			MaxAttempts: job.MaxAttempts,
+			ScheduledAt: t.Now.Add(delay),
+		})
+		stored = toJob(j)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func (r *memoryRepository) ClaimJobs(ctx context.Context, queue string, kinds []string, workerID string, limit int, lease time.Duration) ([]entity.Job, error) {
+	var jobs []entity.Job
+	err := r.store.Write(func(t *memdb.Tables) error {
+		var claimable []*memdb.Job
+		for _, j := range t.Jobs {
+			if j.Queue == queue && slices.Contains(kinds, j.Kind) &&
+				(j.Status == "pending" || j.Status == "failed") && !j.ScheduledAt.After(t.Now) {
+				claimable = append(claimable, j)
+			}
+		}
+
+		slices.SortFunc(claimable, func(a, b *memdb.Job) int {
+			if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+				return c
+			}
+			if c := a.ScheduledAt.Compare(b.ScheduledAt); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.ID, b.ID)
+		})
+
+		for _, j := range claimable[:min(limit, len(claimable))] {
+			j.Status = "processing"
+			j.LockedBy = sql.NullString{String: workerID, Valid: true}
+			j.LockedUntil = sql.NullTime{Time: t.Now.Add(lease), Valid: true}
+			j.UpdatedAt = t.Now
+			jobs = append(jobs, toJob(j))
+		}
+		return nil
+	})
+	return jobs, err
+}
+
+func (r *memoryRepository) MarkCompleted(ctx context.Context, jobID string, workerID string) error {
+	return r.updateLeased(jobID, workerID, func(t *memdb.Tables, j *memdb.Job) {
+		j.Status = "completed"
+		j.ProcessedAt = sql.NullTime{Time: t.Now, Valid: true}
+	})
+}
+
+func (r *memoryRepository) MarkFailed(ctx context.Context, jobID string, workerID string, errMsg string, nextSchedule time.Time) error {
+	return r.updateLeased(jobID, workerID, func(t *memdb.Tables, j *memdb.Job) {
+		j.Status = "failed"
+		j.Attempts++
+		j.LastError = sql.NullString{String: errMsg, Valid: true}
+		j.ScheduledAt = nextSchedule
+	})
+}
+
+func (r *memoryRepository) MarkDead(ctx context.Context, jobID string, workerID string, errMsg string) error {
+	return r.updateLeased(jobID, workerID, func(t *memdb.Tables, j *memdb.Job) {
+		j.Status = "dead"
+		j.Attempts++
+		j.LastError = sql.NullString{String: errMsg, Valid: true}
+	})
+}
+
+// updateLeased applies fn to a job workerID holds the lease on and releases
+// the lease, like the guarded Mark queries.
+func (r *memoryRepository) updateLeased(jobID string, workerID string, fn func(t *memdb.Tables, j *memdb.Job)) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		j, ok := t.Jobs[jobID]
+		if !ok || j.Status != "processing" || j.LockedBy.String != workerID {
+			return apperror.ErrConflict
+		}
+		fn(t, j)
+		j.LockedBy = sql.NullString{}
+		j.LockedUntil = sql.NullTime{}
+		j.UpdatedAt = t.Now
+		return nil
+	})
+}
+
+func (r *memoryRepository) ExtendJobLeases(ctx context.Context, workerID string, jobIDs []string, lease time.Duration) (int, error) {
+	var n int
+	err := r.store.Write(func(t *memdb.Tables) error {
+		for _, id := range jobIDs {
+			if j, ok := t.Jobs[id]; ok && j.Status == "processing" && j.LockedBy.String == workerID {
+				j.LockedUntil = sql.NullTime{Time: t.Now.Add(lease), Valid: true}
+				n++
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+func (r *memoryRepository) ReleaseExpiredJobs(ctx context.Context) (int, error) {
+	var n int
+	err := r.store.Write(func(t *memdb.Tables) error {
+		for _, j := range t.Jobs {
+			if j.Status != "processing" || !j.LockedUntil.Valid || !j.LockedUntil.Time.Before(t.Now) {
+				continue
+			}
+			holder := j.LockedBy.String
+			if holder == "" {
+				holder = "unknown worker"
+			}
+			j.Attempts++
+			j.Status = "pending"
+			if j.Attempts >= j.MaxAttempts {
+				j.Status = "dead"
+			}
+			j.LastError = sql.NullString{String: "lease held by " + holder + " expired", Valid: true}
+			j.ScheduledAt = t.Now
+			j.LockedBy = sql.NullString{}
+			j.LockedUntil = sql.NullTime{}
+			j.UpdatedAt = t.Now
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+func (r *memoryRepository) ListJobs(ctx context.Context, filter entity.JobFilter, limit int, cursorTime *time.Time, cursorID string) ([]entity.Job, error) {
+	jobs := []entity.Job{}
+	err := r.store.Read(func(t *memdb.Tables) error {
+		var rows []*memdb.Job
+		for _, j := range t.Jobs {
+			if (filter.Queue != "" && j.Queue != filter.Queue) ||
+				(filter.Kind != "" && j.Kind != filter.Kind) ||
+				(filter.Status != "" && j.Status != filter.Status) ||
+				(filter.Payload != nil && !containsJSON(j.Payload, filter.Payload)) {
+				continue
+			}
+			if cursorTime != nil && compareJob(j, *cursorTime, cursorID) >= 0 {
+				continue
+			}
+			rows = append(rows, j)
+		}
+		slices.SortFunc(rows, func(a, b *memdb.Job) int { return -compareJob(a, b.UpdatedAt, b.ID) })
+
+		for _, j := range rows[:min(limit, len(rows))] {
+			jobs = append(jobs, toJob(j))
+		}
+		return nil
+	})
+	return jobs, err
+}
+
+// compareJob orders j against the (updatedAt, id) position of another job.
+func compareJob(j *memdb.Job, updatedAt time.Time, id string) int {
+	if c := j.UpdatedAt.Compare(updatedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(j.ID, id)
+}
+
+// containsJSON reports whether the payload object holds every top-level
+// field of filter with an equal value, which covers how JSONB @> is used
+// here.
+func containsJSON(payload, filter []byte) bool {
+	var p, f map[string]any
+	if json.Unmarshal(payload, &p) != nil || json.Unmarshal(filter, &f) != nil {
+		return false
+	}
+	for k, v := range f {
+		if !reflect.DeepEqual(p[k], v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *memoryRepository) GetJob(ctx context.Context, id string) (*entity.Job, error) {
+	var job entity.Job
+	err := r.store.Read(func(t *memdb.Tables) error {
+		j, ok := t.Jobs[id]
+		if !ok {
+			return apperror.ErrNotFound
+		}
+		job = toJob(j)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *memoryRepository) RetryDeadJobs(ctx context.Context, kind string, ids []string) ([]string, error) {
+	var changed []string
+	err := r.store.Write(func(t *memdb.Tables) error {
+		// Newest dead job per unique key, as DISTINCT ON picks.
+		newest := make(map[[2]string]*memdb.Job)
+		for _, j := range deadJobs(t, kind, ids) {
+			key := [2]string{j.Kind, j.ID}
+			if j.UniqueKey.Valid {
+				if t.ActiveJob(j.Kind, j.UniqueKey.String) != nil {
+					continue
+				}
+				key[1] = j.UniqueKey.String
+			}
+			if cur, ok := newest[key]; !ok || j.UpdatedAt.After(cur.UpdatedAt) {
+				newest[key] = j
+			}
+		}
+		for _, j := range newest {
+			j.Status = "pending"
+			j.Attempts = 0
+			j.ScheduledAt = t.Now
+			j.UpdatedAt = t.Now
+			changed = append(changed, j.ID)
+		}
+		return nil
+	})
+	return changed, err
+}
+
+func (r *memoryRepository) DiscardDeadJobs(ctx context.Context, kind string, ids []string) ([]string, error) {
+	var changed []string
+	err := r.store.Write(func(t *memdb.Tables) error {
+		for _, j := range deadJobs(t, kind, ids) {
+			delete(t.Jobs, j.ID)
+			changed = append(changed, j.ID)
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// deadJobs returns the dead jobs of kind among ids, or every dead job of
+// kind for nil ids. An empty kind matches any.
+func deadJobs(t *memdb.Tables, kind string, ids []string) []*memdb.Job {
+	var jobs []*memdb.Job
+	for _, j := range t.Jobs {
+		if j.Status == "dead" && (kind == "" || j.Kind == kind) && (ids == nil || slices.Contains(ids, j.ID)) {
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs
+}
+
+func (r *memoryRepository) JobStats(ctx context.Context, kind string, window time.Duration) (*entity.JobStats, error) {
+	var stats entity.JobStats
+	// Write only for t.Now; nothing is modified.
+	err := r.store.Write(func(t *memdb.Tables) error {
+		stats.CheckedAt = t.Now
+		since := t.Now.Add(-window)
+		for _, j := range t.Jobs {
+			if kind != "" && j.Kind != kind {
+				continue
+			}
+			switch j.Status {
+			case "pending":
+				stats.Pending++
+			case "processing":
+				stats.Processing++
+			case "failed":
+				stats.Failed++
+			case "dead":
+				stats.Dead++
+			case "completed":
+				stats.Completed++
+			}
+
+			waiting := j.Status == "pending" || j.Status == "failed"
+			if waiting && !j.ScheduledAt.After(t.Now) {
+				stats.Due++
+			}
+			if waiting && (!stats.OldestPendingAt.Valid || j.CreatedAt.Before(stats.OldestPendingAt.Time)) {
+				stats.OldestPendingAt = sql.NullTime{Time: j.CreatedAt, Valid: true}
+			}
+			if !j.UpdatedAt.Before(since) {
+				switch j.Status {
+				case "completed":
+					stats.WindowCompleted++
+				case "failed", "dead":
+					stats.WindowFailed++
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func toJob(j *memdb.Job) entity.Job {
+	return entity.Job{
+		ID:          j.ID,
+		Queue:       j.Queue,
+		Kind:        j.Kind,
+		Payload:     bytes.Clone(j.Payload),
+		Priority:    j.Priority,
+		UniqueKey:   j.UniqueKey,
+		Status:      j.Status,
+		Attempts:    j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+		LastError:   j.LastError,
+		ScheduledAt: j.ScheduledAt,
+		ProcessedAt: j.ProcessedAt,
+		LockedUntil: j.LockedUntil,
+		LockedBy:    j.LockedBy,
+		CreatedAt:   j.CreatedAt,
+		UpdatedAt:   j.UpdatedAt,
+	}
+}
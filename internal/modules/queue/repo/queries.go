@@ -0,0 +1,174 @@
+package repo
+
+const jobColumns = `
+	id, queue, kind, payload, priority, unique_key, status, attempts,
+	max_attempts, last_error, scheduled_at, processed_at, locked_until,
+	locked_by, created_at, updated_at
+`
+
+// queryEnqueueJob reschedules the active job holding the unique key, if any,
+// instead of inserting a second one.
+const queryEnqueueJob = `
+	INSERT INTO jobs (queue, kind, payload, priority, unique_key, max_attempts, scheduled_at)
+	VALUES ($1, $2, $3, $4, $5, $6, NOW() + make_interval(secs => $7))
+	ON CONFLICT (kind, unique_key) WHERE unique_key IS NOT NULL AND status IN ('pending', 'processing', 'failed')
+	DO UPDATE SET scheduled_at = EXCLUDED.scheduled_at, updated_at = NOW()
+	RETURNING ` + jobColumns
+
+const queryClaimJobs = `
+	WITH claimable AS (
+		SELECT id
+		FROM jobs
+		WHERE queue = $1
+		  AND kind = ANY($2::TEXT[])
+		  AND status IN ('pending', 'failed')
+		  AND scheduled_at <= NOW()
+		ORDER BY priority DESC, scheduled_at ASC
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	)
+	UPDATE jobs j
+	SET status = 'processing',
+	    locked_by = $4,
+	    locked_until = NOW() + make_interval(secs => $5),
+	    updated_at = NOW()
+	FROM claimable c
+	WHERE j.id = c.id
+	RETURNING j.id, j.queue, j.kind, j.payload, j.priority, j.unique_key, j.status,
+	          j.attempts, j.max_attempts, j.last_error, j.scheduled_at, j.processed_at,
+	          j.locked_until, j.locked_by, j.created_at, j.updated_at
+`
+
+// The Mark queries only apply while $2 still holds the job's lease.
+
+const queryMarkCompleted = `
+	UPDATE jobs
+	SET status = 'completed',
+	    processed_at = NOW(),
+	    locked_by = NULL,
+	    locked_until = NULL,
+	    updated_at = NOW()
+	WHERE id = $1 AND status = 'processing' AND locked_by = $2
+`
+
+const queryMarkFailed = `
+	UPDATE jobs
+	SET status = 'failed',
+	    attempts = attempts + 1,
+	    last_error = $3,
+	    scheduled_at = $4,
+	    locked_by = NULL,
+	    locked_until = NULL,
+	    updated_at = NOW()
+	WHERE id = $1 AND status = 'processing' AND locked_by = $2
+`
+
+const queryMarkDead = `
+	UPDATE jobs
+	SET status = 'dead',
+	    attempts = attempts + 1,
+	    last_error = $3,
+	    locked_by = NULL,
+	    locked_until = NULL,
+	    updated_at = NOW()
+	WHERE id = $1 AND status = 'processing' AND locked_by = $2
+`
+
+const queryExtendJobLeases = `
+	UPDATE jobs
+	SET locked_until = NOW() + make_interval(secs => $3)
+	WHERE id = ANY($1::UUID[]) AND status = 'processing' AND locked_by = $2
+`
+
+// queryReleaseExpiredJobs counts an expired lease as a failed attempt, so a
+// job that keeps crashing its worker ends up dead rather than looping.
+const queryReleaseExpiredJobs = `
+	UPDATE jobs
+	SET status = CASE WHEN attempts + 1 >= max_attempts THEN 'dead' ELSE 'pending' END,
+	    attempts = attempts + 1,
+	    last_error = 'lease held by ' || COALESCE(locked_by, 'unknown worker') || ' expired',
+	    scheduled_at = NOW(),
+	    locked_by = NULL,
+	    locked_until = NULL,
+	    updated_at = NOW()
+	WHERE status = 'processing' AND locked_until < NOW()
+`
+
+// queryListJobs pages newest first by (updated_at, id). NULL filters and a
+// NULL cursor match every job.
+const queryListJobs = `
+	SELECT ` + jobColumns + `
+	FROM jobs
+	WHERE ($2::TEXT IS NULL OR queue = $2)
+	  AND ($3::TEXT IS NULL OR kind = $3)
+	  AND ($4::TEXT IS NULL OR status = $4)
+	  AND ($5::JSONB IS NULL OR payload @> $5)
+	  AND ($6::TIMESTAMPTZ IS NULL OR (updated_at, id) < ($6, $7::UUID))
+	ORDER BY updated_at DESC, id DESC
+	LIMIT $1
+`
+
+const queryGetJob = `
+	SELECT ` + jobColumns + `
+	FROM jobs
+	WHERE id = $1
+`
+
+// queryRetryDeadJobs requeues dead jobs of kind $1 with a fresh attempt
+// budget: the given ones, or every one when $2 is NULL. Only the newest dead
+// job per unique key is requeued, and none while an active job holds the
+// key, as the active unique-key index allows one.
+const queryRetryDeadJobs = `
+	WITH candidates AS (
+		SELECT DISTINCT ON (d.kind, COALESCE(d.unique_key, d.id::TEXT)) d.id
+		FROM jobs d
+		WHERE d.status = 'dead'
+		  AND ($1::TEXT IS NULL OR d.kind = $1)
+		  AND ($2::UUID[] IS NULL OR d.id = ANY($2))
+		  AND (d.unique_key IS NULL OR NOT EXISTS (
+			SELECT 1
+			FROM jobs a
+			WHERE a.kind = d.kind
+			  AND a.unique_key = d.unique_key
+			  AND a.status IN ('pending', 'processing', 'failed')
+		  ))
+		ORDER BY d.kind, COALESCE(d.unique_key, d.id::TEXT), d.updated_at DESC
+	)
+	UPDATE jobs j
+	SET status = 'pending',
+	    attempts = 0,
+	    scheduled_at = NOW(),
+	    updated_at = NOW()
+	FROM candidates c
+	WHERE j.id = c.id
+	RETURNING j.id
+`
+
+// queryDiscardDeadJobs deletes the given dead jobs of kind $1, or every one
+// when $2 is NULL.
+const queryDiscardDeadJobs = `
+	DELETE FROM jobs
+	WHERE status = 'dead'
+	  AND ($1::TEXT IS NULL OR kind = $1)
+	  AND ($2::UUID[] IS NULL OR id = ANY($2))
+	RETURNING id
+`
+
+const queryJobStats = `
+	SELECT COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+	       COUNT(*) FILTER (WHERE status = 'processing') AS processing,
+	       COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+	       COUNT(*) FILTER (WHERE status = 'dead') AS dead,
+	       COUNT(*) FILTER (WHERE status = 'completed') AS completed,
+	       COUNT(*) FILTER (WHERE status IN ('pending', 'failed') AND scheduled_at <= NOW()) AS due,
+	       MIN(created_at) FILTER (WHERE status IN ('pending', 'failed')) AS oldest_pending_at,
+	       COUNT(*) FILTER (
+	           WHERE status = 'completed' AND updated_at >= NOW() - make_interval(secs => $2)
+	       ) AS window_completed,
+	       COUNT(*) FILTER (
+	           WHERE status IN ('failed', 'dead') AND updated_at >= NOW() - make_interval(secs => $2)
+	       ) AS window_failed,
+	       NOW() AS checked_at
+	FROM jobs
+	WHERE ($1::TEXT IS NULL OR kind = $1)
+`
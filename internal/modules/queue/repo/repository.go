@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"cms-api/internal/modules/queue/entity"
+	"cms-api/internal/pkg/apperror"
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Enqueue(ctx context.Context, job *entity.Job, delay time.Duration) (*entity.Job, error) {
+	var stored entity.Job
+	// JSONB is passed as text; lib/pq would send []byte as bytea.
+	err := r.db.GetContext(ctx, &stored, queryEnqueueJob,
+		job.Queue, job.Kind, string(job.Payload), job.Priority, job.UniqueKey, job.MaxAttempts, delay.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func (r *repository) ClaimJobs(ctx context.Context, queue string, kinds []string, workerID string, limit int, lease time.Duration) ([]entity.Job, error) {
+	var jobs []entity.Job
+	err := r.db.SelectContext(ctx, &jobs, queryClaimJobs, queue, pq.StringArray(kinds), limit, workerID, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *repository) MarkCompleted(ctx context.Context, jobID string, workerID string) error {
+	return r.execLeased(ctx, queryMarkCompleted, jobID, workerID)
+}
+
+func (r *repository) MarkFailed(ctx context.Context, jobID string, workerID string, errMsg string, nextSchedule time.Time) error {
+	return r.execLeased(ctx, queryMarkFailed, jobID, workerID, errMsg, nextSchedule)
+}
+
+func (r *repository) MarkDead(ctx context.Context, jobID string, workerID string, errMsg string) error {
+	return r.execLeased(ctx, queryMarkDead, jobID, workerID, errMsg)
+}
+
+// execLeased runs a Mark query and reports apperror.ErrConflict when the
+// job's lease is no longer held.
+func (r *repository) execLeased(ctx context.Context, query string, args ...any) error {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apperror.ErrConflict
+	}
+	return nil
+}
+
+func (r *repository) ExtendJobLeases(ctx context.Context, workerID string, jobIDs []string, lease time.Duration) (int, error) {
+	res, err := r.db.ExecContext(ctx, queryExtendJobLeases, pq.StringArray(jobIDs), workerID, lease.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *repository) ReleaseExpiredJobs(ctx context.Context) (int, error) {
+	res, err := r.db.ExecContext(ctx, queryReleaseExpiredJobs)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *repository) ListJobs(ctx context.Context, filter entity.JobFilter, limit int, cursorTime *time.Time, cursorID string) ([]entity.Job, error) {
+	var after sql.NullTime
+	var afterID sql.NullString
+	if cursorTime != nil {
+		after = sql.NullTime{Time: *cursorTime, Valid: true}
+		afterID = sql.NullString{String: cursorID, Valid: true}
+	}
+
+	jobs := []entity.Job{}
+	err := r.db.SelectContext(ctx, &jobs, queryListJobs, limit,
+		nullString(filter.Queue), nullString(filter.Kind), nullString(filter.Status), nullString(string(filter.Payload)),
+		after, afterID)
+	return jobs, err
+}
+
+func (r *repository) GetJob(ctx context.Context, id string) (*entity.Job, error) {
+	var job entity.Job
+	if err := r.db.GetContext(ctx, &job, queryGetJob, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *repository) RetryDeadJobs(ctx context.Context, kind string, ids []string) ([]string, error) {
+	var changed []string
+	err := r.db.SelectContext(ctx, &changed, queryRetryDeadJobs, nullString(kind), uuidArray(ids))
+	return changed, err
+}
+
+func (r *repository) DiscardDeadJobs(ctx context.Context, kind string, ids []string) ([]string, error) {
+	var changed []string
+	err := r.db.SelectContext(ctx, &changed, queryDiscardDeadJobs, nullString(kind), uuidArray(ids))
+	return changed, err
+}
+
+func (r *repository) JobStats(ctx context.Context, kind string, window time.Duration) (*entity.JobStats, error) {
+	var stats entity.JobStats
+	if err := r.db.GetContext(ctx, &stats, queryJobStats, nullString(kind), window.Seconds()); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// uuidArray passes nil as NULL, which the job queries read as "every job".
+func uuidArray(ids []string) any {
+	if ids == nil {
+		return nil
+	}
+	return pq.StringArray(ids)
+}
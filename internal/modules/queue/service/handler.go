@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"go.uber.org/fx"
+
+	"cms-api/internal/modules/queue/entity"
+)
+
+// Handler runs the jobs of one kind. A returned error fails the job, which
+// is retried with backoff until it runs out of attempts.
+type Handler interface {
+	Kind() string
+	Handle(ctx context.Context, job entity.Job) error
+}
+
+// BatchHandler is a Handler that runs many jobs of its kind in one call;
+// the worker claims up to BatchSize of them together. HandleBatch returns
+// the error of each job that failed, by job ID.
+type BatchHandler interface {
+	Handler
+	BatchSize() int
+	HandleBatch(ctx context.Context, jobs []entity.Job) map[string]error
+}
+
+// Gate is implemented by handlers that depend on something which may be
+// unavailable. While Ready returns an error, the handler's jobs stay queued
+// without spending their attempts.
+type Gate interface {
+	Ready(ctx context.Context) error
+}
+
+// Registry maps job kinds to their handlers.
+type Registry struct {
+	handlers map[string]Handler
+}
+
+type RegistryParams struct {
+	fx.In
+
+	Handlers []Handler `group:"job_handlers"`
+}
+
+func NewRegistry(params RegistryParams) *Registry {
+	handlers := make(map[string]Handler, len(params.Handlers))
+	for _, h := range params.Handlers {
+		handlers[h.Kind()] = h
+	}
+	return &Registry{handlers: handlers}
+}
+
+func (r *Registry) Get(kind string) Handler {
+	if r == nil {
+		return nil
+	}
+	return r.handlers[kind]
+}
+
+// Kinds returns the registered kinds in sorted order.
+func (r *Registry) Kinds() []string {
+	if r == nil {
+		return nil
+	}
+	kinds := make([]string, 0, len(r.handlers))
+	for kind := range r.handlers {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
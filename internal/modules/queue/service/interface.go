@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"cms-api/internal/modules/queue/entity"
+)
+
+// Queue stores and manages jobs without running them. Modules that provide
+// a Handler enqueue through Queue rather than Service, which depends on
+// every handler.
+type Queue interface {
+	// Enqueue stores a job of kind carrying payload, marshalled as JSON.
+	Enqueue(ctx context.Context, kind string, payload any, opts EnqueueOptions) (*entity.Job, error)
+
+	ListJobs(ctx context.Context, filter entity.JobFilter, limit int, cursorTime *time.Time, cursorID string) ([]entity.Job, error)
+	GetJob(ctx context.Context, id string) (*entity.Job, error)
+	// RetryDeadJobs requeues dead jobs of kind with a fresh attempt budget
+	// and DiscardDeadJobs deletes them: those among ids, or all for nil ids.
+	RetryDeadJobs(ctx context.Context, kind string, ids []string) ([]string, error)
+	DiscardDeadJobs(ctx context.Context, kind string, ids []string) ([]string, error)
+	JobStats(ctx context.Context, kind string, window time.Duration) (*entity.JobStats, error)
+}
+
+// Service is the durable job queue. Other modules enqueue background work
+// through it and provide a Handler to the "job_handlers" group for each kind
+// of job they enqueue.
+type Service interface {
+	Queue
+
+	// Start claims and runs the jobs of every configured queue until ctx is
+	// done. Batches still running when it returns keep running; call Drain
+	// to wait for them.
+	Start(ctx context.Context)
+	// Drain waits for the batches left running by Start until ctx is done,
+	// then cancels them and returns ctx.Err().
+	Drain(ctx context.Context) error
+}
+
+type EnqueueOptions struct {
+	// Queue is the queue the job runs on, entity.DefaultQueue if empty. It
+	// must be one of the worker's configured queues.
+	Queue string
+	// Priority orders due jobs on a queue, highest first.
+	Priority int
+	// UniqueKey, if set, allows one active job of the kind with this key.
+	// Enqueuing another reschedules the active job instead.
+	UniqueKey string
+	// Delay holds the job back from running for at least this long.
+	Delay time.Duration
+	// MaxAttempts defaults to the worker's MaxAttempts.
+	MaxAttempts int
+}
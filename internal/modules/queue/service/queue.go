@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cms-api/internal/config"
+	"cms-api/internal/modules/queue/entity"
+	"cms-api/internal/modules/queue/repo"
+)
+
+type jobQueue struct {
+	repo repo.Repository
+	cfg  config.WorkerConfig
+}
+
+func NewQueue(repo repo.Repository, cfg *config.Config) Queue {
+	return &jobQueue{repo: repo, cfg: cfg.Worker}
+}
+
+func (q *jobQueue) Enqueue(ctx context.Context, kind string, payload any, opts EnqueueOptions) (*entity.Job, error) {
+	if kind == "" {
+		return nil, fmt.Errorf("enqueue job: kind is required")
+	}
+	queue := opts.Queue
+	if queue == "" {
+		queue = entity.DefaultQueue
+	}
+	if _, ok := q.cfg.Queues[queue]; !ok {
+		return nil, fmt.Errorf("enqueue %s job: queue %q is not configured", kind, queue)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s job payload: %w", kind, err)
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = q.cfg.MaxAttempts
+	}
+
+	job, err := q.repo.Enqueue(ctx, &entity.Job{
+		Queue:       queue,
+		Kind:        kind,
+		Payload:     data,
+		Priority:    opts.Priority,
+		UniqueKey:   sql.NullString{String: opts.UniqueKey, Valid: opts.UniqueKey != ""},
+		MaxAttempts: maxAttempts,
+	}, max(opts.Delay, 0))
+	if err != nil {
+		return nil, fmt.Errorf("enqueue %s job: %w", kind, err)
+	}
+	return job, nil
+}
+
+func (q *jobQueue) ListJobs(ctx context.Context, filter entity.JobFilter, limit int, cursorTime *time.Time, cursorID string) ([]entity.Job, error) {
+	return q.repo.ListJobs(ctx, filter, limit, cursorTime, cursorID)
+}
+
+func (q *jobQueue) GetJob(ctx context.Context, id string) (*entity.Job, error) {
+	return q.repo.GetJob(ctx, id)
+}
+
+func (q *jobQueue) RetryDeadJobs(ctx context.Context, kind string, ids []string) ([]string, error) {
+	return q.repo.RetryDeadJobs(ctx, kind, ids)
+}
+
+func (q *jobQueue) DiscardDeadJobs(ctx context.Context, kind string, ids []string) ([]string, error) {
+	return q.repo.DiscardDeadJobs(ctx, kind, ids)
+}
+
+func (q *jobQueue) JobStats(ctx context.Context, kind string, window time.Duration) (*entity.JobStats, error) {
+	return q.repo.JobStats(ctx, kind, window)
+}
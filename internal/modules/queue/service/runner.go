@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"cms-api/internal/modules/queue/entity"
+	"cms-api/internal/pkg/apperror"
+	"cms-api/internal/pkg/goroutine"
+)
+
+func (s *service) Start(ctx context.Context) {
+	s.log.Info("Queue workers started",
+		zap.String("worker_id", s.id),
+		zap.Any("queues", s.cfg.Queues),
+		zap.Int("concurrency", cap(s.slots)),
+		zap.Strings("kinds", s.registry.Kinds()),
+		zap.Duration("poll_interval", s.cfg.PollInterval),
+		zap.Duration("job_timeout", s.cfg.JobTimeout),
+		zap.Duration("lease_duration", s.cfg.LeaseDuration),
+	)
+
+	var wg sync.WaitGroup
+	for queue := range s.queueSlots {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx, queue)
+		}()
+	}
+	wg.Wait()
+
+	s.log.Info("Queue workers stopped claiming jobs")
+}
+
+// Drain waits for the batches still running after Start returned. Once ctx
+// is done it cancels their contexts instead and returns ctx.Err(); the jobs
+// fail and are retried, by this worker or, after their leases expire, by
+// another.
+func (s *service) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.log.Info("Queue workers drained")
+		return nil
+	case <-ctx.Done():
+		s.abort()
+		s.log.Warn("Queue drain timed out, canceling running jobs", zap.Error(ctx.Err()))
+		return ctx.Err()
+	}
+}
+
+// work dispatches batches from queue until ctx is done. It wakes on every
+// poll and on every notification, then dispatches the jobs that are due as
+// fast as slots free up.
+func (s *service) work(ctx context.Context, queue string) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	wake := s.listener.Wake(queue)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+		for ctx.Err() == nil && s.dispatch(ctx, queue) {
+		}
+	}
+}
+
+// dispatch waits for a slot of queue and one of the pool, then claims a
+// batch and runs it on its own goroutine. It reports whether it claimed
+// anything.
+func (s *service) dispatch(ctx context.Context, queue string) bool {
+	queueSlots := s.queueSlots[queue]
+	if !acquire(ctx, queueSlots) {
+		return false
+	}
+	if !acquire(ctx, s.slots) {
+		<-queueSlots
+		return false
+	}
+	release := func() {
+		<-s.slots
+		<-queueSlots
+	}
+
+	jobs := s.claim(ctx, queue)
+	if len(jobs) == 0 {
+		release()
+		return false
+	}
+
+	s.inflight.Add(1)
+	go func() {
+		defer s.inflight.Done()
+		defer release()
+		s.run(queue, jobs)
+	}()
+	return true
+}
+
+// acquire takes a slot, returning false if ctx is done first.
+func acquire(ctx context.Context, slots chan struct{}) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// claim claims the next batch of due jobs on queue: a single job, or for a
+// BatchHandler up to its BatchSize jobs of the same kind.
+func (s *service) claim(ctx context.Context, queue string) []entity.Job {
+	s.releaseExpiredJobs(ctx)
+
+	kinds := s.readyKinds(ctx)
+	if len(kinds) == 0 {
+		return nil
+	}
+	jobs, err := s.repo.ClaimJobs(ctx, queue, kinds, s.id, 1, s.cfg.LeaseDuration)
+	if err != nil {
+		s.log.Error("Failed to claim jobs", zap.String("queue", queue), zap.Error(err))
+		return nil
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	kind := jobs[0].Kind
+	if batch, ok := s.registry.Get(kind).(BatchHandler); ok && batch.BatchSize() > 1 {
+		more, err := s.repo.ClaimJobs(ctx, queue, []string{kind}, s.id, batch.BatchSize()-1, s.cfg.LeaseDuration)
+		if err != nil {
+			s.log.Error("Failed to claim jobs", zap.String("queue", queue), zap.String("kind", kind), zap.Error(err))
+		}
+		jobs = append(jobs, more...)
+	}
+	return jobs
+}
+
+// run handles a claimed batch and records each job's outcome. It runs under
+// the service's own context rather than Start's, so shutdown lets it finish
+// until Drain gives up on it.
+func (s *service) run(queue string, jobs []entity.Job) {
+	jobIDs := make([]string, len(jobs))
+	for i, job := range jobs {
+		jobIDs[i] = job.ID
+	}
+	stopHeartbeat := s.heartbeat(s.runCtx, jobIDs)
+	failures := s.handle(jobs)
+	stopHeartbeat()
+
+	// Marking outlives an aborted drain so the outcome of work already done
+	// is not lost.
+	markCtx := context.WithoutCancel(s.runCtx)
+	var completed int
+	for _, job := range jobs {
+		if err, ok := failures[job.ID]; ok {
+			s.handleFailure(markCtx, job, err)
+			continue
+		}
+		s.markCompleted(markCtx, job.ID)
+		completed++
+	}
+	s.log.Info("Processed jobs",
+		zap.String("queue", queue),
+		zap.String("kind", jobs[0].Kind),
+		zap.Int("completed", completed),
+		zap.Int("failed", len(jobs)-completed),
+	)
+}
+
+// handle calls the handler of jobs' kind within JobTimeout and returns the
+// error of each job that failed. A panicking handler fails the whole batch.
+func (s *service) handle(jobs []entity.Job) map[string]error {
+	ctx := s.runCtx
+	if s.cfg.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.JobTimeout)
+		defer cancel()
+	}
+
+	handler := s.registry.Get(jobs[0].Kind)
+	var failures map[string]error
+	err := goroutine.SafeCall(s.log, func() error {
+		if batch, ok := handler.(BatchHandler); ok {
+			failures = batch.HandleBatch(ctx, jobs)
+			return nil
+		}
+		return handler.Handle(ctx, jobs[0])
+	})
+	if err != nil {
+		failures = make(map[string]error, len(jobs))
+		for _, job := range jobs {
+			failures[job.ID] = err
+		}
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		for id, err := range failures {
+			if errors.Is(err, context.DeadlineExceeded) {
+				failures[id] = fmt.Errorf("job timed out after %s: %w", s.cfg.JobTimeout, err)
+			}
+		}
+	}
+	return failures
+}
+
+// readyKinds returns the registered kinds whose handler can run now.
+func (s *service) readyKinds(ctx context.Context) []string {
+	var kinds []string
+	for _, kind := range s.registry.Kinds() {
+		if gate, ok := s.registry.Get(kind).(Gate); ok {
+			if err := gate.Ready(ctx); err != nil {
+				s.log.Warn("Job handler unavailable, holding its jobs", zap.String("kind", kind), zap.Error(err))
+				continue
+			}
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+// releaseExpiredJobs is the reaper: it returns jobs whose worker stopped
+// renewing their lease, most likely because it crashed, to the queue.
+func (s *service) releaseExpiredJobs(ctx context.Context) {
+	n, err := s.repo.ReleaseExpiredJobs(ctx)
+	if err != nil {
+		s.log.Error("Failed to release expired job leases", zap.Error(err))
+		return
+	}
+	if n > 0 {
+		s.log.Warn("Released jobs with expired leases", zap.Int("jobs", n))
+	}
+}
+
+// heartbeat renews the leases on jobIDs every third of the lease duration
+// until the returned stop is called, so a batch slower than the lease keeps
+// its jobs.
+func (s *service) heartbeat(ctx context.Context, jobIDs []string) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(s.cfg.LeaseDuration / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				held, err := s.repo.ExtendJobLeases(ctx, s.id, jobIDs, s.cfg.LeaseDuration)
+				if err != nil {
+					if ctx.Err() == nil {
+						s.log.Error("Failed to extend job leases", zap.Error(err))
+					}
+					continue
+				}
+				if held < len(jobIDs) {
+					s.log.Warn("Lost leases on claimed jobs", zap.Int("claimed", len(jobIDs)), zap.Int("held", held))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (s *service) markCompleted(ctx context.Context, jobID string) {
+	if err := s.repo.MarkCompleted(ctx, jobID, s.id); err != nil {
+		s.logMarkError("completed", jobID, err)
+	}
+}
+
+// logMarkError reports a failed status update. A lost lease is expected
+// after a stall: the job was already returned to the queue and another
+// worker, or a later poll, redoes it.
+func (s *service) logMarkError(status string, jobID string, err error) {
+	if errors.Is(err, apperror.ErrConflict) {
+		s.log.Warn("Job lease lost, leaving job to its new owner", zap.String("job_id", jobID), zap.String("status", status))
+		return
+	}
+	s.log.Error("Failed to mark job "+status, zap.String("job_id", jobID), zap.Error(err))
+}
+
+func (s *service) handleFailure(ctx context.Context, job entity.Job, err error) {
+	nextAttempt := job.Attempts + 1
+
+	if nextAttempt >= job.MaxAttempts {
+		if markErr := s.repo.MarkDead(ctx, job.ID, s.id, err.Error()); markErr != nil {
+			s.logMarkError("dead", job.ID, markErr)
+			return
+		}
+		s.log.Warn("Job moved to dead letter", zap.String("job_id", job.ID), zap.String("kind", job.Kind), zap.Int("attempts", nextAttempt), zap.Error(err))
+		return
+	}
+
+	backoff := time.Duration(math.Pow(2, float64(nextAttempt))) * 10 * time.Second
+	nextSchedule := time.Now().Add(backoff)
+
+	if markErr := s.repo.MarkFailed(ctx, job.ID, s.id, err.Error(), nextSchedule); markErr != nil {
+		s.logMarkError("failed", job.ID, markErr)
+		return
+	}
+	s.log.Warn("Job failed, scheduling retry",
+		zap.String("job_id", job.ID),
+		zap.String("kind", job.Kind),
+		zap.Int("attempt", nextAttempt),
+		zap.Duration("backoff", backoff),
+		zap.Error(err),
+	)
+}
@@ -0,0 +1,546 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"cms-api/internal/config"
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/modules/queue/entity"
+	"cms-api/internal/modules/queue/repo"
+	"cms-api/internal/pkg/apperror"
+)
+
+// recordingHandler runs jobs of its kind, failing those whose payload is in
+// fail and panicking on those in panics. Each call takes delay, or fails
+// every job when its context ends first.
+type recordingHandler struct {
+	kind   string
+	fail   map[string]bool
+	panics map[string]bool
+	delay  time.Duration
+
+	mu     sync.Mutex
+	calls  [][]string
+	active int
+	peak   int
+}
+
+func (h *recordingHandler) Kind() string { return h.kind }
+
+func (h *recordingHandler) Handle(ctx context.Context, job entity.Job) error {
+	return h.run(ctx, []entity.Job{job})[job.ID]
+}
+
+func (h *recordingHandler) run(ctx context.Context, jobs []entity.Job) map[string]error {
+	h.mu.Lock()
+	h.active++
+	h.peak = max(h.peak, h.active)
+	h.mu.Unlock()
+
+	var ctxErr error
+	select {
+	case <-time.After(h.delay):
+	case <-ctx.Done():
+		ctxErr = ctx.Err()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.active--
+	var call []string
+	failures := map[string]error{}
+	for _, job := range jobs {
+		call = append(call, string(job.Payload))
+		if h.panics[string(job.Payload)] {
+			panic("handler bug")
+		}
+		if ctxErr != nil {
+			failures[job.ID] = ctxErr
+		} else if h.fail[string(job.Payload)] {
+			failures[job.ID] = errors.New("boom")
+		}
+	}
+	h.calls = append(h.calls, call)
+	return failures
+}
+
+// running returns the calls in progress and the most ever at once.
+func (h *recordingHandler) running() (active, peak int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.active, h.peak
+}
+
+// handled returns the payloads the handler ran, in order.
+func (h *recordingHandler) handled() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Concat(h.calls...)
+}
+
+type batchHandler struct {
+	*recordingHandler
+	size int
+}
+
+func (h batchHandler) BatchSize() int { return h.size }
+
+func (h batchHandler) HandleBatch(ctx context.Context, jobs []entity.Job) map[string]error {
+	return h.run(ctx, jobs)
+}
+
+type gatedHandler struct {
+	*recordingHandler
+	err error
+}
+
+func (h gatedHandler) Ready(context.Context) error { return h.err }
+
+func newTestService(t *testing.T, handlers ...Handler) (*service, *memdb.Store) {
+	t.Helper()
+
+	store := memdb.New()
+	cfg := &config.Config{Worker: config.WorkerConfig{
+		Queues:        map[string]int{"default": 1, "search": 1},
+		Concurrency:   2,
+		PollInterval:  time.Hour,
+		JobTimeout:    time.Minute,
+		MaxAttempts:   3,
+		LeaseDuration: time.Minute,
+	}}
+	r := repo.NewMemory(store)
+	registry := NewRegistry(RegistryParams{Handlers: handlers})
+	svc := New(NewQueue(r, cfg), r, repo.NewMemoryListener(), registry, cfg, zap.NewNop()).(*service)
+	return svc, store
+}
+
+// claimAndRun claims and runs one batch on queue in the calling goroutine,
+// returning how many jobs it ran.
+func claimAndRun(ctx context.Context, svc *service, queue string) int {
+	jobs := svc.claim(ctx, queue)
+	if len(jobs) > 0 {
+		svc.run(queue, jobs)
+	}
+	return len(jobs)
+}
+
+func enqueue(t *testing.T, svc *service, kind string, payload string, opts EnqueueOptions) *entity.Job {
+	t.Helper()
+
+	job, err := svc.Enqueue(context.Background(), kind, payload, opts)
+	if err != nil {
+		t.Fatalf("enqueue %s: %v", payload, err)
+	}
+	return job
+}
+
+// jobStatuses returns the status of every job by payload.
+func jobStatuses(t *testing.T, store *memdb.Store) map[string]string {
+	t.Helper()
+
+	statuses := map[string]string{}
+	_ = store.Read(func(tb *memdb.Tables) error {
+		for _, j := range tb.Jobs {
+			statuses[string(j.Payload)] = j.Status
+		}
+		return nil
+	})
+	return statuses
+}
+
+func TestEnqueue_RejectsUnknownQueue(t *testing.T) {
+	svc, _ := newTestService(t)
+
+	if _, err := svc.Enqueue(context.Background(), "mail.send", "x", EnqueueOptions{Queue: "mail"}); err == nil {
+		t.Fatal("expected an error for a queue no worker runs")
+	}
+	if _, err := svc.Enqueue(context.Background(), "", "x", EnqueueOptions{}); err == nil {
+		t.Fatal("expected an error for a job without a kind")
+	}
+
+	job := enqueue(t, svc, "mail.send", "x", EnqueueOptions{})
+	if job.Queue != entity.DefaultQueue || job.MaxAttempts != 3 || string(job.Payload) != `"x"` {
+		t.Fatalf("expected the defaults applied, got %+v", job)
+	}
+}
+
+func TestEnqueue_UniqueKeyReschedulesActiveJob(t *testing.T) {
+	svc, store := newTestService(t)
+
+	first := enqueue(t, svc, "mail.send", "a", EnqueueOptions{UniqueKey: "user-1", Delay: time.Hour})
+	second := enqueue(t, svc, "mail.send", "b", EnqueueOptions{UniqueKey: "user-1"})
+	other := enqueue(t, svc, "mail.digest", "c", EnqueueOptions{UniqueKey: "user-1"})
+
+	if second.ID != first.ID || other.ID == first.ID {
+		t.Fatalf("expected one active job per kind and key, got %s, %s and %s", first.ID, second.ID, other.ID)
+	}
+	if !second.ScheduledAt.Before(first.ScheduledAt) {
+		t.Fatal("expected the active job rescheduled to the new run time")
+	}
+	if got := len(jobStatuses(t, store)); got != 2 {
+		t.Fatalf("expected 2 jobs stored, got %d", got)
+	}
+}
+
+func TestClaim_OrdersByPriorityAndSkipsDelayed(t *testing.T) {
+	h := &recordingHandler{kind: "mail.send"}
+	svc, _ := newTestService(t, h)
+	ctx := context.Background()
+
+	enqueue(t, svc, "mail.send", "low", EnqueueOptions{})
+	enqueue(t, svc, "mail.send", "later", EnqueueOptions{Priority: 10, Delay: time.Hour})
+	enqueue(t, svc, "mail.send", "high", EnqueueOptions{Priority: 5})
+	enqueue(t, svc, "mail.send", "elsewhere", EnqueueOptions{Queue: "search"})
+
+	for claimAndRun(ctx, svc, entity.DefaultQueue) > 0 {
+	}
+
+	if got := h.handled(); !slices.Equal(got, []string{`"high"`, `"low"`}) {
+		t.Fatalf("expected due jobs of the queue by priority, got %v", got)
+	}
+}
+
+func TestRun_RetriesThenDeadLetters(t *testing.T) {
+	h := &recordingHandler{kind: "mail.send", fail: map[string]bool{`"bad"`: true}}
+	svc, store := newTestService(t, h)
+	ctx := context.Background()
+
+	enqueue(t, svc, "mail.send", "good", EnqueueOptions{})
+	bad := enqueue(t, svc, "mail.send", "bad", EnqueueOptions{MaxAttempts: 2})
+
+	for claimAndRun(ctx, svc, entity.DefaultQueue) > 0 {
+	}
+	want := map[string]string{`"good"`: "completed", `"bad"`: "failed"}
+	if got := jobStatuses(t, store); !maps.Equal(got, want) {
+		t.Fatalf("unexpected job statuses %v", got)
+	}
+
+	// Skip the backoff.
+	_ = store.Write(func(tb *memdb.Tables) error {
+		tb.Jobs[bad.ID].ScheduledAt = tb.Now
+		return nil
+	})
+	claimAndRun(ctx, svc, entity.DefaultQueue)
+
+	job, err := svc.GetJob(ctx, bad.ID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.Status != "dead" || job.Attempts != 2 || job.LastError.String != "boom" {
+		t.Fatalf("expected the job dead after its own max attempts, got %s after %d (%q)", job.Status, job.Attempts, job.LastError.String)
+	}
+}
+
+func TestClaim_BatchesJobsOfOneKind(t *testing.T) {
+	h := batchHandler{recordingHandler: &recordingHandler{kind: "search.index"}, size: 2}
+	other := &recordingHandler{kind: "search.ping"}
+	svc, _ := newTestService(t, h, other)
+	ctx := context.Background()
+
+	for _, p := range []string{"a", "b", "c"} {
+		enqueue(t, svc, "search.index", p, EnqueueOptions{Queue: "search"})
+	}
+	enqueue(t, svc, "search.ping", "ping", EnqueueOptions{Queue: "search", Priority: 1})
+
+	for claimAndRun(ctx, svc, "search") > 0 {
+	}
+
+	if len(other.calls) != 1 {
+		t.Fatalf("expected the other kind run on its own, got %v", other.calls)
+	}
+	want := [][]string{{`"a"`, `"b"`}, {`"c"`}}
+	if !slices.EqualFunc(h.calls, want, slices.Equal) {
+		t.Fatalf("expected batches of two, got %v", h.calls)
+	}
+}
+
+func TestClaim_GateHoldsJobs(t *testing.T) {
+	h := gatedHandler{recordingHandler: &recordingHandler{kind: "search.index"}, err: errors.New("index unavailable")}
+	svc, store := newTestService(t, h)
+
+	enqueue(t, svc, "search.index", "a", EnqueueOptions{Queue: "search"})
+	if n := claimAndRun(context.Background(), svc, "search"); n != 0 {
+		t.Fatalf("expected no jobs claimed while the gate is closed, got %d", n)
+	}
+	if got := jobStatuses(t, store); got[`"a"`] != "pending" {
+		t.Fatalf("expected the job left pending, got %v", got)
+	}
+}
+
+func TestClaim_RecoversJobsOfCrashedWorker(t *testing.T) {
+	h := &recordingHandler{kind: "mail.send"}
+	svc, store := newTestService(t, h)
+	ctx := context.Background()
+
+	enqueue(t, svc, "mail.send", "a", EnqueueOptions{})
+	enqueue(t, svc, "mail.send", "b", EnqueueOptions{MaxAttempts: 1})
+
+	// A worker claims the jobs and dies before finishing them.
+	claimed, err := svc.repo.ClaimJobs(ctx, entity.DefaultQueue, []string{"mail.send"}, "crashed-worker", 10, 50*time.Millisecond)
+	if err != nil || len(claimed) != 2 {
+		t.Fatalf("claim: %d jobs, %v", len(claimed), err)
+	}
+
+	claimAndRun(ctx, svc, entity.DefaultQueue)
+	if got := h.handled(); len(got) != 0 {
+		t.Fatalf("expected leased jobs to be left alone, ran %v", got)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	for claimAndRun(ctx, svc, entity.DefaultQueue) > 0 {
+	}
+
+	want := map[string]string{`"a"`: "completed", `"b"`: "dead"}
+	if got := jobStatuses(t, store); !maps.Equal(got, want) {
+		t.Fatalf("unexpected job statuses %v", got)
+	}
+	_ = store.Read(func(tb *memdb.Tables) error {
+		for _, j := range tb.Jobs {
+			if !strings.Contains(j.LastError.String, "crashed-worker") || j.LockedBy.Valid {
+				t.Errorf("expected %s to record the expired lease and be unlocked, got %q locked by %q", j.Payload, j.LastError.String, j.LockedBy.String)
+			}
+		}
+		return nil
+	})
+
+	// The crashed worker coming back must not overwrite the outcome.
+	if err := svc.repo.MarkFailed(ctx, claimed[0].ID, "crashed-worker", "late", time.Now()); !errors.Is(err, apperror.ErrConflict) {
+		t.Fatalf("expected conflict for a lost lease, got %v", err)
+	}
+}
+
+func TestRun_HeartbeatKeepsLeases(t *testing.T) {
+	h := &recordingHandler{kind: "mail.send", delay: 500 * time.Millisecond}
+	svc, store := newTestService(t, h)
+	svc.cfg.LeaseDuration = 150 * time.Millisecond
+
+	enqueue(t, svc, "mail.send", "a", EnqueueOptions{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		claimAndRun(context.Background(), svc, entity.DefaultQueue)
+	}()
+
+	// Well past the original lease, another worker's reaper finds nothing.
+	time.Sleep(300 * time.Millisecond)
+	released, err := svc.repo.ReleaseExpiredJobs(context.Background())
+	if err != nil {
+		t.Fatalf("release expired jobs: %v", err)
+	}
+	<-done
+
+	if released != 0 {
+		t.Fatalf("expected the heartbeat to keep the lease, %d jobs released", released)
+	}
+	if got := jobStatuses(t, store); got[`"a"`] != "completed" {
+		t.Fatalf("expected job completed, got %v", got)
+	}
+}
+
+// chanListener wakes each queue from its own channel.
+type chanListener map[string]chan struct{}
+
+func (l chanListener) Wake(queue string) <-chan struct{} { return l[queue] }
+
+func TestStart_DrainsQueueOnWake(t *testing.T) {
+	h := batchHandler{recordingHandler: &recordingHandler{kind: "search.index"}, size: 2}
+	svc, _ := newTestService(t, h)
+	wake := chanListener{"search": make(chan struct{}, 1)}
+	svc.listener = wake
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.Start(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	for _, p := range []string{"a", "b", "c", "d", "e"} {
+		enqueue(t, svc, "search.index", p, EnqueueOptions{Queue: "search"})
+	}
+	wake["search"] <- struct{}{}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(h.handled()) < 5 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected one wake to run every job, ran %v", h.handled())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHandle_IsolatesPanics(t *testing.T) {
+	h := &recordingHandler{kind: "mail.send", panics: map[string]bool{`"bad"`: true}}
+	svc, store := newTestService(t, h)
+	ctx := context.Background()
+
+	enqueue(t, svc, "mail.send", "bad", EnqueueOptions{Priority: 1})
+	enqueue(t, svc, "mail.send", "good", EnqueueOptions{})
+
+	for claimAndRun(ctx, svc, entity.DefaultQueue) > 0 {
+	}
+
+	want := map[string]string{`"bad"`: "failed", `"good"`: "completed"}
+	if got := jobStatuses(t, store); !maps.Equal(got, want) {
+		t.Fatalf("unexpected job statuses %v", got)
+	}
+	_ = store.Read(func(tb *memdb.Tables) error {
+		for _, j := range tb.Jobs {
+			if j.Status == "failed" && !strings.Contains(j.LastError.String, "panic: handler bug") {
+				t.Errorf("expected the panic recorded, got %q", j.LastError.String)
+			}
+		}
+		return nil
+	})
+}
+
+func TestHandle_TimesOutJobs(t *testing.T) {
+	h := &recordingHandler{kind: "mail.send", delay: time.Hour}
+	svc, store := newTestService(t, h)
+	svc.cfg.JobTimeout = 20 * time.Millisecond
+
+	enqueue(t, svc, "mail.send", "slow", EnqueueOptions{})
+	claimAndRun(context.Background(), svc, entity.DefaultQueue)
+
+	_ = store.Read(func(tb *memdb.Tables) error {
+		for _, j := range tb.Jobs {
+			if j.Status != "failed" || !strings.Contains(j.LastError.String, "timed out after 20ms") {
+				t.Errorf("expected the job failed on its timeout, got %s (%q)", j.Status, j.LastError.String)
+			}
+		}
+		return nil
+	})
+}
+
+// startService runs svc.Start until the returned stop is called, which
+// returns once Start has.
+func startService(t *testing.T, svc *service) (stop func()) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.Start(ctx)
+	}()
+
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+// waitFor polls cond until it holds, failing the test after two seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStart_BoundsConcurrency(t *testing.T) {
+	tests := []struct {
+		name       string
+		queueSlots int
+		poolSlots  int
+		want       int
+	}{
+		{name: "pool", queueSlots: 3, poolSlots: 2, want: 2},
+		{name: "queue", queueSlots: 1, poolSlots: 3, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &recordingHandler{kind: "mail.send", delay: 50 * time.Millisecond}
+			svc, _ := newTestService(t, h)
+			svc.queueSlots = map[string]chan struct{}{"default": make(chan struct{}, tt.queueSlots)}
+			svc.slots = make(chan struct{}, tt.poolSlots)
+			wake := chanListener{"default": make(chan struct{}, 1)}
+			svc.listener = wake
+
+			for _, p := range []string{"a", "b", "c", "d", "e"} {
+				enqueue(t, svc, "mail.send", p, EnqueueOptions{})
+			}
+			startService(t, svc)
+			wake["default"] <- struct{}{}
+
+			waitFor(t, "every job to run", func() bool { return len(h.handled()) == 5 })
+			if _, peak := h.running(); peak != tt.want {
+				t.Fatalf("expected at most %d jobs at once, got %d", tt.want, peak)
+			}
+		})
+	}
+}
+
+func TestDrain_WaitsForRunningJobs(t *testing.T) {
+	h := &recordingHandler{kind: "mail.send", delay: 100 * time.Millisecond}
+	svc, store := newTestService(t, h)
+	wake := chanListener{"default": make(chan struct{}, 1)}
+	svc.listener = wake
+
+	enqueue(t, svc, "mail.send", "a", EnqueueOptions{})
+	stop := startService(t, svc)
+	wake["default"] <- struct{}{}
+	waitFor(t, "the job to start", func() bool {
+		active, _ := h.running()
+		return active == 1
+	})
+
+	stop()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := svc.Drain(ctx); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if got := jobStatuses(t, store); got[`"a"`] != "completed" {
+		t.Fatalf("expected the running job to finish, got %v", got)
+	}
+}
+
+func TestDrain_CancelsJobsPastDeadline(t *testing.T) {
+	h := &recordingHandler{kind: "mail.send", delay: time.Hour}
+	svc, store := newTestService(t, h)
+	wake := chanListener{"default": make(chan struct{}, 1)}
+	svc.listener = wake
+
+	enqueue(t, svc, "mail.send", "a", EnqueueOptions{})
+	stop := startService(t, svc)
+	wake["default"] <- struct{}{}
+	waitFor(t, "the job to start", func() bool {
+		active, _ := h.running()
+		return active == 1
+	})
+
+	stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := svc.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the drain to time out, got %v", err)
+	}
+	waitFor(t, "the canceled job to be failed", func() bool {
+		return jobStatuses(t, store)[`"a"`] == "failed"
+	})
+}
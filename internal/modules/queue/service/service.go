@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"cms-api/internal/config"
+	"cms-api/internal/modules/queue/repo"
+)
+
+type service struct {
+	Queue
+
+	repo     repo.Repository
+	listener repo.Listener
+	registry *Registry
+	cfg      config.WorkerConfig
+	log      *zap.Logger
+
+	// id identifies this worker in the leases on the jobs it claims.
+	id string
+
+	// slots bounds the batches running at once across queues, and each
+	// queue's queueSlots those of the queue. inflight tracks the running
+	// batches for Drain.
+	slots      chan struct{}
+	queueSlots map[string]chan struct{}
+	inflight   sync.WaitGroup
+
+	// runCtx is the context batches run under; abort cancels it when Drain
+	// runs out of time.
+	runCtx context.Context
+	abort  context.CancelFunc
+}
+
+func New(queue Queue, repo repo.Repository, listener repo.Listener, registry *Registry, cfg *config.Config, log *zap.Logger) Service {
+	queueSlots := make(map[string]chan struct{}, len(cfg.Worker.Queues))
+	for queue, n := range cfg.Worker.Queues {
+		queueSlots[queue] = make(chan struct{}, max(n, 1))
+	}
+	runCtx, abort := context.WithCancel(context.Background())
+
+	return &service{
+		Queue:      queue,
+		repo:       repo,
+		listener:   listener,
+		registry:   registry,
+		cfg:        cfg.Worker,
+		log:        log.Named("queue"),
+		id:         workerID(),
+		slots:      make(chan struct{}, max(cfg.Worker.Concurrency, 1)),
+		queueSlots: queueSlots,
+		runCtx:     runCtx,
+		abort:      abort,
+	}
+}
+
+// workerID names this process for operators reading locked_by. The random
+// suffix keeps restarts, which may reuse a PID, from inheriting leases.
+func workerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "worker"
+	}
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8])
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// The targets of the retention policies, as named in logs and metrics.
+const (
+	TargetCompletedJobs = "completed_jobs"
+	TargetDeadJobs      = "dead_jobs"
+	TargetRefreshTokens = "refresh_tokens"
+	TargetImportLogs    = "import_logs"
+)
+
+// Run is the outcome of one pass over the retention policies.
+type Run struct {
+	StartedAt time.Time
+	Duration  time.Duration
+	// Deleted counts the rows removed per target. Targets kept forever are
+	// absent.
+	Deleted map[string]int
+}
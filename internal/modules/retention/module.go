@@ -0,0 +1,46 @@
+package retention
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+
+	"cms-api/internal/config"
+	"cms-api/internal/modules/retention/repo"
+	"cms-api/internal/modules/retention/service"
+)
+
+var Module = fx.Module("retention",
+	fx.Provide(repo.New),
+	fx.Provide(service.New),
+	fx.Invoke(startScheduler),
+)
+
+// startScheduler runs the retention scheduler in the background when it is
+// enabled.
+func startScheduler(lc fx.Lifecycle, svc service.Service, cfg *config.Config) {
+	if !cfg.Retention.Enabled {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				svc.Start(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,21 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+// Repository deletes rows that fell out of retention before cutoff. Each
+// method removes at most limit rows, oldest first where the table allows,
+// and returns how many it removed; callers repeat until fewer than limit
+// come back.
+type Repository interface {
+	// DeleteJobs removes queue jobs in status, completed or dead, last
+	// updated before cutoff.
+	DeleteJobs(ctx context.Context, status string, cutoff time.Time, limit int) (int, error)
+	// DeleteRefreshTokens removes tokens revoked or expired before cutoff.
+	DeleteRefreshTokens(ctx context.Context, cutoff time.Time, limit int) (int, error)
+	// DeleteImportLogs removes the logs of imports that finished before
+	// cutoff. Logs of running imports are kept.
+	DeleteImportLogs(ctx context.Context, cutoff time.Time, limit int) (int, error)
+}
@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"cms-api/internal/infra/memdb"
+)
+
+type memoryRepository struct {
+	store *memdb.Store
+}
+
+// NewMemory returns a Repository deleting expired rows from store, so the
+// retention scheduler also runs in the hermetic app.
+func NewMemory(store *memdb.Store) Repository {
+	return &memoryRepository{store: store}
+}
+
+func (r *memoryRepository) DeleteJobs(ctx context.Context, status string, cutoff time.Time, limit int) (int, error) {
+	var n int
+	err := r.store.Write(func(t *memdb.Tables) error {
+		var expired []*memdb.Job
+		for _, j := range t.Jobs {
+			if j.Status == status && j.UpdatedAt.Before(cutoff) {
+				expired = append(expired, j)
+			}
+		}
+		slices.SortFunc(expired, func(a, b *memdb.Job) int {
+			return cmp.Or(a.UpdatedAt.Compare(b.UpdatedAt), cmp.Compare(a.ID, b.ID))
+		})
+		for _, j := range expired[:min(limit, len(expired))] {
+			delete(t.Jobs, j.ID)
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+func (r *memoryRepository) DeleteRefreshTokens(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	var n int
+	err := r.store.Write(func(t *memdb.Tables) error {
+		for id, rt := range t.RefreshTokens {
+			if n == limit {
+				break
+			}
+			if (rt.RevokedAt.Valid && rt.RevokedAt.Time.Before(cutoff)) || rt.ExpiresAt.Before(cutoff) {
+				delete(t.RefreshTokens, id)
+				n++
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+func (r *memoryRepository) DeleteImportLogs(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	var n int
+	err := r.store.Write(func(t *memdb.Tables) error {
+		var expired []*memdb.ImportLog
+		for _, l := range t.ImportLogs {
+			if (l.Status == "completed" || l.Status == "failed") && l.FinishedAt != nil && l.FinishedAt.Before(cutoff) {
+				expired = append(expired, l)
+			}
+		}
+		slices.SortFunc(expired, func(a, b *memdb.ImportLog) int {
+			return cmp.Or(a.FinishedAt.Compare(*b.FinishedAt), cmp.Compare(a.ID, b.ID))
+		})
+		for _, l := range expired[:min(limit, len(expired))] {
+			delete(t.ImportLogs, l.ID)
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
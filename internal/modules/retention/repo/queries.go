@@ -0,0 +1,39 @@
+package repo
+
+// Each delete picks its batch with SKIP LOCKED, so instances running the
+// scheduler at the same time split the rows rather than wait on each other.
+
+const queryDeleteJobs = `
+	DELETE FROM jobs
+	WHERE id IN (
+		SELECT id
+		FROM jobs
+		WHERE status = $1 AND updated_at < $2
+		ORDER BY updated_at
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	)
+`
+
+const queryDeleteRefreshTokens = `
+	DELETE FROM refresh_tokens
+	WHERE id IN (
+		SELECT id
+		FROM refresh_tokens
+		WHERE revoked_at < $1 OR expires_at < $1
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	)
+`
+
+const queryDeleteImportLogs = `
+	DELETE FROM import_logs
+	WHERE id IN (
+		SELECT id
+		FROM import_logs
+		WHERE status IN ('completed', 'failed') AND finished_at < $1
+		ORDER BY finished_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	)
+`
@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) DeleteJobs(ctx context.Context, status string, cutoff time.Time, limit int) (int, error) {
+	return r.exec(ctx, queryDeleteJobs, status, cutoff, limit)
+}
+
+func (r *repository) DeleteRefreshTokens(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	return r.exec(ctx, queryDeleteRefreshTokens, cutoff, limit)
+}
+
+func (r *repository) DeleteImportLogs(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	return r.exec(ctx, queryDeleteImportLogs, cutoff, limit)
+}
+
+// exec runs a delete and returns the rows it removed.
+func (r *repository) exec(ctx context.Context, query string, args ...any) (int, error) {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
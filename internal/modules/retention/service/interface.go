@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"cms-api/internal/modules/retention/entity"
+)
+
+type Service interface {
+	// RunOnce applies every retention policy, deleting batches until each
+	// target is caught up. A failing target does not stop the others; their
+	// errors are joined.
+	RunOnce(ctx context.Context) (*entity.Run, error)
+	// Start runs RunOnce every interval until ctx is done.
+	Start(ctx context.Context)
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/zap"
+
+	"cms-api/internal/config"
+	"cms-api/internal/modules/retention/entity"
+	"cms-api/internal/modules/retention/repo"
+)
+
+type service struct {
+	repo repo.Repository
+	cfg  config.RetentionConfig
+	log  *zap.Logger
+
+	// deleted counts the rows removed per target.
+	deleted metric.Int64Counter
+}
+
+func New(repo repo.Repository, cfg *config.Config, log *zap.Logger) Service {
+	log = log.Named("retention")
+
+	meter := otel.Meter("cms-api/internal/modules/retention")
+	deleted, err := meter.Int64Counter("retention.rows_deleted", metric.WithDescription("Rows deleted by the retention scheduler per target"))
+	if err != nil {
+		log.Warn("failed to register retention metrics", zap.Error(err))
+		deleted = noop.Int64Counter{}
+	}
+
+	return &service{
+		repo:    repo,
+		cfg:     cfg.Retention,
+		log:     log,
+		deleted: deleted,
+	}
+}
+
+// policy deletes one target's rows older than its period.
+type policy struct {
+	target string
+	period time.Duration
+	delete func(ctx context.Context, cutoff time.Time, limit int) (int, error)
+}
+
+func (s *service) policies() []policy {
+	return []policy{
+		{entity.TargetCompletedJobs, s.cfg.CompletedJobs, func(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+			return s.repo.DeleteJobs(ctx, "completed", cutoff, limit)
+		}},
+		{entity.TargetDeadJobs, s.cfg.DeadJobs, func(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+			return s.repo.DeleteJobs(ctx, "dead", cutoff, limit)
+		}},
+		{entity.TargetRefreshTokens, s.cfg.RefreshTokens, s.repo.DeleteRefreshTokens},
+		{entity.TargetImportLogs, s.cfg.ImportLogs, s.repo.DeleteImportLogs},
+	}
+}
+
+func (s *service) Start(ctx context.Context) {
+	s.log.Info("Retention scheduler started",
+		zap.Duration("interval", s.cfg.Interval),
+		zap.Int("batch_size", s.cfg.BatchSize),
+	)
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			s.log.Error("Retention run failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			s.log.Info("Retention scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *service) RunOnce(ctx context.Context) (*entity.Run, error) {
+	run := &entity.Run{StartedAt: time.Now(), Deleted: make(map[string]int)}
+
+	var errs []error
+	var total int
+	for _, p := range s.policies() {
+		if p.period <= 0 {
+			continue
+		}
+		n, err := s.apply(ctx, p, run.StartedAt.Add(-p.period))
+		run.Deleted[p.target] = n
+		total += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.target, err))
+		}
+	}
+	run.Duration = time.Since(run.StartedAt)
+
+	if total > 0 {
+		fields := []zap.Field{zap.Duration("duration", run.Duration)}
+		for target, n := range run.Deleted {
+			fields = append(fields, zap.Int(target, n))
+		}
+		s.log.Info("Deleted rows past retention", fields...)
+	}
+	return run, errors.Join(errs...)
+}
+
+// apply deletes p's rows older than cutoff in batches of BatchSize, pausing
+// between batches, until a short batch shows it caught up. It returns the
+// rows deleted, including those before an error.
+func (s *service) apply(ctx context.Context, p policy, cutoff time.Time) (int, error) {
+	attrs := metric.WithAttributes(attribute.String("target", p.target))
+
+	limit := s.cfg.BatchSize
+
+	var total int
+	for {
+		n, err := p.delete(ctx, cutoff, limit)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		s.deleted.Add(ctx, int64(n), attrs)
+		if n < limit {
+			return total, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(s.cfg.BatchPause):
+		}
+	}
+}
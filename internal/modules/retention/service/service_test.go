@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"cms-api/internal/config"
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/modules/retention/entity"
+	"cms-api/internal/modules/retention/repo"
+)
+
+func newTestService(r repo.Repository) *service {
+	cfg := &config.Config{Retention: config.RetentionConfig{
+		Interval:      time.Hour,
+		BatchSize:     2,
+		CompletedJobs: 24 * time.Hour,
+		DeadJobs:      72 * time.Hour,
+		RefreshTokens: 24 * time.Hour,
+		ImportLogs:    48 * time.Hour,
+	}}
+	return New(r, cfg, zap.NewNop()).(*service)
+}
+
+func ids[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+func TestRunOnce_DeletesRowsPastRetention(t *testing.T) {
+	store := memdb.New()
+	svc := newTestService(repo.NewMemory(store))
+	now := time.Now()
+	ago := func(h int) time.Time { return now.Add(-time.Duration(h) * time.Hour) }
+	finished := func(h int) *time.Time { at := ago(h); return &at }
+
+	_ = store.Write(func(tb *memdb.Tables) error {
+		jobs := []*memdb.Job{
+			{ID: "completed-old-1", Status: "completed", UpdatedAt: ago(30)},
+			{ID: "completed-old-2", Status: "completed", UpdatedAt: ago(40)},
+			{ID: "completed-old-3", Status: "completed", UpdatedAt: ago(50)},
+			{ID: "completed-new", Status: "completed", UpdatedAt: ago(1)},
+			{ID: "dead-grace", Status: "dead", UpdatedAt: ago(30)},
+			{ID: "dead-old", Status: "dead", UpdatedAt: ago(100)},
+			{ID: "failed-old", Status: "failed", UpdatedAt: ago(100)},
+		}
+		for _, j := range jobs {
+			tb.Jobs[j.ID] = j
+		}
+		tb.RefreshTokens["expired"] = &memdb.RefreshToken{ID: "expired", ExpiresAt: ago(30)}
+		tb.RefreshTokens["revoked"] = &memdb.RefreshToken{ID: "revoked", ExpiresAt: now.Add(time.Hour), RevokedAt: sql.NullTime{Time: ago(30), Valid: true}}
+		tb.RefreshTokens["recently-revoked"] = &memdb.RefreshToken{ID: "recently-revoked", ExpiresAt: now.Add(time.Hour), RevokedAt: sql.NullTime{Time: ago(1), Valid: true}}
+		tb.RefreshTokens["live"] = &memdb.RefreshToken{ID: "live", ExpiresAt: now.Add(time.Hour)}
+		tb.ImportLogs["finished-old"] = &memdb.ImportLog{ID: "finished-old", Status: "failed", FinishedAt: finished(50)}
+		tb.ImportLogs["finished-new"] = &memdb.ImportLog{ID: "finished-new", Status: "completed", FinishedAt: finished(1)}
+		tb.ImportLogs["running"] = &memdb.ImportLog{ID: "running", Status: "running", CreatedAt: ago(100)}
+		return nil
+	})
+
+	run, err := svc.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	want := map[string]int{
+		entity.TargetCompletedJobs: 3,
+		entity.TargetDeadJobs:      1,
+		entity.TargetRefreshTokens: 2,
+		entity.TargetImportLogs:    1,
+	}
+	if !maps.Equal(run.Deleted, want) {
+		t.Fatalf("expected %v deleted, got %v", want, run.Deleted)
+	}
+	_ = store.Read(func(tb *memdb.Tables) error {
+		if got := ids(tb.Jobs); !slices.Equal(got, []string{"completed-new", "dead-grace", "failed-old"}) {
+			t.Errorf("unexpected jobs kept %v", got)
+		}
+		if got := ids(tb.RefreshTokens); !slices.Equal(got, []string{"live", "recently-revoked"}) {
+			t.Errorf("unexpected refresh tokens kept %v", got)
+		}
+		if got := ids(tb.ImportLogs); !slices.Equal(got, []string{"finished-new", "running"}) {
+			t.Errorf("unexpected import logs kept %v", got)
+		}
+		return nil
+	})
+}
+
+func TestRunOnce_ZeroPeriodKeepsRows(t *testing.T) {
+	store := memdb.New()
+	svc := newTestService(repo.NewMemory(store))
+	svc.cfg.CompletedJobs = 0
+
+	_ = store.Write(func(tb *memdb.Tables) error {
+		tb.Jobs["old"] = &memdb.Job{ID: "old", Status: "completed", UpdatedAt: time.Now().Add(-1000 * time.Hour)}
+		return nil
+	})
+
+	run, err := svc.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if _, ok := run.Deleted[entity.TargetCompletedJobs]; ok {
+		t.Fatalf("expected completed jobs skipped, got %v", run.Deleted)
+	}
+	_ = store.Read(func(tb *memdb.Tables) error {
+		if len(tb.Jobs) != 1 {
+			t.Errorf("expected the job kept, got %v", ids(tb.Jobs))
+		}
+		return nil
+	})
+}
+
+// failingRepository fails every job delete.
+type failingRepository struct {
+	repo.Repository
+}
+
+func (failingRepository) DeleteJobs(context.Context, string, time.Time, int) (int, error) {
+	return 0, errors.New("lock timeout")
+}
+
+func TestRunOnce_ContinuesPastFailingTarget(t *testing.T) {
+	store := memdb.New()
+	svc := newTestService(failingRepository{repo.NewMemory(store)})
+
+	_ = store.Write(func(tb *memdb.Tables) error {
+		tb.RefreshTokens["expired"] = &memdb.RefreshToken{ID: "expired", ExpiresAt: time.Now().Add(-48 * time.Hour)}
+		return nil
+	})
+
+	run, err := svc.RunOnce(context.Background())
+	if err == nil {
+		t.Fatal("expected the job delete errors reported")
+	}
+	if run.Deleted[entity.TargetRefreshTokens] != 1 {
+		t.Fatalf("expected the other targets cleaned up, got %v", run.Deleted)
+	}
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "cms-api/internal/modules/searchsettings/entity"
+
+func ToSettingsResponse(s *entity.Settings, inSync bool) *SettingsResponse {
+	return &SettingsResponse{
+		IndexName:    s.IndexName,
+		Synonyms:     nonNilGroups(s.Synonyms),
+		StopWords:    nonNil(s.StopWords),
+		RankingRules: nonNil(s.RankingRules),
+		TypoTolerance: TypoToleranceResponse{
+			Enabled:             s.TypoTolerance.Enabled,
+			MinWordSizeOneTypo:  s.TypoTolerance.MinWordSizeOneTypo,
+			MinWordSizeTwoTypos: s.TypoTolerance.MinWordSizeTwoTypos,
+			DisableOnWords:      nonNil(s.TypoTolerance.DisableOnWords),
+			DisableOnAttributes: nonNil(s.TypoTolerance.DisableOnAttributes),
+		},
+		InSync:    inSync,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func nonNilGroups(g [][]string) [][]string {
+	if g == nil {
+		return [][]string{}
+	}
+	return g
+}
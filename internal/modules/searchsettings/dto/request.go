@@ -0,0 +1,19 @@
+package dto
+
+// UpdateSettingsRequest patches the settings; omitted fields are unchanged
+// and an empty list clears the setting. Empty ranking_rules restores the
+// default rules.
+type UpdateSettingsRequest struct {
+	Synonyms      [][]string            `json:"synonyms" validate:"omitempty,max=500,dive,min=2,max=20,dive,required,max=100"`
+	StopWords     []string              `json:"stop_words" validate:"omitempty,max=1000,dive,required,max=100"`
+	RankingRules  []string              `json:"ranking_rules" validate:"omitempty,max=20,dive,ranking_rule"`
+	TypoTolerance *TypoToleranceRequest `json:"typo_tolerance"`
+}
+
+type TypoToleranceRequest struct {
+	Enabled             *bool    `json:"enabled"`
+	MinWordSizeOneTypo  *int     `json:"min_word_size_one_typo" validate:"omitempty,min=0,max=255"`
+	MinWordSizeTwoTypos *int     `json:"min_word_size_two_typos" validate:"omitempty,min=0,max=255"`
+	DisableOnWords      []string `json:"disable_on_words" validate:"omitempty,max=1000,dive,required,max=100"`
+	DisableOnAttributes []string `json:"disable_on_attributes" validate:"omitempty,max=20,dive,oneof=title description"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type SettingsResponse struct {
+	IndexName     string                `json:"index_name"`
+	Synonyms      [][]string            `json:"synonyms"`
+	StopWords     []string              `json:"stop_words"`
+	RankingRules  []string              `json:"ranking_rules"`
+	TypoTolerance TypoToleranceResponse `json:"typo_tolerance"`
+	// InSync reports whether the search index has the stored settings.
+	InSync    bool      `json:"in_sync"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TypoToleranceResponse struct {
+	Enabled             bool     `json:"enabled"`
+	MinWordSizeOneTypo  int      `json:"min_word_size_one_typo"`
+	MinWordSizeTwoTypos int      `json:"min_word_size_two_typos"`
+	DisableOnWords      []string `json:"disable_on_words"`
+	DisableOnAttributes []string `json:"disable_on_attributes"`
+}
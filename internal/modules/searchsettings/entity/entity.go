@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// Settings are the relevance settings of one search index.
+type Settings struct {
+	IndexName string
+	// Synonyms are groups of interchangeable words.
+	Synonyms      [][]string
+	StopWords     []string
+	RankingRules  []string
+	TypoTolerance TypoTolerance
+	UpdatedAt     time.Time
+}
+
+type TypoTolerance struct {
+	Enabled             bool
+	MinWordSizeOneTypo  int
+	MinWordSizeTwoTypos int
+	DisableOnWords      []string
+	DisableOnAttributes []string
+}
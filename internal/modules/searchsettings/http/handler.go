@@ -0,0 +1,64 @@
+package http
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"cms-api/internal/modules/searchsettings/dto"
+	"cms-api/internal/modules/searchsettings/service"
+	"cms-api/internal/pkg/httputil"
+	"cms-api/internal/pkg/validator"
+)
+
+type Handler struct {
+	service service.Service
+	log     *zap.Logger
+}
+
+func NewHandler(service service.Service, log *zap.Logger) *Handler {
+	return &Handler{service: service, log: log}
+}
+
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.Get(r.Context())
+	if err != nil {
+		h.log.Error("failed to get search settings", zap.Error(err))
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdateSettingsRequest
+	if err := httputil.DecodeJSON(w, r, &req); err != nil {
+		httputil.BadRequest(w, err.Error())
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		httputil.ValidationError(w, err)
+		return
+	}
+
+	resp, err := h.service.Update(r.Context(), &req)
+	if err != nil {
+		h.log.Error("failed to update search settings", zap.Error(err))
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) Sync(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.Sync(r.Context())
+	if err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
@@ -0,0 +1,18 @@
+package http
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"cms-api/internal/transport/http/middleware"
+)
+
+func RegisterRoutes(r *chi.Mux, auth *middleware.AuthMiddleware, h *Handler) {
+	r.Route("/api/v1/search/settings", func(r chi.Router) {
+		r.Use(auth.Middleware)
+		r.Use(middleware.RequireRole("admin"))
+
+		r.Get("/", h.Get)
+		r.Patch("/", h.Update)
+		r.Post("/sync", h.Sync)
+	})
+}
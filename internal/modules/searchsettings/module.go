@@ -0,0 +1,44 @@
+package searchsettings
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+
+	settingshttp "cms-api/internal/modules/searchsettings/http"
+	"cms-api/internal/modules/searchsettings/repo"
+	"cms-api/internal/modules/searchsettings/service"
+)
+
+var Module = fx.Module("searchsettings",
+	fx.Provide(repo.New),
+	fx.Provide(service.New),
+	fx.Provide(settingshttp.NewHandler),
+	fx.Invoke(settingshttp.RegisterRoutes),
+	fx.Invoke(startSync),
+)
+
+// startSync diffs and applies the stored settings in the background, so a
+// search engine that is down at boot does not block startup.
+func startSync(lc fx.Lifecycle, svc service.Service) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				svc.Start(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,14 @@
+package repo
+
+import (
+	"context"
+
+	"cms-api/internal/modules/searchsettings/entity"
+)
+
+type Repository interface {
+	// Get returns apperror.ErrNotFound for an index without settings.
+	Get(ctx context.Context, indexName string) (*entity.Settings, error)
+	// Save creates or replaces the settings and sets UpdatedAt.
+	Save(ctx context.Context, settings *entity.Settings) error
+}
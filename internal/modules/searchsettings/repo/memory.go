@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"context"
+	"slices"
+
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/modules/searchsettings/entity"
+	"cms-api/internal/pkg/apperror"
+)
+
+type memoryRepository struct {
+	store *memdb.Store
+}
+
+// NewMemory returns a Repository keeping the admin search settings in store,
+// for the hermetic app and tests.
+func NewMemory(store *memdb.Store) Repository {
+	return &memoryRepository{store: store}
+}
+
+func (r *memoryRepository) Get(ctx context.Context, indexName string) (*entity.Settings, error) {
+	var settings *entity.Settings
+	err := r.store.Read(func(t *memdb.Tables) error {
+		s, ok := t.SearchSettings[indexName]
+		if !ok {
+			return apperror.ErrNotFound
+		}
+		settings = &entity.Settings{
+			IndexName:    s.IndexName,
+			Synonyms:     cloneGroups(s.Synonyms),
+			StopWords:    slices.Clone(s.StopWords),
+			RankingRules: slices.Clone(s.RankingRules),
+			TypoTolerance: entity.TypoTolerance{
+				Enabled:             s.TypoEnabled,
+				MinWordSizeOneTypo:  s.TypoMinWordSizeOneTypo,
+				MinWordSizeTwoTypos: s.TypoMinWordSizeTwoTypos,
+				DisableOnWords:      slices.Clone(s.TypoDisableOnWords),
+				DisableOnAttributes: slices.Clone(s.TypoDisableOnAttributes),
+			},
+			UpdatedAt: s.UpdatedAt,
+		}
+		return nil
+	})
+	return settings, err
+}
+
+func (r *memoryRepository) Save(ctx context.Context, settings *entity.Settings) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		tt := settings.TypoTolerance
+		t.SearchSettings[settings.IndexName] = &memdb.SearchSettings{
+			IndexName:               settings.IndexName,
+			Synonyms:                cloneGroups(nonNilGroups(settings.Synonyms)),
+			StopWords:               slices.Clone(nonNil(settings.StopWords)),
+			RankingRules:            slices.Clone(nonNil(settings.RankingRules)),
+			TypoEnabled:             tt.Enabled,
+			TypoMinWordSizeOneTypo:  tt.MinWordSizeOneTypo,
+			TypoMinWordSizeTwoTypos: tt.MinWordSizeTwoTypos,
+			TypoDisableOnWords:      slices.Clone(nonNil(tt.DisableOnWords)),
+			TypoDisableOnAttributes: slices.Clone(nonNil(tt.DisableOnAttributes)),
+			UpdatedAt:               t.Now,
+		}
+		settings.UpdatedAt = t.Now
+		return nil
+	})
+}
+
+func cloneGroups(g [][]string) [][]string {
+	out := make([][]string, len(g))
+	for i, group := range g {
+		out[i] = slices.Clone(group)
+	}
+	return out
+}
@@ -0,0 +1,29 @@
+package repo
+
+const queryGetSettings = `
+	SELECT index_name, synonyms, stop_words, ranking_rules,
+	       typo_enabled, typo_min_word_size_one_typo, typo_min_word_size_two_typos,
+	       typo_disable_on_words, typo_disable_on_attributes, updated_at
+	FROM search_settings
+	WHERE index_name = $1
+`
+
+const querySaveSettings = `
+	INSERT INTO search_settings (
+		index_name, synonyms, stop_words, ranking_rules,
+		typo_enabled, typo_min_word_size_one_typo, typo_min_word_size_two_typos,
+		typo_disable_on_words, typo_disable_on_attributes, updated_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+	ON CONFLICT (index_name) DO UPDATE
+	SET synonyms = EXCLUDED.synonyms,
+	    stop_words = EXCLUDED.stop_words,
+	    ranking_rules = EXCLUDED.ranking_rules,
+	    typo_enabled = EXCLUDED.typo_enabled,
+	    typo_min_word_size_one_typo = EXCLUDED.typo_min_word_size_one_typo,
+	    typo_min_word_size_two_typos = EXCLUDED.typo_min_word_size_two_typos,
+	    typo_disable_on_words = EXCLUDED.typo_disable_on_words,
+	    typo_disable_on_attributes = EXCLUDED.typo_disable_on_attributes,
+	    updated_at = NOW()
+	RETURNING updated_at
+`
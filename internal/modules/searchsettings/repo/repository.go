@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"cms-api/internal/modules/searchsettings/entity"
+	"cms-api/internal/pkg/apperror"
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+type settingsRow struct {
+	IndexName               string         `db:"index_name"`
+	Synonyms                []byte         `db:"synonyms"`
+	StopWords               pq.StringArray `db:"stop_words"`
+	RankingRules            pq.StringArray `db:"ranking_rules"`
+	TypoEnabled             bool           `db:"typo_enabled"`
+	TypoMinWordSizeOneTypo  int            `db:"typo_min_word_size_one_typo"`
+	TypoMinWordSizeTwoTypos int            `db:"typo_min_word_size_two_typos"`
+	TypoDisableOnWords      pq.StringArray `db:"typo_disable_on_words"`
+	TypoDisableOnAttributes pq.StringArray `db:"typo_disable_on_attributes"`
+	UpdatedAt               time.Time      `db:"updated_at"`
+}
+
+func (r *repository) Get(ctx context.Context, indexName string) (*entity.Settings, error) {
+	var row settingsRow
+	if err := r.db.GetContext(ctx, &row, queryGetSettings, indexName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	settings := &entity.Settings{
+		IndexName:    row.IndexName,
+		StopWords:    []string(row.StopWords),
+		RankingRules: []string(row.RankingRules),
+		TypoTolerance: entity.TypoTolerance{
+			Enabled:             row.TypoEnabled,
+			MinWordSizeOneTypo:  row.TypoMinWordSizeOneTypo,
+			MinWordSizeTwoTypos: row.TypoMinWordSizeTwoTypos,
+			DisableOnWords:      []string(row.TypoDisableOnWords),
+			DisableOnAttributes: []string(row.TypoDisableOnAttributes),
+		},
+		UpdatedAt: row.UpdatedAt,
+	}
+	if err := json.Unmarshal(row.Synonyms, &settings.Synonyms); err != nil {
+		return nil, fmt.Errorf("decode synonyms: %w", err)
+	}
+	return settings, nil
+}
+
+func (r *repository) Save(ctx context.Context, settings *entity.Settings) error {
+	synonyms, err := json.Marshal(nonNilGroups(settings.Synonyms))
+	if err != nil {
+		return fmt.Errorf("encode synonyms: %w", err)
+	}
+
+	t := settings.TypoTolerance
+	return r.db.QueryRowContext(ctx, querySaveSettings,
+		settings.IndexName,
+		synonyms,
+		pq.StringArray(nonNil(settings.StopWords)),
+		pq.StringArray(nonNil(settings.RankingRules)),
+		t.Enabled,
+		t.MinWordSizeOneTypo,
+		t.MinWordSizeTwoTypos,
+		pq.StringArray(nonNil(t.DisableOnWords)),
+		pq.StringArray(nonNil(t.DisableOnAttributes)),
+	).Scan(&settings.UpdatedAt)
+}
+
+// nonNil keeps NOT NULL array columns from receiving NULL.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func nonNilGroups(g [][]string) [][]string {
+	if g == nil {
+		return [][]string{}
+	}
+	return g
+}
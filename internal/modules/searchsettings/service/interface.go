@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+
+	"cms-api/internal/modules/searchsettings/dto"
+)
+
+type Service interface {
+	Get(ctx context.Context) (*dto.SettingsResponse, error)
+	Update(ctx context.Context, req *dto.UpdateSettingsRequest) (*dto.SettingsResponse, error)
+	// Sync pushes the stored settings to the search index.
+	Sync(ctx context.Context) (*dto.SettingsResponse, error)
+	// Start retries Sync every interval until the index is in sync. It blocks
+	// until ctx is cancelled.
+	Start(ctx context.Context)
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"cms-api/internal/config"
+	"cms-api/internal/infra/search"
+	"cms-api/internal/modules/searchsettings/dto"
+	"cms-api/internal/modules/searchsettings/entity"
+	"cms-api/internal/modules/searchsettings/repo"
+	"cms-api/internal/pkg/apperror"
+	"cms-api/internal/pkg/textnorm"
+)
+
+const indexName = "programs"
+
+type service struct {
+	repo     repo.Repository
+	indexer  search.Indexer
+	interval time.Duration
+	log      *zap.Logger
+
+	// syncMu serialises pushes so two admins cannot interleave patches.
+	syncMu sync.Mutex
+	// dirty is set while the index may lag the stored settings. It starts
+	// set so the first sync diffs and applies whatever changed while down.
+	dirty atomic.Bool
+}
+
+func New(repo repo.Repository, indexer search.Indexer, cfg *config.Config, log *zap.Logger) Service {
+	s := &service{
+		repo:     repo,
+		indexer:  indexer,
+		interval: cfg.Search.HealthInterval,
+		log:      log.Named("searchsettings"),
+	}
+	s.dirty.Store(true)
+	return s
+}
+
+func (s *service) Get(ctx context.Context) (*dto.SettingsResponse, error) {
+	settings, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToSettingsResponse(settings, !s.dirty.Load()), nil
+}
+
+func (s *service) Update(ctx context.Context, req *dto.UpdateSettingsRequest) (*dto.SettingsResponse, error) {
+	settings, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Synonyms != nil {
+		settings.Synonyms = normalizeGroups(req.Synonyms)
+	}
+	if req.StopWords != nil {
+		settings.StopWords = normalizeWords(req.StopWords)
+	}
+	if req.RankingRules != nil {
+		settings.RankingRules = slices.Compact(slices.Clone(req.RankingRules))
+		if len(settings.RankingRules) == 0 {
+			settings.RankingRules = slices.Clone(search.DefaultRankingRules)
+		}
+	}
+	if t := req.TypoTolerance; t != nil {
+		typo := &settings.TypoTolerance
+		if t.Enabled != nil {
+			typo.Enabled = *t.Enabled
+		}
+		if t.MinWordSizeOneTypo != nil {
+			typo.MinWordSizeOneTypo = *t.MinWordSizeOneTypo
+		}
+		if t.MinWordSizeTwoTypos != nil {
+			typo.MinWordSizeTwoTypos = *t.MinWordSizeTwoTypos
+		}
+		if t.DisableOnWords != nil {
+			typo.DisableOnWords = normalizeWords(t.DisableOnWords)
+		}
+		if t.DisableOnAttributes != nil {
+			typo.DisableOnAttributes = slices.Compact(slices.Sorted(slices.Values(t.DisableOnAttributes)))
+		}
+		if typo.MinWordSizeOneTypo > typo.MinWordSizeTwoTypos {
+			return nil, apperror.NewAppError(apperror.ErrBadRequest, "min_word_size_one_typo must not exceed min_word_size_two_typos", http.StatusBadRequest)
+		}
+	}
+
+	if err := s.repo.Save(ctx, settings); err != nil {
+		return nil, err
+	}
+	s.dirty.Store(true)
+
+	// The settings are saved either way; a failed push is retried by Start.
+	if err := s.sync(ctx, settings); err != nil {
+		s.log.Warn("Failed to push search settings, will retry", zap.Error(err))
+	}
+	return dto.ToSettingsResponse(settings, !s.dirty.Load()), nil
+}
+
+func (s *service) Sync(ctx context.Context) (*dto.SettingsResponse, error) {
+	settings, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sync(ctx, settings); err != nil {
+		s.log.Error("Failed to push search settings", zap.Error(err))
+		return nil, apperror.NewAppError(apperror.ErrServiceUnavailable, "search index unavailable", http.StatusServiceUnavailable)
+	}
+	return dto.ToSettingsResponse(settings, true), nil
+}
+
+func (s *service) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if s.dirty.Load() {
+			s.syncStored(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *service) syncStored(ctx context.Context) {
+	settings, err := s.load(ctx)
+	if err == nil {
+		err = s.sync(ctx, settings)
+	}
+	if err != nil && ctx.Err() == nil {
+		s.log.Warn("Search settings not in sync, will retry", zap.Error(err))
+	}
+}
+
+// sync reads the index settings and applies only what differs from the
+// stored ones.
+func (s *service) sync(ctx context.Context, settings *entity.Settings) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	current, err := s.indexer.Settings(ctx, indexName)
+	if err != nil {
+		return fmt.Errorf("get index settings: %w", err)
+	}
+
+	patch := search.DiffSettings(*current, toIndexSettings(settings))
+	if !patch.Empty() {
+		if err := s.indexer.ApplySettings(ctx, indexName, patch); err != nil {
+			return fmt.Errorf("apply index settings: %w", err)
+		}
+		s.log.Info("Search settings applied",
+			zap.Bool("synonyms", patch.Synonyms != nil),
+			zap.Bool("stop_words", patch.StopWords != nil),
+			zap.Bool("ranking_rules", patch.RankingRules != nil),
+			zap.Bool("typo_tolerance", patch.TypoTolerance != nil),
+		)
+	}
+	s.dirty.Store(false)
+	return nil
+}
+
+// load returns the stored settings, or the engine defaults if none are.
+func (s *service) load(ctx context.Context) (*entity.Settings, error) {
+	settings, err := s.repo.Get(ctx, indexName)
+	if errors.Is(err, apperror.ErrNotFound) {
+		typo := search.DefaultTypoTolerance
+		return &entity.Settings{
+			IndexName:    indexName,
+			Synonyms:     [][]string{},
+			StopWords:    []string{},
+			RankingRules: slices.Clone(search.DefaultRankingRules),
+			TypoTolerance: entity.TypoTolerance{
+				Enabled:             typo.Enabled,
+				MinWordSizeOneTypo:  typo.MinWordSizeOneTypo,
+				MinWordSizeTwoTypos: typo.MinWordSizeTwoTypos,
+				DisableOnWords:      []string{},
+				DisableOnAttributes: []string{},
+			},
+		}, nil
+	}
+	return settings, err
+}
+
+// toIndexSettings expands each synonym group into mutual synonyms: every
+// word of a group maps to all the others.
+func toIndexSettings(s *entity.Settings) search.IndexSettings {
+	synonyms := make(map[string][]string)
+	for _, group := range s.Synonyms {
+		for _, word := range group {
+			for _, other := range group {
+				if other != word && !slices.Contains(synonyms[word], other) {
+					synonyms[word] = append(synonyms[word], other)
+				}
+			}
+		}
+	}
+
+	t := s.TypoTolerance
+	return search.IndexSettings{
+		Synonyms:     synonyms,
+		StopWords:    slices.Clone(s.StopWords),
+		RankingRules: slices.Clone(s.RankingRules),
+		TypoTolerance: &search.TypoTolerance{
+			Enabled:             t.Enabled,
+			MinWordSizeOneTypo:  t.MinWordSizeOneTypo,
+			MinWordSizeTwoTypos: t.MinWordSizeTwoTypos,
+			DisableOnWords:      slices.Clone(t.DisableOnWords),
+			DisableOnAttributes: slices.Clone(t.DisableOnAttributes),
+		},
+	}
+}
+
+// normalizeWords trims, lowercases, folds and dedupes words, keeping their
+// order. Folding them like indexed text and queries lets a word written with
+// hamza or tashkeel match the normalized tokens the engine sees.
+func normalizeWords(words []string) []string {
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		w = textnorm.Normalize(strings.ToLower(strings.TrimSpace(w)))
+		if w != "" && !slices.Contains(out, w) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// normalizeGroups normalizes each group and drops those left with a single
+// word, which have nothing to be a synonym of.
+func normalizeGroups(groups [][]string) [][]string {
+	out := make([][]string, 0, len(groups))
+	for _, g := range groups {
+		if g = normalizeWords(g); len(g) > 1 {
+			out = append(out, g)
+		}
+	}
+	return out
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"cms-api/internal/config"
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/infra/search"
+	"cms-api/internal/modules/searchsettings/dto"
+	"cms-api/internal/modules/searchsettings/repo"
+	"cms-api/internal/pkg/apperror"
+)
+
+// countingIndexer records the patches applied to the memory engine.
+type countingIndexer struct {
+	*search.Memory
+	patches []search.IndexSettings
+	down    bool
+}
+
+func (i *countingIndexer) Settings(ctx context.Context, index string) (*search.IndexSettings, error) {
+	if i.down {
+		return nil, errors.New("connection refused")
+	}
+	return i.Memory.Settings(ctx, index)
+}
+
+func (i *countingIndexer) ApplySettings(ctx context.Context, index string, settings search.IndexSettings) error {
+	i.patches = append(i.patches, settings)
+	return i.Memory.ApplySettings(ctx, index, settings)
+}
+
+func newTestService(t *testing.T) (*service, *countingIndexer) {
+	t.Helper()
+
+	indexer := &countingIndexer{Memory: search.NewMemory()}
+	if err := indexer.EnsureIndex(context.Background(), indexName, "id", search.IndexConfig{}); err != nil {
+		t.Fatalf("ensure index: %v", err)
+	}
+	cfg := &config.Config{Search: config.SearchConfig{HealthInterval: time.Second}}
+	svc := New(repo.NewMemory(memdb.New()), indexer, cfg, zap.NewNop()).(*service)
+	return svc, indexer
+}
+
+func TestSettingsService_SyncAppliesDiffOnce(t *testing.T) {
+	svc, indexer := newTestService(t)
+	ctx := context.Background()
+
+	resp, err := svc.Sync(ctx)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if !resp.InSync {
+		t.Fatal("expected in sync")
+	}
+	// Only the seeded synonyms differ from a new index.
+	if len(indexer.patches) != 1 || indexer.patches[0].Synonyms == nil || indexer.patches[0].RankingRules != nil {
+		t.Fatalf("unexpected patches %+v", indexer.patches)
+	}
+
+	got, _ := indexer.Memory.Settings(ctx, indexName)
+	if !slices.Equal(got.Synonyms["بودكاست"], []string{"podcast"}) {
+		t.Fatalf("expected mutual synonyms, got %+v", got.Synonyms)
+	}
+
+	if _, err := svc.Sync(ctx); err != nil {
+		t.Fatalf("second sync: %v", err)
+	}
+	if len(indexer.patches) != 1 {
+		t.Fatalf("expected no patch when in sync, got %d", len(indexer.patches))
+	}
+}
+
+func TestSettingsService_UpdateNormalizesAndPushes(t *testing.T) {
+	svc, indexer := newTestService(t)
+	ctx := context.Background()
+
+	enabled := false
+	resp, err := svc.Update(ctx, &dto.UpdateSettingsRequest{
+		StopWords:     []string{" The ", "the", "في"},
+		RankingRules:  []string{},
+		TypoTolerance: &dto.TypoToleranceRequest{Enabled: &enabled},
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if !slices.Equal(resp.StopWords, []string{"the", "في"}) {
+		t.Fatalf("unexpected stop words %v", resp.StopWords)
+	}
+	if !slices.Equal(resp.RankingRules, search.DefaultRankingRules) {
+		t.Fatalf("expected default ranking rules, got %v", resp.RankingRules)
+	}
+	if len(resp.Synonyms) != 2 {
+		t.Fatalf("expected synonyms unchanged, got %v", resp.Synonyms)
+	}
+	if !resp.InSync {
+		t.Fatal("expected in sync")
+	}
+
+	got, _ := indexer.Memory.Settings(ctx, indexName)
+	if got.TypoTolerance.Enabled || !slices.Equal(got.StopWords, []string{"the", "في"}) {
+		t.Fatalf("settings not pushed: %+v", got)
+	}
+}
+
+func TestSettingsService_UpdateKeepsSettingsWhenIndexDown(t *testing.T) {
+	svc, indexer := newTestService(t)
+	indexer.down = true
+	ctx := context.Background()
+
+	resp, err := svc.Update(ctx, &dto.UpdateSettingsRequest{StopWords: []string{"the"}})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if resp.InSync {
+		t.Fatal("expected out of sync")
+	}
+
+	if _, err := svc.Sync(ctx); !errors.Is(err, apperror.ErrServiceUnavailable) {
+		t.Fatalf("expected service unavailable, got %v", err)
+	}
+
+	indexer.down = false
+	resp, err = svc.Sync(ctx)
+	if err != nil || !resp.InSync || !slices.Equal(resp.StopWords, []string{"the"}) {
+		t.Fatalf("unexpected sync result %+v, %v", resp, err)
+	}
+}
+
+func TestSettingsService_UpdateRejectsTypoSizes(t *testing.T) {
+	svc, _ := newTestService(t)
+
+	one, two := 8, 4
+	_, err := svc.Update(context.Background(), &dto.UpdateSettingsRequest{
+		TypoTolerance: &dto.TypoToleranceRequest{MinWordSizeOneTypo: &one, MinWordSizeTwoTypos: &two},
+	})
+	if !errors.Is(err, apperror.ErrBadRequest) {
+		t.Fatalf("expected bad request, got %v", err)
+	}
+}
+
+func TestSettingsService_UpdateFoldsArabicWords(t *testing.T) {
+	svc, indexer := newTestService(t)
+	ctx := context.Background()
+
+	resp, err := svc.Update(ctx, &dto.UpdateSettingsRequest{
+		Synonyms:  [][]string{{"مدرسة", "مَدْرَسَة", "School"}},
+		StopWords: []string{"إلى", "الى"},
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if len(resp.Synonyms) != 1 || !slices.Equal(resp.Synonyms[0], []string{"مدرسه", "school"}) {
+		t.Fatalf("unexpected synonyms %v", resp.Synonyms)
+	}
+	if !slices.Equal(resp.StopWords, []string{"الي"}) {
+		t.Fatalf("unexpected stop words %v", resp.StopWords)
+	}
+
+	got, _ := indexer.Memory.Settings(ctx, indexName)
+	if !slices.Equal(got.Synonyms["مدرسه"], []string{"school"}) || !slices.Equal(got.StopWords, []string{"الي"}) {
+		t.Fatalf("folded words not pushed: %+v", got)
+	}
+}
@@ -0,0 +1,138 @@
+package dto
+
+import (
+	"database/sql"
+	"time"
+
+	queueentity "cms-api/internal/modules/queue/entity"
+	"cms-api/internal/modules/worker/entity"
+)
+
+func ToReindexRunResponse(run *entity.ReindexRun) *ReindexRunResponse {
+	resp := &ReindexRunResponse{
+		ID:               run.ID,
+		IndexName:        run.IndexName,
+		TargetIndex:      run.TargetIndex,
+		Status:           run.Status,
+		TotalDocuments:   run.TotalDocuments,
+		IndexedDocuments: run.IndexedDocuments,
+		CaughtUp:         run.CaughtUp,
+		Error:            run.ErrorMessage.String,
+		StartedAt:        timePtr(run.StartedAt),
+		SwappedAt:        timePtr(run.SwappedAt),
+		FinishedAt:       timePtr(run.FinishedAt),
+		CreatedAt:        run.CreatedAt,
+	}
+	switch {
+	case run.Status == entity.ReindexStatusCompleted:
+		resp.Progress = 1
+	case run.TotalDocuments > 0:
+		resp.Progress = min(float64(run.IndexedDocuments)/float64(run.TotalDocuments), 1)
+	}
+	return resp
+}
+
+func ToJobResponse(job *queueentity.Job) *JobResponse {
+	var payload entity.IndexJobPayload
+	_ = job.DecodePayload(&payload)
+	return &JobResponse{
+		ID:          job.ID,
+		ProgramID:   payload.ProgramID,
+		Action:      payload.Action,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LastError:   stringPtr(job.LastError),
+		ScheduledAt: job.ScheduledAt,
+		ProcessedAt: timePtr(job.ProcessedAt),
+		LockedBy:    stringPtr(job.LockedBy),
+		LockedUntil: timePtr(job.LockedUntil),
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+}
+
+func ToJobListResponse(jobs []queueentity.Job, nextCursor string, hasNext bool) *JobListResponse {
+	items := make([]*JobResponse, len(jobs))
+	for i := range jobs {
+		items[i] = ToJobResponse(&jobs[i])
+	}
+	return &JobListResponse{Items: items, NextCursor: nextCursor, HasNext: hasNext}
+}
+
+func ToJobBatchResponse(ids []string) *JobBatchResponse {
+	if ids == nil {
+		ids = []string{}
+	}
+	return &JobBatchResponse{IDs: ids, Count: len(ids)}
+}
+
+func ToJobStatsResponse(stats *queueentity.JobStats, window time.Duration) *JobStatsResponse {
+	resp := &JobStatsResponse{
+		Depth: stats.Pending + stats.Processing + stats.Failed,
+		Due:   stats.Due,
+		ByStatus: map[string]int{
+			"pending":    stats.Pending,
+			"processing": stats.Processing,
+			"completed":  stats.Completed,
+			"failed":     stats.Failed,
+			"dead":       stats.Dead,
+		},
+		WindowSeconds: int64(window / time.Second),
+	}
+	if stats.OldestPendingAt.Valid {
+		age := int64(max(stats.CheckedAt.Sub(stats.OldestPendingAt.Time), 0) / time.Second)
+		resp.OldestPendingAgeSeconds = &age
+	}
+	if ended := stats.WindowCompleted + stats.WindowFailed; ended > 0 {
+		resp.FailureRate = float64(stats.WindowFailed) / float64(ended)
+	}
+	return resp
+}
+
+func ToConsistencyRunResponse(run *entity.ConsistencyRun) *ConsistencyRunResponse {
+	resp := &ConsistencyRunResponse{
+		ID:                 run.ID,
+		IndexName:          run.IndexName,
+		Repair:             run.Repair,
+		Status:             run.Status,
+		Programs:           run.Programs,
+		Documents:          run.Documents,
+		RepairJobsEnqueued: run.RepairJobs,
+		Error:              run.ErrorMessage.String,
+		StartedAt:          timePtr(run.StartedAt),
+		FinishedAt:         timePtr(run.FinishedAt),
+		CreatedAt:          run.CreatedAt,
+	}
+	if run.Status == entity.ConsistencyStatusCompleted {
+		resp.Missing = toConsistencyIssues(run.MissingCount, run.MissingIDs)
+		resp.Stale = toConsistencyIssues(run.StaleCount, run.StaleIDs)
+		resp.Orphaned = toConsistencyIssues(run.OrphanedCount, run.OrphanedIDs)
+	}
+	if run.StartedAt.Valid && run.FinishedAt.Valid {
+		ms := run.FinishedAt.Time.Sub(run.StartedAt.Time).Milliseconds()
+		resp.DurationMS = &ms
+	}
+	return resp
+}
+
+func toConsistencyIssues(count int, ids []string) *ConsistencyIssues {
+	if ids == nil {
+		ids = []string{}
+	}
+	return &ConsistencyIssues{Count: count, IDs: ids}
+}
+
+func stringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,44 @@
+package dto
+
+type PathReindexRunID struct {
+	ID string `validate:"required,uuid"`
+}
+
+type PathConsistencyRunID struct {
+	ID string `validate:"required,uuid"`
+}
+
+type PathJobID struct {
+	ID string `validate:"required,uuid"`
+}
+
+type ListJobsRequest struct {
+	Status    string `validate:"omitempty,oneof=pending processing completed failed dead"`
+	Action    string `validate:"omitempty,oneof=upsert delete"`
+	ProgramID string `validate:"omitempty,uuid"`
+	Cursor    string
+	Limit     int `validate:"omitempty,min=1,max=100"`
+}
+
+func NewListJobsRequest(status, action, programID, cursorStr string, limit int) ListJobsRequest {
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return ListJobsRequest{Status: status, Action: action, ProgramID: programID, Cursor: cursorStr, Limit: limit}
+}
+
+// JobBatchRequest selects dead jobs to retry or discard: the listed IDs, or
+// every dead job when All is set.
+type JobBatchRequest struct {
+	IDs []string `json:"ids" validate:"required_without=All,excluded_with=All,max=500,dive,uuid"`
+	All bool     `json:"all"`
+}
+
+// ConsistencyCheckRequest queues a consistency check; with Repair set, index
+// jobs are enqueued for every problem it finds.
+type ConsistencyCheckRequest struct {
+	Repair bool `json:"repair"`
+}
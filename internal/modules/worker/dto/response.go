@@ -0,0 +1,92 @@
+package dto
+
+import "time"
+
+type ReindexRunResponse struct {
+	ID               string `json:"id"`
+	IndexName        string `json:"index_name"`
+	TargetIndex      string `json:"target_index"`
+	Status           string `json:"status"`
+	TotalDocuments   int    `json:"total_documents"`
+	IndexedDocuments int    `json:"indexed_documents"`
+	CaughtUp         int    `json:"caught_up"`
+	// Progress is the share of documents indexed, from 0 to 1.
+	Progress   float64    `json:"progress"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	SwappedAt  *time.Time `json:"swapped_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type JobResponse struct {
+	ID          string     `json:"id"`
+	ProgramID   string     `json:"program_id"`
+	Action      string     `json:"action"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	LastError   *string    `json:"last_error"`
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+	LockedBy    *string    `json:"locked_by,omitempty"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type JobListResponse struct {
+	Items      []*JobResponse `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	HasNext    bool           `json:"has_next"`
+}
+
+// JobBatchResponse lists the jobs a batch retry or discard changed. Jobs
+// that were not dead, or were already covered by an active job, are left
+// out.
+type JobBatchResponse struct {
+	IDs   []string `json:"ids"`
+	Count int      `json:"count"`
+}
+
+type JobStatsResponse struct {
+	// Depth counts jobs not yet done: pending, processing or failed.
+	Depth    int            `json:"depth"`
+	Due      int            `json:"due"`
+	ByStatus map[string]int `json:"by_status"`
+	// OldestPendingAgeSeconds is how long the oldest pending or failed job
+	// has been queued.
+	OldestPendingAgeSeconds *int64 `json:"oldest_pending_age_seconds,omitempty"`
+	// FailureRate is the share of attempts ending within the window that
+	// failed, from 0 to 1.
+	FailureRate   float64 `json:"failure_rate"`
+	WindowSeconds int64   `json:"window_seconds"`
+}
+
+type ConsistencyRunResponse struct {
+	ID        string `json:"id"`
+	IndexName string `json:"index_name"`
+	Repair    bool   `json:"repair"`
+	Status    string `json:"status"`
+	Programs  int    `json:"programs"`
+	Documents int    `json:"documents"`
+	// Missing, Stale and Orphaned are set once the run has completed.
+	Missing  *ConsistencyIssues `json:"missing,omitempty"`
+	Stale    *ConsistencyIssues `json:"stale,omitempty"`
+	Orphaned *ConsistencyIssues `json:"orphaned,omitempty"`
+	// RepairJobsEnqueued is zero unless a repair was requested.
+	RepairJobsEnqueued int        `json:"repair_jobs_enqueued"`
+	Error              string     `json:"error,omitempty"`
+	StartedAt          *time.Time `json:"started_at,omitempty"`
+	FinishedAt         *time.Time `json:"finished_at,omitempty"`
+	// DurationMS is how long a finished run took.
+	DurationMS *int64    `json:"duration_ms,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ConsistencyIssues counts the programs with one kind of problem and lists
+// the first IDs, in order.
+type ConsistencyIssues struct {
+	Count int      `json:"count"`
+	IDs   []string `json:"ids"`
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	ConsistencyStatusPending   = "pending"
+	ConsistencyStatusRunning   = "running"
+	ConsistencyStatusCompleted = "completed"
+	ConsistencyStatusFailed    = "failed"
+)
+
+// ConsistencySampleSize caps the IDs a run keeps per kind of problem.
+const ConsistencySampleSize = 100
+
+// ProgramVersion is the last-modified time of a program, what a
+// consistency check compares index documents against.
+type ProgramVersion struct {
+	ID        string    `db:"id"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// ConsistencyReport is the outcome of comparing an index with Postgres.
+type ConsistencyReport struct {
+	Programs  int
+	Documents int
+	// Missing are programs with no index document, Stale programs whose
+	// document predates their last update, and Orphaned documents with no
+	// live program behind them. Each is sorted.
+	Missing  []string
+	Stale    []string
+	Orphaned []string
+	// RepairJobs counts the index jobs enqueued to fix the above, when a
+	// repair was asked for.
+	RepairJobs int
+}
+
+// ConsistencyRun is a queued or finished check of IndexName. Its ID lists
+// keep the first ConsistencySampleSize IDs of the report's; the counts
+// cover all of them.
+type ConsistencyRun struct {
+	ID            string
+	IndexName     string
+	Repair        bool
+	Status        string
+	Programs      int
+	Documents     int
+	MissingCount  int
+	StaleCount    int
+	OrphanedCount int
+	MissingIDs    []string
+	StaleIDs      []string
+	OrphanedIDs   []string
+	RepairJobs    int
+	ErrorMessage  sql.NullString
+	RequestedBy   sql.NullString
+	StartedAt     sql.NullTime
+	FinishedAt    sql.NullTime
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// SetReport records report on the run.
+func (r *ConsistencyRun) SetReport(report *ConsistencyReport) {
+	sample := func(ids []string) []string {
+		return append([]string{}, ids[:min(len(ids), ConsistencySampleSize)]...)
+	}
+	r.Programs, r.Documents = report.Programs, report.Documents
+	r.MissingCount, r.MissingIDs = len(report.Missing), sample(report.Missing)
+	r.StaleCount, r.StaleIDs = len(report.Stale), sample(report.Stale)
+	r.OrphanedCount, r.OrphanedIDs = len(report.Orphaned), sample(report.Orphaned)
+	r.RepairJobs = report.RepairJobs
+}
@@ -1,22 +1,17 @@
 package entity
 
-import (
-	"database/sql"
-	"time"
+// IndexJobKind is the queue job kind the program triggers enqueue, on the
+// IndexJobQueue, for every change a program's index documents need.
+const (
+	IndexJobKind  = "search.index"
+	IndexJobQueue = "search"
 )
 
-type SearchIndexJob struct {
-	ID           string       `db:"id"`
-	ProgramID    string       `db:"program_id"`
-	Action       string       `db:"action"`
-	Status       string       `db:"status"`
-	Attempts     int          `db:"attempts"`
-	MaxAttempts  int          `db:"max_attempts"`
-	LastError    sql.NullString `db:"last_error"`
-	ScheduledAt  time.Time    `db:"scheduled_at"`
-	ProcessedAt  sql.NullTime `db:"processed_at"`
-	CreatedAt    time.Time    `db:"created_at"`
-	UpdatedAt    time.Time    `db:"updated_at"`
+// IndexJobPayload is the payload of an IndexJobKind job.
+type IndexJobPayload struct {
+	ProgramID string `json:"program_id"`
+	// Action is "upsert" or "delete".
+	Action string `json:"action"`
 }
 
 type ProgramDocument struct {
@@ -32,4 +27,55 @@ type ProgramDocument struct {
 	Thumbnail   string  `json:"thumbnail"`
 	VideoURL    string  `json:"video_url"`
 	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+
+	Transcript []TranscriptCue `json:"transcript,omitempty"`
+
+	// TitleNormalized and DescriptionNormalized hold textnorm.Normalize of
+	// their fields, so queries match regardless of Arabic spelling variants.
+	TitleNormalized       string `json:"title_normalized"`
+	DescriptionNormalized string `json:"description_normalized"`
+
+	// CategoryID and CategorySlug feed the suggestion index only.
+	CategoryID   *int64  `json:"-"`
+	CategorySlug *string `json:"-"`
+}
+
+type TranscriptCue struct {
+	StartMS int64  `json:"start_ms" db:"start_ms"`
+	EndMS   int64  `json:"end_ms" db:"end_ms"`
+	Text    string `json:"text" db:"text"`
+}
+
+type Category struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+	Slug string `db:"slug"`
+}
+
+// SuggestionDocument is an entry in the search-as-you-type index: a
+// published program title or a category name.
+type SuggestionDocument struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"`
+	Text      string  `json:"text"`
+	ProgramID *string `json:"program_id,omitempty"`
+	Slug      *string `json:"slug,omitempty"`
+	Language  *string `json:"language,omitempty"`
+
+	// TextNormalized is textnorm.Normalize(Text).
+	TextNormalized string `json:"text_normalized"`
+}
+
+// TranscriptCueDocument is a cue in the transcript index, which search uses
+// to find where in a program's transcript a query matched.
+type TranscriptCueDocument struct {
+	ID        string `json:"id"`
+	ProgramID string `json:"program_id"`
+	StartMS   int64  `json:"start_ms"`
+	EndMS     int64  `json:"end_ms"`
+	Text      string `json:"text"`
+
+	// TextNormalized is textnorm.Normalize(Text).
+	TextNormalized string `json:"text_normalized"`
 }
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	ReindexStatusPending   = "pending"
+	ReindexStatusRunning   = "running"
+	ReindexStatusCompleted = "completed"
+	ReindexStatusFailed    = "failed"
+)
+
+// ReindexRun is a full rebuild of IndexName into TargetIndex, which is
+// swapped in once it has caught up.
+type ReindexRun struct {
+	ID               string         `db:"id"`
+	IndexName        string         `db:"index_name"`
+	TargetIndex      string         `db:"target_index"`
+	Status           string         `db:"status"`
+	TotalDocuments   int            `db:"total_documents"`
+	IndexedDocuments int            `db:"indexed_documents"`
+	CaughtUp         int            `db:"caught_up"`
+	ErrorMessage     sql.NullString `db:"error_message"`
+	RequestedBy      sql.NullString `db:"requested_by"`
+	StartedAt        sql.NullTime   `db:"started_at"`
+	SwappedAt        sql.NullTime   `db:"swapped_at"`
+	FinishedAt       sql.NullTime   `db:"finished_at"`
+	CreatedAt        time.Time      `db:"created_at"`
+	UpdatedAt        time.Time      `db:"updated_at"`
+}
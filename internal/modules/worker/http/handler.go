@@ -0,0 +1,235 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"cms-api/internal/modules/worker/dto"
+	"cms-api/internal/modules/worker/service"
+	"cms-api/internal/pkg/apperror"
+	"cms-api/internal/pkg/httputil"
+	"cms-api/internal/pkg/validator"
+)
+
+type Handler struct {
+	service service.Service
+	log     *zap.Logger
+}
+
+func NewHandler(service service.Service, log *zap.Logger) *Handler {
+	return &Handler{service: service, log: log}
+}
+
+func (h *Handler) RequestReindex(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.RequestReindex(r.Context())
+	if err != nil {
+		h.log.Error("failed to request reindex", zap.Error(err))
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.Accepted(w, resp)
+}
+
+func (h *Handler) LatestReindex(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.LatestReindexRun(r.Context())
+	if err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) GetReindex(w http.ResponseWriter, r *http.Request) {
+	pathID := dto.PathReindexRunID{ID: chi.URLParam(r, "id")}
+	if err := validator.Validate(pathID); err != nil {
+		httputil.BadRequest(w, "invalid reindex run id")
+		return
+	}
+
+	resp, err := h.service.GetReindexRun(r.Context(), pathID.ID)
+	if err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+
+	req := dto.NewListJobsRequest(q.Get("status"), q.Get("action"), q.Get("program_id"), q.Get("cursor"), limit)
+	if err := validator.Validate(req); err != nil {
+		httputil.ValidationError(w, err)
+		return
+	}
+
+	resp, err := h.service.ListJobs(r.Context(), &req)
+	if err != nil {
+		h.log.Error("failed to list index jobs", zap.Error(err))
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) JobStats(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.JobStats(r.Context())
+	if err != nil {
+		h.log.Error("failed to get index job stats", zap.Error(err))
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
+	pathID := dto.PathJobID{ID: chi.URLParam(r, "id")}
+	if err := validator.Validate(pathID); err != nil {
+		httputil.BadRequest(w, "invalid job id")
+		return
+	}
+
+	resp, err := h.service.GetJob(r.Context(), pathID.ID)
+	if err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	pathID := dto.PathJobID{ID: chi.URLParam(r, "id")}
+	if err := validator.Validate(pathID); err != nil {
+		httputil.BadRequest(w, "invalid job id")
+		return
+	}
+
+	resp, err := h.service.RetryJob(r.Context(), pathID.ID)
+	if err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) DiscardJob(w http.ResponseWriter, r *http.Request) {
+	pathID := dto.PathJobID{ID: chi.URLParam(r, "id")}
+	if err := validator.Validate(pathID); err != nil {
+		httputil.BadRequest(w, "invalid job id")
+		return
+	}
+
+	if err := h.service.DiscardJob(r.Context(), pathID.ID); err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.NoContent(w)
+}
+
+func (h *Handler) RetryJobs(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeJobBatch(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.RetryJobs(r.Context(), req)
+	if err != nil {
+		h.log.Error("failed to retry index jobs", zap.Error(err))
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) DiscardJobs(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeJobBatch(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.DiscardJobs(r.Context(), req)
+	if err != nil {
+		h.log.Error("failed to discard index jobs", zap.Error(err))
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) RequestConsistencyCheck(w http.ResponseWriter, r *http.Request) {
+	var req dto.ConsistencyCheckRequest
+	if err := httputil.DecodeJSON(w, r, &req); err != nil {
+		httputil.BadRequest(w, err.Error())
+		return
+	}
+
+	resp, err := h.service.RequestConsistencyCheck(r.Context(), &req)
+	if err != nil {
+		// A check already queued or running is expected, not a fault.
+		if errors.Is(err, apperror.ErrConflict) {
+			h.log.Warn("consistency check already in progress", zap.Error(err))
+		} else {
+			h.log.Error("failed to request consistency check", zap.Error(err))
+		}
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.Accepted(w, resp)
+}
+
+func (h *Handler) LatestConsistencyCheck(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.LatestConsistencyRun(r.Context())
+	if err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func (h *Handler) GetConsistencyCheck(w http.ResponseWriter, r *http.Request) {
+	pathID := dto.PathConsistencyRunID{ID: chi.URLParam(r, "id")}
+	if err := validator.Validate(pathID); err != nil {
+		httputil.BadRequest(w, "invalid consistency run id")
+		return
+	}
+
+	resp, err := h.service.GetConsistencyRun(r.Context(), pathID.ID)
+	if err != nil {
+		httputil.HandleError(w, r, err)
+		return
+	}
+
+	httputil.OK(w, resp)
+}
+
+func decodeJobBatch(w http.ResponseWriter, r *http.Request) (*dto.JobBatchRequest, bool) {
+	var req dto.JobBatchRequest
+	if err := httputil.DecodeJSON(w, r, &req); err != nil {
+		httputil.BadRequest(w, err.Error())
+		return nil, false
+	}
+
+	if err := validator.Validate(req); err != nil {
+		httputil.ValidationError(w, err)
+		return nil, false
+	}
+
+	return &req, true
+}
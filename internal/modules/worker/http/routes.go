@@ -0,0 +1,40 @@
+package http
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"cms-api/internal/transport/http/middleware"
+)
+
+func RegisterRoutes(r *chi.Mux, auth *middleware.AuthMiddleware, h *Handler) {
+	r.Route("/api/v1/search/reindex", func(r chi.Router) {
+		r.Use(auth.Middleware)
+		r.Use(middleware.RequireRole("admin"))
+
+		r.Post("/", h.RequestReindex)
+		r.Get("/", h.LatestReindex)
+		r.Get("/{id}", h.GetReindex)
+	})
+
+	r.Route("/api/v1/search/jobs", func(r chi.Router) {
+		r.Use(auth.Middleware)
+		r.Use(middleware.RequireRole("admin"))
+
+		r.Get("/", h.ListJobs)
+		r.Get("/stats", h.JobStats)
+		r.Post("/retry", h.RetryJobs)
+		r.Post("/discard", h.DiscardJobs)
+		r.Get("/{id}", h.GetJob)
+		r.Post("/{id}/retry", h.RetryJob)
+		r.Delete("/{id}", h.DiscardJob)
+	})
+
+	r.Route("/api/v1/search/consistency", func(r chi.Router) {
+		r.Use(auth.Middleware)
+		r.Use(middleware.RequireRole("admin"))
+
+		r.Post("/", h.RequestConsistencyCheck)
+		r.Get("/", h.LatestConsistencyCheck)
+		r.Get("/{id}", h.GetConsistencyCheck)
+	})
+}
@@ -6,6 +6,9 @@ import (
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 
+	"cms-api/internal/config"
+	queueservice "cms-api/internal/modules/queue/service"
+	workerhttp "cms-api/internal/modules/worker/http"
 	"cms-api/internal/modules/worker/repo"
 	"cms-api/internal/modules/worker/service"
 )
@@ -13,17 +16,35 @@ import (
 var Module = fx.Module("worker",
 	fx.Provide(repo.New),
 	fx.Provide(service.New),
+	fx.Provide(
+		fx.Annotate(
+			indexHandler,
+			fx.ResultTags(`group:"job_handlers"`),
+		),
+	),
+	fx.Provide(workerhttp.NewHandler),
+	fx.Invoke(workerhttp.RegisterRoutes),
 	fx.Invoke(startWorker),
 )
 
-func startWorker(lc fx.Lifecycle, svc service.Service, log *zap.Logger) {
+// indexHandler hands search index jobs to the worker.
+func indexHandler(svc service.Service) queueservice.Handler {
+	return svc
+}
+
+func startWorker(lc fx.Lifecycle, svc service.Service, cfg *config.Config, log *zap.Logger) {
 	var cancel context.CancelFunc
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			if err := svc.EnsureIndex(ctx); err != nil {
-				log.Error("Failed to ensure Meilisearch index", zap.Error(err))
-				return err
+				// With a fallback configured the app keeps serving search and
+				// the worker retries the index setup on every poll.
+				if cfg.Search.Backend == config.SearchBackendMeilisearch {
+					log.Error("Failed to ensure Meilisearch index", zap.Error(err))
+					return err
+				}
+				log.Warn("Meilisearch index unavailable, worker will retry", zap.Error(err))
 			}
 
 			var workerCtx context.Context
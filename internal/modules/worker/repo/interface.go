@@ -8,9 +8,42 @@ import (
 )
 
 type Repository interface {
-	ClaimPendingJobs(ctx context.Context, batchSize int) ([]entity.SearchIndexJob, error)
-	MarkCompleted(ctx context.Context, jobID string) error
-	MarkFailed(ctx context.Context, jobID string, errMsg string, nextSchedule time.Time) error
-	MarkDead(ctx context.Context, jobID string, errMsg string) error
 	GetProgramForIndex(ctx context.Context, programID string) (*entity.ProgramDocument, error)
+	// GetProgramsForIndex loads the given programs in one round trip. Missing
+	// and deleted programs are left out rather than reported as errors.
+	GetProgramsForIndex(ctx context.Context, programIDs []string) ([]*entity.ProgramDocument, error)
+	ListCategories(ctx context.Context) ([]entity.Category, error)
+
+	// CountProgramsForIndex counts the programs a full reindex covers.
+	CountProgramsForIndex(ctx context.Context) (int, error)
+	// ListProgramsForIndex pages through non-deleted programs by ID, starting
+	// after afterID ("" for the first page).
+	ListProgramsForIndex(ctx context.Context, afterID string, limit int) ([]*entity.ProgramDocument, error)
+	// ListProgramVersions pages through the IDs and update times of the
+	// programs ListProgramsForIndex returns, without loading their documents.
+	ListProgramVersions(ctx context.Context, afterID string, limit int) ([]entity.ProgramVersion, error)
+	// ListChangedProgramIDs returns the programs with index jobs enqueued or
+	// processed since since, and the database time to pass as since next.
+	ListChangedProgramIDs(ctx context.Context, since time.Time) ([]string, time.Time, error)
+
+	// CreateReindexRun returns apperror.ErrConflict while another run for the
+	// same index is pending or running.
+	CreateReindexRun(ctx context.Context, run *entity.ReindexRun) error
+	// ClaimReindexRun starts the oldest pending run, or takes over a running
+	// one not updated for staleAfter. It returns nil when there is none.
+	ClaimReindexRun(ctx context.Context, staleAfter time.Duration) (*entity.ReindexRun, error)
+	UpdateReindexRun(ctx context.Context, run *entity.ReindexRun) error
+	GetReindexRun(ctx context.Context, id string) (*entity.ReindexRun, error)
+	GetLatestReindexRun(ctx context.Context, indexName string) (*entity.ReindexRun, error)
+
+	// CreateConsistencyRun returns apperror.ErrConflict while another run for
+	// the same index is pending or running.
+	CreateConsistencyRun(ctx context.Context, run *entity.ConsistencyRun) error
+	// ClaimConsistencyRun starts the oldest pending run, or takes over a
+	// running one not updated for staleAfter. It returns nil when there is
+	// none.
+	ClaimConsistencyRun(ctx context.Context, staleAfter time.Duration) (*entity.ConsistencyRun, error)
+	UpdateConsistencyRun(ctx context.Context, run *entity.ConsistencyRun) error
+	GetConsistencyRun(ctx context.Context, id string) (*entity.ConsistencyRun, error)
+	GetLatestConsistencyRun(ctx context.Context, indexName string) (*entity.ConsistencyRun, error)
 }
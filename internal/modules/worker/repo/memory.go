@@ -0,0 +1,475 @@
+package repo
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/modules/worker/entity"
+	"cms-api/internal/pkg/apperror"
+)
+
+type memoryRepository struct {
+	store *memdb.Store
+}
+
+// NewMemory returns a Repository that loads index documents and keeps
+// reindex and consistency runs in store, so the hermetic app's worker
+// indexes without Postgres.
+func NewMemory(store *memdb.Store) Repository {
+	return &memoryRepository{store: store}
+}
+
+// GetProgramForIndex returns sql.ErrNoRows for a missing or deleted program,
+// as the SQL repository does.
+func (r *memoryRepository) GetProgramForIndex(ctx context.Context, programID string) (*entity.ProgramDocument, error) {
+	var doc *entity.ProgramDocument
+	err := r.store.Read(func(t *memdb.Tables) error {
+		row, ok := t.Programs[programID]
+		if !ok || row.DeletedAt.Valid {
+			return sql.ErrNoRows
+		}
+		doc = toDocument(t, row)
+		return nil
+	})
+	return doc, err
+}
+
+func toDocument(t *memdb.Tables, row *memdb.Program) *entity.ProgramDocument {
+	doc := &entity.ProgramDocument{
+		ID:          row.ID,
+		Title:       row.Title,
+		Description: row.Description,
+		ProgramType: row.ProgramType,
+		Status:      row.Status,
+		Thumbnail:   row.Thumbnail,
+		VideoURL:    row.VideoURL,
+		CreatedAt:   row.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   row.UpdatedAt.Format(time.RFC3339),
+	}
+	if row.Duration.Valid {
+		d := row.Duration.String
+		doc.Duration = &d
+	}
+	if row.PublishedAt.Valid {
+		p := row.PublishedAt.Time.Format(time.RFC3339Nano)
+		doc.PublishedAt = &p
+	}
+	if c := t.CategoryOf(row); c != nil {
+		name, id, slug := c.Name, c.ID, c.Slug
+		doc.Category, doc.CategoryID, doc.CategorySlug = &name, &id, &slug
+	}
+	if l := t.LanguageOf(row); l != nil {
+		code := l.Code
+		doc.Language = &code
+	}
+
+	cues := slices.Clone(t.Cues[row.ID])
+	slices.SortFunc(cues, func(a, b *memdb.TranscriptCue) int { return cmp.Compare(a.Position, b.Position) })
+	for _, c := range cues {
+		doc.Transcript = append(doc.Transcript, entity.TranscriptCue{StartMS: c.StartMS, EndMS: c.EndMS, Text: c.Text})
+	}
+	return doc
+}
+
+func (r *memoryRepository) ListCategories(ctx context.Context) ([]entity.Category, error) {
+	var categories []entity.Category
+	err := r.store.Read(func(t *memdb.Tables) error {
+		for _, c := range t.Categories {
+			categories = append(categories, entity.Category{ID: c.ID, Name: c.Name, Slug: c.Slug})
+		}
+		slices.SortFunc(categories, func(a, b entity.Category) int { return cmp.Compare(a.ID, b.ID) })
+		return nil
+	})
+	return categories, err
+}
+
+func (r *memoryRepository) CountProgramsForIndex(ctx context.Context) (int, error) {
+	var count int
+	err := r.store.Read(func(t *memdb.Tables) error {
+		for _, p := range t.Programs {
+			if !p.DeletedAt.Valid {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+func (r *memoryRepository) ListProgramsForIndex(ctx context.Context, afterID string, limit int) ([]*entity.ProgramDocument, error) {
+	var docs []*entity.ProgramDocument
+	err := r.store.Read(func(t *memdb.Tables) error {
+		var rows []*memdb.Program
+		for _, p := range t.Programs {
+			if !p.DeletedAt.Valid && p.ID > afterID {
+				rows = append(rows, p)
+			}
+		}
+		slices.SortFunc(rows, func(a, b *memdb.Program) int { return cmp.Compare(a.ID, b.ID) })
+
+		for _, p := range rows[:min(limit, len(rows))] {
+			docs = append(docs, toDocument(t, p))
+		}
+		return nil
+	})
+	return docs, err
+}
+
+func (r *memoryRepository) ListProgramVersions(ctx context.Context, afterID string, limit int) ([]entity.ProgramVersion, error) {
+	var versions []entity.ProgramVersion
+	err := r.store.Read(func(t *memdb.Tables) error {
+		for _, p := range t.Programs {
+			if !p.DeletedAt.Valid && p.ID > afterID {
+				versions = append(versions, entity.ProgramVersion{ID: p.ID, UpdatedAt: p.UpdatedAt})
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(versions, func(a, b entity.ProgramVersion) int { return cmp.Compare(a.ID, b.ID) })
+	return versions[:min(limit, len(versions))], err
+}
+
+func (r *memoryRepository) GetProgramsForIndex(ctx context.Context, programIDs []string) ([]*entity.ProgramDocument, error) {
+	var docs []*entity.ProgramDocument
+	err := r.store.Read(func(t *memdb.Tables) error {
+		var rows []*memdb.Program
+		for _, id := range programIDs {
+			if p, ok := t.Programs[id]; ok && !p.DeletedAt.Valid && !slices.Contains(rows, p) {
+				rows = append(rows, p)
+			}
+		}
+		slices.SortFunc(rows, func(a, b *memdb.Program) int { return cmp.Compare(a.ID, b.ID) })
+
+		for _, p := range rows {
+			docs = append(docs, toDocument(t, p))
+		}
+		return nil
+	})
+	return docs, err
+}
+
+func (r *memoryRepository) ListChangedProgramIDs(ctx context.Context, since time.Time) ([]string, time.Time, error) {
+	var ids []string
+	var checkedAt time.Time
+	// Write only for t.Now; nothing is modified.
+	err := r.store.Write(func(t *memdb.Tables) error {
+		checkedAt = t.Now
+		for _, j := range t.Jobs {
+			if j.Kind != "search.index" || j.UpdatedAt.Before(since) {
+				continue
+			}
+			var payload struct {
+				ProgramID string `json:"program_id"`
+			}
+			if json.Unmarshal(j.Payload, &payload) == nil && !slices.Contains(ids, payload.ProgramID) {
+				ids = append(ids, payload.ProgramID)
+			}
+		}
+		return nil
+	})
+	slices.Sort(ids)
+	return ids, checkedAt, err
+}
+
+func (r *memoryRepository) CreateReindexRun(ctx context.Context, run *entity.ReindexRun) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		for _, existing := range t.ReindexRuns {
+			if existing.IndexName == run.IndexName && (existing.Status == entity.ReindexStatusPending || existing.Status == entity.ReindexStatusRunning) {
+				return apperror.ErrConflict
+			}
+		}
+		if _, ok := t.ReindexRuns[run.ID]; ok {
+			return memdb.ErrDuplicateKey
+		}
+
+		run.Status = entity.ReindexStatusPending
+		run.CreatedAt, run.UpdatedAt = t.Now, t.Now
+		row := fromReindexRun(run)
+		t.ReindexRuns[run.ID] = &row
+		return nil
+	})
+}
+
+func (r *memoryRepository) ClaimReindexRun(ctx context.Context, staleAfter time.Duration) (*entity.ReindexRun, error) {
+	var run *entity.ReindexRun
+	err := r.store.Write(func(t *memdb.Tables) error {
+		var claimable []*memdb.ReindexRun
+		for _, rr := range t.ReindexRuns {
+			stale := rr.Status == entity.ReindexStatusRunning && rr.UpdatedAt.Before(t.Now.Add(-staleAfter))
+			if rr.Status == entity.ReindexStatusPending || stale {
+				claimable = append(claimable, rr)
+			}
+		}
+		if len(claimable) == 0 {
+			return nil
+		}
+		rr := slices.MinFunc(claimable, func(a, b *memdb.ReindexRun) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+		rr.Status = entity.ReindexStatusRunning
+		rr.TotalDocuments, rr.IndexedDocuments, rr.CaughtUp = 0, 0, 0
+		rr.ErrorMessage = sql.NullString{}
+		rr.StartedAt = sql.NullTime{Time: t.Now, Valid: true}
+		rr.SwappedAt, rr.FinishedAt = sql.NullTime{}, sql.NullTime{}
+		rr.UpdatedAt = t.Now
+		claimed := toReindexRun(rr)
+		run = &claimed
+		return nil
+	})
+	return run, err
+}
+
+func (r *memoryRepository) UpdateReindexRun(ctx context.Context, run *entity.ReindexRun) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		rr, ok := t.ReindexRuns[run.ID]
+		if !ok {
+			return nil
+		}
+		rr.Status = run.Status
+		rr.TotalDocuments = run.TotalDocuments
+		rr.IndexedDocuments = run.IndexedDocuments
+		rr.CaughtUp = run.CaughtUp
+		rr.ErrorMessage = run.ErrorMessage
+		rr.SwappedAt = run.SwappedAt
+		rr.FinishedAt = run.FinishedAt
+		rr.UpdatedAt = t.Now
+		return nil
+	})
+}
+
+func (r *memoryRepository) GetReindexRun(ctx context.Context, id string) (*entity.ReindexRun, error) {
+	var run entity.ReindexRun
+	err := r.store.Read(func(t *memdb.Tables) error {
+		rr, ok := t.ReindexRuns[id]
+		if !ok {
+			return apperror.ErrNotFound
+		}
+		run = toReindexRun(rr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *memoryRepository) GetLatestReindexRun(ctx context.Context, indexName string) (*entity.ReindexRun, error) {
+	var run entity.ReindexRun
+	err := r.store.Read(func(t *memdb.Tables) error {
+		var latest *memdb.ReindexRun
+		for _, rr := range t.ReindexRuns {
+			if rr.IndexName == indexName && (latest == nil || rr.CreatedAt.After(latest.CreatedAt)) {
+				latest = rr
+			}
+		}
+		if latest == nil {
+			return apperror.ErrNotFound
+		}
+		run = toReindexRun(latest)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func toReindexRun(rr *memdb.ReindexRun) entity.ReindexRun {
+	return entity.ReindexRun{
+		ID:               rr.ID,
+		IndexName:        rr.IndexName,
+		TargetIndex:      rr.TargetIndex,
+		Status:           rr.Status,
+		TotalDocuments:   rr.TotalDocuments,
+		IndexedDocuments: rr.IndexedDocuments,
+		CaughtUp:         rr.CaughtUp,
+		ErrorMessage:     rr.ErrorMessage,
+		RequestedBy:      rr.RequestedBy,
+		StartedAt:        rr.StartedAt,
+		SwappedAt:        rr.SwappedAt,
+		FinishedAt:       rr.FinishedAt,
+		CreatedAt:        rr.CreatedAt,
+		UpdatedAt:        rr.UpdatedAt,
+	}
+}
+
+func fromReindexRun(run *entity.ReindexRun) memdb.ReindexRun {
+	return memdb.ReindexRun{
+		ID:               run.ID,
+		IndexName:        run.IndexName,
+		TargetIndex:      run.TargetIndex,
+		Status:           run.Status,
+		TotalDocuments:   run.TotalDocuments,
+		IndexedDocuments: run.IndexedDocuments,
+		CaughtUp:         run.CaughtUp,
+		ErrorMessage:     run.ErrorMessage,
+		RequestedBy:      run.RequestedBy,
+		StartedAt:        run.StartedAt,
+		SwappedAt:        run.SwappedAt,
+		FinishedAt:       run.FinishedAt,
+		CreatedAt:        run.CreatedAt,
+		UpdatedAt:        run.UpdatedAt,
+	}
+}
+
+func (r *memoryRepository) CreateConsistencyRun(ctx context.Context, run *entity.ConsistencyRun) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		for _, existing := range t.ConsistencyRuns {
+			if existing.IndexName == run.IndexName && (existing.Status == entity.ConsistencyStatusPending || existing.Status == entity.ConsistencyStatusRunning) {
+				return apperror.ErrConflict
+			}
+		}
+		if _, ok := t.ConsistencyRuns[run.ID]; ok {
+			return memdb.ErrDuplicateKey
+		}
+
+		run.Status = entity.ConsistencyStatusPending
+		run.CreatedAt, run.UpdatedAt = t.Now, t.Now
+		row := fromConsistencyRun(run)
+		t.ConsistencyRuns[run.ID] = &row
+		return nil
+	})
+}
+
+func (r *memoryRepository) ClaimConsistencyRun(ctx context.Context, staleAfter time.Duration) (*entity.ConsistencyRun, error) {
+	var run *entity.ConsistencyRun
+	err := r.store.Write(func(t *memdb.Tables) error {
+		var claimable []*memdb.ConsistencyRun
+		for _, cr := range t.ConsistencyRuns {
+			stale := cr.Status == entity.ConsistencyStatusRunning && cr.UpdatedAt.Before(t.Now.Add(-staleAfter))
+			if cr.Status == entity.ConsistencyStatusPending || stale {
+				claimable = append(claimable, cr)
+			}
+		}
+		if len(claimable) == 0 {
+			return nil
+		}
+		cr := slices.MinFunc(claimable, func(a, b *memdb.ConsistencyRun) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+		cr.Status = entity.ConsistencyStatusRunning
+		cr.Programs, cr.Documents = 0, 0
+		cr.MissingCount, cr.StaleCount, cr.OrphanedCount = 0, 0, 0
+		cr.MissingIDs, cr.StaleIDs, cr.OrphanedIDs = nil, nil, nil
+		cr.RepairJobs = 0
+		cr.ErrorMessage = sql.NullString{}
+		cr.StartedAt = sql.NullTime{Time: t.Now, Valid: true}
+		cr.FinishedAt = sql.NullTime{}
+		cr.UpdatedAt = t.Now
+		claimed := toConsistencyRun(cr)
+		run = &claimed
+		return nil
+	})
+	return run, err
+}
+
+func (r *memoryRepository) UpdateConsistencyRun(ctx context.Context, run *entity.ConsistencyRun) error {
+	return r.store.Write(func(t *memdb.Tables) error {
+		cr, ok := t.ConsistencyRuns[run.ID]
+		if !ok {
+			return nil
+		}
+		cr.Status = run.Status
+		cr.Programs = run.Programs
+		cr.Documents = run.Documents
+		cr.MissingCount = run.MissingCount
+		cr.StaleCount = run.StaleCount
+		cr.OrphanedCount = run.OrphanedCount
+		cr.MissingIDs = slices.Clone(run.MissingIDs)
+		cr.StaleIDs = slices.Clone(run.StaleIDs)
+		cr.OrphanedIDs = slices.Clone(run.OrphanedIDs)
+		cr.RepairJobs = run.RepairJobs
+		cr.ErrorMessage = run.ErrorMessage
+		cr.FinishedAt = run.FinishedAt
+		cr.UpdatedAt = t.Now
+		return nil
+	})
+}
+
+func (r *memoryRepository) GetConsistencyRun(ctx context.Context, id string) (*entity.ConsistencyRun, error) {
+	var run entity.ConsistencyRun
+	err := r.store.Read(func(t *memdb.Tables) error {
+		cr, ok := t.ConsistencyRuns[id]
+		if !ok {
+			return apperror.ErrNotFound
+		}
+		run = toConsistencyRun(cr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *memoryRepository) GetLatestConsistencyRun(ctx context.Context, indexName string) (*entity.ConsistencyRun, error) {
+	var run entity.ConsistencyRun
+	err := r.store.Read(func(t *memdb.Tables) error {
+		var latest *memdb.ConsistencyRun
+		for _, cr := range t.ConsistencyRuns {
+			if cr.IndexName == indexName && (latest == nil || cr.CreatedAt.After(latest.CreatedAt)) {
+				latest = cr
+			}
+		}
+		if latest == nil {
+			return apperror.ErrNotFound
+		}
+		run = toConsistencyRun(latest)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func toConsistencyRun(cr *memdb.ConsistencyRun) entity.ConsistencyRun {
+	return entity.ConsistencyRun{
+		ID:            cr.ID,
+		IndexName:     cr.IndexName,
+		Repair:        cr.Repair,
+		Status:        cr.Status,
+		Programs:      cr.Programs,
+		Documents:     cr.Documents,
+		MissingCount:  cr.MissingCount,
+		StaleCount:    cr.StaleCount,
+		OrphanedCount: cr.OrphanedCount,
+		MissingIDs:    slices.Clone(cr.MissingIDs),
+		StaleIDs:      slices.Clone(cr.StaleIDs),
+		OrphanedIDs:   slices.Clone(cr.OrphanedIDs),
+		RepairJobs:    cr.RepairJobs,
+		ErrorMessage:  cr.ErrorMessage,
+		RequestedBy:   cr.RequestedBy,
+		StartedAt:     cr.StartedAt,
+		FinishedAt:    cr.FinishedAt,
+		CreatedAt:     cr.CreatedAt,
+		UpdatedAt:     cr.UpdatedAt,
+	}
+}
+
+func fromConsistencyRun(run *entity.ConsistencyRun) memdb.ConsistencyRun {
+	return memdb.ConsistencyRun{
+		ID:            run.ID,
+		IndexName:     run.IndexName,
+		Repair:        run.Repair,
+		Status:        run.Status,
+		Programs:      run.Programs,
+		Documents:     run.Documents,
+		MissingCount:  run.MissingCount,
+		StaleCount:    run.StaleCount,
+		OrphanedCount: run.OrphanedCount,
+		MissingIDs:    slices.Clone(run.MissingIDs),
+		StaleIDs:      slices.Clone(run.StaleIDs),
+		OrphanedIDs:   slices.Clone(run.OrphanedIDs),
+		RepairJobs:    run.RepairJobs,
+		ErrorMessage:  run.ErrorMessage,
+		RequestedBy:   run.RequestedBy,
+		StartedAt:     run.StartedAt,
+		FinishedAt:    run.FinishedAt,
+		CreatedAt:     run.CreatedAt,
+		UpdatedAt:     run.UpdatedAt,
+	}
+}
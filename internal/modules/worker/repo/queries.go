@@ -1,49 +1,47 @@
 package repo
 
-const queryClaimPendingJobs = `
-	WITH claimable AS (
-		SELECT id
-		FROM search_index_jobs
-		WHERE status IN ('pending', 'failed')
-		  AND scheduled_at <= NOW()
-		ORDER BY scheduled_at ASC
-		LIMIT $1
-		FOR UPDATE SKIP LOCKED
-	)
-	UPDATE search_index_jobs j
-	SET status = 'processing', updated_at = NOW()
-	FROM claimable c
-	WHERE j.id = c.id
-	RETURNING j.id, j.program_id, j.action, j.status, j.attempts, j.max_attempts,
-	          j.last_error, j.scheduled_at, j.processed_at, j.created_at, j.updated_at
+const queryGetProgramForIndex = `
+	SELECT p.id,
+	       p.title,
+	       p.description,
+	       p.program_type,
+	       p.status,
+	       p.duration::TEXT AS duration,
+	       p.published_at,
+	       c.name AS category,
+	       l.code AS language,
+	       p.thumbnail,
+	       p.video_url,
+	       p.created_at,
+	       p.updated_at,
+	       c.id AS category_id,
+	       c.slug AS category_slug
+	FROM programs p
+	LEFT JOIN categories c ON c.id = p.category_id
+	LEFT JOIN languages l ON l.id = p.language_id
+	WHERE p.id = $1 AND p.deleted_at IS NULL
 `
 
-const queryMarkCompleted = `
-	UPDATE search_index_jobs
-	SET status = 'completed', processed_at = NOW(), updated_at = NOW()
-	WHERE id = $1
+const queryListTranscriptCues = `
+	SELECT start_ms, end_ms, text
+	FROM program_transcript_cues
+	WHERE program_id = $1
+	ORDER BY position ASC
 `
 
-const queryMarkFailed = `
-	UPDATE search_index_jobs
-	SET status = 'failed',
-	    attempts = attempts + 1,
-	    last_error = $2,
-	    scheduled_at = $3,
-	    updated_at = NOW()
-	WHERE id = $1
+const queryListCategories = `
+	SELECT id, name, slug
+	FROM categories
+	ORDER BY id ASC
 `
 
-const queryMarkDead = `
-	UPDATE search_index_jobs
-	SET status = 'dead',
-	    attempts = attempts + 1,
-	    last_error = $2,
-	    updated_at = NOW()
-	WHERE id = $1
+const queryCountProgramsForIndex = `
+	SELECT COUNT(*)
+	FROM programs
+	WHERE deleted_at IS NULL
 `
 
-const queryGetProgramForIndex = `
+const queryListProgramsForIndex = `
 	SELECT p.id,
 	       p.title,
 	       p.description,
@@ -55,9 +53,223 @@ const queryGetProgramForIndex = `
 	       l.code AS language,
 	       p.thumbnail,
 	       p.video_url,
-	       p.created_at
+	       p.created_at,
+	       p.updated_at,
+	       c.id AS category_id,
+	       c.slug AS category_slug
 	FROM programs p
 	LEFT JOIN categories c ON c.id = p.category_id
 	LEFT JOIN languages l ON l.id = p.language_id
-	WHERE p.id = $1 AND p.deleted_at IS NULL
+	WHERE p.deleted_at IS NULL AND p.id > $1
+	ORDER BY p.id ASC
+	LIMIT $2
+`
+
+const queryGetProgramsForIndex = `
+	SELECT p.id,
+	       p.title,
+	       p.description,
+	       p.program_type,
+	       p.status,
+	       p.duration::TEXT AS duration,
+	       p.published_at,
+	       c.name AS category,
+	       l.code AS language,
+	       p.thumbnail,
+	       p.video_url,
+	       p.created_at,
+	       p.updated_at,
+	       c.id AS category_id,
+	       c.slug AS category_slug
+	FROM programs p
+	LEFT JOIN categories c ON c.id = p.category_id
+	LEFT JOIN languages l ON l.id = p.language_id
+	WHERE p.id = ANY($1::UUID[]) AND p.deleted_at IS NULL
+	ORDER BY p.id ASC
+`
+
+const queryListProgramVersions = `
+	SELECT id, updated_at
+	FROM programs
+	WHERE deleted_at IS NULL AND id > $1
+	ORDER BY id ASC
+	LIMIT $2
+`
+
+const queryListTranscriptCuesForPrograms = `
+	SELECT program_id, start_ms, end_ms, text
+	FROM program_transcript_cues
+	WHERE program_id = ANY($1::UUID[])
+	ORDER BY program_id ASC, position ASC
+`
+
+// queryListChangedProgramIDs returns the programs whose index jobs were
+// enqueued or processed since $1, and the database time it was taken at.
+const queryListChangedProgramIDs = `
+	SELECT NOW() AS checked_at,
+	       COALESCE(array_agg(DISTINCT payload->>'program_id'), '{}') AS program_ids
+	FROM jobs
+	WHERE kind = 'search.index' AND updated_at >= $1
+`
+
+const reindexRunColumns = `
+	id, index_name, target_index, status, total_documents, indexed_documents,
+	caught_up, error_message, requested_by, started_at, swapped_at, finished_at,
+	created_at, updated_at
+`
+
+// queryCreateReindexRun inserts nothing while a run for the same index is
+// pending or running.
+const queryCreateReindexRun = `
+	INSERT INTO search_reindex_runs (id, index_name, target_index, requested_by)
+	SELECT $1, $2, $3, $4
+	WHERE NOT EXISTS (
+		SELECT 1 FROM search_reindex_runs
+		WHERE index_name = $2 AND status IN ('pending', 'running')
+	)
+	ON CONFLICT DO NOTHING
+	RETURNING ` + reindexRunColumns
+
+// queryClaimReindexRun takes the oldest pending run, or a running one whose
+// worker stopped reporting progress. Either starts over, so whatever the
+// previous attempt recorded is cleared.
+const queryClaimReindexRun = `
+	WITH claimable AS (
+		SELECT id
+		FROM search_reindex_runs
+		WHERE status = 'pending'
+		   OR (status = 'running' AND updated_at < NOW() - make_interval(secs => $1))
+		ORDER BY created_at ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+	UPDATE search_reindex_runs r
+	SET status = 'running',
+	    total_documents = 0,
+	    indexed_documents = 0,
+	    caught_up = 0,
+	    error_message = NULL,
+	    started_at = NOW(),
+	    swapped_at = NULL,
+	    finished_at = NULL,
+	    updated_at = NOW()
+	FROM claimable c
+	WHERE r.id = c.id
+	RETURNING r.id, r.index_name, r.target_index, r.status, r.total_documents,
+	          r.indexed_documents, r.caught_up, r.error_message, r.requested_by,
+	          r.started_at, r.swapped_at, r.finished_at, r.created_at, r.updated_at
+`
+
+const queryUpdateReindexRun = `
+	UPDATE search_reindex_runs
+	SET status = $2,
+	    total_documents = $3,
+	    indexed_documents = $4,
+	    caught_up = $5,
+	    error_message = $6,
+	    swapped_at = $7,
+	    finished_at = $8,
+	    updated_at = NOW()
+	WHERE id = $1
+`
+
+const queryGetReindexRun = `
+	SELECT ` + reindexRunColumns + `
+	FROM search_reindex_runs
+	WHERE id = $1
+`
+
+const queryGetLatestReindexRun = `
+	SELECT ` + reindexRunColumns + `
+	FROM search_reindex_runs
+	WHERE index_name = $1
+	ORDER BY created_at DESC
+	LIMIT 1
+`
+
+const consistencyRunColumns = `
+	id, index_name, repair, status, programs, documents, missing_count,
+	stale_count, orphaned_count, missing_ids, stale_ids, orphaned_ids,
+	repair_jobs, error_message, requested_by, started_at, finished_at,
+	created_at, updated_at
+`
+
+// queryCreateConsistencyRun inserts nothing while a run for the same index
+// is pending or running.
+const queryCreateConsistencyRun = `
+	INSERT INTO search_consistency_runs (id, index_name, repair, requested_by)
+	SELECT $1, $2, $3, $4
+	WHERE NOT EXISTS (
+		SELECT 1 FROM search_consistency_runs
+		WHERE index_name = $2 AND status IN ('pending', 'running')
+	)
+	ON CONFLICT DO NOTHING
+	RETURNING ` + consistencyRunColumns
+
+// queryClaimConsistencyRun takes the oldest pending run, or a running one
+// whose worker stopped reporting progress. Either starts over, so whatever
+// the previous attempt recorded is cleared.
+const queryClaimConsistencyRun = `
+	WITH claimable AS (
+		SELECT id
+		FROM search_consistency_runs
+		WHERE status = 'pending'
+		   OR (status = 'running' AND updated_at < NOW() - make_interval(secs => $1))
+		ORDER BY created_at ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+	UPDATE search_consistency_runs r
+	SET status = 'running',
+	    programs = 0,
+	    documents = 0,
+	    missing_count = 0,
+	    stale_count = 0,
+	    orphaned_count = 0,
+	    missing_ids = '{}',
+	    stale_ids = '{}',
+	    orphaned_ids = '{}',
+	    repair_jobs = 0,
+	    error_message = NULL,
+	    started_at = NOW(),
+	    finished_at = NULL,
+	    updated_at = NOW()
+	FROM claimable c
+	WHERE r.id = c.id
+	RETURNING r.id, r.index_name, r.repair, r.status, r.programs, r.documents,
+	          r.missing_count, r.stale_count, r.orphaned_count, r.missing_ids,
+	          r.stale_ids, r.orphaned_ids, r.repair_jobs, r.error_message,
+	          r.requested_by, r.started_at, r.finished_at, r.created_at, r.updated_at
+`
+
+const queryUpdateConsistencyRun = `
+	UPDATE search_consistency_runs
+	SET status = $2,
+	    programs = $3,
+	    documents = $4,
+	    missing_count = $5,
+	    stale_count = $6,
+	    orphaned_count = $7,
+	    missing_ids = $8,
+	    stale_ids = $9,
+	    orphaned_ids = $10,
+	    repair_jobs = $11,
+	    error_message = $12,
+	    finished_at = $13,
+	    updated_at = NOW()
+	WHERE id = $1
+`
+
+const queryGetConsistencyRun = `
+	SELECT ` + consistencyRunColumns + `
+	FROM search_consistency_runs
+	WHERE id = $1
+`
+
+const queryGetLatestConsistencyRun = `
+	SELECT ` + consistencyRunColumns + `
+	FROM search_consistency_runs
+	WHERE index_name = $1
+	ORDER BY created_at DESC
+	LIMIT 1
 `
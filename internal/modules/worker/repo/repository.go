@@ -3,11 +3,14 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"cms-api/internal/modules/worker/entity"
+	"cms-api/internal/pkg/apperror"
 )
 
 type repository struct {
@@ -18,36 +21,25 @@ func New(db *sqlx.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) ClaimPendingJobs(ctx context.Context, batchSize int) ([]entity.SearchIndexJob, error) {
-	var jobs []entity.SearchIndexJob
-	err := r.db.SelectContext(ctx, &jobs, queryClaimPendingJobs, batchSize)
+func (r *repository) GetProgramForIndex(ctx context.Context, programID string) (*entity.ProgramDocument, error) {
+	doc, err := scanProgramDocument(r.db.QueryRowContext(ctx, queryGetProgramForIndex, programID))
 	if err != nil {
 		return nil, err
 	}
-	return jobs, nil
-}
 
-func (r *repository) MarkCompleted(ctx context.Context, jobID string) error {
-	_, err := r.db.ExecContext(ctx, queryMarkCompleted, jobID)
-	return err
-}
-
-func (r *repository) MarkFailed(ctx context.Context, jobID string, errMsg string, nextSchedule time.Time) error {
-	_, err := r.db.ExecContext(ctx, queryMarkFailed, jobID, errMsg, nextSchedule)
-	return err
-}
+	if err := r.db.SelectContext(ctx, &doc.Transcript, queryListTranscriptCues, programID); err != nil {
+		return nil, err
+	}
 
-func (r *repository) MarkDead(ctx context.Context, jobID string, errMsg string) error {
-	_, err := r.db.ExecContext(ctx, queryMarkDead, jobID, errMsg)
-	return err
+	return doc, nil
 }
 
-func (r *repository) GetProgramForIndex(ctx context.Context, programID string) (*entity.ProgramDocument, error) {
-	row := r.db.QueryRowContext(ctx, queryGetProgramForIndex, programID)
-
+// scanProgramDocument scans the columns of queryGetProgramForIndex.
+func scanProgramDocument(row interface{ Scan(dest ...any) error }) (*entity.ProgramDocument, error) {
 	var doc entity.ProgramDocument
-	var duration, publishedAt, category, language sql.NullString
-	var createdAt time.Time
+	var duration, publishedAt, category, language, categorySlug sql.NullString
+	var categoryID sql.NullInt64
+	var createdAt, updatedAt time.Time
 
 	err := row.Scan(
 		&doc.ID,
@@ -62,6 +54,9 @@ func (r *repository) GetProgramForIndex(ctx context.Context, programID string) (
 		&doc.Thumbnail,
 		&doc.VideoURL,
 		&createdAt,
+		&updatedAt,
+		&categoryID,
+		&categorySlug,
 	)
 	if err != nil {
 		return nil, err
@@ -79,7 +74,283 @@ func (r *repository) GetProgramForIndex(ctx context.Context, programID string) (
 	if language.Valid {
 		doc.Language = &language.String
 	}
+	if categoryID.Valid {
+		doc.CategoryID = &categoryID.Int64
+		doc.CategorySlug = &categorySlug.String
+	}
 	doc.CreatedAt = createdAt.Format(time.RFC3339)
+	doc.UpdatedAt = updatedAt.Format(time.RFC3339)
 
 	return &doc, nil
 }
+
+func (r *repository) ListCategories(ctx context.Context) ([]entity.Category, error) {
+	var categories []entity.Category
+	if err := r.db.SelectContext(ctx, &categories, queryListCategories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *repository) CountProgramsForIndex(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, queryCountProgramsForIndex)
+	return count, err
+}
+
+func (r *repository) ListProgramsForIndex(ctx context.Context, afterID string, limit int) ([]*entity.ProgramDocument, error) {
+	// The nil UUID sorts before every other, so it stands for "from the start".
+	if afterID == "" {
+		afterID = "00000000-0000-0000-0000-000000000000"
+	}
+
+	return r.listProgramDocuments(ctx, queryListProgramsForIndex, afterID, limit)
+}
+
+func (r *repository) ListProgramVersions(ctx context.Context, afterID string, limit int) ([]entity.ProgramVersion, error) {
+	if afterID == "" {
+		afterID = "00000000-0000-0000-0000-000000000000"
+	}
+
+	var versions []entity.ProgramVersion
+	if err := r.db.SelectContext(ctx, &versions, queryListProgramVersions, afterID, limit); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (r *repository) GetProgramsForIndex(ctx context.Context, programIDs []string) ([]*entity.ProgramDocument, error) {
+	if len(programIDs) == 0 {
+		return nil, nil
+	}
+	return r.listProgramDocuments(ctx, queryGetProgramsForIndex, pq.StringArray(programIDs))
+}
+
+// listProgramDocuments runs a query selecting the columns of
+// queryGetProgramForIndex and loads the transcripts of every returned
+// program in a single follow-up query.
+func (r *repository) listProgramDocuments(ctx context.Context, query string, args ...any) ([]*entity.ProgramDocument, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []*entity.ProgramDocument
+	byID := make(map[string]*entity.ProgramDocument)
+	for rows.Next() {
+		doc, err := scanProgramDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+		byID[doc.ID] = doc
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(docs))
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	var cues []struct {
+		ProgramID string `db:"program_id"`
+		entity.TranscriptCue
+	}
+	if err := r.db.SelectContext(ctx, &cues, queryListTranscriptCuesForPrograms, pq.StringArray(ids)); err != nil {
+		return nil, err
+	}
+	for _, c := range cues {
+		doc := byID[c.ProgramID]
+		doc.Transcript = append(doc.Transcript, c.TranscriptCue)
+	}
+
+	return docs, nil
+}
+
+func (r *repository) ListChangedProgramIDs(ctx context.Context, since time.Time) ([]string, time.Time, error) {
+	var row struct {
+		CheckedAt  time.Time      `db:"checked_at"`
+		ProgramIDs pq.StringArray `db:"program_ids"`
+	}
+	if err := r.db.GetContext(ctx, &row, queryListChangedProgramIDs, since); err != nil {
+		return nil, time.Time{}, err
+	}
+	return row.ProgramIDs, row.CheckedAt, nil
+}
+
+func (r *repository) CreateReindexRun(ctx context.Context, run *entity.ReindexRun) error {
+	err := r.db.GetContext(ctx, run, queryCreateReindexRun, run.ID, run.IndexName, run.TargetIndex, run.RequestedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.ErrConflict
+	}
+	return err
+}
+
+func (r *repository) ClaimReindexRun(ctx context.Context, staleAfter time.Duration) (*entity.ReindexRun, error) {
+	var run entity.ReindexRun
+	err := r.db.GetContext(ctx, &run, queryClaimReindexRun, staleAfter.Seconds())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *repository) UpdateReindexRun(ctx context.Context, run *entity.ReindexRun) error {
+	_, err := r.db.ExecContext(ctx, queryUpdateReindexRun,
+		run.ID,
+		run.Status,
+		run.TotalDocuments,
+		run.IndexedDocuments,
+		run.CaughtUp,
+		run.ErrorMessage,
+		run.SwappedAt,
+		run.FinishedAt,
+	)
+	return err
+}
+
+func (r *repository) GetReindexRun(ctx context.Context, id string) (*entity.ReindexRun, error) {
+	return r.getReindexRun(ctx, queryGetReindexRun, id)
+}
+
+func (r *repository) GetLatestReindexRun(ctx context.Context, indexName string) (*entity.ReindexRun, error) {
+	return r.getReindexRun(ctx, queryGetLatestReindexRun, indexName)
+}
+
+func (r *repository) getReindexRun(ctx context.Context, query string, arg any) (*entity.ReindexRun, error) {
+	var run entity.ReindexRun
+	if err := r.db.GetContext(ctx, &run, query, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// consistencyRunRow is a search_consistency_runs row, scanning the ID
+// arrays.
+type consistencyRunRow struct {
+	ID            string         `db:"id"`
+	IndexName     string         `db:"index_name"`
+	Repair        bool           `db:"repair"`
+	Status        string         `db:"status"`
+	Programs      int            `db:"programs"`
+	Documents     int            `db:"documents"`
+	MissingCount  int            `db:"missing_count"`
+	StaleCount    int            `db:"stale_count"`
+	OrphanedCount int            `db:"orphaned_count"`
+	MissingIDs    pq.StringArray `db:"missing_ids"`
+	StaleIDs      pq.StringArray `db:"stale_ids"`
+	OrphanedIDs   pq.StringArray `db:"orphaned_ids"`
+	RepairJobs    int            `db:"repair_jobs"`
+	ErrorMessage  sql.NullString `db:"error_message"`
+	RequestedBy   sql.NullString `db:"requested_by"`
+	StartedAt     sql.NullTime   `db:"started_at"`
+	FinishedAt    sql.NullTime   `db:"finished_at"`
+	CreatedAt     time.Time      `db:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+}
+
+func (row *consistencyRunRow) toEntity() *entity.ConsistencyRun {
+	return &entity.ConsistencyRun{
+		ID:            row.ID,
+		IndexName:     row.IndexName,
+		Repair:        row.Repair,
+		Status:        row.Status,
+		Programs:      row.Programs,
+		Documents:     row.Documents,
+		MissingCount:  row.MissingCount,
+		StaleCount:    row.StaleCount,
+		OrphanedCount: row.OrphanedCount,
+		MissingIDs:    row.MissingIDs,
+		StaleIDs:      row.StaleIDs,
+		OrphanedIDs:   row.OrphanedIDs,
+		RepairJobs:    row.RepairJobs,
+		ErrorMessage:  row.ErrorMessage,
+		RequestedBy:   row.RequestedBy,
+		StartedAt:     row.StartedAt,
+		FinishedAt:    row.FinishedAt,
+		CreatedAt:     row.CreatedAt,
+		UpdatedAt:     row.UpdatedAt,
+	}
+}
+
+func (r *repository) CreateConsistencyRun(ctx context.Context, run *entity.ConsistencyRun) error {
+	var row consistencyRunRow
+	err := r.db.GetContext(ctx, &row, queryCreateConsistencyRun, run.ID, run.IndexName, run.Repair, run.RequestedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+	*run = *row.toEntity()
+	return nil
+}
+
+func (r *repository) ClaimConsistencyRun(ctx context.Context, staleAfter time.Duration) (*entity.ConsistencyRun, error) {
+	var row consistencyRunRow
+	err := r.db.GetContext(ctx, &row, queryClaimConsistencyRun, staleAfter.Seconds())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return row.toEntity(), nil
+}
+
+func (r *repository) UpdateConsistencyRun(ctx context.Context, run *entity.ConsistencyRun) error {
+	_, err := r.db.ExecContext(ctx, queryUpdateConsistencyRun,
+		run.ID,
+		run.Status,
+		run.Programs,
+		run.Documents,
+		run.MissingCount,
+		run.StaleCount,
+		run.OrphanedCount,
+		pq.StringArray(nonNil(run.MissingIDs)),
+		pq.StringArray(nonNil(run.StaleIDs)),
+		pq.StringArray(nonNil(run.OrphanedIDs)),
+		run.RepairJobs,
+		run.ErrorMessage,
+		run.FinishedAt,
+	)
+	return err
+}
+
+func (r *repository) GetConsistencyRun(ctx context.Context, id string) (*entity.ConsistencyRun, error) {
+	return r.getConsistencyRun(ctx, queryGetConsistencyRun, id)
+}
+
+func (r *repository) GetLatestConsistencyRun(ctx context.Context, indexName string) (*entity.ConsistencyRun, error) {
+	return r.getConsistencyRun(ctx, queryGetLatestConsistencyRun, indexName)
+}
+
+func (r *repository) getConsistencyRun(ctx context.Context, query string, arg any) (*entity.ConsistencyRun, error) {
+	var row consistencyRunRow
+	if err := r.db.GetContext(ctx, &row, query, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return row.toEntity(), nil
+}
+
+// nonNil stores a nil list as an empty array, as the NOT NULL columns need.
+func nonNil(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"cms-api/internal/infra/search"
+	queueservice "cms-api/internal/modules/queue/service"
+	"cms-api/internal/modules/worker/dto"
+	"cms-api/internal/modules/worker/entity"
+	"cms-api/internal/pkg/apperror"
+	"cms-api/internal/pkg/contextutil"
+	"cms-api/internal/pkg/uuidutil"
+)
+
+// consistencyFields are the only document fields a check reads.
+var consistencyFields = []string{"id", "updated_at"}
+
+func (s *service) RequestConsistencyCheck(ctx context.Context, req *dto.ConsistencyCheckRequest) (*dto.ConsistencyRunResponse, error) {
+	run, err := s.queueConsistencyCheck(ctx, req.Repair)
+	if err != nil {
+		if errors.Is(err, apperror.ErrConflict) {
+			return nil, apperror.NewAppError(apperror.ErrConflict, "a consistency check is already queued or running", http.StatusConflict)
+		}
+		return nil, err
+	}
+
+	s.log.Info("Consistency check requested", zap.String("run_id", run.ID), zap.Bool("repair", run.Repair))
+	return dto.ToConsistencyRunResponse(run), nil
+}
+
+func (s *service) GetConsistencyRun(ctx context.Context, id string) (*dto.ConsistencyRunResponse, error) {
+	run, err := s.repo.GetConsistencyRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToConsistencyRunResponse(run), nil
+}
+
+func (s *service) LatestConsistencyRun(ctx context.Context) (*dto.ConsistencyRunResponse, error) {
+	run, err := s.repo.GetLatestConsistencyRun(ctx, indexName)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToConsistencyRunResponse(run), nil
+}
+
+func (s *service) queueConsistencyCheck(ctx context.Context, repair bool) (*entity.ConsistencyRun, error) {
+	id, err := uuidutil.NewV7String()
+	if err != nil {
+		return nil, fmt.Errorf("generate consistency run id: %w", err)
+	}
+
+	run := &entity.ConsistencyRun{ID: id, IndexName: indexName, Repair: repair}
+	if userID := contextutil.GetUserID(ctx); userID != "" {
+		run.RequestedBy = sql.NullString{String: userID, Valid: true}
+	}
+	if err := s.repo.CreateConsistencyRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// queueScheduledConsistencyCheck queues the periodic check. A check already
+// queued or running, here or on another instance, stands in for it.
+func (s *service) queueScheduledConsistencyCheck(ctx context.Context) {
+	run, err := s.queueConsistencyCheck(ctx, s.cfg.ConsistencyRepair)
+	switch {
+	case errors.Is(err, apperror.ErrConflict):
+		s.log.Debug("Consistency check already queued, skipping scheduled check")
+	case err != nil:
+		if ctx.Err() == nil {
+			s.log.Error("Failed to queue consistency check", zap.Error(err))
+		}
+	default:
+		s.log.Debug("Scheduled consistency check queued", zap.String("run_id", run.ID))
+	}
+}
+
+// startPendingConsistencyCheck claims a queued check and runs it in the
+// background, like startPendingReindex.
+func (s *service) startPendingConsistencyCheck(ctx context.Context) {
+	if !s.indexReady.Load() || !s.checking.CompareAndSwap(false, true) {
+		return
+	}
+
+	run, err := s.repo.ClaimConsistencyRun(ctx, s.cfg.ReindexStaleAfter)
+	if err != nil || run == nil {
+		s.checking.Store(false)
+		if err != nil && ctx.Err() == nil {
+			s.log.Error("Failed to claim consistency run", zap.Error(err))
+		}
+		return
+	}
+
+	go func() {
+		defer s.checking.Store(false)
+		s.runConsistencyCheck(ctx, run)
+	}()
+}
+
+func (s *service) runConsistencyCheck(ctx context.Context, run *entity.ConsistencyRun) {
+	log := s.log.With(zap.String("run_id", run.ID), zap.Bool("repair", run.Repair))
+	log.Info("Consistency check started")
+
+	report, err := s.checkConsistency(ctx, run)
+
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), reindexSaveTimeout)
+	defer cancel()
+
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+	switch {
+	case err == nil:
+		run.SetReport(report)
+		run.Status = entity.ConsistencyStatusCompleted
+		run.FinishedAt = now
+		logResult := log.Info
+		if run.MissingCount+run.StaleCount+run.OrphanedCount > 0 {
+			logResult = log.Warn
+		}
+		logResult("Consistency check completed",
+			zap.Int("programs", run.Programs),
+			zap.Int("documents", run.Documents),
+			zap.Int("missing", run.MissingCount),
+			zap.Int("stale", run.StaleCount),
+			zap.Int("orphaned", run.OrphanedCount),
+			zap.Int("repair_jobs", run.RepairJobs),
+			zap.Duration("duration", now.Time.Sub(run.StartedAt.Time)),
+		)
+	case ctx.Err() != nil:
+		// Shutting down: hand the run back so the next worker starts it
+		// over. Repairs already enqueued absorb the ones it enqueues again.
+		run.Status = entity.ConsistencyStatusPending
+		log.Info("Consistency check interrupted, requeued")
+	default:
+		run.Status = entity.ConsistencyStatusFailed
+		run.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
+		run.FinishedAt = now
+		log.Error("Consistency check failed", zap.Error(err))
+	}
+
+	if err := s.repo.UpdateConsistencyRun(saveCtx, run); err != nil {
+		log.Error("Failed to save consistency run", zap.Error(err))
+	}
+}
+
+func (s *service) checkConsistency(ctx context.Context, run *entity.ConsistencyRun) (*entity.ConsistencyReport, error) {
+	if err := s.Ready(ctx); err != nil {
+		return nil, fmt.Errorf("search index unavailable: %w", err)
+	}
+
+	report := &entity.ConsistencyReport{}
+	if err := s.compareIndex(ctx, run, report); err != nil {
+		return nil, err
+	}
+	if run.Repair {
+		if err := s.repairIndex(ctx, run, report); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// compareIndex fills report by reading every index document and then every
+// program. Reading the index first means a program deleted during the check
+// is reported orphaned, not that one created meanwhile is: repairs of
+// missing and stale documents are harmless upserts, but a wrong delete
+// would drop a live program until its next change.
+// Progress on run doubles as the heartbeat that keeps it claimed.
+func (s *service) compareIndex(ctx context.Context, run *entity.ConsistencyRun, report *entity.ConsistencyReport) error {
+	limit := max(s.cfg.ReindexBatchSize, 1)
+
+	// Writes may shift documents between pages, so one can be read twice;
+	// keying by ID counts it once.
+	indexed := make(map[string]string)
+	for offset := 0; ; offset += limit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page, err := s.search.Documents(ctx, indexName, offset, limit, consistencyFields)
+		if err != nil {
+			if errors.Is(err, search.ErrUnsupported) {
+				return errors.New("the search backend does not support consistency checks")
+			}
+			return fmt.Errorf("read index documents: %w", err)
+		}
+		for _, raw := range page.Documents {
+			var doc struct {
+				ID        string `json:"id"`
+				UpdatedAt string `json:"updated_at"`
+			}
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				return fmt.Errorf("decode index document: %w", err)
+			}
+			indexed[doc.ID] = doc.UpdatedAt
+		}
+		run.Documents = len(indexed)
+		if err := s.repo.UpdateConsistencyRun(ctx, run); err != nil {
+			return err
+		}
+		if len(page.Documents) < limit {
+			break
+		}
+	}
+	report.Documents = len(indexed)
+
+	after := ""
+	for {
+		versions, err := s.repo.ListProgramVersions(ctx, after, limit)
+		if err != nil {
+			return fmt.Errorf("list programs: %w", err)
+		}
+		if len(versions) == 0 {
+			break
+		}
+
+		for _, v := range versions {
+			updatedAt, ok := indexed[v.ID]
+			switch {
+			case !ok:
+				report.Missing = append(report.Missing, v.ID)
+			case isStale(updatedAt, v.UpdatedAt):
+				report.Stale = append(report.Stale, v.ID)
+			}
+			delete(indexed, v.ID)
+		}
+		report.Programs += len(versions)
+		after = versions[len(versions)-1].ID
+
+		run.Programs = report.Programs
+		if err := s.repo.UpdateConsistencyRun(ctx, run); err != nil {
+			return err
+		}
+	}
+
+	for id := range indexed {
+		report.Orphaned = append(report.Orphaned, id)
+	}
+	slices.Sort(report.Orphaned)
+	return nil
+}
+
+// isStale reports whether a document stamped indexedAt predates a program
+// last updated at updatedAt. Documents carry whole seconds; one written
+// before updated_at was indexed has no stamp and is always stale.
+func isStale(indexedAt string, updatedAt time.Time) bool {
+	t, err := time.Parse(time.RFC3339, indexedAt)
+	if err != nil {
+		return true
+	}
+	return t.Before(updatedAt.Truncate(time.Second))
+}
+
+// repairIndex enqueues an upsert for every missing or stale document and a
+// delete for every orphaned one. An index job already queued for the same
+// program and action absorbs the repair.
+func (s *service) repairIndex(ctx context.Context, run *entity.ConsistencyRun, report *entity.ConsistencyReport) error {
+	enqueue := func(action string, ids []string) error {
+		for _, id := range ids {
+			_, err := s.queue.Enqueue(ctx, entity.IndexJobKind, entity.IndexJobPayload{ProgramID: id, Action: action}, queueservice.EnqueueOptions{
+				Queue:     entity.IndexJobQueue,
+				UniqueKey: action + ":" + id,
+			})
+			if err != nil {
+				return fmt.Errorf("enqueue %s of %s: %w", action, id, err)
+			}
+			report.RepairJobs++
+			run.RepairJobs = report.RepairJobs
+		}
+		return nil
+	}
+
+	if err := enqueue("upsert", report.Missing); err != nil {
+		return err
+	}
+	if err := enqueue("upsert", report.Stale); err != nil {
+		return err
+	}
+	return enqueue("delete", report.Orphaned)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/modules/worker/dto"
+	"cms-api/internal/modules/worker/entity"
+	"cms-api/internal/pkg/apperror"
+)
+
+// seedIndexed seeds programs and indexes them directly, without jobs.
+func seedIndexed(t *testing.T, svc *service, store *memdb.Store, programs []string) {
+	t.Helper()
+
+	seed(t, store, programs, nil)
+	if failures := svc.indexPrograms(context.Background(), programs); len(failures) != 0 {
+		t.Fatalf("index programs: %v", failures)
+	}
+}
+
+// checkConsistency queues a check, runs it to completion and returns the
+// finished run.
+func checkConsistency(t *testing.T, svc *service, repair bool) *dto.ConsistencyRunResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	queued, err := svc.RequestConsistencyCheck(ctx, &dto.ConsistencyCheckRequest{Repair: repair})
+	if err != nil {
+		t.Fatalf("request check: %v", err)
+	}
+	run, err := svc.repo.ClaimConsistencyRun(ctx, time.Minute)
+	if err != nil || run == nil || run.ID != queued.ID {
+		t.Fatalf("claim check %s: got %v, %v", queued.ID, run, err)
+	}
+	svc.runConsistencyCheck(ctx, run)
+
+	resp, err := svc.GetConsistencyRun(ctx, queued.ID)
+	if err != nil {
+		t.Fatalf("get check: %v", err)
+	}
+	if resp.Status != entity.ConsistencyStatusCompleted {
+		t.Fatalf("expected a completed check, got %s (%s)", resp.Status, resp.Error)
+	}
+	return resp
+}
+
+func TestCheckConsistency_ReportsAndRepairs(t *testing.T) {
+	svc, store, indexer := newTestService(t)
+
+	seedIndexed(t, svc, store, []string{"a", "b", "c", "d"})
+	seed(t, store, []string{"e"}, nil)
+	_ = store.Write(func(tb *memdb.Tables) error {
+		tb.Programs["b"].UpdatedAt = tb.Now.Add(time.Hour)
+		tb.Programs["d"].DeletedAt.Valid = true
+		return nil
+	})
+
+	resp := checkConsistency(t, svc, false)
+	if resp.Programs != 4 || resp.Documents != 4 {
+		t.Fatalf("expected 4 programs and 4 documents, got %d and %d", resp.Programs, resp.Documents)
+	}
+	if !slices.Equal(resp.Missing.IDs, []string{"e"}) || !slices.Equal(resp.Stale.IDs, []string{"b"}) || !slices.Equal(resp.Orphaned.IDs, []string{"d"}) {
+		t.Fatalf("unexpected report: missing %v, stale %v, orphaned %v", resp.Missing.IDs, resp.Stale.IDs, resp.Orphaned.IDs)
+	}
+	var jobs int
+	_ = store.Read(func(tb *memdb.Tables) error {
+		jobs = len(tb.Jobs)
+		return nil
+	})
+	if resp.RepairJobsEnqueued != 0 || jobs != 0 {
+		t.Fatalf("expected no repair jobs without repair, got %d", jobs)
+	}
+
+	resp = checkConsistency(t, svc, true)
+	if resp.RepairJobsEnqueued != 3 {
+		t.Fatalf("expected 3 repair jobs, got %d", resp.RepairJobsEnqueued)
+	}
+	if failures := handlePending(t, svc, store); len(failures) != 0 {
+		t.Fatalf("unexpected repair failures %v", failures)
+	}
+	if got := indexedIDs(t, indexer); !slices.Equal(got, []string{"a", "b", "c", "e"}) {
+		t.Fatalf("unexpected indexed programs after repair %v", got)
+	}
+
+	// The repaired document of b carries its new update time.
+	resp = checkConsistency(t, svc, false)
+	if resp.Missing.Count+resp.Stale.Count+resp.Orphaned.Count != 0 {
+		t.Fatalf("expected a consistent index, got missing %v, stale %v, orphaned %v", resp.Missing.IDs, resp.Stale.IDs, resp.Orphaned.IDs)
+	}
+}
+
+func TestCheckConsistency_PagesThroughIndex(t *testing.T) {
+	svc, store, _ := newTestService(t)
+	svc.cfg.ReindexBatchSize = 2
+
+	seedIndexed(t, svc, store, []string{"a", "b", "c", "d", "e"})
+
+	resp := checkConsistency(t, svc, false)
+	if resp.Programs != 5 || resp.Documents != 5 || resp.Missing.Count+resp.Stale.Count+resp.Orphaned.Count != 0 {
+		t.Fatalf("unexpected report %+v", resp)
+	}
+}
+
+func TestCheckConsistency_OneAtATime(t *testing.T) {
+	svc, _, _ := newTestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.RequestConsistencyCheck(ctx, &dto.ConsistencyCheckRequest{}); err != nil {
+		t.Fatalf("request check: %v", err)
+	}
+	_, err := svc.RequestConsistencyCheck(ctx, &dto.ConsistencyCheckRequest{})
+	if !errors.Is(err, apperror.ErrConflict) {
+		t.Fatalf("expected a conflict while a check is queued, got %v", err)
+	}
+
+	// A scheduled tick leaves the queued check alone.
+	svc.queueScheduledConsistencyCheck(ctx)
+	latest, err := svc.LatestConsistencyRun(ctx)
+	if err != nil || latest.Status != entity.ConsistencyStatusPending {
+		t.Fatalf("expected the first check still pending, got %+v, %v", latest, err)
+	}
+}
+
+func TestCheckConsistency_RequeuesOnShutdown(t *testing.T) {
+	svc, store, _ := newTestService(t)
+	seedIndexed(t, svc, store, []string{"a"})
+
+	queued, err := svc.RequestConsistencyCheck(context.Background(), &dto.ConsistencyCheckRequest{})
+	if err != nil {
+		t.Fatalf("request check: %v", err)
+	}
+	run, err := svc.repo.ClaimConsistencyRun(context.Background(), time.Minute)
+	if err != nil || run == nil {
+		t.Fatalf("claim check: %v, %v", run, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc.runConsistencyCheck(ctx, run)
+
+	resp, err := svc.GetConsistencyRun(context.Background(), queued.ID)
+	if err != nil {
+		t.Fatalf("get check: %v", err)
+	}
+	if resp.Status != entity.ConsistencyStatusPending {
+		t.Fatalf("expected an interrupted check to be requeued, got %s", resp.Status)
+	}
+}
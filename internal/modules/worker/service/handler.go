@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"cms-api/internal/infra/search"
+	queueentity "cms-api/internal/modules/queue/entity"
+	"cms-api/internal/modules/worker/entity"
+)
+
+func (s *service) Kind() string {
+	return entity.IndexJobKind
+}
+
+func (s *service) BatchSize() int {
+	return s.cfg.BatchSize
+}
+
+// Ready holds index jobs back until EnsureIndex succeeds, so documents never
+// land in an unconfigured index.
+func (s *service) Ready(ctx context.Context) error {
+	if s.indexReady.Load() {
+		return nil
+	}
+	return s.EnsureIndex(ctx)
+}
+
+func (s *service) Handle(ctx context.Context, job queueentity.Job) error {
+	return s.HandleBatch(ctx, []queueentity.Job{job})[job.ID]
+}
+
+// HandleBatch applies a batch of index jobs with one indexing task per
+// action and returns the error of each job that failed.
+func (s *service) HandleBatch(ctx context.Context, jobs []queueentity.Job) map[string]error {
+	failures := make(map[string]error)
+	var deletes, upserts []indexJob
+	for _, job := range jobs {
+		var payload entity.IndexJobPayload
+		if err := job.DecodePayload(&payload); err != nil || payload.ProgramID == "" {
+			failures[job.ID] = fmt.Errorf("invalid index job payload %s", job.Payload)
+			continue
+		}
+		switch payload.Action {
+		case "delete":
+			deletes = append(deletes, indexJob{id: job.ID, programID: payload.ProgramID})
+		case "upsert":
+			upserts = append(upserts, indexJob{id: job.ID, programID: payload.ProgramID})
+		default:
+			failures[job.ID] = fmt.Errorf("unknown index job action %q", payload.Action)
+		}
+	}
+
+	// Deletes go first: upserts load the program as it is now, so a job
+	// restoring a program deleted earlier in the batch must win.
+	if len(deletes) > 0 {
+		if err := s.deletePrograms(ctx, programIDs(deletes)); err != nil {
+			for _, job := range deletes {
+				failures[job.id] = err
+			}
+		}
+	}
+	if len(upserts) > 0 {
+		for programID, err := range s.indexPrograms(ctx, programIDs(upserts)) {
+			for _, job := range upserts {
+				if job.programID == programID {
+					failures[job.id] = err
+				}
+			}
+		}
+	}
+
+	s.log.Debug("Processed index jobs",
+		zap.Int("upserts", len(upserts)),
+		zap.Int("deletes", len(deletes)),
+		zap.Int("failed", len(failures)),
+	)
+	return failures
+}
+
+// indexJob is a claimed index job with its decoded program.
+type indexJob struct {
+	id        string
+	programID string
+}
+
+// deletePrograms removes programIDs with their title suggestions and
+// transcript cues, one task per index. All tasks are enqueued before any is
+// waited on.
+func (s *service) deletePrograms(ctx context.Context, programIDs []string) error {
+	suggestionIDs := make([]string, 0, len(programIDs))
+	for _, id := range programIDs {
+		suggestionIDs = append(suggestionIDs, programSuggestionID(id))
+	}
+
+	programsTask, err := s.search.DeleteDocuments(ctx, indexName, programIDs)
+	if err != nil {
+		return err
+	}
+	suggestTask, err := s.search.DeleteDocuments(ctx, suggestIndexName, suggestionIDs)
+	if err != nil {
+		return err
+	}
+	transcriptTask, err := s.search.DeleteDocumentsByFilter(ctx, transcriptIndexName, programFilter(programIDs))
+	if err != nil {
+		return err
+	}
+	if err := s.await(ctx, programsTask); err != nil {
+		return err
+	}
+	if err := s.await(ctx, suggestTask); err != nil {
+		return err
+	}
+	return s.await(ctx, transcriptTask)
+}
+
+// indexPrograms loads programIDs and upserts them with a single indexing
+// task, returning the error for each program that could not be indexed.
+func (s *service) indexPrograms(ctx context.Context, programIDs []string) map[string]error {
+	failures := make(map[string]error)
+	failAll := func(ids []string, err error) map[string]error {
+		for _, id := range ids {
+			failures[id] = err
+		}
+		return failures
+	}
+
+	docs, err := s.repo.GetProgramsForIndex(ctx, programIDs)
+	if err != nil {
+		return failAll(programIDs, err)
+	}
+	found := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		found[doc.ID] = true
+		normalizeDocument(doc)
+	}
+	for _, id := range programIDs {
+		if !found[id] {
+			failures[id] = sql.ErrNoRows
+		}
+	}
+
+	docs = s.addProgramDocuments(ctx, docs, failures)
+	if len(docs) == 0 {
+		return failures
+	}
+	ids := documentIDs(docs)
+	err = s.indexSuggestions(ctx, docs)
+	if err == nil {
+		err = s.indexTranscripts(ctx, ids, docs)
+	}
+	if err != nil {
+		return failAll(ids, err)
+	}
+	return failures
+}
+
+// addProgramDocuments adds docs in one task and returns those indexed. When
+// the engine rejects the batch, each document is retried on its own so one
+// invalid document does not fail the jobs of the others.
+func (s *service) addProgramDocuments(ctx context.Context, docs []*entity.ProgramDocument, failures map[string]error) []*entity.ProgramDocument {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	batch := make([]any, 0, len(docs))
+	for _, doc := range docs {
+		batch = append(batch, doc)
+	}
+	err := s.addDocuments(ctx, indexName, batch)
+	if err == nil {
+		return docs
+	}
+	if len(docs) == 1 || !errors.Is(err, search.ErrTaskFailed) {
+		for _, doc := range docs {
+			failures[doc.ID] = err
+		}
+		return nil
+	}
+
+	s.log.Warn("Index batch rejected, retrying documents one by one", zap.Int("documents", len(docs)), zap.Error(err))
+	tasks := make([]search.Task, len(docs))
+	for i, doc := range docs {
+		task, err := s.search.AddDocuments(ctx, indexName, []any{doc})
+		if err != nil {
+			for _, doc := range docs[i:] {
+				failures[doc.ID] = err
+			}
+			docs = docs[:i]
+			break
+		}
+		tasks[i] = task
+	}
+	var indexed []*entity.ProgramDocument
+	for i, doc := range docs {
+		if err := s.await(ctx, tasks[i]); err != nil {
+			failures[doc.ID] = err
+			continue
+		}
+		indexed = append(indexed, doc)
+	}
+	return indexed
+}
+
+// documentIDs returns the program IDs of docs.
+func documentIDs(docs []*entity.ProgramDocument) []string {
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	return ids
+}
+
+// programIDs returns the distinct programs of jobs, in claim order.
+func programIDs(jobs []indexJob) []string {
+	seen := make(map[string]bool, len(jobs))
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		if !seen[job.programID] {
+			seen[job.programID] = true
+			ids = append(ids, job.programID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"cms-api/internal/config"
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/infra/search"
+	queuerepo "cms-api/internal/modules/queue/repo"
+	queueservice "cms-api/internal/modules/queue/service"
+	"cms-api/internal/modules/worker/entity"
+	"cms-api/internal/modules/worker/repo"
+)
+
+// recordingIndexer counts write calls per index. As Meilisearch would, it
+// accepts any batch holding a document whose ID is in reject and fails its
+// task. While stuck, tasks are never processed; otherwise each wait takes at
+// least delay.
+type recordingIndexer struct {
+	*search.Memory
+	adds    map[string]int
+	deletes map[string]int
+	reject  map[string]bool
+	failed  map[int64]bool
+	stuck   bool
+	delay   time.Duration
+}
+
+func (i *recordingIndexer) AddDocuments(ctx context.Context, index string, docs []any) (search.Task, error) {
+	i.adds[index]++
+	for _, d := range docs {
+		if doc, ok := d.(*entity.ProgramDocument); ok && i.reject[doc.ID] {
+			task, err := i.Memory.AddDocuments(ctx, index, nil)
+			i.failed[task.UID] = true
+			return task, err
+		}
+	}
+	return i.Memory.AddDocuments(ctx, index, docs)
+}
+
+func (i *recordingIndexer) DeleteDocuments(ctx context.Context, index string, docIDs []string) (search.Task, error) {
+	i.deletes[index]++
+	return i.Memory.DeleteDocuments(ctx, index, docIDs)
+}
+
+func (i *recordingIndexer) WaitForTask(ctx context.Context, task search.Task) error {
+	if i.stuck {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	select {
+	case <-time.After(i.delay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if i.failed[task.UID] {
+		return fmt.Errorf("add documents: invalid document: %w", search.ErrTaskFailed)
+	}
+	return i.Memory.WaitForTask(ctx, task)
+}
+
+func newTestService(t *testing.T) (*service, *memdb.Store, *recordingIndexer) {
+	t.Helper()
+
+	store := memdb.New()
+	indexer := &recordingIndexer{
+		Memory:  search.NewMemory(),
+		adds:    map[string]int{},
+		deletes: map[string]int{},
+		reject:  map[string]bool{},
+		failed:  map[int64]bool{},
+	}
+	cfg := &config.Config{Worker: config.WorkerConfig{
+		Queues:    map[string]int{"search": 1},
+		BatchSize: 50, MaxAttempts: 5, TaskTimeout: time.Second, LeaseDuration: time.Minute,
+	}}
+	queue := queueservice.NewQueue(queuerepo.NewMemory(store), cfg)
+	svc := New(repo.NewMemory(store), queue, indexer, cfg, zap.NewNop()).(*service)
+	if err := svc.EnsureIndex(context.Background()); err != nil {
+		t.Fatalf("ensure index: %v", err)
+	}
+	clear(indexer.adds)
+	return svc, store, indexer
+}
+
+// seed adds active programs and a pending index job for each
+// "<action>:<program id>" in jobs, duplicates included.
+func seed(t *testing.T, store *memdb.Store, programs []string, jobs []string) {
+	t.Helper()
+
+	err := store.Write(func(tb *memdb.Tables) error {
+		for _, id := range programs {
+			tb.Programs[id] = &memdb.Program{ID: id, Title: "Program " + id, ProgramType: "podcast", Status: "active", CreatedAt: tb.Now, UpdatedAt: tb.Now}
+		}
+		for _, key := range jobs {
+			action, programID, _ := strings.Cut(key, ":")
+			payload, _ := json.Marshal(entity.IndexJobPayload{ProgramID: programID, Action: action})
+			id := fmt.Sprintf("job-%02d", len(tb.Jobs))
+			tb.Jobs[id] = &memdb.Job{
+				ID: id, Queue: "search", Kind: entity.IndexJobKind, Payload: payload,
+				UniqueKey: sql.NullString{String: key, Valid: true}, Status: "pending", MaxAttempts: 5,
+				ScheduledAt: tb.Now.Add(-time.Minute), CreatedAt: tb.Now, UpdatedAt: tb.Now,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+}
+
+// handlePending claims every pending index job and runs them as one batch,
+// returning the failures by "<action>:<program id>".
+func handlePending(t *testing.T, svc *service, store *memdb.Store) map[string]error {
+	t.Helper()
+
+	jobs, err := queuerepo.NewMemory(store).ClaimJobs(context.Background(), "search", []string{entity.IndexJobKind}, "test-worker", 100, time.Minute)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	keys := make(map[string]string, len(jobs))
+	for _, job := range jobs {
+		keys[job.ID] = job.UniqueKey.String
+	}
+
+	failures := map[string]error{}
+	for id, err := range svc.HandleBatch(context.Background(), jobs) {
+		failures[keys[id]] = err
+	}
+	return failures
+}
+
+func indexedIDs(t *testing.T, indexer *recordingIndexer) []string {
+	t.Helper()
+
+	res, err := indexer.Search(context.Background(), indexName, search.SearchRequest{PerPage: 100})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	var ids []string
+	for _, hit := range res.Hits {
+		var doc struct{ ID string }
+		if err := json.Unmarshal(hit, &doc); err != nil {
+			t.Fatalf("decode hit: %v", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestHandleBatch_OneTaskPerAction(t *testing.T) {
+	svc, store, indexer := newTestService(t)
+
+	seed(t, store, []string{"a", "b", "c"}, []string{"upsert:a", "upsert:b", "upsert:c"})
+	handlePending(t, svc, store)
+	seed(t, store, nil, []string{"delete:c", "upsert:a"})
+	clear(indexer.adds)
+	_ = store.Write(func(tb *memdb.Tables) error {
+		tb.Programs["c"].DeletedAt.Valid = true
+		return nil
+	})
+
+	if failures := handlePending(t, svc, store); len(failures) != 0 {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+	if indexer.adds[indexName] != 1 || indexer.deletes[indexName] != 1 {
+		t.Fatalf("expected one add and one delete task, got %d adds and %d deletes", indexer.adds[indexName], indexer.deletes[indexName])
+	}
+	if got := indexedIDs(t, indexer); !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatalf("unexpected indexed programs %v", got)
+	}
+}
+
+func TestHandleBatch_FailsJobsIndividually(t *testing.T) {
+	svc, store, indexer := newTestService(t)
+	indexer.reject["b"] = true
+
+	seed(t, store, []string{"a", "b", "c"}, []string{"upsert:a", "upsert:b", "upsert:c", "upsert:missing"})
+	failures := handlePending(t, svc, store)
+
+	if got := slices.Sorted(maps.Keys(failures)); !slices.Equal(got, []string{"upsert:b", "upsert:missing"}) {
+		t.Fatalf("unexpected failed jobs %v", failures)
+	}
+	if got := indexedIDs(t, indexer); !slices.Equal(got, []string{"a", "c"}) {
+		t.Fatalf("unexpected indexed programs %v", got)
+	}
+}
+
+func TestHandleBatch_FailsJobsWhenTaskTimesOut(t *testing.T) {
+	svc, store, indexer := newTestService(t)
+	svc.cfg.TaskTimeout = 10 * time.Millisecond
+	indexer.stuck = true
+
+	seed(t, store, []string{"a"}, []string{"upsert:a", "delete:b"})
+	failures := handlePending(t, svc, store)
+
+	if len(failures) != 2 {
+		t.Fatalf("expected both jobs to fail, got %v", failures)
+	}
+	for key, err := range failures {
+		if !strings.Contains(err.Error(), "not processed within") {
+			t.Errorf("expected %s to fail on the timeout, got %v", key, err)
+		}
+	}
+}
+
+func TestHandleBatch_RejectsInvalidPayloads(t *testing.T) {
+	svc, store, _ := newTestService(t)
+
+	seed(t, store, []string{"a"}, []string{"upsert:a", "rename:a"})
+	failures := handlePending(t, svc, store)
+
+	if got := slices.Sorted(maps.Keys(failures)); !slices.Equal(got, []string{"rename:a"}) {
+		t.Fatalf("expected only the unknown action to fail, got %v", failures)
+	}
+}
+
+func TestHandleBatch_ReplacesTranscriptCues(t *testing.T) {
+	svc, store, indexer := newTestService(t)
+	cueTexts := func() []string {
+		t.Helper()
+		res, err := indexer.Search(context.Background(), transcriptIndexName, search.SearchRequest{PerPage: 100})
+		if err != nil {
+			t.Fatalf("search cues: %v", err)
+		}
+		var texts []string
+		for _, hit := range res.Hits {
+			var cue entity.TranscriptCueDocument
+			if err := json.Unmarshal(hit, &cue); err != nil {
+				t.Fatalf("decode cue: %v", err)
+			}
+			texts = append(texts, cue.ProgramID+":"+cue.Text)
+		}
+		return texts
+	}
+	setCues := func(texts ...string) {
+		_ = store.Write(func(tb *memdb.Tables) error {
+			tb.Cues["a"] = nil
+			for i, text := range texts {
+				tb.Cues["a"] = append(tb.Cues["a"], &memdb.TranscriptCue{Position: i, StartMS: int64(i) * 1000, EndMS: int64(i+1) * 1000, Text: text})
+			}
+			return nil
+		})
+	}
+
+	seed(t, store, []string{"a", "b"}, []string{"upsert:a", "upsert:b"})
+	setCues("hello", "world")
+	handlePending(t, svc, store)
+	if got := cueTexts(); !slices.Equal(got, []string{"a:hello", "a:world"}) {
+		t.Fatalf("unexpected cues %v", got)
+	}
+
+	// A shorter transcript leaves none of the old cues behind.
+	setCues("goodbye")
+	seed(t, store, nil, []string{"upsert:a"})
+	handlePending(t, svc, store)
+	if got := cueTexts(); !slices.Equal(got, []string{"a:goodbye"}) {
+		t.Fatalf("unexpected cues after replace %v", got)
+	}
+
+	seed(t, store, nil, []string{"delete:a"})
+	_ = store.Write(func(tb *memdb.Tables) error {
+		tb.Programs["a"].DeletedAt.Valid = true
+		return nil
+	})
+	if failures := handlePending(t, svc, store); len(failures) != 0 {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+	if got := cueTexts(); len(got) != 0 {
+		t.Fatalf("expected the cues of a deleted program removed, got %v", got)
+	}
+}
@@ -2,21 +2,87 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"go.uber.org/zap"
 
 	"cms-api/internal/infra/search"
+	"cms-api/internal/modules/worker/entity"
+	"cms-api/internal/pkg/textnorm"
 )
 
+var programsIndexConfig = search.IndexConfig{
+	SearchableAttributes: []string{"title", "title_normalized", "description", "description_normalized", "transcript.text"},
+	FilterableAttributes: []string{"status", "program_type", "category", "language"},
+	SortableAttributes:   []string{"published_at", "created_at"},
+}
+
 func (s *service) EnsureIndex(ctx context.Context) error {
-	if err := s.search.EnsureIndex(ctx, indexName, "id", search.IndexConfig{
-		SearchableAttributes: []string{"title", "description"},
-		FilterableAttributes: []string{"status", "program_type", "category", "language"},
-		SortableAttributes:   []string{"published_at", "created_at"},
+	if err := s.search.EnsureIndex(ctx, indexName, "id", programsIndexConfig); err != nil {
+		return err
+	}
+	if err := s.search.EnsureIndex(ctx, suggestIndexName, "id", search.IndexConfig{
+		SearchableAttributes: []string{"text", "text_normalized"},
+		FilterableAttributes: []string{"type", "language"},
+	}); err != nil {
+		return err
+	}
+	if err := s.search.EnsureIndex(ctx, transcriptIndexName, "id", search.IndexConfig{
+		SearchableAttributes: []string{"text", "text_normalized"},
+		FilterableAttributes: []string{"program_id"},
 	}); err != nil {
 		return err
 	}
+	if err := s.syncCategorySuggestions(ctx); err != nil {
+		return err
+	}
+	s.indexReady.Store(true)
 
-	s.log.Info("Meilisearch index configured", zap.String("index", indexName))
+	s.log.Info("Meilisearch index configured",
+		zap.String("index", indexName),
+		zap.String("suggest_index", suggestIndexName),
+		zap.String("transcript_index", transcriptIndexName),
+	)
 	return nil
 }
+
+// normalizeDocument fills the normalized shadow fields of doc.
+func normalizeDocument(doc *entity.ProgramDocument) {
+	doc.TitleNormalized = textnorm.Normalize(doc.Title)
+	doc.DescriptionNormalized = textnorm.Normalize(doc.Description)
+}
+
+// addDocuments upserts docs into index and waits for the write to be
+// applied.
+func (s *service) addDocuments(ctx context.Context, index string, docs []any) error {
+	task, err := s.search.AddDocuments(ctx, index, docs)
+	if err != nil {
+		return err
+	}
+	return s.await(ctx, task)
+}
+
+// deleteDocuments removes docIDs from index and waits for the write to be
+// applied.
+func (s *service) deleteDocuments(ctx context.Context, index string, docIDs []string) error {
+	task, err := s.search.DeleteDocuments(ctx, index, docIDs)
+	if err != nil {
+		return err
+	}
+	return s.await(ctx, task)
+}
+
+// await waits up to cfg.TaskTimeout for task to be processed. A task still
+// queued at the deadline counts as failed so its jobs are retried; writes
+// are idempotent, so it landing later does no harm.
+func (s *service) await(ctx context.Context, task search.Task) error {
+	waitCtx, cancel := context.WithTimeout(ctx, s.cfg.TaskTimeout)
+	defer cancel()
+
+	err := s.search.WaitForTask(waitCtx, task)
+	if err != nil && ctx.Err() == nil && errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("search task %d not processed within %s", task.UID, s.cfg.TaskTimeout)
+	}
+	return err
+}
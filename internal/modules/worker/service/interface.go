@@ -1,8 +1,43 @@
 package service
 
-import "context"
+import (
+	"context"
+
+	queueservice "cms-api/internal/modules/queue/service"
+	"cms-api/internal/modules/worker/dto"
+)
 
 type Service interface {
+	// Service runs search.index jobs for the queue, holding them unclaimed
+	// while the search index is unavailable.
+	queueservice.BatchHandler
+	queueservice.Gate
+
 	EnsureIndex(ctx context.Context) error
 	Start(ctx context.Context)
+
+	// RequestReindex queues a full rebuild of the programs index, which a
+	// worker picks up on its next poll.
+	RequestReindex(ctx context.Context) (*dto.ReindexRunResponse, error)
+	GetReindexRun(ctx context.Context, id string) (*dto.ReindexRunResponse, error)
+	LatestReindexRun(ctx context.Context) (*dto.ReindexRunResponse, error)
+
+	ListJobs(ctx context.Context, req *dto.ListJobsRequest) (*dto.JobListResponse, error)
+	GetJob(ctx context.Context, id string) (*dto.JobResponse, error)
+	// RetryJob and RetryJobs requeue dead jobs with a fresh attempt budget;
+	// DiscardJob and DiscardJobs delete them. The single-job forms return a
+	// conflict for a job that is not dead.
+	RetryJob(ctx context.Context, id string) (*dto.JobResponse, error)
+	RetryJobs(ctx context.Context, req *dto.JobBatchRequest) (*dto.JobBatchResponse, error)
+	DiscardJob(ctx context.Context, id string) error
+	DiscardJobs(ctx context.Context, req *dto.JobBatchRequest) (*dto.JobBatchResponse, error)
+	JobStats(ctx context.Context) (*dto.JobStatsResponse, error)
+
+	// RequestConsistencyCheck queues a comparison of the programs index with
+	// Postgres, which a worker picks up on its next poll and records missing,
+	// stale and orphaned documents on. It returns a conflict while another
+	// check is queued or running.
+	RequestConsistencyCheck(ctx context.Context, req *dto.ConsistencyCheckRequest) (*dto.ConsistencyRunResponse, error)
+	GetConsistencyRun(ctx context.Context, id string) (*dto.ConsistencyRunResponse, error)
+	LatestConsistencyRun(ctx context.Context) (*dto.ConsistencyRunResponse, error)
 }
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	queueentity "cms-api/internal/modules/queue/entity"
+	"cms-api/internal/modules/worker/dto"
+	"cms-api/internal/modules/worker/entity"
+	"cms-api/internal/pkg/apperror"
+	"cms-api/internal/pkg/cursor"
+)
+
+// jobStatsWindow is the span the queue failure rate covers.
+const jobStatsWindow = time.Hour
+
+func (s *service) ListJobs(ctx context.Context, req *dto.ListJobsRequest) (*dto.JobListResponse, error) {
+	var cursorTime *time.Time
+	var cursorID string
+	if req.Cursor != "" {
+		t, id, err := cursor.DecodePair(req.Cursor)
+		if err != nil {
+			return nil, apperror.ErrBadRequest
+		}
+		cursorTime = &t
+		cursorID = id
+	}
+
+	filter := queueentity.JobFilter{Kind: entity.IndexJobKind, Status: req.Status}
+	if match := indexPayloadFilter(req); len(match) > 0 {
+		payload, err := json.Marshal(match)
+		if err != nil {
+			return nil, fmt.Errorf("encode job filter: %w", err)
+		}
+		filter.Payload = payload
+	}
+	jobs, err := s.queue.ListJobs(ctx, filter, req.Limit+1, cursorTime, cursorID)
+	if err != nil {
+		return nil, fmt.Errorf("list index jobs: %w", err)
+	}
+
+	hasNext := len(jobs) > req.Limit
+	if hasNext {
+		jobs = jobs[:req.Limit]
+	}
+
+	var nextCursor string
+	if hasNext && len(jobs) > 0 {
+		last := jobs[len(jobs)-1]
+		nextCursor = cursor.EncodePair(last.UpdatedAt, last.ID)
+	}
+
+	return dto.ToJobListResponse(jobs, nextCursor, hasNext), nil
+}
+
+func (s *service) GetJob(ctx context.Context, id string) (*dto.JobResponse, error) {
+	job, err := s.getIndexJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToJobResponse(job), nil
+}
+
+// getIndexJob loads job id, treating jobs of other kinds as not found.
+func (s *service) getIndexJob(ctx context.Context, id string) (*queueentity.Job, error) {
+	job, err := s.queue.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Kind != entity.IndexJobKind {
+		return nil, apperror.ErrNotFound
+	}
+	return job, nil
+}
+
+func (s *service) RetryJob(ctx context.Context, id string) (*dto.JobResponse, error) {
+	retried, err := s.queue.RetryDeadJobs(ctx, entity.IndexJobKind, []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("retry index job: %w", err)
+	}
+	if len(retried) == 0 {
+		return nil, s.deadJobConflict(ctx, id, "an active job already covers this program")
+	}
+
+	s.log.Info("Dead index job requeued", zap.String("job_id", id))
+	return s.GetJob(ctx, id)
+}
+
+func (s *service) RetryJobs(ctx context.Context, req *dto.JobBatchRequest) (*dto.JobBatchResponse, error) {
+	retried, err := s.queue.RetryDeadJobs(ctx, entity.IndexJobKind, batchIDs(req))
+	if err != nil {
+		return nil, fmt.Errorf("retry index jobs: %w", err)
+	}
+
+	s.log.Info("Dead index jobs requeued", zap.Int("jobs", len(retried)), zap.Bool("all", req.All))
+	return dto.ToJobBatchResponse(retried), nil
+}
+
+func (s *service) DiscardJob(ctx context.Context, id string) error {
+	discarded, err := s.queue.DiscardDeadJobs(ctx, entity.IndexJobKind, []string{id})
+	if err != nil {
+		return fmt.Errorf("discard index job: %w", err)
+	}
+	if len(discarded) == 0 {
+		return s.deadJobConflict(ctx, id, "")
+	}
+
+	s.log.Info("Dead index job discarded", zap.String("job_id", id))
+	return nil
+}
+
+func (s *service) DiscardJobs(ctx context.Context, req *dto.JobBatchRequest) (*dto.JobBatchResponse, error) {
+	discarded, err := s.queue.DiscardDeadJobs(ctx, entity.IndexJobKind, batchIDs(req))
+	if err != nil {
+		return nil, fmt.Errorf("discard index jobs: %w", err)
+	}
+
+	s.log.Info("Dead index jobs discarded", zap.Int("jobs", len(discarded)), zap.Bool("all", req.All))
+	return dto.ToJobBatchResponse(discarded), nil
+}
+
+func (s *service) JobStats(ctx context.Context) (*dto.JobStatsResponse, error) {
+	stats, err := s.queue.JobStats(ctx, entity.IndexJobKind, jobStatsWindow)
+	if err != nil {
+		return nil, fmt.Errorf("index job stats: %w", err)
+	}
+	return dto.ToJobStatsResponse(stats, jobStatsWindow), nil
+}
+
+// deadJobConflict explains why a single-job action changed nothing: the job
+// does not exist, is not dead, or, with a non-empty deadReason, is dead but
+// was skipped for that reason.
+func (s *service) deadJobConflict(ctx context.Context, id string, deadReason string) error {
+	job, err := s.getIndexJob(ctx, id)
+	if err != nil {
+		return err
+	}
+	if job.Status != "dead" {
+		return apperror.NewAppError(apperror.ErrConflict, fmt.Sprintf("job is %s; only dead jobs can be changed", job.Status), http.StatusConflict)
+	}
+	return apperror.NewAppError(apperror.ErrConflict, deadReason, http.StatusConflict)
+}
+
+// indexPayloadFilter returns the payload fields req narrows the listing to.
+func indexPayloadFilter(req *dto.ListJobsRequest) map[string]string {
+	match := make(map[string]string, 2)
+	if req.ProgramID != "" {
+		match["program_id"] = req.ProgramID
+	}
+	if req.Action != "" {
+		match["action"] = req.Action
+	}
+	return match
+}
+
+// batchIDs maps a batch request to the repository's selection, where nil
+// means every dead job.
+func batchIDs(req *dto.JobBatchRequest) []string {
+	if req.All {
+		return nil
+	}
+	return req.IDs
+}
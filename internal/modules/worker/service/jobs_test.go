@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"cms-api/internal/infra/memdb"
+	"cms-api/internal/modules/worker/dto"
+	"cms-api/internal/pkg/apperror"
+)
+
+// setJobStatus moves the first job for "<action>:<program id>" still pending
+// to status.
+func setJobStatus(t *testing.T, store *memdb.Store, key, status string) string {
+	t.Helper()
+
+	var id string
+	_ = store.Write(func(tb *memdb.Tables) error {
+		for _, jobID := range slices.Sorted(maps.Keys(tb.Jobs)) {
+			j := tb.Jobs[jobID]
+			if j.UniqueKey.String == key && j.Status == "pending" && id == "" {
+				j.Status = status
+				j.Attempts = 5
+				j.LastError.String, j.LastError.Valid = "boom", true
+				j.UpdatedAt = tb.Now
+				id = j.ID
+			}
+		}
+		return nil
+	})
+	if id == "" {
+		t.Fatalf("no job %s", key)
+	}
+	return id
+}
+
+func TestJobs_ListFiltersAndPaginates(t *testing.T) {
+	svc, store, _ := newTestService(t)
+	ctx := context.Background()
+
+	seed(t, store, nil, []string{"upsert:a", "upsert:b", "delete:c"})
+	setJobStatus(t, store, "upsert:a", "dead")
+	setJobStatus(t, store, "delete:c", "dead")
+
+	dead, err := svc.ListJobs(ctx, &dto.ListJobsRequest{Status: "dead", Limit: 1})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(dead.Items) != 1 || !dead.HasNext || dead.Items[0].LastError == nil {
+		t.Fatalf("unexpected first page %+v", dead)
+	}
+	next, err := svc.ListJobs(ctx, &dto.ListJobsRequest{Status: "dead", Cursor: dead.NextCursor, Limit: 1})
+	if err != nil {
+		t.Fatalf("list next page: %v", err)
+	}
+	if len(next.Items) != 1 || next.HasNext || next.Items[0].ID == dead.Items[0].ID {
+		t.Fatalf("unexpected second page %+v", next)
+	}
+
+	deletes, err := svc.ListJobs(ctx, &dto.ListJobsRequest{Action: "delete", Limit: 20})
+	if err != nil || len(deletes.Items) != 1 || deletes.Items[0].ProgramID != "c" {
+		t.Fatalf("expected only the delete job, got %+v, %v", deletes, err)
+	}
+
+	if _, err := svc.ListJobs(ctx, &dto.ListJobsRequest{Cursor: "garbage", Limit: 20}); !errors.Is(err, apperror.ErrBadRequest) {
+		t.Fatalf("expected bad request for an invalid cursor, got %v", err)
+	}
+}
+
+func TestJobs_RetryJob(t *testing.T) {
+	svc, store, _ := newTestService(t)
+	ctx := context.Background()
+
+	seed(t, store, nil, []string{"upsert:a", "upsert:b", "upsert:b"})
+	deadID := setJobStatus(t, store, "upsert:a", "dead")
+	completedID := setJobStatus(t, store, "upsert:b", "completed")
+	coveredID := setJobStatus(t, store, "upsert:b", "dead")
+
+	job, err := svc.RetryJob(ctx, deadID)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if job.Status != "pending" || job.Attempts != 0 {
+		t.Fatalf("expected a fresh pending job, got %s with %d attempts", job.Status, job.Attempts)
+	}
+
+	if _, err := svc.RetryJob(ctx, completedID); !errors.Is(err, apperror.ErrConflict) {
+		t.Fatalf("expected conflict for a completed job, got %v", err)
+	}
+	if _, err := svc.RetryJob(ctx, "missing"); !errors.Is(err, apperror.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	// The seeded pending upsert:b job already covers the program.
+	_ = store.Write(func(tb *memdb.Tables) error {
+		tb.Jobs[completedID].Status = "pending"
+		return nil
+	})
+	if _, err := svc.RetryJob(ctx, coveredID); !errors.Is(err, apperror.ErrConflict) {
+		t.Fatalf("expected conflict for a job with an active duplicate, got %v", err)
+	}
+}
+
+func TestJobs_RetryAndDiscardBatches(t *testing.T) {
+	svc, store, _ := newTestService(t)
+	ctx := context.Background()
+
+	seed(t, store, nil, []string{"upsert:a", "upsert:b", "delete:c", "upsert:d"})
+	a := setJobStatus(t, store, "upsert:a", "dead")
+	b := setJobStatus(t, store, "upsert:b", "dead")
+	c := setJobStatus(t, store, "delete:c", "dead")
+	d := setJobStatus(t, store, "upsert:d", "completed")
+
+	retried, err := svc.RetryJobs(ctx, &dto.JobBatchRequest{IDs: []string{a, d}})
+	if err != nil || retried.Count != 1 || retried.IDs[0] != a {
+		t.Fatalf("expected only the dead job retried, got %+v, %v", retried, err)
+	}
+
+	discarded, err := svc.DiscardJobs(ctx, &dto.JobBatchRequest{All: true})
+	if err != nil {
+		t.Fatalf("discard: %v", err)
+	}
+	slices.Sort(discarded.IDs)
+	want := []string{b, c}
+	slices.Sort(want)
+	if !slices.Equal(discarded.IDs, want) {
+		t.Fatalf("expected dead jobs %v discarded, got %v", want, discarded.IDs)
+	}
+
+	if err := svc.DiscardJob(ctx, a); !errors.Is(err, apperror.ErrConflict) {
+		t.Fatalf("expected conflict discarding a pending job, got %v", err)
+	}
+	if err := svc.DiscardJob(ctx, b); !errors.Is(err, apperror.ErrNotFound) {
+		t.Fatalf("expected not found for a discarded job, got %v", err)
+	}
+}
+
+func TestJobs_Stats(t *testing.T) {
+	svc, store, _ := newTestService(t)
+
+	seed(t, store, nil, []string{"upsert:a", "upsert:b", "upsert:c", "upsert:d"})
+	setJobStatus(t, store, "upsert:b", "completed")
+	setJobStatus(t, store, "upsert:c", "completed")
+	setJobStatus(t, store, "upsert:d", "dead")
+
+	stats, err := svc.JobStats(context.Background())
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Depth != 1 || stats.Due != 1 || stats.ByStatus["dead"] != 1 || stats.ByStatus["completed"] != 2 {
+		t.Fatalf("unexpected counts %+v", stats)
+	}
+	if stats.OldestPendingAgeSeconds == nil {
+		t.Fatal("expected the age of the pending job")
+	}
+	if stats.FailureRate < 0.33 || stats.FailureRate > 0.34 || stats.WindowSeconds != 3600 {
+		t.Fatalf("expected one of three attempts failed over an hour, got %v over %ds", stats.FailureRate, stats.WindowSeconds)
+	}
+}
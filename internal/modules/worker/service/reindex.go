@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"cms-api/internal/modules/worker/dto"
+	"cms-api/internal/modules/worker/entity"
+	"cms-api/internal/pkg/apperror"
+	"cms-api/internal/pkg/contextutil"
+	"cms-api/internal/pkg/uuidutil"
+)
+
+// reindexCatchUpOverlap widens each catch-up window to cover jobs committed
+// just after the previous check but stamped before it. Re-applying a
+// program is idempotent, so overlap only costs a few extra writes.
+const reindexCatchUpOverlap = 5 * time.Second
+
+// reindexSaveTimeout bounds the final state write of a run, which must
+// happen even while the worker is shutting down.
+const reindexSaveTimeout = 5 * time.Second
+
+func (s *service) RequestReindex(ctx context.Context) (*dto.ReindexRunResponse, error) {
+	id, err := uuidutil.NewV7String()
+	if err != nil {
+		return nil, fmt.Errorf("generate reindex run id: %w", err)
+	}
+
+	run := &entity.ReindexRun{
+		ID:          id,
+		IndexName:   indexName,
+		TargetIndex: fmt.Sprintf("%s_v%d", indexName, time.Now().Unix()),
+	}
+	if userID := contextutil.GetUserID(ctx); userID != "" {
+		run.RequestedBy = sql.NullString{String: userID, Valid: true}
+	}
+
+	if err := s.repo.CreateReindexRun(ctx, run); err != nil {
+		if errors.Is(err, apperror.ErrConflict) {
+			return nil, apperror.NewAppError(apperror.ErrConflict, "a reindex is already in progress", http.StatusConflict)
+		}
+		return nil, err
+	}
+
+	s.log.Info("Reindex requested", zap.String("run_id", run.ID), zap.String("target_index", run.TargetIndex))
+	return dto.ToReindexRunResponse(run), nil
+}
+
+func (s *service) GetReindexRun(ctx context.Context, id string) (*dto.ReindexRunResponse, error) {
+	run, err := s.repo.GetReindexRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToReindexRunResponse(run), nil
+}
+
+func (s *service) LatestReindexRun(ctx context.Context) (*dto.ReindexRunResponse, error) {
+	run, err := s.repo.GetLatestReindexRun(ctx, indexName)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToReindexRunResponse(run), nil
+}
+
+// startPendingReindex claims a queued run and builds it in the background,
+// so incremental jobs keep flowing into the live index meanwhile.
+func (s *service) startPendingReindex(ctx context.Context) {
+	if !s.indexReady.Load() || !s.reindexing.CompareAndSwap(false, true) {
+		return
+	}
+
+	run, err := s.repo.ClaimReindexRun(ctx, s.cfg.ReindexStaleAfter)
+	if err != nil || run == nil {
+		s.reindexing.Store(false)
+		if err != nil && ctx.Err() == nil {
+			s.log.Error("Failed to claim reindex run", zap.Error(err))
+		}
+		return
+	}
+
+	go func() {
+		defer s.reindexing.Store(false)
+		s.runReindex(ctx, run)
+	}()
+}
+
+func (s *service) runReindex(ctx context.Context, run *entity.ReindexRun) {
+	log := s.log.With(zap.String("run_id", run.ID), zap.String("target_index", run.TargetIndex))
+	log.Info("Reindex started")
+
+	err := s.reindex(ctx, run)
+
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), reindexSaveTimeout)
+	defer cancel()
+
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+	switch {
+	case err == nil:
+		run.Status = entity.ReindexStatusCompleted
+		run.FinishedAt = now
+		log.Info("Reindex completed",
+			zap.Int("indexed", run.IndexedDocuments),
+			zap.Int("caught_up", run.CaughtUp),
+		)
+	case ctx.Err() != nil && !run.SwappedAt.Valid:
+		// Shutting down before the swap: hand the run back so the next
+		// worker starts it over.
+		run.Status = entity.ReindexStatusPending
+		log.Info("Reindex interrupted, requeued")
+	default:
+		run.Status = entity.ReindexStatusFailed
+		run.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
+		run.FinishedAt = now
+		log.Error("Reindex failed", zap.Error(err))
+	}
+
+	if err != nil {
+		// Before the swap the target is a partial build; after it, the old
+		// index. Either way it is no longer needed.
+		if delErr := s.search.DeleteIndex(saveCtx, run.TargetIndex); delErr != nil {
+			log.Warn("Failed to delete reindex target", zap.Error(delErr))
+		}
+	}
+	if err := s.repo.UpdateReindexRun(saveCtx, run); err != nil {
+		log.Error("Failed to save reindex run", zap.Error(err))
+	}
+}
+
+// reindex builds run.TargetIndex from Postgres in batches, catches up on the
+// programs the live worker changed meanwhile, swaps it in for the live index
+// and catches up once more on changes that landed in the old index before
+// the swap. The suggestion index is rebuilt incrementally and is left as
+// is; transcript cues have no index to swap and are rewritten in place
+// alongside each batch, so a reindex also backfills them.
+func (s *service) reindex(ctx context.Context, run *entity.ReindexRun) error {
+	target := run.TargetIndex
+
+	// A run taken over from a stopped worker may have left a partial build.
+	if err := s.search.DeleteIndex(ctx, target); err != nil {
+		return err
+	}
+	if err := s.search.EnsureIndex(ctx, target, "id", programsIndexConfig); err != nil {
+		return fmt.Errorf("create target index: %w", err)
+	}
+	settings, err := s.search.Settings(ctx, indexName)
+	if err != nil {
+		return fmt.Errorf("read live index settings: %w", err)
+	}
+	if err := s.search.ApplySettings(ctx, target, *settings); err != nil {
+		return fmt.Errorf("copy settings to target index: %w", err)
+	}
+
+	total, err := s.repo.CountProgramsForIndex(ctx)
+	if err != nil {
+		return fmt.Errorf("count programs: %w", err)
+	}
+	run.TotalDocuments = total
+	if err := s.repo.UpdateReindexRun(ctx, run); err != nil {
+		return err
+	}
+
+	since := run.StartedAt.Time.Add(-reindexCatchUpOverlap)
+	after := ""
+	for {
+		docs, err := s.repo.ListProgramsForIndex(ctx, after, s.cfg.ReindexBatchSize)
+		if err != nil {
+			return fmt.Errorf("list programs: %w", err)
+		}
+		if len(docs) == 0 {
+			break
+		}
+
+		batch := make([]any, len(docs))
+		for i, doc := range docs {
+			normalizeDocument(doc)
+			batch[i] = doc
+		}
+		if err := s.addDocuments(ctx, target, batch); err != nil {
+			return err
+		}
+		if err := s.indexTranscripts(ctx, documentIDs(docs), docs); err != nil {
+			return err
+		}
+
+		after = docs[len(docs)-1].ID
+		run.IndexedDocuments += len(docs)
+		// Progress doubles as the heartbeat that keeps the run claimed.
+		if err := s.repo.UpdateReindexRun(ctx, run); err != nil {
+			return err
+		}
+	}
+
+	since, err = s.catchUp(ctx, run, target, since)
+	if err != nil {
+		return err
+	}
+
+	if err := s.search.SwapIndexes(ctx, indexName, target); err != nil {
+		return err
+	}
+	run.SwappedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.repo.UpdateReindexRun(ctx, run); err != nil {
+		return err
+	}
+
+	if _, err := s.catchUp(ctx, run, indexName, since); err != nil {
+		return err
+	}
+	return s.search.DeleteIndex(ctx, target)
+}
+
+// catchUp re-applies to index every program with index jobs since since
+// and returns the watermark for the next pass.
+func (s *service) catchUp(ctx context.Context, run *entity.ReindexRun, index string, since time.Time) (time.Time, error) {
+	ids, checkedAt, err := s.repo.ListChangedProgramIDs(ctx, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("list changed programs: %w", err)
+	}
+
+	if len(ids) > 0 {
+		docs, err := s.repo.GetProgramsForIndex(ctx, ids)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("get changed programs: %w", err)
+		}
+
+		// Programs no longer returned were deleted since the last pass.
+		found := make(map[string]bool, len(docs))
+		batch := make([]any, 0, len(docs))
+		for _, doc := range docs {
+			found[doc.ID] = true
+			normalizeDocument(doc)
+			batch = append(batch, doc)
+		}
+		var deleted []string
+		for _, id := range ids {
+			if !found[id] {
+				deleted = append(deleted, id)
+			}
+		}
+
+		if len(deleted) > 0 {
+			if err := s.deleteDocuments(ctx, index, deleted); err != nil {
+				return time.Time{}, err
+			}
+		}
+		if len(batch) > 0 {
+			if err := s.addDocuments(ctx, index, batch); err != nil {
+				return time.Time{}, err
+			}
+		}
+		if err := s.indexTranscripts(ctx, ids, docs); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	run.CaughtUp += len(ids)
+	if err := s.repo.UpdateReindexRun(ctx, run); err != nil {
+		return time.Time{}, err
+	}
+	return checkedAt.Add(-reindexCatchUpOverlap), nil
+}
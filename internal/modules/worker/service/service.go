@@ -2,41 +2,66 @@ package service
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 
 	"cms-api/internal/config"
 	"cms-api/internal/infra/search"
+	queueservice "cms-api/internal/modules/queue/service"
 	"cms-api/internal/modules/worker/repo"
 )
 
-const indexName = "programs"
+const (
+	indexName           = "programs"
+	suggestIndexName    = "program_suggestions"
+	transcriptIndexName = "program_transcripts"
+)
 
 type service struct {
 	repo   repo.Repository
+	queue  queueservice.Queue
 	search search.Indexer
 	cfg    config.WorkerConfig
 	log    *zap.Logger
+
+	// indexReady is set once EnsureIndex succeeds; until then batches are
+	// held back so documents never land in an unconfigured index.
+	indexReady atomic.Bool
+	// reindexing is set while this worker builds a reindex run.
+	reindexing atomic.Bool
+	// checking is set while this worker runs a consistency check.
+	checking atomic.Bool
 }
 
-func New(repo repo.Repository, search search.Indexer, cfg *config.Config, log *zap.Logger) Service {
+func New(repo repo.Repository, queue queueservice.Queue, search search.Indexer, cfg *config.Config, log *zap.Logger) Service {
 	return &service{
 		repo:   repo,
+		queue:  queue,
 		search: search,
 		cfg:    cfg.Worker,
 		log:    log.Named("worker"),
 	}
 }
 
+// Start polls for reindex runs and consistency checks, and queues scheduled
+// checks, until ctx is done. Index jobs are run by the queue workers.
 func (s *service) Start(ctx context.Context) {
 	ticker := time.NewTicker(s.cfg.PollInterval)
 	defer ticker.Stop()
 
+	// A nil channel never fires, leaving scheduled checks off.
+	var consistency <-chan time.Time
+	if s.cfg.ConsistencyInterval > 0 {
+		consistencyTicker := time.NewTicker(s.cfg.ConsistencyInterval)
+		defer consistencyTicker.Stop()
+		consistency = consistencyTicker.C
+	}
+
 	s.log.Info("Worker started",
 		zap.Duration("poll_interval", s.cfg.PollInterval),
 		zap.Int("batch_size", s.cfg.BatchSize),
-		zap.Int("max_attempts", s.cfg.MaxAttempts),
 	)
 
 	for {
@@ -45,7 +70,10 @@ func (s *service) Start(ctx context.Context) {
 			s.log.Info("Worker stopped")
 			return
 		case <-ticker.C:
-			s.processBatch(ctx)
+			s.startPendingReindex(ctx)
+			s.startPendingConsistencyCheck(ctx)
+		case <-consistency:
+			s.queueScheduledConsistencyCheck(ctx)
 		}
 	}
 }
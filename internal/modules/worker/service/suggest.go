@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"cms-api/internal/modules/worker/entity"
+	"cms-api/internal/pkg/textnorm"
+)
+
+const (
+	suggestionTypeTitle    = "title"
+	suggestionTypeCategory = "category"
+)
+
+func programSuggestionID(programID string) string {
+	return "program-" + programID
+}
+
+func categorySuggestionID(categoryID int64) string {
+	return "category-" + strconv.FormatInt(categoryID, 10)
+}
+
+// indexSuggestions keeps the titles of docs in the suggestion index while
+// the programs are active, and refreshes their categories so renames show
+// up without waiting for a restart.
+func (s *service) indexSuggestions(ctx context.Context, docs []*entity.ProgramDocument) error {
+	var suggestions []any
+	var inactive []string
+	categories := make(map[int64]bool)
+	for _, doc := range docs {
+		if doc.Status != "active" {
+			inactive = append(inactive, programSuggestionID(doc.ID))
+			continue
+		}
+
+		id := doc.ID
+		suggestions = append(suggestions, entity.SuggestionDocument{
+			ID:             programSuggestionID(doc.ID),
+			Type:           suggestionTypeTitle,
+			Text:           doc.Title,
+			TextNormalized: doc.TitleNormalized,
+			ProgramID:      &id,
+			Language:       doc.Language,
+		})
+		if doc.CategoryID != nil && doc.Category != nil && !categories[*doc.CategoryID] {
+			categories[*doc.CategoryID] = true
+			suggestions = append(suggestions, entity.SuggestionDocument{
+				ID:             categorySuggestionID(*doc.CategoryID),
+				Type:           suggestionTypeCategory,
+				Text:           *doc.Category,
+				TextNormalized: textnorm.Normalize(*doc.Category),
+				Slug:           doc.CategorySlug,
+			})
+		}
+	}
+
+	if len(inactive) > 0 {
+		if err := s.deleteDocuments(ctx, suggestIndexName, inactive); err != nil {
+			return err
+		}
+	}
+	if len(suggestions) > 0 {
+		return s.addDocuments(ctx, suggestIndexName, suggestions)
+	}
+	return nil
+}
+
+// syncCategorySuggestions upserts every category. Categories have no index
+// jobs of their own, so this runs whenever the index is (re)configured.
+func (s *service) syncCategorySuggestions(ctx context.Context) error {
+	categories, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return err
+	}
+	if len(categories) == 0 {
+		return nil
+	}
+
+	docs := make([]any, 0, len(categories))
+	for _, c := range categories {
+		slug := c.Slug
+		docs = append(docs, entity.SuggestionDocument{
+			ID:             categorySuggestionID(c.ID),
+			Type:           suggestionTypeCategory,
+			Text:           c.Name,
+			TextNormalized: textnorm.Normalize(c.Name),
+			Slug:           &slug,
+		})
+	}
+	return s.addDocuments(ctx, suggestIndexName, docs)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cms-api/internal/modules/worker/entity"
+	"cms-api/internal/pkg/textnorm"
+)
+
+func transcriptCueID(programID string, position int) string {
+	return programID + "-" + strconv.Itoa(position)
+}
+
+// programFilter matches the documents of programIDs in the transcript index.
+func programFilter(programIDs []string) string {
+	quoted := make([]string, len(programIDs))
+	for i, id := range programIDs {
+		quoted[i] = "'" + strings.ReplaceAll(id, "'", `\'`) + "'"
+	}
+	return fmt.Sprintf("program_id IN [%s]", strings.Join(quoted, ", "))
+}
+
+// indexTranscripts replaces the cues of programIDs in the transcript index
+// with those of docs, the programs among them that still exist. The old
+// cues are deleted first since a shorter transcript leaves no cue ID to
+// overwrite; the engine applies both writes in order, so both are enqueued
+// before either is waited on.
+func (s *service) indexTranscripts(ctx context.Context, programIDs []string, docs []*entity.ProgramDocument) error {
+	var cues []any
+	for _, doc := range docs {
+		for i, c := range doc.Transcript {
+			cues = append(cues, entity.TranscriptCueDocument{
+				ID:             transcriptCueID(doc.ID, i),
+				ProgramID:      doc.ID,
+				StartMS:        c.StartMS,
+				EndMS:          c.EndMS,
+				Text:           c.Text,
+				TextNormalized: textnorm.Normalize(c.Text),
+			})
+		}
+	}
+
+	deleteTask, err := s.search.DeleteDocumentsByFilter(ctx, transcriptIndexName, programFilter(programIDs))
+	if err != nil {
+		return err
+	}
+	if len(cues) == 0 {
+		return s.await(ctx, deleteTask)
+	}
+	addTask, err := s.search.AddDocuments(ctx, transcriptIndexName, cues)
+	if err != nil {
+		return err
+	}
+	if err := s.await(ctx, deleteTask); err != nil {
+		return err
+	}
+	return s.await(ctx, addTask)
+}
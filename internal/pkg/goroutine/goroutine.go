@@ -63,6 +63,19 @@ func BackgroundWithCancel(logger *zap.Logger, cancel <-chan struct{}, fn func(ct
 	}()
 }
 
+// SafeCall runs fn on the calling goroutine and turns a panic into an error.
+// Use for: Running untrusted callbacks inside a worker that must survive them (job handlers)
+func SafeCall(logger *zap.Logger, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("call panic", zap.Any("panic", r), zap.Stack("stacktrace"))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return fn()
+}
+
 // Stream launches a panic-safe goroutine for streaming operations.
 // Closes errChan when done, sends any error before closing.
 // Use for: Streaming responses (audio, video, SSE)
@@ -12,7 +12,9 @@ func BinaryStream(ctx context.Context, w http.ResponseWriter, contentType string
 	w.Header().Set("Content-Type", contentType)
 	w.Header().Set("Transfer-Encoding", "chunked")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Accept-Ranges", "bytes")
+	// A chunked stream has no known length and cannot seek, so ranges are
+	// not supported here; use ServeContent for seekable media.
+	w.Header().Set("Accept-Ranges", "none")
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
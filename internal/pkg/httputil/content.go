@@ -0,0 +1,34 @@
+package httputil
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ContentOptions struct {
+	Name        string
+	ContentType string
+	ETag        string
+	ModTime     time.Time
+	MaxAge      time.Duration
+}
+
+// ServeContent replies with a seekable body, honouring Range, If-Range,
+// If-Match, If-None-Match, If-Modified-Since and If-Unmodified-Since.
+// Content-Length and 206 Partial Content are handled by http.ServeContent.
+func ServeContent(w http.ResponseWriter, r *http.Request, opts ContentOptions, content io.ReadSeeker) {
+	h := w.Header()
+	if opts.ContentType != "" {
+		h.Set("Content-Type", opts.ContentType)
+	}
+	if opts.ETag != "" {
+		h.Set("ETag", opts.ETag)
+	}
+	if opts.MaxAge > 0 {
+		h.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(opts.MaxAge.Seconds())))
+	}
+
+	http.ServeContent(w, r, opts.Name, opts.ModTime, content)
+}
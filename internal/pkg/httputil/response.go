@@ -45,6 +45,13 @@ func Created(w http.ResponseWriter, data interface{}) {
 	})
 }
 
+func Accepted(w http.ResponseWriter, data interface{}) {
+	JSON(w, http.StatusAccepted, Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
 func NoContent(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,72 @@
+// Package textnorm folds the spelling variants of Arabic text that readers
+// treat as the same word, so an index and the queries against it agree.
+//
+// The rules are mirrored by the normalize_arabic SQL function used by the
+// Postgres search fallback; change both together.
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize returns s lowercased with Arabic text folded:
+//
+//   - diacritics (tashkeel), Quranic annotation marks and tatweel are removed
+//   - alef with hamza or madda and alef wasla become a bare alef
+//   - hamza on waw and on yeh become waw and yeh
+//   - taa marbuta becomes heh and alef maqsura and Farsi yeh become yeh
+//   - Arabic-Indic and extended Arabic-Indic digits become ASCII digits
+//   - zero-width joiners and bidi marks are removed
+//
+// Spacing and punctuation are kept, so word boundaries do not move.
+func Normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if r < 0x80 {
+			b.WriteByte(byte(unicode.ToLower(r)))
+			continue
+		}
+		if dropped(r) {
+			continue
+		}
+		b.WriteRune(fold(r))
+	}
+	return b.String()
+}
+
+func dropped(r rune) bool {
+	switch {
+	case r >= 0x0610 && r <= 0x061A: // Quranic honorifics and small letters
+	case r >= 0x064B && r <= 0x065F: // tashkeel, including combining hamza
+	case r == 0x0670: // superscript alef
+	case r >= 0x06D6 && r <= 0x06ED: // Quranic annotation marks
+	case r == 0x0640: // tatweel
+	case r >= 0x200B && r <= 0x200F: // zero-width space, ZWNJ, ZWJ, LRM, RLM
+	case r == 0x061C: // Arabic letter mark
+	default:
+		return false
+	}
+	return true
+}
+
+func fold(r rune) rune {
+	switch r {
+	case 'أ', 'إ', 'آ', 'ٱ':
+		return 'ا'
+	case 'ؤ':
+		return 'و'
+	case 'ئ', 'ى', 'ی':
+		return 'ي'
+	case 'ة':
+		return 'ه'
+	}
+	switch {
+	case r >= '٠' && r <= '٩':
+		return '0' + r - '٠'
+	case r >= '۰' && r <= '۹':
+		return '0' + r - '۰'
+	}
+	return unicode.ToLower(r)
+}
@@ -0,0 +1,73 @@
+package textnorm
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"plain arabic", "محمد", "محمد"},
+		{"tashkeel", "مُحَمَّدٌ", "محمد"},
+		{"tanween and sukun", "كِتَابًا مُفِيْدًا", "كتابا مفيدا"},
+		{"superscript alef", "هٰذا", "هذا"},
+		{"quranic marks", "ٱلرَّحْمَٰنِ ۚ", "الرحمن "},
+		{"tatweel", "بـــودكاست", "بودكاست"},
+		{"alef hamza above", "أحمد", "احمد"},
+		{"alef hamza below", "إسلام", "اسلام"},
+		{"alef madda", "آمن", "امن"},
+		{"alef wasla", "ٱلكتاب", "الكتاب"},
+		{"combining hamza", "\u0627\u0654حمد", "احمد"},
+		{"hamza on waw", "مؤتمر", "موتمر"},
+		{"hamza on yeh", "رئيس", "رييس"},
+		{"taa marbuta", "مدرسة", "مدرسه"},
+		{"alef maqsura", "مستشفى", "مستشفي"},
+		{"farsi yeh", "فارسی", "فارسي"},
+		{"arabic-indic digits", "الحلقة ١٢٣", "الحلقه 123"},
+		{"extended arabic-indic digits", "۲۰۲۶", "2026"},
+		{"zero-width non-joiner", "می\u200cخواهم", "ميخواهم"},
+		{"bidi marks", "\u200fمرحبا\u200e\u061c", "مرحبا"},
+		{"latin lowercased", "Thmanyah PODCAST", "thmanyah podcast"},
+		{"non-ascii latin lowercased", "ÉCOLE", "école"},
+		{"mixed script", "بودكاست Fnjan: الحَلْقَةُ ٥", "بودكاست fnjan: الحلقه 5"},
+		{"punctuation and spacing kept", "  سؤال؟  جواب!  ", "  سوال؟  جواب!  "},
+		{"all rules", "إِنَّ الـمَدْرَسَةَ كُبْرَى", "ان المدرسه كبري"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize_Idempotent(t *testing.T) {
+	for _, in := range []string{"مُحَمَّدٌ", "إِنَّ الـمَدْرَسَةَ كُبْرَى", "Mixed نصٌّ ١٢"} {
+		once := Normalize(in)
+		if twice := Normalize(once); twice != once {
+			t.Errorf("Normalize(Normalize(%q)) = %q, want %q", in, twice, once)
+		}
+	}
+}
+
+func TestNormalize_VariantsMatch(t *testing.T) {
+	// Spellings a reader would type for the same word fold to one form.
+	groups := [][]string{
+		{"أسامة", "اسامه", "أُسَامَة", "إسامة"},
+		{"القرآن", "القران", "الْقُرْآن"},
+		{"مستشفى", "مستشفي"},
+		{"الحلقة ١", "الحلقه 1"},
+	}
+	for _, g := range groups {
+		want := Normalize(g[0])
+		for _, v := range g[1:] {
+			if got := Normalize(v); got != want {
+				t.Errorf("Normalize(%q) = %q, want %q (as %q)", v, got, want, g[0])
+			}
+		}
+	}
+}
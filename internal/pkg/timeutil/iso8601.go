@@ -0,0 +1,32 @@
+package timeutil
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ISO8601Duration formats d as an ISO 8601 duration such as "PT1H2M15S".
+// Sub-second precision is dropped.
+func ISO8601Duration(d time.Duration) string {
+	secs := int64(d / time.Second)
+	if secs <= 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	b.WriteString("PT")
+	if h := secs / 3600; h > 0 {
+		b.WriteString(strconv.FormatInt(h, 10))
+		b.WriteByte('H')
+	}
+	if m := (secs / 60) % 60; m > 0 {
+		b.WriteString(strconv.FormatInt(m, 10))
+		b.WriteByte('M')
+	}
+	if s := secs % 60; s > 0 {
+		b.WriteString(strconv.FormatInt(s, 10))
+		b.WriteByte('S')
+	}
+	return b.String()
+}
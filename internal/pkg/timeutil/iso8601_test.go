@@ -0,0 +1,26 @@
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestISO8601Duration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "PT0S"},
+		{-time.Second, "PT0S"},
+		{45 * time.Second, "PT45S"},
+		{90 * time.Minute, "PT1H30M"},
+		{time.Hour + 2*time.Minute + 15*time.Second, "PT1H2M15S"},
+		{26*time.Hour + 500*time.Millisecond, "PT26H"},
+	}
+
+	for _, tt := range tests {
+		if got := ISO8601Duration(tt.in); got != tt.want {
+			t.Errorf("ISO8601Duration(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatWebVTT = "vtt"
+	FormatSRT    = "srt"
+)
+
+var (
+	ErrInvalidTranscript = errors.New("invalid transcript")
+	ErrUnsupportedFormat = errors.New("unsupported transcript format")
+)
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// Parse decodes a WebVTT or SRT document into cues ordered as they appear.
+func Parse(format string, data []byte) ([]Cue, error) {
+	switch format {
+	case FormatWebVTT:
+		return ParseWebVTT(data)
+	case FormatSRT:
+		return ParseSRT(data)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// FormatFromContentType maps an upload Content-Type to a transcript format.
+func FormatFromContentType(contentType string) string {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	switch mediaType {
+	case "text/vtt":
+		return FormatWebVTT
+	case "application/x-subrip", "application/srt", "text/srt":
+		return FormatSRT
+	default:
+		return ""
+	}
+}
+
+func ParseWebVTT(data []byte) ([]Cue, error) {
+	blocks := splitBlocks(data)
+	if len(blocks) == 0 || !isWebVTTHeader(blocks[0][0]) {
+		return nil, fmt.Errorf("%w: missing WEBVTT header", ErrInvalidTranscript)
+	}
+
+	var cues []Cue
+	for _, block := range blocks[1:] {
+		first := block[0]
+		if strings.HasPrefix(first, "NOTE") || first == "STYLE" || first == "REGION" {
+			continue
+		}
+
+		timingIdx := 0
+		if !strings.Contains(first, "-->") {
+			// Optional cue identifier
+			timingIdx = 1
+		}
+		if timingIdx >= len(block) {
+			return nil, fmt.Errorf("%w: cue %q has no timing line", ErrInvalidTranscript, first)
+		}
+
+		cue, err := parseCue(block[timingIdx], block[timingIdx+1:], '.')
+		if err != nil {
+			return nil, err
+		}
+		cues = append(cues, cue)
+	}
+
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("%w: no cues found", ErrInvalidTranscript)
+	}
+
+	return cues, nil
+}
+
+func ParseSRT(data []byte) ([]Cue, error) {
+	var cues []Cue
+	for _, block := range splitBlocks(data) {
+		timingIdx := 0
+		if !strings.Contains(block[0], "-->") {
+			// Numeric sequence counter
+			timingIdx = 1
+		}
+		if timingIdx >= len(block) {
+			return nil, fmt.Errorf("%w: cue %q has no timing line", ErrInvalidTranscript, block[0])
+		}
+
+		cue, err := parseCue(block[timingIdx], block[timingIdx+1:], ',')
+		if err != nil {
+			return nil, err
+		}
+		cues = append(cues, cue)
+	}
+
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("%w: no cues found", ErrInvalidTranscript)
+	}
+
+	return cues, nil
+}
+
+// EncodeWebVTT renders cues as a WebVTT document.
+func EncodeWebVTT(cues []Cue) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("WEBVTT\n")
+	for _, c := range cues {
+		buf.WriteString("\n")
+		buf.WriteString(formatTimestamp(c.Start))
+		buf.WriteString(" --> ")
+		buf.WriteString(formatTimestamp(c.End))
+		buf.WriteString("\n")
+		buf.WriteString(c.Text)
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+func isWebVTTHeader(line string) bool {
+	return line == "WEBVTT" || strings.HasPrefix(line, "WEBVTT ") || strings.HasPrefix(line, "WEBVTT\t")
+}
+
+// splitBlocks normalises line endings and groups non-blank lines into blocks.
+func splitBlocks(data []byte) [][]string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var blocks [][]string
+	var current []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+
+	return blocks
+}
+
+func parseCue(timing string, textLines []string, fractionSep byte) (Cue, error) {
+	parts := strings.SplitN(timing, "-->", 2)
+	if len(parts) != 2 {
+		return Cue{}, fmt.Errorf("%w: bad timing line %q", ErrInvalidTranscript, timing)
+	}
+
+	start, err := parseTimestamp(strings.TrimSpace(parts[0]), fractionSep)
+	if err != nil {
+		return Cue{}, err
+	}
+
+	// WebVTT allows cue settings after the end timestamp
+	endFields := strings.Fields(parts[1])
+	if len(endFields) == 0 {
+		return Cue{}, fmt.Errorf("%w: bad timing line %q", ErrInvalidTranscript, timing)
+	}
+	end, err := parseTimestamp(endFields[0], fractionSep)
+	if err != nil {
+		return Cue{}, err
+	}
+	if end < start {
+		return Cue{}, fmt.Errorf("%w: cue ends before it starts %q", ErrInvalidTranscript, timing)
+	}
+
+	lines := make([]string, 0, len(textLines))
+	for _, l := range textLines {
+		l = strings.TrimSpace(html.UnescapeString(tagPattern.ReplaceAllString(l, "")))
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+
+	return Cue{Start: start, End: end, Text: strings.Join(lines, "\n")}, nil
+}
+
+// parseTimestamp accepts "hh:mm:ss.ttt" and "mm:ss.ttt" with the given
+// fraction separator ('.' for WebVTT, ',' for SRT).
+func parseTimestamp(s string, fractionSep byte) (time.Duration, error) {
+	invalid := fmt.Errorf("%w: bad timestamp %q", ErrInvalidTranscript, s)
+
+	idx := strings.LastIndexByte(s, fractionSep)
+	if idx < 0 {
+		return 0, invalid
+	}
+	clock, fraction := s[:idx], s[idx+1:]
+	if len(fraction) != 3 {
+		return 0, invalid
+	}
+	millis, err := strconv.Atoi(fraction)
+	if err != nil {
+		return 0, invalid
+	}
+
+	fields := strings.Split(clock, ":")
+	if len(fields) < 2 || len(fields) > 3 {
+		return 0, invalid
+	}
+
+	var total time.Duration
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return 0, invalid
+		}
+		// Minutes and seconds are bounded; hours are not.
+		if i > 0 || len(fields) == 2 {
+			if n > 59 {
+				return 0, invalid
+			}
+		}
+		total = total*60 + time.Duration(n)
+	}
+
+	return total*time.Second + time.Duration(millis)*time.Millisecond, nil
+}
+
+func formatTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	h := ms / 3_600_000
+	m := (ms / 60_000) % 60
+	sec := (ms / 1000) % 60
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", h, m, sec, ms%1000)
+}
@@ -0,0 +1,132 @@
+package transcript
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseWebVTT(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Cue
+		wantErr bool
+	}{
+		{
+			name: "basic cues with identifiers and settings",
+			input: "WEBVTT - episode 1\n\n" +
+				"intro\n00:00:00.000 --> 00:00:04.500 align:start\n<v Host>Welcome to the show</v>\n\n" +
+				"00:01:02.250 --> 00:01:05.000\nمرحباً بكم\nفي الحلقة\n",
+			want: []Cue{
+				{Start: 0, End: 4500 * time.Millisecond, Text: "Welcome to the show"},
+				{Start: 62250 * time.Millisecond, End: 65 * time.Second, Text: "مرحباً بكم\nفي الحلقة"},
+			},
+		},
+		{
+			name:  "short timestamps, CRLF, BOM, notes and entities",
+			input: "\xef\xbb\xbfWEBVTT\r\n\r\nNOTE generated\r\n\r\n01:02.000 --> 01:03.000\r\nTom &amp; Jerry\r\n",
+			want: []Cue{
+				{Start: 62 * time.Second, End: 63 * time.Second, Text: "Tom & Jerry"},
+			},
+		},
+		{
+			name:  "hours beyond 99",
+			input: "WEBVTT\n\n100:00:00.000 --> 100:00:01.000\nlate\n",
+			want: []Cue{
+				{Start: 100 * time.Hour, End: 100*time.Hour + time.Second, Text: "late"},
+			},
+		},
+		{name: "missing header", input: "00:00:00.000 --> 00:00:01.000\nhi\n", wantErr: true},
+		{name: "header only", input: "WEBVTT\n", wantErr: true},
+		{name: "srt separator", input: "WEBVTT\n\n00:00:00,000 --> 00:00:01,000\nhi\n", wantErr: true},
+		{name: "end before start", input: "WEBVTT\n\n00:00:02.000 --> 00:00:01.000\nhi\n", wantErr: true},
+		{name: "minutes out of range", input: "WEBVTT\n\n00:61:00.000 --> 00:62:00.000\nhi\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWebVTT([]byte(tt.input))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTranscript) {
+					t.Fatalf("expected ErrInvalidTranscript, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSRT(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Cue
+		wantErr bool
+	}{
+		{
+			name:  "numbered cues with formatting tags",
+			input: "1\n00:00:01,000 --> 00:00:02,500\n<i>Hello</i>\n\n2\n00:00:03,000 --> 00:00:04,000\nworld\n",
+			want: []Cue{
+				{Start: time.Second, End: 2500 * time.Millisecond, Text: "Hello"},
+				{Start: 3 * time.Second, End: 4 * time.Second, Text: "world"},
+			},
+		},
+		{
+			name:  "missing counter",
+			input: "00:00:01,000 --> 00:00:02,000\nno counter\n",
+			want: []Cue{
+				{Start: time.Second, End: 2 * time.Second, Text: "no counter"},
+			},
+		},
+		{name: "empty", input: "", wantErr: true},
+		{name: "no timing", input: "1\nhello\n", wantErr: true},
+		{name: "bad fraction", input: "1\n00:00:01,0 --> 00:00:02,000\nhi\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSRT([]byte(tt.input))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTranscript) {
+					t.Fatalf("expected ErrInvalidTranscript, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeWebVTT_RoundTrip(t *testing.T) {
+	cues := []Cue{
+		{Start: 0, End: 1500 * time.Millisecond, Text: "first"},
+		{Start: time.Hour + 2*time.Minute + 3*time.Second, End: time.Hour + 2*time.Minute + 4*time.Second, Text: "second\nline"},
+	}
+
+	got, err := ParseWebVTT(EncodeWebVTT(cues))
+	if err != nil {
+		t.Fatalf("parse formatted: %v", err)
+	}
+	if !reflect.DeepEqual(got, cues) {
+		t.Fatalf("got %+v, want %+v", got, cues)
+	}
+}
+
+func TestParse_UnsupportedFormat(t *testing.T) {
+	if _, err := Parse("txt", []byte("hello")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package validator
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -24,7 +25,23 @@ func init() {
 	registerCustomValidations()
 }
 
-func registerCustomValidations() {}
+func registerCustomValidations() {
+	_ = validate.RegisterValidation("ranking_rule", validateRankingRule)
+}
+
+var builtinRankingRules = map[string]bool{
+	"words": true, "typo": true, "proximity": true,
+	"attribute": true, "sort": true, "exactness": true,
+}
+
+var customRankingRule = regexp.MustCompile(`^[a-z_][a-z0-9_]*:(asc|desc)$`)
+
+// validateRankingRule accepts a Meilisearch built-in rule or a custom
+// "attribute:asc" / "attribute:desc" rule.
+func validateRankingRule(fl validator.FieldLevel) bool {
+	rule := fl.Field().String()
+	return builtinRankingRules[rule] || customRankingRule.MatchString(rule)
+}
 
 func Validate(s interface{}) error {
 	err := validate.Struct(s)
@@ -51,6 +68,10 @@ func formatValidationError(err validator.FieldError) string {
 	switch tag {
 	case "required":
 		return fmt.Sprintf("%s is required", field)
+	case "required_without":
+		return fmt.Sprintf("%s is required unless %s is set", field, strings.ToLower(err.Param()))
+	case "excluded_with":
+		return fmt.Sprintf("%s must be empty when %s is set", field, strings.ToLower(err.Param()))
 	case "email":
 		return fmt.Sprintf("%s must be a valid email address", field)
 	case "min":
@@ -61,6 +82,8 @@ func formatValidationError(err validator.FieldError) string {
 		return fmt.Sprintf("%s must be a valid UUID", field)
 	case "url":
 		return fmt.Sprintf("%s must be a valid URL", field)
+	case "ranking_rule":
+		return fmt.Sprintf("%s must be a built-in ranking rule or attribute:asc|desc", field)
 	case "oneof":
 		return fmt.Sprintf("%s must be one of: %s", field, err.Param())
 	default:
@@ -0,0 +1,3 @@
+ALTER TABLE programs
+    DROP COLUMN IF EXISTS media_type,
+    DROP COLUMN IF EXISTS media_path;
@@ -0,0 +1,5 @@
+-- Locally stored audio/video served by the discovery media endpoint.
+-- media_path is a key relative to STORAGE_LOCAL_ROOT.
+ALTER TABLE programs
+    ADD COLUMN media_path VARCHAR(1024),
+    ADD COLUMN media_type VARCHAR(100);
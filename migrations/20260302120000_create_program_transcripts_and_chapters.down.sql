@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS program_chapters;
+DROP TABLE IF EXISTS program_transcript_cues;
+DROP TABLE IF EXISTS program_transcripts;
@@ -0,0 +1,33 @@
+CREATE TABLE program_transcripts (
+    program_id  UUID PRIMARY KEY REFERENCES programs(id) ON DELETE CASCADE,
+    format      VARCHAR(10) NOT NULL,
+    language    VARCHAR(10) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_transcript_format CHECK (format IN ('vtt', 'srt'))
+);
+
+-- Parsed cues; the uploaded file is not kept, WebVTT is regenerated on demand.
+CREATE TABLE program_transcript_cues (
+    program_id  UUID NOT NULL REFERENCES program_transcripts(program_id) ON DELETE CASCADE,
+    position    INT NOT NULL,
+    start_ms    BIGINT NOT NULL,
+    end_ms      BIGINT NOT NULL,
+    text        TEXT NOT NULL,
+
+    PRIMARY KEY (program_id, position)
+);
+
+CREATE TABLE program_chapters (
+    program_id  UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    position    INT NOT NULL,
+    start_ms    BIGINT NOT NULL,
+    end_ms      BIGINT,
+    title       VARCHAR(255) NOT NULL,
+    url         VARCHAR(2048) NOT NULL DEFAULT '',
+    image       VARCHAR(2048) NOT NULL DEFAULT '',
+    toc         BOOLEAN NOT NULL DEFAULT TRUE,
+
+    PRIMARY KEY (program_id, position)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_programs_search_vector;
+
+ALTER TABLE programs
+    DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,12 @@
+-- Full-text search over title/description, used when Meilisearch is
+-- unavailable. Both Arabic and English configurations are applied so either
+-- language stems correctly; titles outrank descriptions.
+ALTER TABLE programs
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('arabic', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('arabic', coalesce(description, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX idx_programs_search_vector ON programs USING GIN (search_vector);
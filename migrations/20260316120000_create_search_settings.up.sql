@@ -0,0 +1,24 @@
+-- Relevance settings for each search index. This table is the source of
+-- truth; the API pushes changes to Meilisearch and reconciles on startup.
+CREATE TABLE search_settings (
+    index_name                   VARCHAR(100) PRIMARY KEY,
+    -- Groups of interchangeable words, e.g. [["podcast", "بودكاست"]].
+    synonyms                     JSONB NOT NULL DEFAULT '[]',
+    stop_words                   TEXT[] NOT NULL DEFAULT '{}',
+    ranking_rules                TEXT[] NOT NULL DEFAULT '{words,typo,proximity,attribute,sort,exactness}',
+    typo_enabled                 BOOLEAN NOT NULL DEFAULT TRUE,
+    typo_min_word_size_one_typo  INT NOT NULL DEFAULT 5,
+    typo_min_word_size_two_typos INT NOT NULL DEFAULT 9,
+    typo_disable_on_words        TEXT[] NOT NULL DEFAULT '{}',
+    typo_disable_on_attributes   TEXT[] NOT NULL DEFAULT '{}',
+    updated_at                   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_synonyms_array CHECK (jsonb_typeof(synonyms) = 'array'),
+    CONSTRAINT chk_typo_word_sizes CHECK (
+        typo_min_word_size_one_typo >= 0 AND
+        typo_min_word_size_two_typos >= typo_min_word_size_one_typo
+    )
+);
+
+INSERT INTO search_settings (index_name, synonyms) VALUES
+    ('programs', '[["podcast", "بودكاست"], ["documentary", "وثائقي"]]');
@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_programs_search_vector;
+
+ALTER TABLE programs
+    DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE programs
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('arabic', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('arabic', coalesce(description, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX idx_programs_search_vector ON programs USING GIN (search_vector);
+
+DROP FUNCTION IF EXISTS normalize_arabic(TEXT);
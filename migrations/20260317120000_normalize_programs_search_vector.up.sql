@@ -0,0 +1,31 @@
+-- normalize_arabic mirrors textnorm.Normalize: it folds Arabic diacritics,
+-- tatweel, alef/hamza, taa marbuta and alef maqsura variants and Arabic-Indic
+-- digits, so the fallback search matches the same spellings Meilisearch does.
+CREATE FUNCTION normalize_arabic(t TEXT) RETURNS TEXT
+    LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE
+AS $$
+    SELECT translate(translate(translate(translate(translate(translate(
+        -- Diacritics, Quranic marks, tatweel, zero-width and bidi marks.
+        regexp_replace(lower(t), '[\u0610-\u061A\u064B-\u065F\u0670\u06D6-\u06ED\u0640\u200B-\u200F\u061C]', '', 'g'),
+        'أإآٱ', 'اااا'),
+        'ؤ', 'و'),
+        'ئىی', 'ييي'),
+        'ة', 'ه'),
+        '٠١٢٣٤٥٦٧٨٩', '0123456789'),
+        '۰۱۲۳۴۵۶۷۸۹', '0123456789')
+$$;
+
+DROP INDEX IF EXISTS idx_programs_search_vector;
+
+ALTER TABLE programs
+    DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE programs
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('arabic', normalize_arabic(coalesce(title, ''))), 'A') ||
+        setweight(to_tsvector('english', normalize_arabic(coalesce(title, ''))), 'A') ||
+        setweight(to_tsvector('arabic', normalize_arabic(coalesce(description, ''))), 'B') ||
+        setweight(to_tsvector('english', normalize_arabic(coalesce(description, ''))), 'B')
+    ) STORED;
+
+CREATE INDEX idx_programs_search_vector ON programs USING GIN (search_vector);
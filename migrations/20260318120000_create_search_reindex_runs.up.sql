@@ -0,0 +1,28 @@
+-- Full rebuilds of a search index. The API inserts a pending run; a worker
+-- claims it, builds target_index from Postgres, catches up on index jobs
+-- enqueued meanwhile and swaps it in for index_name.
+CREATE TABLE search_reindex_runs (
+    id                UUID PRIMARY KEY,
+    index_name        VARCHAR(100) NOT NULL,
+    target_index      VARCHAR(100) NOT NULL,
+    status            VARCHAR(15) NOT NULL DEFAULT 'pending',
+    total_documents   INT NOT NULL DEFAULT 0,
+    indexed_documents INT NOT NULL DEFAULT 0,
+    caught_up         INT NOT NULL DEFAULT 0,
+    error_message     TEXT,
+    requested_by      UUID REFERENCES users(id) ON DELETE SET NULL,
+    started_at        TIMESTAMPTZ,
+    swapped_at        TIMESTAMPTZ,
+    finished_at       TIMESTAMPTZ,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_reindex_status CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+-- At most one run per index is queued or in progress.
+CREATE UNIQUE INDEX idx_search_reindex_runs_active
+    ON search_reindex_runs(index_name)
+    WHERE status IN ('pending', 'running');
+
+CREATE INDEX idx_search_reindex_runs_created ON search_reindex_runs(created_at DESC);
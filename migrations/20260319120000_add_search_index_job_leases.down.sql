@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_search_index_jobs_lease;
+
+ALTER TABLE search_index_jobs
+    DROP COLUMN IF EXISTS locked_by,
+    DROP COLUMN IF EXISTS locked_until;
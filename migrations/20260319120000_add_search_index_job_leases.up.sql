@@ -0,0 +1,16 @@
+-- A claimed job is leased to one worker until locked_until. Jobs whose lease
+-- runs out, because the worker crashed or lost its connection, are returned
+-- to the queue by the next worker to poll.
+ALTER TABLE search_index_jobs
+    ADD COLUMN locked_until TIMESTAMPTZ,
+    ADD COLUMN locked_by    VARCHAR(128);
+
+CREATE INDEX idx_search_index_jobs_lease
+    ON search_index_jobs (locked_until)
+    WHERE status = 'processing';
+
+-- Jobs left in processing by a worker that predates leases are expired
+-- right away.
+UPDATE search_index_jobs
+SET locked_until = NOW()
+WHERE status = 'processing';
@@ -0,0 +1,31 @@
+CREATE OR REPLACE FUNCTION notify_program_index() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO search_index_jobs (program_id, action, status, scheduled_at)
+    VALUES (NEW.id, 'upsert', 'pending', NOW())
+    ON CONFLICT (program_id, action) WHERE status IN ('pending', 'processing', 'failed')
+    DO UPDATE SET scheduled_at = NOW(), updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_program_soft_delete() RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        DELETE FROM search_index_jobs
+        WHERE program_id = OLD.id
+          AND status IN ('completed', 'dead');
+
+        INSERT INTO search_index_jobs (program_id, action, status, scheduled_at)
+        VALUES (OLD.id, 'delete', 'pending', NOW())
+        ON CONFLICT (program_id, action) WHERE status IN ('pending', 'processing', 'failed')
+        DO UPDATE SET scheduled_at = NOW(), updated_at = NOW();
+
+        DELETE FROM search_index_jobs
+        WHERE program_id = OLD.id
+          AND action = 'upsert'
+          AND status IN ('pending', 'failed');
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,40 @@
+-- Wake listening index workers when a job is enqueued. Notifications are
+-- delivered on commit, so a woken worker always sees the job.
+CREATE OR REPLACE FUNCTION notify_program_index() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO search_index_jobs (program_id, action, status, scheduled_at)
+    VALUES (NEW.id, 'upsert', 'pending', NOW())
+    ON CONFLICT (program_id, action) WHERE status IN ('pending', 'processing', 'failed')
+    DO UPDATE SET scheduled_at = NOW(), updated_at = NOW();
+
+    PERFORM pg_notify('search_index_jobs', NEW.id::TEXT);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_program_soft_delete() RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        -- Remove completed/dead jobs for this program (no longer needed)
+        DELETE FROM search_index_jobs
+        WHERE program_id = OLD.id
+          AND status IN ('completed', 'dead');
+
+        -- Enqueue the delete job (dedupe with active partial unique index)
+        INSERT INTO search_index_jobs (program_id, action, status, scheduled_at)
+        VALUES (OLD.id, 'delete', 'pending', NOW())
+        ON CONFLICT (program_id, action) WHERE status IN ('pending', 'processing', 'failed')
+        DO UPDATE SET scheduled_at = NOW(), updated_at = NOW();
+
+        -- Cancel any pending upsert job for this program
+        DELETE FROM search_index_jobs
+        WHERE program_id = OLD.id
+          AND action = 'upsert'
+          AND status IN ('pending', 'failed');
+
+        PERFORM pg_notify('search_index_jobs', OLD.id::TEXT);
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
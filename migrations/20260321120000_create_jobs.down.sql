@@ -0,0 +1,75 @@
+CREATE TABLE search_index_jobs (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    program_id    UUID NOT NULL,
+    action        VARCHAR(10) NOT NULL DEFAULT 'upsert',
+    status        VARCHAR(15) NOT NULL DEFAULT 'pending',
+    attempts      INT NOT NULL DEFAULT 0,
+    max_attempts  INT NOT NULL DEFAULT 5,
+    last_error    TEXT,
+    scheduled_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    processed_at  TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until  TIMESTAMPTZ,
+    locked_by     VARCHAR(128),
+
+    CONSTRAINT chk_action CHECK (action IN ('upsert', 'delete')),
+    CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'dead'))
+);
+
+CREATE INDEX idx_search_index_jobs_poll ON search_index_jobs(status, scheduled_at);
+CREATE INDEX idx_search_index_jobs_program ON search_index_jobs(program_id);
+CREATE UNIQUE INDEX idx_search_index_jobs_active
+    ON search_index_jobs (program_id, action)
+    WHERE status IN ('pending', 'processing', 'failed');
+CREATE INDEX idx_search_index_jobs_lease
+    ON search_index_jobs (locked_until)
+    WHERE status = 'processing';
+
+INSERT INTO search_index_jobs (id, program_id, action, status, attempts, max_attempts,
+                               last_error, scheduled_at, processed_at, created_at,
+                               updated_at, locked_until, locked_by)
+SELECT id, (payload->>'program_id')::UUID, payload->>'action', status, attempts,
+       max_attempts, last_error, scheduled_at, processed_at, created_at, updated_at,
+       locked_until, locked_by
+FROM jobs
+WHERE kind = 'search.index';
+
+CREATE OR REPLACE FUNCTION notify_program_index() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO search_index_jobs (program_id, action, status, scheduled_at)
+    VALUES (NEW.id, 'upsert', 'pending', NOW())
+    ON CONFLICT (program_id, action) WHERE status IN ('pending', 'processing', 'failed')
+    DO UPDATE SET scheduled_at = NOW(), updated_at = NOW();
+
+    PERFORM pg_notify('search_index_jobs', NEW.id::TEXT);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_program_soft_delete() RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        DELETE FROM search_index_jobs
+        WHERE program_id = OLD.id
+          AND status IN ('completed', 'dead');
+
+        INSERT INTO search_index_jobs (program_id, action, status, scheduled_at)
+        VALUES (OLD.id, 'delete', 'pending', NOW())
+        ON CONFLICT (program_id, action) WHERE status IN ('pending', 'processing', 'failed')
+        DO UPDATE SET scheduled_at = NOW(), updated_at = NOW();
+
+        DELETE FROM search_index_jobs
+        WHERE program_id = OLD.id
+          AND action = 'upsert'
+          AND status IN ('pending', 'failed');
+
+        PERFORM pg_notify('search_index_jobs', OLD.id::TEXT);
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TABLE jobs;
+DROP FUNCTION notify_job_ready();
@@ -0,0 +1,108 @@
+-- Generic durable job queue. Each job names its kind, which selects the
+-- handler that runs it, and the queue it runs on; queues are worked by their
+-- own pool of workers. Jobs run highest priority first, then in scheduled
+-- order, and a job with a unique_key has at most one active row per kind.
+CREATE TABLE jobs (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    queue         VARCHAR(64) NOT NULL DEFAULT 'default',
+    kind          VARCHAR(64) NOT NULL,
+    payload       JSONB NOT NULL DEFAULT '{}',
+    priority      SMALLINT NOT NULL DEFAULT 0,
+    unique_key    VARCHAR(255),
+    status        VARCHAR(15) NOT NULL DEFAULT 'pending',
+    attempts      INT NOT NULL DEFAULT 0,
+    max_attempts  INT NOT NULL DEFAULT 5,
+    last_error    TEXT,
+    scheduled_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    processed_at  TIMESTAMPTZ,
+    locked_until  TIMESTAMPTZ,
+    locked_by     VARCHAR(128),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_jobs_status CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'dead'))
+);
+
+CREATE INDEX idx_jobs_poll
+    ON jobs (queue, priority DESC, scheduled_at)
+    WHERE status IN ('pending', 'failed');
+
+CREATE INDEX idx_jobs_lease
+    ON jobs (locked_until)
+    WHERE status = 'processing';
+
+CREATE UNIQUE INDEX idx_jobs_active_unique_key
+    ON jobs (kind, unique_key)
+    WHERE unique_key IS NOT NULL AND status IN ('pending', 'processing', 'failed');
+
+CREATE INDEX idx_jobs_unique_key ON jobs (kind, unique_key) WHERE unique_key IS NOT NULL;
+CREATE INDEX idx_jobs_kind_updated ON jobs (kind, updated_at DESC, id DESC);
+
+-- Wake the workers of a queue when a job becomes ready on it. Notifications
+-- are delivered on commit, so a woken worker always sees the job.
+CREATE FUNCTION notify_job_ready() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('jobs', NEW.queue);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_jobs_ready
+    AFTER INSERT OR UPDATE OF status, scheduled_at ON jobs
+    FOR EACH ROW
+    WHEN (NEW.status = 'pending')
+    EXECUTE FUNCTION notify_job_ready();
+
+-- Search index jobs move onto the queue as kind 'search.index' on queue
+-- 'search', keyed by action and program so the active-job dedupe carries over.
+INSERT INTO jobs (id, queue, kind, payload, unique_key, status, attempts, max_attempts,
+                  last_error, scheduled_at, processed_at, locked_until, locked_by,
+                  created_at, updated_at)
+SELECT id, 'search', 'search.index',
+       jsonb_build_object('program_id', program_id, 'action', action),
+       action || ':' || program_id,
+       status, attempts, max_attempts, last_error, scheduled_at, processed_at,
+       locked_until, locked_by, created_at, updated_at
+FROM search_index_jobs;
+
+CREATE OR REPLACE FUNCTION notify_program_index() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO jobs (queue, kind, payload, unique_key)
+    VALUES ('search', 'search.index',
+            jsonb_build_object('program_id', NEW.id, 'action', 'upsert'),
+            'upsert:' || NEW.id)
+    ON CONFLICT (kind, unique_key) WHERE unique_key IS NOT NULL AND status IN ('pending', 'processing', 'failed')
+    DO UPDATE SET scheduled_at = NOW(), updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_program_soft_delete() RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        -- Remove completed/dead jobs for this program (no longer needed)
+        DELETE FROM jobs
+        WHERE kind = 'search.index'
+          AND unique_key IN ('upsert:' || OLD.id, 'delete:' || OLD.id)
+          AND status IN ('completed', 'dead');
+
+        -- Enqueue the delete job (dedupe with active partial unique index)
+        INSERT INTO jobs (queue, kind, payload, unique_key)
+        VALUES ('search', 'search.index',
+                jsonb_build_object('program_id', OLD.id, 'action', 'delete'),
+                'delete:' || OLD.id)
+        ON CONFLICT (kind, unique_key) WHERE unique_key IS NOT NULL AND status IN ('pending', 'processing', 'failed')
+        DO UPDATE SET scheduled_at = NOW(), updated_at = NOW();
+
+        -- Cancel any pending upsert job for this program
+        DELETE FROM jobs
+        WHERE kind = 'search.index'
+          AND unique_key = 'upsert:' || OLD.id
+          AND status IN ('pending', 'failed');
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TABLE search_index_jobs;
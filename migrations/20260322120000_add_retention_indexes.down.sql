@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_import_logs_finished_at;
+DROP INDEX IF EXISTS idx_refresh_tokens_revoked_at;
+DROP INDEX IF EXISTS idx_jobs_finished;
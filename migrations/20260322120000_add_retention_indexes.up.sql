@@ -0,0 +1,13 @@
+-- The retention scheduler deletes the oldest finished rows of each table in
+-- small batches; these indexes let it find them without a scan.
+CREATE INDEX idx_jobs_finished
+    ON jobs (status, updated_at)
+    WHERE status IN ('completed', 'dead');
+
+CREATE INDEX idx_refresh_tokens_revoked_at
+    ON refresh_tokens (revoked_at)
+    WHERE revoked_at IS NOT NULL;
+
+CREATE INDEX idx_import_logs_finished_at
+    ON import_logs (finished_at)
+    WHERE status IN ('completed', 'failed');
@@ -0,0 +1,34 @@
+-- Checks of a search index against Postgres. The API or a worker's schedule
+-- inserts a pending run; a worker claims it, compares every index document
+-- with the programs table and records what it found. Only the first IDs of
+-- each kind of problem are kept; the counts cover all of them.
+CREATE TABLE search_consistency_runs (
+    id             UUID PRIMARY KEY,
+    index_name     VARCHAR(100) NOT NULL,
+    repair         BOOLEAN NOT NULL DEFAULT FALSE,
+    status         VARCHAR(15) NOT NULL DEFAULT 'pending',
+    programs       INT NOT NULL DEFAULT 0,
+    documents      INT NOT NULL DEFAULT 0,
+    missing_count  INT NOT NULL DEFAULT 0,
+    stale_count    INT NOT NULL DEFAULT 0,
+    orphaned_count INT NOT NULL DEFAULT 0,
+    missing_ids    TEXT[] NOT NULL DEFAULT '{}',
+    stale_ids      TEXT[] NOT NULL DEFAULT '{}',
+    orphaned_ids   TEXT[] NOT NULL DEFAULT '{}',
+    repair_jobs    INT NOT NULL DEFAULT 0,
+    error_message  TEXT,
+    requested_by   UUID REFERENCES users(id) ON DELETE SET NULL,
+    started_at     TIMESTAMPTZ,
+    finished_at    TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_consistency_status CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+-- At most one run per index is queued or in progress.
+CREATE UNIQUE INDEX idx_search_consistency_runs_active
+    ON search_consistency_runs(index_name)
+    WHERE status IN ('pending', 'running');
+
+CREATE INDEX idx_search_consistency_runs_created ON search_consistency_runs(created_at DESC);
@@ -0,0 +1,72 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"cms-api/internal/infra/search"
+	"cms-api/internal/modules/program/entity"
+	"cms-api/internal/modules/program/repo"
+	"cms-api/internal/pkg/uuidutil"
+)
+
+func TestPostgresSearch_MatchesTitle(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	var column sql.NullString
+	if err := db.Get(&column, "SELECT column_name FROM information_schema.columns WHERE table_name = 'programs' AND column_name = 'search_vector'"); err != nil && err != sql.ErrNoRows {
+		t.Fatalf("check search_vector column: %v", err)
+	}
+	if !column.Valid {
+		t.Skip("programs.search_vector not found; run migrations before tests")
+	}
+
+	id, err := uuidutil.NewV7String()
+	if err != nil {
+		t.Fatalf("uuid: %v", err)
+	}
+
+	ctx := context.Background()
+	p := &entity.Program{
+		ID:          id,
+		Title:       "Zanzibar spice routes",
+		Description: "البحارة والتجارة",
+		ProgramType: "documentary",
+		Status:      "active",
+	}
+	if err := repo.New(db).Create(ctx, p); err != nil {
+		t.Fatalf("create program: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = db.ExecContext(context.Background(), "DELETE FROM programs WHERE id = $1", id)
+	})
+
+	searcher := search.NewPostgres(db)
+	for _, q := range []string{"zanzibar spice", "البحارة"} {
+		res, err := searcher.Search(ctx, "programs", search.SearchRequest{
+			Query:  q,
+			Filter: `status = "active" AND program_type = "documentary"`,
+			Sort:   []string{"published_at:desc"},
+		})
+		if err != nil {
+			t.Fatalf("search %q: %v", q, err)
+		}
+
+		found := false
+		for _, h := range res.Hits {
+			var doc struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(h, &doc); err != nil {
+				t.Fatalf("decode hit: %v", err)
+			}
+			found = found || doc.ID == id
+		}
+		if !found {
+			t.Fatalf("search %q: program %s not found in %d hits", q, id, len(res.Hits))
+		}
+	}
+}
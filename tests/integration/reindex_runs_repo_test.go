@@ -0,0 +1,131 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"cms-api/internal/modules/worker/entity"
+	"cms-api/internal/modules/worker/repo"
+	"cms-api/internal/pkg/apperror"
+	"cms-api/internal/pkg/uuidutil"
+)
+
+func TestReindexRunRepo_CreateClaimUpdate(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	var table sql.NullString
+	if err := db.Get(&table, "SELECT to_regclass('search_reindex_runs')::TEXT"); err != nil {
+		t.Fatalf("check search_reindex_runs table: %v", err)
+	}
+	if !table.Valid {
+		t.Skip("search_reindex_runs not found; run migrations before tests")
+	}
+
+	ctx := context.Background()
+	r := repo.New(db)
+	const index = "integration_test"
+	t.Cleanup(func() {
+		_, _ = db.ExecContext(context.Background(), "DELETE FROM search_reindex_runs WHERE index_name = $1", index)
+	})
+
+	newRun := func() *entity.ReindexRun {
+		id, err := uuidutil.NewV7String()
+		if err != nil {
+			t.Fatalf("uuid: %v", err)
+		}
+		return &entity.ReindexRun{ID: id, IndexName: index, TargetIndex: index + "_v1"}
+	}
+
+	run := newRun()
+	if err := r.CreateReindexRun(ctx, run); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if run.Status != entity.ReindexStatusPending {
+		t.Fatalf("expected pending run, got %q", run.Status)
+	}
+	if err := r.CreateReindexRun(ctx, newRun()); !errors.Is(err, apperror.ErrConflict) {
+		t.Fatalf("expected conflict for a second active run, got %v", err)
+	}
+
+	claimed, err := r.ClaimReindexRun(ctx, time.Hour)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if claimed == nil || claimed.ID != run.ID || claimed.Status != entity.ReindexStatusRunning || !claimed.StartedAt.Valid {
+		t.Fatalf("unexpected claimed run %+v", claimed)
+	}
+
+	claimed.Status = entity.ReindexStatusCompleted
+	claimed.IndexedDocuments = 3
+	claimed.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := r.UpdateReindexRun(ctx, claimed); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	latest, err := r.GetLatestReindexRun(ctx, index)
+	if err != nil {
+		t.Fatalf("get latest: %v", err)
+	}
+	if latest.Status != entity.ReindexStatusCompleted || latest.IndexedDocuments != 3 {
+		t.Fatalf("unexpected latest run %+v", latest)
+	}
+	if err := r.CreateReindexRun(ctx, newRun()); err != nil {
+		t.Fatalf("create after completion: %v", err)
+	}
+}
+
+func TestReindexRunRepo_ClaimStaleRunStartsOver(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	var table sql.NullString
+	if err := db.Get(&table, "SELECT to_regclass('search_reindex_runs')::TEXT"); err != nil {
+		t.Fatalf("check search_reindex_runs table: %v", err)
+	}
+	if !table.Valid {
+		t.Skip("search_reindex_runs not found; run migrations before tests")
+	}
+
+	ctx := context.Background()
+	r := repo.New(db)
+	const index = "integration_test_stale"
+	t.Cleanup(func() {
+		_, _ = db.ExecContext(context.Background(), "DELETE FROM search_reindex_runs WHERE index_name = $1", index)
+	})
+
+	id, err := uuidutil.NewV7String()
+	if err != nil {
+		t.Fatalf("uuid: %v", err)
+	}
+	if err := r.CreateReindexRun(ctx, &entity.ReindexRun{ID: id, IndexName: index, TargetIndex: index + "_v1"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	run, err := r.ClaimReindexRun(ctx, time.Hour)
+	if err != nil || run == nil || run.ID != id {
+		t.Fatalf("claim: %+v, %v", run, err)
+	}
+
+	// The worker swapped the index, recorded an error and then stopped.
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+	run.SwappedAt = now
+	run.FinishedAt = now
+	run.ErrorMessage = sql.NullString{String: "boom", Valid: true}
+	if err := r.UpdateReindexRun(ctx, run); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE search_reindex_runs SET updated_at = NOW() - INTERVAL '2 hours' WHERE id = $1", id); err != nil {
+		t.Fatalf("age run: %v", err)
+	}
+
+	taken, err := r.ClaimReindexRun(ctx, time.Hour)
+	if err != nil {
+		t.Fatalf("take over: %v", err)
+	}
+	if taken == nil || taken.ID != id || taken.SwappedAt.Valid || taken.FinishedAt.Valid || taken.ErrorMessage.Valid {
+		t.Fatalf("expected the taken-over run to start over, got %+v", taken)
+	}
+}
@@ -0,0 +1,46 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cms-api/internal/modules/retention/repo"
+)
+
+func TestRetentionRepo_DeleteJobsInBatches(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	requireJobsTable(t, db)
+
+	ctx := context.Background()
+	r := repo.New(db)
+	t.Cleanup(func() {
+		_, _ = db.Exec("DELETE FROM jobs WHERE kind = 'retention.test'")
+	})
+
+	if _, err := db.ExecContext(ctx, `
+		INSERT INTO jobs (kind, status, updated_at)
+		VALUES ('retention.test', 'completed', NOW() - INTERVAL '3 hours'),
+		       ('retention.test', 'completed', NOW() - INTERVAL '2 hours'),
+		       ('retention.test', 'completed', NOW()),
+		       ('retention.test', 'failed', NOW() - INTERVAL '3 hours')`); err != nil {
+		t.Fatalf("insert jobs: %v", err)
+	}
+
+	cutoff := time.Now().Add(-time.Hour)
+	if n, err := r.DeleteJobs(ctx, "completed", cutoff, 1); err != nil || n != 1 {
+		t.Fatalf("expected one job per batch, got %d, %v", n, err)
+	}
+	if n, err := r.DeleteJobs(ctx, "completed", cutoff, 10); err != nil || n != 1 {
+		t.Fatalf("expected the remaining old job deleted, got %d, %v", n, err)
+	}
+
+	var left []string
+	if err := db.SelectContext(ctx, &left, "SELECT status FROM jobs WHERE kind = 'retention.test' ORDER BY status"); err != nil {
+		t.Fatalf("list jobs: %v", err)
+	}
+	if len(left) != 2 || left[0] != "completed" || left[1] != "failed" {
+		t.Fatalf("expected the recent and the failed job kept, got %v", left)
+	}
+}
@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cms-api/internal/modules/worker/repo"
+)
+
+func TestWorkerRepo_ListProgramVersions(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	requireJobsTable(t, db)
+
+	ctx := context.Background()
+	r := repo.New(db)
+	live := insertIndexedProgram(t, db, "Consistency live")
+	deleted := insertIndexedProgram(t, db, "Consistency deleted")
+	if _, err := db.Exec("UPDATE programs SET deleted_at = NOW() WHERE id = $1", deleted); err != nil {
+		t.Fatalf("delete program: %v", err)
+	}
+
+	var updatedAt time.Time
+	if err := db.Get(&updatedAt, "SELECT updated_at FROM programs WHERE id = $1", live); err != nil {
+		t.Fatalf("get updated_at: %v", err)
+	}
+
+	versions := map[string]time.Time{}
+	after := ""
+	for {
+		page, err := r.ListProgramVersions(ctx, after, 500)
+		if err != nil {
+			t.Fatalf("list versions: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, v := range page {
+			if v.ID <= after {
+				t.Fatalf("versions out of order: %s after %s", v.ID, after)
+			}
+			versions[v.ID] = v.UpdatedAt
+			after = v.ID
+		}
+	}
+
+	if got, ok := versions[live]; !ok || !got.Equal(updatedAt) {
+		t.Fatalf("expected live program updated at %s, got %s (listed %v)", updatedAt, got, ok)
+	}
+	if _, ok := versions[deleted]; ok {
+		t.Fatal("deleted program listed")
+	}
+
+	doc, err := r.GetProgramForIndex(ctx, live)
+	if err != nil {
+		t.Fatalf("get program: %v", err)
+	}
+	if doc.UpdatedAt != updatedAt.Format(time.RFC3339) {
+		t.Fatalf("expected document updated_at %s, got %q", updatedAt.Format(time.RFC3339), doc.UpdatedAt)
+	}
+}
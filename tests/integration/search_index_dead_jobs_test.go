@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cms-api/internal/modules/queue/entity"
+	"cms-api/internal/modules/queue/repo"
+)
+
+func TestSearchIndexDeadJobs_RetryAndDiscard(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	requireJobsTable(t, db)
+
+	ctx := context.Background()
+	r := repo.New(db)
+	programID := insertIndexedProgram(t, db, "Dead letter test")
+	const kind = "search.index"
+
+	// Two dead upserts for the program, the second newer.
+	var olderID, newerID string
+	if err := db.GetContext(ctx, &olderID, `
+		UPDATE jobs
+		SET status = 'dead', attempts = 5, last_error = 'boom', updated_at = NOW() - INTERVAL '1 minute'
+		WHERE kind = 'search.index' AND payload->>'program_id' = $1
+		RETURNING id`, programID); err != nil {
+		t.Fatalf("kill job: %v", err)
+	}
+	if err := db.GetContext(ctx, &newerID, `
+		INSERT INTO jobs (queue, kind, payload, unique_key, status, attempts, last_error)
+		VALUES ('search', 'search.index', jsonb_build_object('program_id', $1::text, 'action', 'upsert'),
+			'upsert:' || $1, 'dead', 5, 'boom')
+		RETURNING id`, programID); err != nil {
+		t.Fatalf("insert dead job: %v", err)
+	}
+
+	filter := entity.JobFilter{Kind: kind, Payload: []byte(`{"program_id": "` + programID + `"}`)}
+	dead, err := r.ListJobs(ctx, entity.JobFilter{Kind: kind, Status: "dead", Payload: filter.Payload}, 10, nil, "")
+	if err != nil || len(dead) != 2 || dead[0].ID != newerID {
+		t.Fatalf("expected both dead jobs newest first, got %d, %v", len(dead), err)
+	}
+	page, err := r.ListJobs(ctx, filter, 10, &dead[0].UpdatedAt, dead[0].ID)
+	if err != nil || len(page) != 1 || page[0].ID != olderID {
+		t.Fatalf("expected the older job after the cursor, got %d, %v", len(page), err)
+	}
+
+	retried, err := r.RetryDeadJobs(ctx, kind, []string{olderID, newerID})
+	if err != nil || len(retried) != 1 || retried[0] != newerID {
+		t.Fatalf("expected only the newest dead job retried, got %v, %v", retried, err)
+	}
+	// The requeued job now covers the program.
+	if retried, err := r.RetryDeadJobs(ctx, kind, []string{olderID}); err != nil || len(retried) != 0 {
+		t.Fatalf("expected no retry while an active job exists, got %v, %v", retried, err)
+	}
+
+	discarded, err := r.DiscardDeadJobs(ctx, kind, []string{olderID, newerID})
+	if err != nil || len(discarded) != 1 || discarded[0] != olderID {
+		t.Fatalf("expected only the dead job discarded, got %v, %v", discarded, err)
+	}
+
+	stats, err := r.JobStats(ctx, kind, time.Hour)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Pending < 1 || !stats.OldestPendingAt.Valid {
+		t.Fatalf("expected the requeued job counted as pending, got %+v", stats)
+	}
+}
@@ -0,0 +1,92 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"cms-api/internal/modules/queue/repo"
+	"cms-api/internal/pkg/apperror"
+	"cms-api/internal/pkg/uuidutil"
+)
+
+// requireJobsTable skips the test unless the generic jobs table exists.
+func requireJobsTable(t *testing.T, db *sqlx.DB) {
+	t.Helper()
+
+	var hasJobs bool
+	if err := db.Get(&hasJobs, "SELECT to_regclass('public.jobs') IS NOT NULL"); err != nil {
+		t.Fatalf("check jobs table: %v", err)
+	}
+	if !hasJobs {
+		t.Skip("jobs table not found; run migrations before tests")
+	}
+}
+
+// insertIndexedProgram inserts an inactive program, which the insert trigger
+// gives a search.index upsert job, and removes both when the test ends.
+func insertIndexedProgram(t *testing.T, db *sqlx.DB, title string) string {
+	t.Helper()
+
+	programID, err := uuidutil.NewV7String()
+	if err != nil {
+		t.Fatalf("uuid: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO programs (id, title, description, program_type, thumbnail, video_url, status)
+		VALUES ($1, $2, '', 'podcast', '', '', 'inactive')`, programID, title); err != nil {
+		t.Fatalf("insert program: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = db.Exec("DELETE FROM programs WHERE id = $1", programID)
+		_, _ = db.Exec("DELETE FROM jobs WHERE kind = 'search.index' AND payload->>'program_id' = $1", programID)
+	})
+	return programID
+}
+
+func TestSearchIndexJobLeases_ReapAndFence(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	requireJobsTable(t, db)
+
+	ctx := context.Background()
+	r := repo.New(db)
+	programID := insertIndexedProgram(t, db, "Lease test")
+
+	var jobID string
+	if err := db.GetContext(ctx, &jobID, `
+		UPDATE jobs
+		SET status = 'processing', locked_by = 'crashed-worker', locked_until = NOW() - INTERVAL '1 second'
+		WHERE kind = 'search.index' AND queue = 'search' AND payload->>'program_id' = $1
+		RETURNING id`, programID); err != nil {
+		t.Fatalf("simulate crashed claim: %v", err)
+	}
+
+	if n, err := r.ExtendJobLeases(ctx, "other-worker", []string{jobID}, time.Minute); err != nil || n != 0 {
+		t.Fatalf("expected no lease renewed for another worker, got %d, %v", n, err)
+	}
+	if _, err := r.ReleaseExpiredJobs(ctx); err != nil {
+		t.Fatalf("release expired jobs: %v", err)
+	}
+
+	var job struct {
+		Status    string  `db:"status"`
+		Attempts  int     `db:"attempts"`
+		LastError string  `db:"last_error"`
+		LockedBy  *string `db:"locked_by"`
+	}
+	if err := db.GetContext(ctx, &job, "SELECT status, attempts, last_error, locked_by FROM jobs WHERE id = $1", jobID); err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.Status != "pending" || job.Attempts != 1 || !strings.Contains(job.LastError, "crashed-worker") || job.LockedBy != nil {
+		t.Fatalf("unexpected released job %+v", job)
+	}
+
+	if err := r.MarkCompleted(ctx, jobID, "crashed-worker"); !errors.Is(err, apperror.ErrConflict) {
+		t.Fatalf("expected conflict for a lost lease, got %v", err)
+	}
+}
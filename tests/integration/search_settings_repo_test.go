@@ -0,0 +1,60 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+
+	"cms-api/internal/modules/searchsettings/entity"
+	"cms-api/internal/modules/searchsettings/repo"
+)
+
+func TestSearchSettingsRepo_SaveAndGet(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	var table sql.NullString
+	if err := db.Get(&table, "SELECT to_regclass('search_settings')::TEXT"); err != nil {
+		t.Fatalf("check search_settings table: %v", err)
+	}
+	if !table.Valid {
+		t.Skip("search_settings not found; run migrations before tests")
+	}
+
+	ctx := context.Background()
+	r := repo.New(db)
+	const index = "integration_test"
+	t.Cleanup(func() {
+		_, _ = db.ExecContext(context.Background(), "DELETE FROM search_settings WHERE index_name = $1", index)
+	})
+
+	settings := &entity.Settings{
+		IndexName:    index,
+		Synonyms:     [][]string{{"podcast", "بودكاست"}},
+		StopWords:    []string{"the"},
+		RankingRules: []string{"words", "sort"},
+		TypoTolerance: entity.TypoTolerance{
+			Enabled:             true,
+			MinWordSizeOneTypo:  4,
+			MinWordSizeTwoTypos: 8,
+		},
+	}
+	if err := r.Save(ctx, settings); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if settings.UpdatedAt.IsZero() {
+		t.Fatal("expected updated_at to be set")
+	}
+
+	got, err := r.Get(ctx, index)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(got.Synonyms) != 1 || !slices.Equal(got.Synonyms[0], settings.Synonyms[0]) {
+		t.Fatalf("unexpected synonyms %v", got.Synonyms)
+	}
+	if !slices.Equal(got.RankingRules, settings.RankingRules) || got.TypoTolerance.MinWordSizeOneTypo != 4 {
+		t.Fatalf("unexpected settings %+v", got)
+	}
+}